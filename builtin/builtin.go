@@ -8,6 +8,7 @@ package builtin
 import (
 	"fmt"
 	"math/big"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/go-python/gpython/compile"
@@ -36,14 +37,14 @@ func init() {
 		py.MustNewMethod("divmod", builtin_divmod, 0, divmod_doc),
 		py.MustNewMethod("eval", py.InternalMethodEval, 0, eval_doc),
 		py.MustNewMethod("exec", py.InternalMethodExec, 0, exec_doc),
-		// py.MustNewMethod("format", builtin_format, 0, format_doc),
+		py.MustNewMethod("format", builtin_format, 0, format_doc),
 		py.MustNewMethod("getattr", builtin_getattr, 0, getattr_doc),
 		py.MustNewMethod("globals", py.InternalMethodGlobals, 0, globals_doc),
 		py.MustNewMethod("hasattr", builtin_hasattr, 0, hasattr_doc),
-		// py.MustNewMethod("hash", builtin_hash, 0, hash_doc),
+		py.MustNewMethod("hash", builtin_hash, 0, hash_doc),
 		py.MustNewMethod("hex", builtin_hex, 0, hex_doc),
 		// py.MustNewMethod("id", builtin_id, 0, id_doc),
-		// py.MustNewMethod("input", builtin_input, 0, input_doc),
+		py.MustNewMethod("input", builtin_input, 0, input_doc),
 		py.MustNewMethod("isinstance", builtin_isinstance, 0, isinstance_doc),
 		// py.MustNewMethod("issubclass", builtin_issubclass, 0, issubclass_doc),
 		py.MustNewMethod("iter", builtin_iter, 0, iter_doc),
@@ -64,18 +65,18 @@ func init() {
 		py.MustNewMethod("sum", builtin_sum, 0, sum_doc),
 		// py.MustNewMethod("vars", builtin_vars, 0, vars_doc),
 	}
-	globals := py.StringDict{
-		"None":     py.None,
-		"Ellipsis": py.Ellipsis,
-		"False":    py.False,
-		"True":     py.True,
-		"bool":     py.BoolType,
-		// "memoryview":     py.MemoryViewType,
-		// "bytearray":      py.ByteArrayType,
+	globals := py.NewStringDictFromMap(map[string]py.Object{
+		"None":        py.None,
+		"Ellipsis":    py.Ellipsis,
+		"False":       py.False,
+		"True":        py.True,
+		"bool":        py.BoolType,
+		"memoryview":  py.MemoryViewType,
+		"bytearray":   py.ByteArrayType,
 		"bytes":       py.BytesType,
 		"classmethod": py.ClassMethodType,
 		"complex":     py.ComplexType,
-		"dict":        py.StringDictType, // FIXME
+		"dict":        py.DictType,
 		"enumerate":   py.EnumerateType,
 		// "filter":         py.FilterType,
 		"float":     py.FloatType,
@@ -105,6 +106,7 @@ func init() {
 		"BrokenPipeError":           py.BrokenPipeError,
 		"BufferError":               py.BufferError,
 		"BytesWarning":              py.BytesWarning,
+		"CancelledError":            py.CancelledError,
 		"ChildProcessError":         py.ChildProcessError,
 		"ConnectionAbortedError":    py.ConnectionAbortedError,
 		"ConnectionError":           py.ConnectionError,
@@ -121,6 +123,7 @@ func init() {
 		"GeneratorExit":             py.GeneratorExit,
 		"IOError":                   py.OSError,
 		"ImportError":               py.ImportError,
+		"ModuleNotFoundError":       py.ModuleNotFoundError,
 		"ImportWarning":             py.ImportWarning,
 		"IndentationError":          py.IndentationError,
 		"IndexError":                py.IndexError,
@@ -139,7 +142,9 @@ func init() {
 		"PendingDeprecationWarning": py.PendingDeprecationWarning,
 		"PermissionError":           py.PermissionError,
 		"ProcessLookupError":        py.ProcessLookupError,
+		"RecursionError":            py.RecursionError,
 		"ReferenceError":            py.ReferenceError,
+		"ResourceError":             py.ResourceError,
 		"ResourceWarning":           py.ResourceWarning,
 		"RuntimeError":              py.RuntimeError,
 		"RuntimeWarning":            py.RuntimeWarning,
@@ -161,8 +166,13 @@ func init() {
 		"ValueError":                py.ValueError,
 		"Warning":                   py.Warning,
 		"ZeroDivisionError":         py.ZeroDivisionError,
-	}
-	py.NewModule("builtins", builtin_doc, methods, globals)
+	})
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "builtins",
+		Doc:     builtin_doc,
+		Methods: methods,
+		Globals: globals,
+	})
 }
 
 const print_doc = `print(value, ..., sep=' ', end='\\n', file=sys.stdout, flush=False)
@@ -178,11 +188,20 @@ func builtin_print(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Obje
 	var (
 		sepObj py.Object = py.String(" ")
 		endObj py.Object = py.String("\n")
-		file   py.Object = py.MustGetModule("sys").Globals["stdout"]
+		file   py.Object
 		flush  py.Object
 	)
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	sys, err := ctx.GetModule("sys")
+	if err != nil {
+		return nil, err
+	}
+	file = sys.Globals.Get("stdout")
 	kwlist := []string{"sep", "end", "file", "flush"}
-	err := py.ParseTupleAndKeywords(nil, kwargs, "|ssOO:print", kwlist, &sepObj, &endObj, &file, &flush)
+	err = py.ParseTupleAndKeywords(nil, kwargs, "|ssOO:print", kwlist, &sepObj, &endObj, &file, &flush)
 	if err != nil {
 		return nil, err
 	}
@@ -405,10 +424,10 @@ func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict
 	bases := args[2:]
 
 	if kwargs != nil {
-		mkw = kwargs.Copy()      // Don't modify kwds passed in!
-		meta := mkw["metaclass"] // _PyDict_GetItemId(mkw, &PyId_metaclass)
+		mkw = kwargs.Copy()          // Don't modify kwds passed in!
+		meta := mkw.Get("metaclass") // _PyDict_GetItemId(mkw, &PyId_metaclass)
 		if meta != nil {
-			delete(mkw, "metaclass")
+			mkw.Delete("metaclass")
 			// metaclass is explicitly given, check if it's indeed a class
 			_, isclass = meta.(*py.Type)
 		}
@@ -437,7 +456,7 @@ func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict
 	}
 	// else: meta is not a class, so we cannot do the metaclass
 	// calculation, so we will use the explicitly given object as it is
-	prep = meta.Type().Dict["___prepare__"] // FIXME should be using _PyObject_GetAttr
+	prep = meta.Type().Dict.Get("___prepare__") // FIXME should be using _PyObject_GetAttr
 	if prep == nil {
 		ns = py.NewStringDict()
 	} else {
@@ -445,11 +464,14 @@ func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict
 		if err != nil {
 			return nil, err
 		}
-		ns = nsObj.(py.StringDict)
+		ns, err = py.DictAsStringDict(nsObj)
+		if err != nil {
+			return nil, err
+		}
 	}
 	// fmt.Printf("Calling %v with %v and %v\n", fn.Name, fn.Globals, ns)
 	// fmt.Printf("Code = %#v\n", fn.Code)
-	cell, err = py.VmRun(fn.Globals, ns, fn.Code, fn.Closure)
+	cell, err = py.VmRun(fn.Context, fn.Globals, ns, fn.Code, fn.Closure)
 	if err != nil {
 		return nil, err
 	}
@@ -566,7 +588,11 @@ func builtin_open(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Objec
 		return nil, py.ExceptionNewf(py.NotImplementedError, "opener not implemented yet")
 	}
 
-	return py.OpenFile(string(filename.(py.String)),
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.OpenFile(string(filename.(py.String)),
 		string(mode.(py.String)),
 		int(buffering.(py.Int)))
 }
@@ -589,14 +615,11 @@ func builtin_ord(self, obj py.Object) (py.Object, error) {
 		if size == runeSize && rune != utf8.RuneError {
 			return py.Int(rune), nil
 		}
-	//case py.ByteArray:
-	// XXX Hopefully this is temporary
-	// FIXME implement
-	// size = PyByteArray_GET_SIZE(obj)
-	// if size == 1 {
-	// 	ord = (long)((char) * PyByteArray_AS_STRING(obj))
-	// 	return PyLong_FromLong(ord)
-	// }
+	case *py.ByteArray:
+		size = len(x.Bytes)
+		if size == 1 {
+			return py.Int(x.Bytes[0]), nil
+		}
 	default:
 		return nil, py.ExceptionNewf(py.TypeError, "ord() expected string of length 1, but %s found", obj.Type().Name)
 	}
@@ -604,6 +627,35 @@ func builtin_ord(self, obj py.Object) (py.Object, error) {
 	return nil, py.ExceptionNewf(py.TypeError, "ord() expected a character, but string of length %d found", size)
 }
 
+const format_doc = `format(value[, format_spec]) -> string
+
+Returns value.__format__(format_spec)
+format_spec defaults to ""`
+
+func builtin_format(self py.Object, args py.Tuple) (py.Object, error) {
+	var value py.Object
+	var spec py.Object = py.String("")
+	err := py.ParseTuple(args, "O|U:format", &value, &spec)
+	if err != nil {
+		return nil, err
+	}
+	return py.Format(value, string(spec.(py.String)))
+}
+
+// checkAttr checks the policy of the interpreter self belongs to
+// allows access to the attribute name
+func checkAttr(self py.Object, name py.Object) error {
+	key, err := py.AttributeName(name)
+	if err != nil {
+		return err
+	}
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return err
+	}
+	return ctx.CheckAttr(key)
+}
+
 const getattr_doc = `getattr(object, name[, default]) -> value
 
 Get a named attribute from an object; getattr(x, 'y') is equivalent to x.y.
@@ -618,15 +670,23 @@ func builtin_getattr(self py.Object, args py.Tuple) (py.Object, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = checkAttr(self, name)
+	if err != nil {
+		return nil, err
+	}
 
 	result, err = py.GetAttr(v, name)
 	if err != nil {
 		if dflt == nil {
 			return nil, err
 		}
-		result = dflt
+		return dflt, nil
 	}
-	return result, nil
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.BindContext(ctx, result), nil
 }
 
 const hasattr_doc = `hasattr(object, name) -> bool
@@ -641,6 +701,10 @@ func builtin_hasattr(self py.Object, args py.Tuple) (py.Object, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = checkAttr(self, name)
+	if err != nil {
+		return nil, err
+	}
 	_, err = py.GetAttr(v, name)
 	return py.NewBool(err == nil), nil
 }
@@ -659,6 +723,10 @@ func builtin_setattr(self py.Object, args py.Tuple) (py.Object, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = checkAttr(self, name)
+	if err != nil {
+		return nil, err
+	}
 
 	return py.SetAttr(v, name, value)
 }
@@ -689,6 +757,10 @@ func builtin_delattr(self py.Object, args py.Tuple) (py.Object, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = checkAttr(self, name)
+	if err != nil {
+		return nil, err
+	}
 
 	err = py.DeleteAttr(v, name)
 	if err != nil {
@@ -726,7 +798,15 @@ func builtin_compile(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Ob
 	// start := []int{Py_file_input, Py_eval_input, Py_single_input}
 	var result py.Object
 
-	err := py.ParseTupleAndKeywords(args, kwargs, "Oss|iii:compile", kwlist,
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.CheckBuiltin("compile")
+	if err != nil {
+		return nil, err
+	}
+	err = py.ParseTupleAndKeywords(args, kwargs, "Oss|iii:compile", kwlist,
 		&cmd,
 		&filename,
 		&startstr,
@@ -872,6 +952,63 @@ func builtin_hex(self, v py.Object) (py.Object, error) {
 	return py.String(str), nil
 }
 
+const input_doc = `input([prompt]) -> string
+
+Read a string from standard input.  The trailing newline is stripped.
+If the user hits EOF (Unix: Ctl-D, Windows: Ctl-Z+Return), raise EOFError.
+The prompt string, if given, is printed without a trailing newline
+before reading.`
+
+func builtin_input(self py.Object, args py.Tuple) (py.Object, error) {
+	var prompt py.Object = py.None
+	err := py.UnpackTuple(args, nil, "input", 0, 1, &prompt)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	sys, err := ctx.GetModule("sys")
+	if err != nil {
+		return nil, err
+	}
+	if prompt != py.None {
+		prompt, err = py.Str(prompt)
+		if err != nil {
+			return nil, err
+		}
+		stdout := sys.Globals.Get("stdout")
+		_, err = callMethod(stdout, "write", py.Tuple{prompt})
+		if err != nil {
+			return nil, err
+		}
+		// Errors flushing are ignored as some writers can't be flushed
+		_, _ = callMethod(stdout, "flush", nil)
+	}
+	line, err := callMethod(sys.Globals.Get("stdin"), "readline", nil)
+	if err != nil {
+		return nil, err
+	}
+	str, ok := line.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "object.readline() returned non-string")
+	}
+	if str == "" {
+		return nil, py.ExceptionNewf(py.EOFError, "EOF when reading a line")
+	}
+	return py.String(strings.TrimSuffix(string(str), "\n")), nil
+}
+
+// callMethod calls the method called name of obj with args
+func callMethod(obj py.Object, name string, args py.Tuple) (py.Object, error) {
+	method, err := py.GetAttrString(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(method, args, nil)
+}
+
 const isinstance_doc = `isinstance(obj, class_or_tuple) -> bool
 
 Return whether an object is an instance of a class or of a subclass thereof.
@@ -946,6 +1083,19 @@ func builtin_iter(self py.Object, args py.Tuple) (py.Object, error) {
 
 // For code see vm/builtin.go
 
+const hash_doc = `hash(object) -> integer
+
+Return a hash value for the object.  Two objects with the same value have
+the same hash value.  The reverse is not necessarily true, but likely.`
+
+func builtin_hash(self, obj py.Object) (py.Object, error) {
+	h, err := py.Hash(obj)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(h), nil
+}
+
 const len_doc = `len(object) -> integer
 
 Return the number of items of a sequence or mapping.`
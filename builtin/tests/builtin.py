@@ -2,7 +2,7 @@
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
 
-from libtest import assertRaises
+from libtest import assertRaises, assertRaisesText
 
 doc="abs"
 assert abs(0) == 0
@@ -25,7 +25,12 @@ doc="ascii"
 assert ascii('hello world') == "'hello world'"
 assert ascii('안녕 세상') == "'\\uc548\\ub155 \\uc138\\uc0c1'"
 assert ascii(chr(0x10001)) == "'\\U00010001'"
+assert ascii('caf\xe9') == "'caf\\xe9'"
 assert ascii('안녕 gpython') == "'\\uc548\\ub155 gpython'"
+assert ascii('\x7f') == "'\\x7f'"
+assert ascii('\xa0\xff') == "'\\xa0\\xff'"
+assert repr('\x7f') == "'\\x7f'"
+assert repr('\xa0\xe9') == "'\\xa0\xe9'"
 
 doc="bin"
 assert bin(False) == '0b0'
@@ -68,6 +73,28 @@ glob = {"a":100}
 assert exec("b = a+100", glob) == None
 assert glob["b"] == 200
 
+doc="format"
+class F:
+    def __format__(self, spec):
+        return "F(" + spec + ")"
+assert format(F()) == "F()"
+assert format(F(), "xyz") == "F(xyz)"
+assert "{:abc} {!s:5}".format(F(), 1) == "F(abc) 1    "
+class Bad:
+    def __format__(self, spec):
+        return 1
+assertRaisesText(TypeError, "__format__ must return a str, not int", format, Bad())
+class Plain:
+    def __str__(self):
+        return "plain"
+assert format(Plain()) == "plain"
+assert "{}".format(Plain()) == "plain"
+assertRaisesText(TypeError, "unsupported format string passed to Plain.__format__", format, Plain(), "5")
+assertRaises(TypeError, format, 1, 2)
+assert (42).__format__("x") == "2a"
+assert "ab".__format__(">3") == " ab"
+assert 1.5.__format__("") == "1.5"
+
 doc="getattr"
 class C:
     def __init__(self):
@@ -153,6 +180,63 @@ except ValueError:
     ok = True
 assert ok, "ValueError not raised"
 
+doc="hash"
+assert hash(0) == 0
+assert hash(1) == 1
+assert hash(-1) == -2
+assert hash(2**61) == 1
+assert hash(-2**61) == -1 - 1
+assert hash(1) == hash(1.0) == hash(True) == hash(1+0j)
+assert hash(0) == hash(0.0) == hash(False) == hash(0j)
+assert hash(2**70) == hash(float(2**70))
+assert hash(1.5) == hash(complex(1.5, 0))
+assert hash(1+2j) == 2000007
+assert hash("abc") == hash("ab" + "c")
+assert hash(b"abc") == hash(bytes([97, 98, 99]))
+assert hash((1, "a", 2.0)) == hash((1.0, "a", 2))
+assert hash(frozenset([1, 2])) == hash(frozenset([2, 1]))
+assert hash(None) == hash(None)
+assert isinstance(hash(len), int)
+def f():
+    pass
+assert hash(f) == hash(f)
+assertRaises(TypeError, hash, [])
+assertRaises(TypeError, hash, {})
+assertRaises(TypeError, hash, {1})
+assertRaises(TypeError, hash, (1, []))
+
+class Plain:
+    pass
+p = Plain()
+assert hash(p) == hash(p)
+assert {p: 1}[p] == 1
+
+class WithEq:
+    def __eq__(self, other):
+        return True
+assertRaises(TypeError, hash, WithEq())
+
+class WithEqAndHash:
+    def __eq__(self, other):
+        return isinstance(other, WithEqAndHash)
+    def __hash__(self):
+        return 42
+assert hash(WithEqAndHash()) == 42
+assert len({WithEqAndHash(), WithEqAndHash()}) == 1
+
+class SubWithEq(WithEqAndHash):
+    pass
+assert hash(SubWithEq()) == 42
+
+class NoHash:
+    __hash__ = None
+assertRaises(TypeError, hash, NoHash())
+
+class BadHash:
+    def __hash__(self):
+        return "x"
+assertRaises(TypeError, hash, BadHash())
+
 doc="hex"
 assert hex( 0)=="0x0",    "hex(0)"
 assert hex( 1)=="0x1",    "hex(1)"
@@ -1,6 +1,6 @@
 module github.com/go-python/gpython
 
-go 1.12
+go 1.16
 
 require (
 	github.com/gopherjs/gopherwasm v1.0.0 // indirect
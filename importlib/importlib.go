@@ -7,15 +7,28 @@
 package py
 
 import (
-	"log"
+	"bytes"
 
 	"github.com/go-python/gpython/marshal"
+	"github.com/go-python/gpython/py"
 )
 
-// Load the frozen module
+// Register the frozen module
 func init() {
-	_, err := marshal.LoadFrozenModule("importlib", data)
-	log.Fatalf("Failed to load importlib: %v", err)
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "importlib",
+		Init: initContext,
+	})
+}
+
+// Runs the frozen module in the interpreter the module was made in
+func initContext(m *py.Module) error {
+	obj, err := marshal.ReadObject(bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	_, err = py.VmRun(m.Context, m.Globals, m.Globals, obj.(*py.Code), nil)
+	return err
 }
 
 // Auto-generated by Modules/_freeze_importlib.c
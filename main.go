@@ -25,7 +25,7 @@ import (
 	"github.com/go-python/gpython/marshal"
 	_ "github.com/go-python/gpython/math"
 	"github.com/go-python/gpython/py"
-	pysys "github.com/go-python/gpython/sys"
+	_ "github.com/go-python/gpython/sys"
 	_ "github.com/go-python/gpython/time"
 	"github.com/go-python/gpython/vm"
 )
@@ -35,6 +35,7 @@ var (
 	// Flags
 	debug      = flag.Bool("d", false, "Print lots of debugging")
 	cpuprofile = flag.String("cpuprofile", "", "Write cpu profile to file")
+	noBytecode = flag.Bool("B", false, "Don't write .pyc files on import; also PYTHONDONTWRITEBYTECODE=x")
 )
 
 // syntaxError prints the syntax
@@ -62,7 +63,10 @@ func main() {
 	flag.Usage = syntaxError
 	flag.Parse()
 	args := flag.Args()
-	py.MustGetModule("sys").Globals["argv"] = pysys.MakeArgv(args)
+	ctx := py.NewContext(py.ContextOpts{
+		Argv:              args,
+		DontWriteBytecode: *noBytecode || os.Getenv("PYTHONDONTWRITEBYTECODE") != "",
+	})
 	if len(args) == 0 {
 
 		fmt.Printf("Python 3.4.0 (%s, %s)\n", commit, date)
@@ -70,7 +74,7 @@ func main() {
 		fmt.Printf("- os/arch: %s/%s\n", runtime.GOOS, runtime.GOARCH)
 		fmt.Printf("- go version: %s\n", runtime.Version())
 
-		cli.RunREPL()
+		cli.RunREPL(ctx)
 		return
 	}
 	prog := args[0]
@@ -115,11 +119,11 @@ func main() {
 		log.Fatalf("Failed to close %q: %v", prog, err)
 	}
 	code := obj.(*py.Code)
-	module := py.NewModule("__main__", "", nil, nil)
-	module.Globals["__file__"] = py.String(prog)
-	res, err := vm.Run(module.Globals, module.Globals, code, nil)
+	module := ctx.NewModule("__main__", "", nil, nil)
+	module.Globals.Set("__file__", py.String(prog))
+	res, err := vm.Run(ctx, module.Globals, module.Globals, code, nil)
 	if err != nil {
-		py.TracebackDump(err)
+		ctx.TracebackDump(err)
 		log.Fatal(err)
 	}
 	// fmt.Printf("Return = %v\n", res)
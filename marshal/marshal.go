@@ -11,15 +11,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"strconv"
+	"time"
 
 	"github.com/go-python/gpython/py"
 	"github.com/go-python/gpython/vm"
 )
 
 const (
-	MARSHAL_VERSION     = 3
+	MARSHAL_VERSION     = 4
 	TYPE_NULL           = '0'
 	TYPE_NONE           = 'N'
 	TYPE_FALSE          = 'F'
@@ -67,6 +69,21 @@ type rFile struct {
 	refs []py.Object
 }
 
+// Reads a float written as a string with a 1 byte length
+func (rfile *rFile) readFloatString() (float64, error) {
+	var length uint8
+	err := binary.Read(rfile.r, binary.LittleEndian, &length)
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, int(length))
+	_, err = io.ReadFull(rfile.r, buf)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(buf), 64)
+}
+
 // Reads an object from the input
 func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 	var code byte
@@ -139,18 +156,8 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		return addRef(py.Int(n)), nil
 	case TYPE_FLOAT:
 		// Floating point number as a string
-		var length uint8
-		err = binary.Read(rfile.r, binary.LittleEndian, &length)
-		if err != nil {
-			return
-		}
-		buf := make([]byte, int(length))
-		_, err = io.ReadFull(rfile.r, buf)
-		if err != nil {
-			return
-		}
 		var f float64
-		f, err = strconv.ParseFloat(string(buf), 64)
+		f, err = rfile.readFloatString()
 		if err != nil {
 			return
 		}
@@ -163,24 +170,17 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		}
 		return addRef(py.Float(f)), nil
 	case TYPE_COMPLEX:
-		// Complex number as a string
-		// FIXME this is using Go conversion not Python conversion which may differ
-		var length uint8
-		err = binary.Read(rfile.r, binary.LittleEndian, &length)
-		if err != nil {
-			return
-		}
-		buf := make([]byte, int(length))
-		_, err = io.ReadFull(rfile.r, buf)
+		// Real and imaginary parts as strings
+		var re, im float64
+		re, err = rfile.readFloatString()
 		if err != nil {
 			return
 		}
-		var c complex128
-		// FIXME c, err = strconv.ParseComplex(string(buf), 64)
+		im, err = rfile.readFloatString()
 		if err != nil {
 			return
 		}
-		return addRef(py.Complex(c)), nil
+		return addRef(py.Complex(complex(re, im))), nil
 	case TYPE_BINARY_COMPLEX:
 		var c complex128
 		err = binary.Read(rfile.r, binary.LittleEndian, &c)
@@ -194,36 +194,54 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		if err != nil {
 			return
 		}
-		// FIXME negative := false
+		negative := false
 		if size < 0 {
-			// FIXME negative = true
+			negative = true
 			size = -size
 		}
 		if size < 0 || size > SIZE32_MAX {
 			return nil, errors.New("bad marshal data (long size out of range)")
 		}
-		// FIXME not sure what -ve size means!
-		// Now read shorts which have 15 bits of the number in
+		// Now read shorts which have 15 bits of the number in,
+		// least significant first
 		digits := make([]int16, size)
 		err = binary.Read(rfile.r, binary.LittleEndian, &digits)
 		if err != nil {
 			return
 		}
-		if digits[0] == 0 {
+		if size > 0 && digits[size-1] == 0 {
 			// FIXME should be ValueError
 			return nil, errors.New("bad marshal data (digit out of range in long)")
 		}
 		// Convert into a big.Int
 		r := new(big.Int)
 		t := new(big.Int)
-		for _, digit := range digits {
-			r.Lsh(r, 15)
-			t.SetInt64(int64(digit))
+		for i := len(digits) - 1; i >= 0; i-- {
+			r.Lsh(r, PyLong_MARSHAL_SHIFT)
+			t.SetInt64(int64(digits[i]))
 			r.Add(r, t)
 		}
-		// FIXME try to fit into int64 if possible
-		return addRef((*py.BigInt)(r)), nil
-	case TYPE_STRING, TYPE_INTERNED, TYPE_UNICODE, TYPE_ASCII, TYPE_ASCII_INTERNED:
+		if negative {
+			r.Neg(r)
+		}
+		return addRef((*py.BigInt)(r).MaybeInt()), nil
+	case TYPE_STRING:
+		// Bytes
+		var size int32
+		err = binary.Read(rfile.r, binary.LittleEndian, &size)
+		if err != nil {
+			return
+		}
+		if size < 0 || size > SIZE32_MAX {
+			return nil, errors.New("bad marshal data (bytes object size out of range)")
+		}
+		buf := make([]byte, int(size))
+		_, err = io.ReadFull(rfile.r, buf)
+		if err != nil {
+			return
+		}
+		return addRef(py.Bytes(buf)), nil
+	case TYPE_INTERNED, TYPE_UNICODE, TYPE_ASCII, TYPE_ASCII_INTERNED:
 		var size int32
 		err = binary.Read(rfile.r, binary.LittleEndian, &size)
 		if err != nil {
@@ -275,9 +293,17 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		case TYPE_LIST:
 			return updateRef(iref, py.NewListFromItems(tuple)), nil
 		case TYPE_SET:
-			return updateRef(iref, py.NewSetFromItems(tuple)), nil
+			set, err := py.NewSetFromItems(tuple)
+			if err != nil {
+				return nil, err
+			}
+			return updateRef(iref, set), nil
 		case TYPE_FROZENSET:
-			return updateRef(iref, py.NewFrozenSetFromItems(tuple)), nil
+			set, err := py.NewFrozenSetFromItems(tuple)
+			if err != nil {
+				return nil, err
+			}
+			return updateRef(iref, set), nil
 		}
 	case TYPE_SMALL_TUPLE:
 		var size uint8
@@ -295,8 +321,7 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		}
 		return updateRef(iref, py.Tuple(tuple)), nil
 	case TYPE_DICT:
-		// FIXME should be py.Dict
-		dict := py.NewStringDict()
+		dict := py.NewDict()
 		iref := reserveRef()
 		var key, value py.Object
 		for {
@@ -312,8 +337,10 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 				return
 			}
 			if value != nil {
-				// FIXME should be objects as key
-				dict[string(key.(py.String))] = value
+				err = dict.SetItem(key, value)
+				if err != nil {
+					return
+				}
 			}
 		}
 		return updateRef(iref, dict), nil
@@ -413,6 +440,13 @@ func (rfile *rFile) ReadObject() (obj py.Object, err error) {
 		// fmt.Printf("firstlineno = %v\n", firstlineno)
 		// fmt.Printf("lnotab = %x\n", lnotab)
 
+		// The bytecode and line number table are bytes
+		if b, ok := code.(py.Bytes); ok {
+			code = py.String(b)
+		}
+		if b, ok := lnotab.(py.Bytes); ok {
+			lnotab = py.String(b)
+		}
 		v := py.NewCode(
 			argcount, kwonlyargcount,
 			nlocals, stacksize, flags,
@@ -433,39 +467,455 @@ func ReadObject(r io.Reader) (obj py.Object, err error) {
 	return rfile.ReadObject()
 }
 
-// The header on a .pyc file
+// The header on a .pyc file written by CPython 3.4
 type PycHeader struct {
 	Magic     uint32
 	Timestamp int32
 	Length    int32
 }
 
-// Reads a pyc file
+// Reads a pyc file written by CPython 3.4, or by WritePyc or the
+// import system which use the 16 byte py.PycHeader instead
+//
+// The header is checked but not compared with the source.
 func ReadPyc(r io.Reader) (obj py.Object, err error) {
-	var header PycHeader
-	if err = binary.Read(r, binary.LittleEndian, &header); err != nil {
+	var magic [4]byte
+	if _, err = io.ReadFull(r, magic[:]); err != nil {
 		return
 	}
-	// FIXME do something with timestamp & length?
-	if header.Magic>>16 != 0x0a0d {
-		return nil, errors.New("Bad magic in .pyc file")
+	r = io.MultiReader(bytes.NewReader(magic[:]), r)
+	if binary.LittleEndian.Uint32(magic[:]) == PYC_MAGIC {
+		var header py.PycHeader
+		if err = binary.Read(r, binary.LittleEndian, &header); err != nil {
+			return
+		}
+		if err = header.Check(); err != nil {
+			return nil, err
+		}
+	} else {
+		var header PycHeader
+		if err = binary.Read(r, binary.LittleEndian, &header); err != nil {
+			return
+		}
+		// FIXME do something with timestamp & length?
+		if header.Magic>>16 != 0x0a0d {
+			return nil, errors.New("Bad magic in .pyc file")
+		}
 	}
-	// fmt.Printf("header = %v\n", header)
 	return ReadObject(r)
 }
 
-// Unmarshals a frozen module
-func LoadFrozenModule(name string, data []byte) (*py.Module, error) {
+// The deepest nesting of containers WriteObject will write
+const MAX_MARSHAL_STACK_DEPTH = 2000
+
+// Represents currently being marshalled file
+type wFile struct {
+	w       io.Writer
+	version int
+	depth   int
+	refs    map[interface{}]int32
+}
+
+// Keys in the refs map for objects which aren't comparable or which
+// compare equal to objects of other types
+type (
+	bytesKey   string
+	floatKey   uint64
+	complexKey [2]uint64
+)
+
+// Returns the key identifying obj in the refs map or nil if it can't
+// be referred to
+func refKey(obj py.Object) interface{} {
+	switch x := obj.(type) {
+	case py.Int, py.String, *py.BigInt, *py.FrozenSet, *py.Code:
+		return x
+	case py.Bytes:
+		return bytesKey(x)
+	case py.Float:
+		return floatKey(math.Float64bits(float64(x)))
+	case py.Complex:
+		return complexKey{math.Float64bits(real(x)), math.Float64bits(imag(x))}
+	}
+	return nil
+}
+
+// Writes data to the output in little endian binary
+func (wfile *wFile) write(data interface{}) error {
+	return binary.Write(wfile.w, binary.LittleEndian, data)
+}
+
+// Writes a size in 4 bytes
+func (wfile *wFile) writeSize(n int) error {
+	if n > SIZE32_MAX {
+		return py.ExceptionNewf(py.ValueError, "unmarshallable object")
+	}
+	return wfile.write(int32(n))
+}
+
+// Writes a type code and a run of bytes with a 4 byte size
+func (wfile *wFile) writeBytes(Type byte, buf []byte) error {
+	if err := wfile.write(Type); err != nil {
+		return err
+	}
+	if err := wfile.writeSize(len(buf)); err != nil {
+		return err
+	}
+	_, err := wfile.w.Write(buf)
+	return err
+}
+
+// Writes a run of bytes with a 1 byte size
+func (wfile *wFile) writeShortBytes(buf []byte) error {
+	if err := wfile.write(uint8(len(buf))); err != nil {
+		return err
+	}
+	_, err := wfile.w.Write(buf)
+	return err
+}
+
+// Writes the items of a sequence with a 4 byte size
+func (wfile *wFile) writeItems(items []py.Object) error {
+	if err := wfile.writeSize(len(items)); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := wfile.writeObject(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes a float as text for versions before 2
+func (wfile *wFile) writeFloatString(f float64) error {
+	var s string
+	switch {
+	case math.IsInf(f, 1):
+		s = "inf"
+	case math.IsInf(f, -1):
+		s = "-inf"
+	case math.IsNaN(f):
+		s = "nan"
+	default:
+		s = strconv.FormatFloat(f, 'g', 17, 64)
+	}
+	return wfile.writeShortBytes([]byte(s))
+}
+
+// Writes a long in base 2**15 digits, least significant first, with
+// the sign in the digit count
+func (wfile *wFile) writeLong(flag byte, x *big.Int) error {
+	var digits []int16
+	n := new(big.Int).Abs(x)
+	mask := big.NewInt(PyLong_MARSHAL_MASK)
+	digit := new(big.Int)
+	for n.Sign() != 0 {
+		digits = append(digits, int16(digit.And(n, mask).Int64()))
+		n.Rsh(n, PyLong_MARSHAL_SHIFT)
+	}
+	size := int32(len(digits))
+	if x.Sign() < 0 {
+		size = -size
+	}
+	if err := wfile.write(TYPE_LONG | flag); err != nil {
+		return err
+	}
+	if err := wfile.write(size); err != nil {
+		return err
+	}
+	return wfile.write(digits)
+}
+
+// Writes a str, interned if it is an identifier
+func (wfile *wFile) writeString(flag byte, s py.String, interned bool) error {
+	interned = interned && wfile.version >= 3
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if wfile.version >= 4 && ascii {
+		if len(s) < 256 {
+			Type := byte(TYPE_SHORT_ASCII)
+			if interned {
+				Type = TYPE_SHORT_ASCII_INTERNED
+			}
+			if err := wfile.write(Type | flag); err != nil {
+				return err
+			}
+			return wfile.writeShortBytes([]byte(s))
+		}
+		if interned {
+			return wfile.writeBytes(TYPE_ASCII_INTERNED|flag, []byte(s))
+		}
+		return wfile.writeBytes(TYPE_ASCII|flag, []byte(s))
+	}
+	if interned {
+		return wfile.writeBytes(TYPE_INTERNED|flag, []byte(s))
+	}
+	return wfile.writeBytes(TYPE_UNICODE|flag, []byte(s))
+}
+
+// Writes a str which is an identifier
+func (wfile *wFile) writeName(name string) error {
+	return wfile.writeRef(py.String(name), true)
+}
+
+// Writes obj to the output
+func (wfile *wFile) writeObject(obj py.Object) error {
+	return wfile.writeRef(obj, false)
+}
+
+// Writes obj to the output, or a reference to it if it has been
+// written already
+//
+// Strings are interned if interned is set.
+func (wfile *wFile) writeRef(obj py.Object, interned bool) error {
+	wfile.depth++
+	defer func() { wfile.depth-- }()
+	if wfile.depth > MAX_MARSHAL_STACK_DEPTH {
+		return py.ExceptionNewf(py.ValueError, "object too deeply nested to marshal")
+	}
+	var flag byte
+	if key := refKey(obj); key != nil && wfile.refs != nil {
+		if i, ok := wfile.refs[key]; ok {
+			if err := wfile.write(byte(TYPE_REF)); err != nil {
+				return err
+			}
+			return wfile.write(i)
+		}
+		// Numbered in the order the reader meets them
+		wfile.refs[key] = int32(len(wfile.refs))
+		flag = FLAG_REF
+	}
+	switch x := obj.(type) {
+	case nil:
+		return wfile.write(byte(TYPE_NULL))
+	case py.NoneType:
+		return wfile.write(byte(TYPE_NONE))
+	case py.Bool:
+		if x {
+			return wfile.write(byte(TYPE_TRUE))
+		}
+		return wfile.write(byte(TYPE_FALSE))
+	case py.EllipsisType:
+		return wfile.write(byte(TYPE_ELLIPSIS))
+	case *py.Type:
+		if x == py.StopIteration {
+			return wfile.write(byte(TYPE_STOPITER))
+		}
+	case py.Int:
+		if x >= math.MinInt32 && x <= math.MaxInt32 {
+			if err := wfile.write(TYPE_INT | flag); err != nil {
+				return err
+			}
+			return wfile.write(int32(x))
+		}
+		return wfile.writeLong(flag, big.NewInt(int64(x)))
+	case *py.BigInt:
+		if i, ok := x.MaybeInt().(py.Int); ok && i >= math.MinInt32 && i <= math.MaxInt32 {
+			if err := wfile.write(TYPE_INT | flag); err != nil {
+				return err
+			}
+			return wfile.write(int32(i))
+		}
+		return wfile.writeLong(flag, (*big.Int)(x))
+	case py.Float:
+		if wfile.version < 2 {
+			if err := wfile.write(TYPE_FLOAT | flag); err != nil {
+				return err
+			}
+			return wfile.writeFloatString(float64(x))
+		}
+		if err := wfile.write(TYPE_BINARY_FLOAT | flag); err != nil {
+			return err
+		}
+		return wfile.write(float64(x))
+	case py.Complex:
+		if wfile.version < 2 {
+			if err := wfile.write(TYPE_COMPLEX | flag); err != nil {
+				return err
+			}
+			if err := wfile.writeFloatString(real(x)); err != nil {
+				return err
+			}
+			return wfile.writeFloatString(imag(x))
+		}
+		if err := wfile.write(TYPE_BINARY_COMPLEX | flag); err != nil {
+			return err
+		}
+		return wfile.write(complex128(x))
+	case py.String:
+		return wfile.writeString(flag, x, interned)
+	case py.Bytes:
+		return wfile.writeBytes(TYPE_STRING|flag, []byte(x))
+	case py.Tuple:
+		if wfile.version >= 4 && len(x) < 256 {
+			if err := wfile.write(byte(TYPE_SMALL_TUPLE)); err != nil {
+				return err
+			}
+			if err := wfile.write(uint8(len(x))); err != nil {
+				return err
+			}
+			for _, item := range x {
+				if err := wfile.writeObject(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := wfile.write(byte(TYPE_TUPLE)); err != nil {
+			return err
+		}
+		return wfile.writeItems(x)
+	case *py.List:
+		if err := wfile.write(byte(TYPE_LIST)); err != nil {
+			return err
+		}
+		return wfile.writeItems(x.Items)
+	case *py.FrozenSet:
+		if err := wfile.write(TYPE_FROZENSET | flag); err != nil {
+			return err
+		}
+		return wfile.writeItems(x.Items())
+	case *py.Set:
+		if err := wfile.write(byte(TYPE_SET)); err != nil {
+			return err
+		}
+		return wfile.writeItems(x.Items())
+	case *py.Dict:
+		if err := wfile.write(byte(TYPE_DICT)); err != nil {
+			return err
+		}
+		for _, item := range x.Items() {
+			if err := wfile.writeObject(item[0]); err != nil {
+				return err
+			}
+			if err := wfile.writeObject(item[1]); err != nil {
+				return err
+			}
+		}
+		return wfile.write(byte(TYPE_NULL))
+	case *py.Code:
+		return wfile.writeCode(flag, x)
+	}
+	return py.ExceptionNewf(py.ValueError, "unmarshallable object")
+}
+
+// Writes a code object
+func (wfile *wFile) writeCode(flag byte, code *py.Code) error {
+	if err := wfile.write(TYPE_CODE | flag); err != nil {
+		return err
+	}
+	for _, n := range []int32{code.Argcount, code.Kwonlyargcount, code.Nlocals, code.Stacksize, code.Flags} {
+		if err := wfile.write(n); err != nil {
+			return err
+		}
+	}
+	if err := wfile.writeObject(py.Bytes(code.Code)); err != nil {
+		return err
+	}
+	if err := wfile.writeObject(code.Consts); err != nil {
+		return err
+	}
+	for _, names := range [][]string{code.Names, code.Varnames, code.Freevars, code.Cellvars} {
+		if err := wfile.writeNames(names); err != nil {
+			return err
+		}
+	}
+	if err := wfile.writeObject(py.String(code.Filename)); err != nil {
+		return err
+	}
+	if err := wfile.writeName(code.Name); err != nil {
+		return err
+	}
+	if err := wfile.write(code.Firstlineno); err != nil {
+		return err
+	}
+	return wfile.writeObject(py.Bytes(code.Lnotab))
+}
+
+// Writes a tuple of identifiers
+func (wfile *wFile) writeNames(names []string) error {
+	tuple := make(py.Tuple, len(names))
+	for i, name := range names {
+		tuple[i] = py.String(name)
+	}
+	if wfile.version >= 4 && len(tuple) < 256 {
+		if err := wfile.write(byte(TYPE_SMALL_TUPLE)); err != nil {
+			return err
+		}
+		if err := wfile.write(uint8(len(tuple))); err != nil {
+			return err
+		}
+	} else {
+		if err := wfile.write(byte(TYPE_TUPLE)); err != nil {
+			return err
+		}
+		if err := wfile.writeSize(len(tuple)); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		if err := wfile.writeName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes obj to w in the marshal format version
+//
+// Versions 0 to MARSHAL_VERSION are supported.  Version 2 writes
+// floats in binary, version 3 interns identifiers and writes
+// references to objects already written rather than writing them
+// again, and version 4 writes short ASCII strings and tuples more
+// compactly.
+func WriteObject(w io.Writer, obj py.Object, version int) error {
+	if version < 0 || version > MARSHAL_VERSION {
+		return py.ExceptionNewf(py.ValueError, "unsupported marshal version %d", version)
+	}
+	wfile := &wFile{w: w, version: version}
+	if version >= 3 {
+		wfile.refs = make(map[interface{}]int32)
+	}
+	return wfile.writeObject(obj)
+}
+
+// The magic number at the start of the .pyc files written by WritePyc
+const PYC_MAGIC = py.PycMagic
+
+// Writes a pyc file of obj, normally a *py.Code, compiled from a
+// source file with modification time mtime and size bytes long
+//
+// The file has the same 16 byte header as the __pycache__ files.
+func WritePyc(w io.Writer, obj py.Object, mtime time.Time, size int64) error {
+	header := py.PycHeader{
+		Magic: PYC_MAGIC,
+		Mtime: uint32(mtime.Unix()),
+		Size:  uint32(size),
+	}
+	if err := binary.Write(w, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	return WriteObject(w, obj, MARSHAL_VERSION)
+}
+
+// Unmarshals a frozen module into the interpreter ctx
+func LoadFrozenModule(ctx *py.Context, name string, data []byte) (*py.Module, error) {
 	r := bytes.NewBuffer(data)
 	obj, err := ReadObject(r)
 	if err != nil {
 		return nil, err
 	}
 	code := obj.(*py.Code)
-	module := py.NewModule(name, "", nil, nil)
-	_, err = vm.Run(module.Globals, module.Globals, code, nil)
+	module := ctx.NewModule(name, "", nil, nil)
+	_, err = vm.Run(ctx, module.Globals, module.Globals, code, nil)
 	if err != nil {
-		py.TracebackDump(err)
+		ctx.TracebackDump(err)
 		return nil, err
 	}
 	return module, nil
@@ -631,8 +1081,17 @@ func init() {
 		py.MustNewMethod("dumps", marshal_dumps, 0, dumps_doc),
 		py.MustNewMethod("loads", marshal_loads, 0, loads_doc),
 	}
-	globals := py.StringDict{
+	globals := py.NewStringDictFromMap(map[string]py.Object{
 		"version": py.Int(MARSHAL_VERSION),
+	})
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "marshal",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+	})
+	py.MarshalReadObject = ReadObject
+	py.MarshalWriteObject = func(w io.Writer, obj py.Object) error {
+		return WriteObject(w, obj, MARSHAL_VERSION)
 	}
-	py.NewModule("marshal", module_doc, methods, globals)
 }
@@ -0,0 +1,351 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package marshal_test
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/go-python/gpython/builtin"
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/marshal"
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/sys"
+	"github.com/go-python/gpython/vm"
+)
+
+// equal returns true if a and b are of the same type and equal,
+// treating NaNs as equal to each other
+func equal(t *testing.T, a, b py.Object) bool {
+	t.Helper()
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	switch x := a.(type) {
+	case py.Float:
+		return math.Float64bits(float64(x)) == math.Float64bits(float64(b.(py.Float)))
+	case py.Complex:
+		y := b.(py.Complex)
+		return math.Float64bits(real(x)) == math.Float64bits(real(y)) && math.Float64bits(imag(x)) == math.Float64bits(imag(y))
+	case py.Tuple:
+		y := b.(py.Tuple)
+		if len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(t, x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case *py.Code:
+		y := b.(*py.Code)
+		return x.Argcount == y.Argcount &&
+			x.Kwonlyargcount == y.Kwonlyargcount &&
+			x.Nlocals == y.Nlocals &&
+			x.Stacksize == y.Stacksize &&
+			x.Flags == y.Flags &&
+			x.Code == y.Code &&
+			equal(t, x.Consts, y.Consts) &&
+			equalStrings(x.Names, y.Names) &&
+			equalStrings(x.Varnames, y.Varnames) &&
+			equalStrings(x.Freevars, y.Freevars) &&
+			equalStrings(x.Cellvars, y.Cellvars) &&
+			x.Filename == y.Filename &&
+			x.Name == y.Name &&
+			x.Firstlineno == y.Firstlineno &&
+			x.Lnotab == y.Lnotab
+	case *py.Dict:
+		// Check the order too
+		return equal(t, py.NewListFromItems(x.Keys()), py.NewListFromItems(b.(*py.Dict).Keys())) &&
+			equal(t, py.NewListFromItems(x.Values()), py.NewListFromItems(b.(*py.Dict).Values()))
+	}
+	res, err := py.Eq(a, b)
+	if err != nil {
+		t.Fatalf("Eq failed: %v", err)
+	}
+	return res == py.True
+}
+
+// equalStrings returns true if a and b hold the same strings
+func equalStrings(a, b []string) bool {
+	return len(a) == len(b) && (len(a) == 0 || reflect.DeepEqual(a, b))
+}
+
+// roundTrip writes obj with version and reads it back
+func roundTrip(t *testing.T, obj py.Object, version int) py.Object {
+	t.Helper()
+	var buf bytes.Buffer
+	err := marshal.WriteObject(&buf, obj, version)
+	if err != nil {
+		t.Fatalf("version %d: WriteObject(%#v) failed: %v", version, obj, err)
+	}
+	got, err := marshal.ReadObject(&buf)
+	if err != nil {
+		t.Fatalf("version %d: ReadObject failed: %v", version, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("version %d: %d bytes left unread", version, buf.Len())
+	}
+	return got
+}
+
+func TestRoundTrip(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	frozen, err := py.NewFrozenSetFromItems([]py.Object{py.Int(1), py.String("two")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := py.NewSetFromItems([]py.Object{py.Int(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := py.NewDict()
+	for _, item := range []py.Tuple{
+		{py.String("z"), py.Int(1)},
+		{py.Int(2), py.String("two")},
+		{py.Tuple{py.Int(3), py.None}, py.Float(3)},
+		{py.Bytes("a"), py.NewDict()},
+	} {
+		if err := dict.SetItem(item[0], item[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	long := make(py.Tuple, 300)
+	for i := range long {
+		long[i] = py.Int(i)
+	}
+	for _, obj := range []py.Object{
+		py.None,
+		py.True,
+		py.False,
+		py.Ellipsis,
+		py.StopIteration,
+		py.Int(0),
+		py.Int(-1),
+		py.Int(math.MaxInt32),
+		py.Int(math.MinInt32),
+		py.Int(math.MaxInt32 + 1),
+		py.Int(math.MinInt32 - 1),
+		py.Int(math.MaxInt64),
+		py.Int(math.MinInt64),
+		(*py.BigInt)(huge),
+		(*py.BigInt)(new(big.Int).Neg(huge)),
+		py.Float(0),
+		py.Float(math.Copysign(0, -1)),
+		py.Float(0.1),
+		py.Float(-1.5e300),
+		py.Float(math.Inf(1)),
+		py.Float(math.Inf(-1)),
+		py.Float(math.NaN()),
+		py.Complex(complex(1.5, -0.1)),
+		py.String(""),
+		py.String("hello"),
+		py.String("héllo wörld"),
+		py.String(strings.Repeat("x", 300)),
+		py.Bytes(""),
+		py.Bytes("\x00\xff bytes"),
+		py.Tuple{},
+		py.Tuple{py.Int(1), py.String("a"), py.Tuple{py.None}},
+		long,
+		py.NewListFromItems([]py.Object{py.Int(1), py.Bytes("b")}),
+		frozen,
+		set,
+		py.NewStringDictFromMap(map[string]py.Object{"a": py.Int(1), "b": py.Tuple{py.String("c")}}),
+		dict,
+		py.Tuple{py.String("same"), py.String("same"), py.Bytes("same"), py.Float(1), py.Int(1), (*py.BigInt)(huge), (*py.BigInt)(huge)},
+	} {
+		for version := 0; version <= marshal.MARSHAL_VERSION; version++ {
+			got := roundTrip(t, obj, version)
+			if _, ok := obj.(*py.BigInt); ok {
+				// Read back as an Int if it fits
+				if i, ok := got.(py.Int); ok {
+					got = (*py.BigInt)(big.NewInt(int64(i)))
+				}
+			}
+			want := obj
+			if sd, ok := obj.(py.StringDict); ok {
+				// Read back as a dict
+				want = py.NewDictFromStringDict(sd)
+			}
+			if !equal(t, want, got) {
+				t.Errorf("version %d: want %#v got %#v", version, want, got)
+			}
+		}
+	}
+}
+
+func TestWriteObjectFormat(t *testing.T) {
+	// Output of marshal.dumps(value, version) from CPython
+	for _, test := range []struct {
+		obj     py.Object
+		version int
+		want    string
+	}{
+		{py.Int(-1), 2, "i\xff\xff\xff\xff"},
+		{py.Int(1 << 31), 2, "l\x03\x00\x00\x00\x00\x00\x00\x00\x02\x00"},
+		{py.Int(-(1 << 40)), 2, "l\xfd\xff\xff\xff\x00\x00\x00\x00\x00\x04"},
+		{py.Float(1.5), 1, "f\x031.5"},
+		{py.Float(0.1), 1, "f\x130.10000000000000001"},
+		{py.Float(math.Inf(1)), 1, "f\x03inf"},
+		{py.Float(-0.5), 2, "g\x00\x00\x00\x00\x00\x00\xe0\xbf"},
+		{py.Complex(1.5i), 2, "y\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf8?"},
+		{py.String("héllo"), 2, "u\x06\x00\x00\x00h\xc3\xa9llo"},
+		{py.Bytes("ab"), 2, "s\x02\x00\x00\x00ab"},
+		{py.Tuple{py.String("a"), py.Int(1)}, 2, "(\x02\x00\x00\x00u\x01\x00\x00\x00ai\x01\x00\x00\x00"},
+		{py.NewListFromItems([]py.Object{py.None, py.True, py.False, py.Ellipsis, py.StopIteration}), 2, "[\x05\x00\x00\x00NTF.S"},
+		{py.NewFrozenSet(), 2, ">\x00\x00\x00\x00"},
+		{py.NewStringDictFromMap(map[string]py.Object{"a": py.Int(1)}), 2, "{u\x01\x00\x00\x00ai\x01\x00\x00\x000"},
+		// Short ASCII strings, small tuples and references.  CPython
+		// also sets FLAG_REF on the tuple if it is shared.
+		{py.Tuple{py.String("a"), py.String("a")}, 4, ")\x02\xfa\x01ar\x00\x00\x00\x00"},
+	} {
+		var buf bytes.Buffer
+		err := marshal.WriteObject(&buf, test.obj, test.version)
+		if err != nil {
+			t.Fatalf("WriteObject(%#v) failed: %v", test.obj, err)
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("WriteObject(%#v, %d): want %q got %q", test.obj, test.version, test.want, got)
+		}
+	}
+}
+
+func TestWriteObjectErrors(t *testing.T) {
+	var buf bytes.Buffer
+	err := marshal.WriteObject(&buf, py.NewList(), marshal.MARSHAL_VERSION+1)
+	if !py.IsException(py.ValueError, err) {
+		t.Errorf("want ValueError for bad version got %v", err)
+	}
+	err = marshal.WriteObject(&buf, py.IntType, marshal.MARSHAL_VERSION)
+	if !py.IsException(py.ValueError, err) {
+		t.Errorf("want ValueError for unmarshallable object got %v", err)
+	}
+	var deep py.Object = py.None
+	for i := 0; i < marshal.MAX_MARSHAL_STACK_DEPTH+1; i++ {
+		deep = py.Tuple{deep}
+	}
+	err = marshal.WriteObject(&buf, deep, marshal.MARSHAL_VERSION)
+	if !py.IsException(py.ValueError, err) {
+		t.Errorf("want ValueError for deep nesting got %v", err)
+	}
+}
+
+const codeSource = `
+big = 123456789012345678901234567890
+def outer(a, *args, b=2.5, **kwargs):
+    c = 1j
+    e = a
+    def inner(d):
+        return e + d
+    return inner(b), c, args, kwargs
+class K:
+    def method(self):
+        return __class__
+result = (outer(1, 2, x=3), b"bytes", "héllo", big, -big, ..., None, 2 in {1, 2}, K().method() is K)
+`
+
+func TestCodeRoundTrip(t *testing.T) {
+	obj, err := compile.Compile(codeSource, "<code>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	code := obj.(*py.Code)
+	run := func(code *py.Code) string {
+		t.Helper()
+		ctx := py.NewContext(py.ContextOpts{})
+		module := ctx.NewModule("__main__", "", nil, nil)
+		_, err := vm.Run(ctx, module.Globals, module.Globals, code, nil)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		repr, err := py.ReprAsString(module.Globals.Get("result"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repr
+	}
+	want := run(code)
+	for version := 0; version <= marshal.MARSHAL_VERSION; version++ {
+		got := roundTrip(t, code, version)
+		if !equal(t, code, got) {
+			t.Errorf("version %d: code object changed", version)
+		}
+		if result := run(got.(*py.Code)); result != want {
+			t.Errorf("version %d: want %s got %s", version, want, result)
+		}
+	}
+
+	// References keep one copy of each code object
+	var plain, refs bytes.Buffer
+	pair := py.Tuple{code, code}
+	if err := marshal.WriteObject(&plain, pair, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := marshal.WriteObject(&refs, pair, marshal.MARSHAL_VERSION); err != nil {
+		t.Fatal(err)
+	}
+	if refs.Len() >= plain.Len()/2 {
+		t.Errorf("references didn't shrink the output: %d >= %d/2", refs.Len(), plain.Len())
+	}
+	got, err := marshal.ReadObject(&refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tuple := got.(py.Tuple); tuple[0] != tuple[1] {
+		t.Errorf("code object not shared")
+	}
+}
+
+func TestWritePyc(t *testing.T) {
+	obj, err := compile.Compile("x = 1\n", "<pyc>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	var buf bytes.Buffer
+	mtime := time.Unix(1234567890, 0)
+	err = marshal.WritePyc(&buf, obj, mtime, 6)
+	if err != nil {
+		t.Fatalf("WritePyc failed: %v", err)
+	}
+	code := append([]byte{}, buf.Bytes()[16:]...)
+	header := buf.Bytes()[:16]
+	want := []byte{'g', 'p', '\r', '\n', 0, 0, 0, 0, 0xd2, 0x02, 0x96, 0x49, 6, 0, 0, 0}
+	if !bytes.Equal(header, want) {
+		t.Errorf("want header %x got %x", want, header)
+	}
+	got, err := marshal.ReadPyc(&buf)
+	if err != nil {
+		t.Fatalf("ReadPyc failed: %v", err)
+	}
+	if !equal(t, obj, got) {
+		t.Errorf("code object changed")
+	}
+
+	// The 12 byte header of CPython 3.4 .pyc files is read too
+	cpython := append([]byte{0xee, 0x0c, '\r', '\n', 0xd2, 0x02, 0x96, 0x49, 6, 0, 0, 0}, code...)
+	got, err = marshal.ReadPyc(bytes.NewReader(cpython))
+	if err != nil {
+		t.Fatalf("ReadPyc of CPython header failed: %v", err)
+	}
+	if !equal(t, obj, got) {
+		t.Errorf("code object changed reading CPython header")
+	}
+
+	// Unknown flags in our header and bad magic numbers are rejected
+	badFlags := append([]byte{'g', 'p', '\r', '\n', 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, code...)
+	if _, err := marshal.ReadPyc(bytes.NewReader(badFlags)); !py.IsException(py.ImportError, err) {
+		t.Errorf("want ImportError for bad flags got %v", err)
+	}
+	badMagic := append([]byte{1, 2, 3, 4, 0, 0, 0, 0, 0, 0, 0, 0}, code...)
+	if _, err := marshal.ReadPyc(bytes.NewReader(badMagic)); err == nil {
+		t.Errorf("want error for bad magic")
+	}
+}
@@ -418,7 +418,7 @@ func ParseTupleAndKeywords(args Tuple, kwargs StringDict, format string, kwlist
 	}
 	min, max, name, ops := parseFormat(format)
 	keywordOnly := false
-	err := checkNumberOfArgs(name, len(args)+len(kwargs), len(results), min, max)
+	err := checkNumberOfArgs(name, len(args)+kwargs.Len(), len(results), min, max)
 	if err != nil {
 		return err
 	}
@@ -429,7 +429,7 @@ func ParseTupleAndKeywords(args Tuple, kwargs StringDict, format string, kwlist
 	}
 	// Check all the kwargs are in kwlist
 	// O(N^2) Slow but kwlist is usually short
-	for kwargName := range kwargs {
+	for _, kwargName := range kwargs.StringKeys() {
 		for _, kw := range kwlist {
 			if kw == kwargName {
 				goto found
@@ -442,16 +442,29 @@ func ParseTupleAndKeywords(args Tuple, kwargs StringDict, format string, kwlist
 	// Create args tuple with all the arguments we have in
 	args = args.Copy()
 	for i, kw := range kwlist {
-		if value, ok := kwargs[kw]; ok {
+		if value, ok := kwargs.Lookup(kw); ok {
 			if len(args) > i {
 				return ExceptionNewf(TypeError, "%s() got multiple values for argument '%s'", name, kw)
 			}
+			// Leave a gap for any optional arguments skipped
+			for len(args) < i {
+				args = append(args, nil)
+			}
 			args = append(args, value)
 		} else if keywordOnly {
 			args = append(args, nil)
 		}
 	}
+	// Only the optional arguments may be skipped
+	for i := 0; i < min && !keywordOnly; i++ {
+		if i >= len(args) || args[i] == nil {
+			return ExceptionNewf(TypeError, "%s() missing required argument '%s' (pos %d)", name, kwlist[i], i+1)
+		}
+	}
 	for i, arg := range args {
+		if arg == nil && !keywordOnly {
+			continue
+		}
 		op := ops[i]
 		result := results[i]
 		switch op {
@@ -553,7 +566,7 @@ func checkNumberOfArgs(name string, nargs, nresults, min, max int) error {
 //
 // Up to the caller to set default values
 func UnpackTuple(args Tuple, kwargs StringDict, name string, min int, max int, results ...*Object) error {
-	if len(kwargs) != 0 {
+	if kwargs.Len() != 0 {
 		return ExceptionNewf(TypeError, "%s() does not take keyword arguments", name)
 	}
 
@@ -0,0 +1,49 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import "testing"
+
+func TestParseTupleAndKeywords(t *testing.T) {
+	kwlist := []string{"a", "b", "c"}
+	for _, test := range []struct {
+		args    Tuple
+		kwargs  StringDict
+		want    Tuple
+		wantMsg string
+	}{
+		{Tuple{Int(1)}, nil, Tuple{Int(1), nil, nil}, ""},
+		{Tuple{Int(1), Int(2), Int(3)}, nil, Tuple{Int(1), Int(2), Int(3)}, ""},
+		{nil, NewStringDictFromMap(map[string]Object{"a": Int(1)}), Tuple{Int(1), nil, nil}, ""},
+		{Tuple{Int(1)}, NewStringDictFromMap(map[string]Object{"c": Int(3)}), Tuple{Int(1), nil, Int(3)}, ""},
+		{nil, NewStringDictFromMap(map[string]Object{"c": Int(3), "a": Int(1)}), Tuple{Int(1), nil, Int(3)}, ""},
+		{nil, nil, nil, "f() takes at least 1 arguments (0 given)"},
+		{nil, NewStringDictFromMap(map[string]Object{"b": Int(2), "c": Int(3)}), nil, "f() missing required argument 'a' (pos 1)"},
+		{nil, NewStringDictFromMap(map[string]Object{"c": Int(3)}), nil, "f() missing required argument 'a' (pos 1)"},
+		{Tuple{Int(1)}, NewStringDictFromMap(map[string]Object{"a": Int(1)}), nil, "f() got multiple values for argument 'a'"},
+		{nil, NewStringDictFromMap(map[string]Object{"d": Int(4)}), nil, "f() got an unexpected keyword argument 'd'"},
+	} {
+		var a, b, c Object
+		err := ParseTupleAndKeywords(test.args, test.kwargs, "O|OO:f", kwlist, &a, &b, &c)
+		if test.wantMsg != "" {
+			exc, ok := err.(*Exception)
+			if !ok || !IsException(TypeError, exc) {
+				t.Errorf("%v %v: want TypeError got %v", test.args, test.kwargs, err)
+			} else if msg := string(exc.Args.(Tuple)[0].(String)); msg != test.wantMsg {
+				t.Errorf("%v %v: want %q got %q", test.args, test.kwargs, test.wantMsg, msg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v %v: failed: %v", test.args, test.kwargs, err)
+			continue
+		}
+		for i, got := range []Object{a, b, c} {
+			if got != test.want[i] {
+				t.Errorf("%v %v: arg %d want %v got %v", test.args, test.kwargs, i, test.want[i], got)
+			}
+		}
+	}
+}
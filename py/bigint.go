@@ -29,6 +29,14 @@ func (a *BigInt) M__repr__() (Object, error) {
 	return a.M__str__()
 }
 
+func (a *BigInt) M__format__(spec Object) (Object, error) {
+	s, err := formatSpecString(spec)
+	if err != nil {
+		return nil, err
+	}
+	return formatInt((*big.Int)(a), s, "int")
+}
+
 // Some common BigInts
 var (
 	bigInt0   = (*BigInt)(big.NewInt(0))
@@ -569,6 +577,10 @@ func (a *BigInt) M__ge__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a *BigInt) M__hash__() (Object, error) {
+	return Int(hashBigInt((*big.Int)(a))), nil
+}
+
 func (a *BigInt) M__ceil__() (Object, error) {
 	return a, nil
 }
@@ -591,3 +603,5 @@ var _ I__index__ = (*BigInt)(nil)
 var _ richComparison = (*BigInt)(nil)
 var _ IGoInt = (*BigInt)(nil)
 var _ IGoInt64 = (*BigInt)(nil)
+var _ I__hash__ = (*BigInt)(nil)
+var _ I__format__ = (*BigInt)(nil)
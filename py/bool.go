@@ -50,6 +50,18 @@ func (a Bool) M__repr__() (Object, error) {
 	return String("False"), nil
 }
 
+func (a Bool) M__format__(spec Object) (Object, error) {
+	s, err := formatSpecString(spec)
+	if err != nil {
+		return nil, err
+	}
+	if s == "" {
+		return a.M__str__()
+	}
+	index, _ := a.M__index__()
+	return index.M__format__(spec)
+}
+
 // Convert an Object to an Bool
 //
 // Retrurns ok as to whether the conversion worked or not
@@ -93,6 +105,13 @@ func (a Bool) M__ne__(other Object) (Object, error) {
 	return True, nil
 }
 
+func (a Bool) M__hash__() (Object, error) {
+	if a {
+		return Int(1), nil
+	}
+	return Int(0), nil
+}
+
 func notEq(eq Object, err error) (Object, error) {
 	if err != nil {
 		return nil, err
@@ -110,3 +129,5 @@ var _ I__str__ = Bool(false)
 var _ I__repr__ = Bool(false)
 var _ I__eq__ = Bool(false)
 var _ I__ne__ = Bool(false)
+var _ I__hash__ = Bool(false)
+var _ I__format__ = Bool(false)
@@ -12,6 +12,8 @@ package py
 type BoundMethod struct {
 	Self   Object
 	Method Object
+	// Context the method was looked up in or nil
+	Context *Context
 }
 
 var BoundMethodType = NewType("boundmethod", "boundmethod object")
@@ -26,17 +28,25 @@ func NewBoundMethod(self, method Object) *BoundMethod {
 	return &BoundMethod{Self: self, Method: method}
 }
 
+// BindContext returns obj with ctx recorded in it if it is a bound
+// method which needs the Context it was looked up in, such as
+// str.format which checks the attributes it reads against the policy
+func BindContext(ctx *Context, obj Object) Object {
+	if bm, ok := obj.(*BoundMethod); ok && bm.Context == nil {
+		if m, ok := bm.Method.(*Method); ok && m.needsContext() {
+			return &BoundMethod{Self: bm.Self, Method: bm.Method, Context: ctx}
+		}
+	}
+	return obj
+}
+
 // Call the bound method
 func (bm *BoundMethod) M__call__(args Tuple, kwargs StringDict) (Object, error) {
 	// Call built in methods slightly differently
 	// FIXME not sure this is sensible! something is wrong with the call interface
 	// as we aren't sure whether to call it with a self or not
 	if m, ok := bm.Method.(*Method); ok {
-		if kwargs != nil {
-			return m.CallWithKeywords(bm.Self, args, kwargs)
-		} else {
-			return m.Call(bm.Self, args)
-		}
+		return m.CallContext(bm.Context, bm.Self, args, kwargs)
 	}
 	newArgs := make(Tuple, len(args)+1)
 	newArgs[0] = bm.Self
@@ -0,0 +1,416 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// ByteArray objects
+//
+// A bytearray is a mutable bytes.  The methods it shares with bytes
+// are defined in bytes.go.
+
+package py
+
+var ByteArrayType = ObjectType.NewType("bytearray",
+	`bytearray(iterable_of_ints) -> bytearray
+bytearray(string, encoding[, errors]) -> bytearray
+bytearray(bytes_or_buffer) -> mutable copy of bytes_or_buffer
+bytearray(int) -> bytes array of size given by the parameter initialized with null bytes
+bytearray() -> empty bytes array
+
+Construct a mutable bytearray object from:
+  - an iterable yielding integers in range(256)
+  - a text string encoded using the specified encoding
+  - a bytes or a buffer object
+  - any object implementing the buffer API.
+  - an integer`, ByteArrayNew, nil)
+
+type ByteArray struct {
+	Bytes   Bytes
+	exports int // number of memoryviews sharing Bytes
+}
+
+// Type of this ByteArray object
+func (o *ByteArray) Type() *Type {
+	return ByteArrayType
+}
+
+// ByteArrayNew
+func ByteArrayNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	b, err := BytesNew(BytesType, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return NewByteArray(b.(Bytes)), nil
+}
+
+// Make a new bytearray with a copy of b
+func NewByteArray(b []byte) *ByteArray {
+	return &ByteArray{Bytes: append(Bytes{}, b...)}
+}
+
+// resizable returns an error if a memoryview is sharing the bytes of
+// a so they may not be reallocated
+func (a *ByteArray) resizable() error {
+	if a.exports > 0 {
+		return ExceptionNewf(BufferError, "Existing exports of data: object cannot be re-sized")
+	}
+	return nil
+}
+
+// byteValue converts obj into a byte
+func byteValue(obj Object) (byte, error) {
+	value, err := IndexInt(obj)
+	if err != nil {
+		return 0, err
+	}
+	if value < 0 || value >= 256 {
+		return 0, ExceptionNewf(ValueError, "byte must be in range(0, 256)")
+	}
+	return byte(value), nil
+}
+
+func init() {
+	ByteArrayType.Dict.Set("append", MustNewMethod("append", func(self, item Object) (Object, error) {
+		a := self.(*ByteArray)
+		c, err := byteValue(item)
+		if err != nil {
+			return nil, err
+		}
+		if err = a.resizable(); err != nil {
+			return nil, err
+		}
+		a.Bytes = append(a.Bytes, c)
+		return None, nil
+	}, 0, "B.append(int) -> None\n\nAppend a single item to the end of B."))
+
+	ByteArrayType.Dict.Set("extend", MustNewMethod("extend", func(self, iterable Object) (Object, error) {
+		a := self.(*ByteArray)
+		b, err := BytesFromObject(iterable)
+		if err != nil {
+			return nil, err
+		}
+		if err = a.resizable(); err != nil {
+			return nil, err
+		}
+		a.Bytes = append(a.Bytes, b...)
+		return None, nil
+	}, 0, "B.extend(iterable_of_ints) -> None\n\nAppend all the elements from the iterator or sequence to the\nend of B."))
+
+	ByteArrayType.Dict.Set("insert", MustNewMethod("insert", func(self Object, args Tuple) (Object, error) {
+		a := self.(*ByteArray)
+		var index, item Object
+		err := UnpackTuple(args, nil, "insert", 2, 2, &index, &item)
+		if err != nil {
+			return nil, err
+		}
+		i, err := IndexInt(index)
+		if err != nil {
+			return nil, err
+		}
+		c, err := byteValue(item)
+		if err != nil {
+			return nil, err
+		}
+		if err = a.resizable(); err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			i += len(a.Bytes)
+			if i < 0 {
+				i = 0
+			}
+		} else if i > len(a.Bytes) {
+			i = len(a.Bytes)
+		}
+		a.Bytes = append(a.Bytes, 0)
+		copy(a.Bytes[i+1:], a.Bytes[i:])
+		a.Bytes[i] = c
+		return None, nil
+	}, 0, "B.insert(index, int) -> None\n\nInsert a single item into the bytearray before the given index."))
+
+	ByteArrayType.Dict.Set("pop", MustNewMethod("pop", func(self Object, args Tuple) (Object, error) {
+		a := self.(*ByteArray)
+		var index Object = Int(-1)
+		err := UnpackTuple(args, nil, "pop", 0, 1, &index)
+		if err != nil {
+			return nil, err
+		}
+		if len(a.Bytes) == 0 {
+			return nil, ExceptionNewf(IndexError, "pop from empty bytearray")
+		}
+		i, err := IndexInt(index)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			i += len(a.Bytes)
+		}
+		if i < 0 || i >= len(a.Bytes) {
+			return nil, ExceptionNewf(IndexError, "pop index out of range")
+		}
+		if err = a.resizable(); err != nil {
+			return nil, err
+		}
+		c := a.Bytes[i]
+		a.Bytes = append(a.Bytes[:i], a.Bytes[i+1:]...)
+		return Int(c), nil
+	}, 0, "B.pop([index]) -> int\n\nRemove and return a single item from B. If no index\nargument is given, will pop the last value."))
+
+	ByteArrayType.Dict.Set("remove", MustNewMethod("remove", func(self, value Object) (Object, error) {
+		a := self.(*ByteArray)
+		c, err := byteValue(value)
+		if err != nil {
+			return nil, err
+		}
+		for i := range a.Bytes {
+			if a.Bytes[i] == c {
+				if err = a.resizable(); err != nil {
+					return nil, err
+				}
+				a.Bytes = append(a.Bytes[:i], a.Bytes[i+1:]...)
+				return None, nil
+			}
+		}
+		return nil, ExceptionNewf(ValueError, "value not found in bytearray")
+	}, 0, "B.remove(int) -> None\n\nRemove the first occurrence of a value in B."))
+
+	ByteArrayType.Dict.Set("clear", MustNewMethod("clear", func(self Object) (Object, error) {
+		a := self.(*ByteArray)
+		if err := a.resizable(); err != nil {
+			return nil, err
+		}
+		a.Bytes = Bytes{}
+		return None, nil
+	}, 0, "B.clear() -> None\n\nClear the bytearray."))
+
+	ByteArrayType.Dict.Set("copy", MustNewMethod("copy", func(self Object) (Object, error) {
+		return NewByteArray(self.(*ByteArray).Bytes), nil
+	}, 0, "B.copy() -> bytearray\n\nReturn a copy of B."))
+
+	ByteArrayType.Dict.Set("reverse", MustNewMethod("reverse", func(self Object) (Object, error) {
+		b := self.(*ByteArray).Bytes
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return None, nil
+	}, 0, "B.reverse() -> None\n\nReverse the order of the values in B in place."))
+}
+
+func (a *ByteArray) M__str__() (Object, error) {
+	return a.M__repr__()
+}
+
+func (a *ByteArray) M__repr__() (Object, error) {
+	repr, err := a.Bytes.M__repr__()
+	if err != nil {
+		return nil, err
+	}
+	return String("bytearray(" + string(repr.(String)) + ")"), nil
+}
+
+func (a *ByteArray) M__len__() (Object, error) {
+	return Int(len(a.Bytes)), nil
+}
+
+func (a *ByteArray) M__iter__() (Object, error) {
+	return a.Bytes.M__iter__()
+}
+
+func (a *ByteArray) M__getitem__(key Object) (Object, error) {
+	return bytesGetItem(a, a.Bytes, key)
+}
+
+func (a *ByteArray) M__setitem__(key, value Object) (Object, error) {
+	if slice, ok := key.(*Slice); ok {
+		start, stop, step, slicelength, err := slice.GetIndices(len(a.Bytes))
+		if err != nil {
+			return nil, err
+		}
+		switch value.(type) {
+		case Int, *BigInt, Bool:
+			return nil, ExceptionNewf(TypeError, "can assign only bytes, buffers, or iterables of ints in range(0, 256)")
+		}
+		// BytesFromObject copies the new bytes in case value is a itself
+		newBytes, err := BytesFromObject(value)
+		if err != nil {
+			return nil, err
+		}
+		if step == 1 {
+			if stop < start {
+				stop = start
+			}
+			if stop-start == len(newBytes) {
+				// Same size so overwrite in place
+				copy(a.Bytes[start:stop], newBytes)
+				return None, nil
+			}
+			if err = a.resizable(); err != nil {
+				return nil, err
+			}
+			b := make(Bytes, 0, len(a.Bytes)-(stop-start)+len(newBytes))
+			b = append(b, a.Bytes[:start]...)
+			b = append(b, newBytes...)
+			a.Bytes = append(b, a.Bytes[stop:]...)
+		} else {
+			if len(newBytes) != slicelength {
+				return nil, ExceptionNewf(ValueError, "attempt to assign bytes of size %d to extended slice of size %d", len(newBytes), slicelength)
+			}
+			for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
+				a.Bytes[i] = newBytes[j]
+			}
+		}
+		return None, nil
+	}
+	i, err := IndexIntCheck(key, len(a.Bytes))
+	if err != nil {
+		return nil, err
+	}
+	c, err := byteValue(value)
+	if err != nil {
+		return nil, err
+	}
+	a.Bytes[i] = c
+	return None, nil
+}
+
+func (a *ByteArray) M__delitem__(key Object) (Object, error) {
+	if err := a.resizable(); err != nil {
+		return nil, err
+	}
+	if slice, ok := key.(*Slice); ok {
+		start, stop, step, slicelength, err := slice.GetIndices(len(a.Bytes))
+		if err != nil {
+			return nil, err
+		}
+		if step == 1 {
+			if stop > start {
+				a.Bytes = append(a.Bytes[:start], a.Bytes[stop:]...)
+			}
+		} else if slicelength > 0 {
+			if step < 0 {
+				// Delete the same bytes working forwards
+				start += (slicelength - 1) * step
+				step = -step
+			}
+			end := start + (slicelength-1)*step
+			j := start
+			for i := start; i < len(a.Bytes); i++ {
+				if i <= end && (i-start)%step == 0 {
+					continue
+				}
+				a.Bytes[j] = a.Bytes[i]
+				j++
+			}
+			a.Bytes = a.Bytes[:j]
+		}
+		return None, nil
+	}
+	i, err := IndexIntCheck(key, len(a.Bytes))
+	if err != nil {
+		return nil, err
+	}
+	a.Bytes = append(a.Bytes[:i], a.Bytes[i+1:]...)
+	return None, nil
+}
+
+func (a *ByteArray) M__contains__(item Object) (Object, error) {
+	return a.Bytes.M__contains__(item)
+}
+
+func (a *ByteArray) M__add__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		c := make(Bytes, 0, len(a.Bytes)+len(b))
+		c = append(c, a.Bytes...)
+		return &ByteArray{Bytes: append(c, b...)}, nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__iadd__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		if err := a.resizable(); err != nil {
+			return nil, err
+		}
+		a.Bytes = append(a.Bytes, b...)
+		return a, nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__mul__(other Object) (Object, error) {
+	res, err := a.Bytes.M__mul__(other)
+	if err != nil || res == NotImplemented {
+		return res, err
+	}
+	return &ByteArray{Bytes: res.(Bytes)}, nil
+}
+
+func (a *ByteArray) M__rmul__(other Object) (Object, error) {
+	return a.M__mul__(other)
+}
+
+func (a *ByteArray) M__imul__(other Object) (Object, error) {
+	res, err := a.M__mul__(other)
+	if err != nil || res == NotImplemented {
+		return res, err
+	}
+	if err = a.resizable(); err != nil {
+		return nil, err
+	}
+	a.Bytes = res.(*ByteArray).Bytes
+	return a, nil
+}
+
+func (a *ByteArray) M__mod__(other Object) (Object, error) {
+	b, err := bytesFormat(a.Bytes, other)
+	if err != nil {
+		return nil, err
+	}
+	return &ByteArray{Bytes: b}, nil
+}
+
+// Buffer shares the bytes which may be written to
+func (a *ByteArray) Buffer() ([]byte, bool, error) {
+	return a.Bytes, false, nil
+}
+
+// Rich comparison
+
+func (a *ByteArray) M__lt__(other Object) (Object, error) {
+	return a.Bytes.M__lt__(other)
+}
+
+func (a *ByteArray) M__le__(other Object) (Object, error) {
+	return a.Bytes.M__le__(other)
+}
+
+func (a *ByteArray) M__eq__(other Object) (Object, error) {
+	return a.Bytes.M__eq__(other)
+}
+
+func (a *ByteArray) M__ne__(other Object) (Object, error) {
+	return a.Bytes.M__ne__(other)
+}
+
+func (a *ByteArray) M__gt__(other Object) (Object, error) {
+	return a.Bytes.M__gt__(other)
+}
+
+func (a *ByteArray) M__ge__(other Object) (Object, error) {
+	return a.Bytes.M__ge__(other)
+}
+
+// Check interface is satisfied
+var _ richComparison = (*ByteArray)(nil)
+var _ I__add__ = (*ByteArray)(nil)
+var _ I__iadd__ = (*ByteArray)(nil)
+var _ I__mul__ = (*ByteArray)(nil)
+var _ I__imul__ = (*ByteArray)(nil)
+var _ I__repr__ = (*ByteArray)(nil)
+var _ I__len__ = (*ByteArray)(nil)
+var _ I__iter__ = (*ByteArray)(nil)
+var _ I__getitem__ = (*ByteArray)(nil)
+var _ I__setitem__ = (*ByteArray)(nil)
+var _ I__delitem__ = (*ByteArray)(nil)
+var _ I__contains__ = (*ByteArray)(nil)
+var _ I__mod__ = (*ByteArray)(nil)
+var _ IBuffer = (*ByteArray)(nil)
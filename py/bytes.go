@@ -8,8 +8,11 @@ package py
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 var BytesType = ObjectType.NewType("bytes",
@@ -56,15 +59,10 @@ func BytesNew(metatype *Type, args Tuple, kwargs StringDict) (res Object, err er
 		if encoding == nil {
 			return nil, ExceptionNewf(TypeError, "string argument without an encoding")
 		}
-		encodingStr := strings.ToLower(string(encoding.(String)))
-		if encodingStr == "utf-8" || encodingStr == "utf8" {
-			return Bytes([]byte(s)), nil
+		if errors == nil {
+			errors = String("strict")
 		}
-		// FIXME
-		// New = PyUnicode_AsEncodedString(x, encoding, errors)
-		// assert(PyBytes_Check(New))
-		// return New
-		return nil, ExceptionNewf(NotImplementedError, "String decode for %q not implemented", encodingStr)
+		return s.Encode(string(encoding.(String)), string(errors.(String)))
 	}
 
 	// We'd like to call PyObject_Bytes here, but we need to check for an
@@ -99,6 +97,9 @@ no_bytes_method:
 		if size < 0 {
 			return nil, ExceptionNewf(ValueError, "negative count")
 		}
+		if err := checkSize(Int(size), 1); err != nil {
+			return nil, err
+		}
 		return make(Bytes, size), nil
 	}
 
@@ -113,13 +114,22 @@ no_bytes_method:
 // Converts an object into bytes
 func BytesFromObject(x Object) (Bytes, error) {
 	// Look for special cases
-	// FIXME implement converting from any object implementing the buffer API.
 	switch z := x.(type) {
 	case Bytes:
 		// Immutable type so just return what was passed in
 		return z, nil
+	case *ByteArray:
+		return append(Bytes{}, z.Bytes...), nil
 	case String:
 		return nil, ExceptionNewf(TypeError, "cannot convert unicode object to bytes")
+	case *MemoryView:
+		return z.Bytes()
+	case IBuffer:
+		buf, _, err := z.Buffer()
+		if err != nil {
+			return nil, err
+		}
+		return append(Bytes{}, buf...), nil
 	}
 	// Otherwise iterate through the whatever converting it into ints
 	b := Bytes{}
@@ -192,10 +202,923 @@ func convertToBytes(other Object) (Bytes, bool) {
 	switch b := other.(type) {
 	case Bytes:
 		return b, true
+	case *ByteArray:
+		return b.Bytes, true
+	case IBuffer:
+		buf, _, err := b.Buffer()
+		if err == nil {
+			return buf, true
+		}
 	}
 	return []byte(nil), false
 }
 
+// bytesArg converts an argument which must be bytes-like into Bytes
+func bytesArg(obj Object) (Bytes, error) {
+	if b, ok := convertToBytes(obj); ok {
+		return b, nil
+	}
+	return nil, ExceptionNewf(TypeError, "a bytes-like object is required, not '%s'", obj.Type().Name)
+}
+
+// newBytesLike makes a bytes or a bytearray from b to match the type
+// of self
+func newBytesLike(self Object, b []byte) Object {
+	if _, ok := self.(*ByteArray); ok {
+		return &ByteArray{Bytes: b}
+	}
+	return Bytes(b)
+}
+
+// bytesList makes a python list of bytes or bytearrays from bs to
+// match the type of self
+func bytesList(self Object, bs []string) *List {
+	l := NewListSized(len(bs))
+	for i := range bs {
+		l.Items[i] = newBytesLike(self, []byte(bs[i]))
+	}
+	return l
+}
+
+// isASCIISpace reports whether r is whitespace as bytes.isspace sees
+// it
+func isASCIISpace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r', '\x0b', '\x0c':
+		return true
+	}
+	return false
+}
+
+// bytesSub parses the sub, start and end arguments of find, count and
+// friends returning sub and the bytes of self to search
+func bytesSub(self Object, args Tuple, name string) (sub, b Bytes, offset int, ok bool, err error) {
+	var subObj, start, end Object
+	err = ParseTuple(args, "O|OO:"+name, &subObj, &start, &end)
+	if err != nil {
+		return nil, nil, 0, false, err
+	}
+	switch subObj.(type) {
+	case Int, *BigInt, Bool:
+		c, err := byteValue(subObj)
+		if err != nil {
+			return nil, nil, 0, false, err
+		}
+		sub = Bytes{c}
+	default:
+		sub, err = bytesArg(subObj)
+		if err != nil {
+			return nil, nil, 0, false, err
+		}
+	}
+	b, _ = convertToBytes(self)
+	i, j, err := indices(start, end, len(b))
+	if err != nil || i > j {
+		return nil, nil, 0, false, err
+	}
+	return sub, b[i:j], i, true, nil
+}
+
+// bytesFind implements find, rfind, index and rindex returning the
+// position of the subsequence or -1 if not found
+func bytesFind(self Object, args Tuple, name string, reverse bool) (int, error) {
+	sub, b, offset, ok, err := bytesSub(self, args, name)
+	if err != nil || !ok {
+		return -1, err
+	}
+	var n int
+	if reverse {
+		n = bytes.LastIndex(b, sub)
+	} else {
+		n = bytes.Index(b, sub)
+	}
+	if n < 0 {
+		return -1, nil
+	}
+	return offset + n, nil
+}
+
+// bytesStripArg parses the optional argument of strip, lstrip and
+// rstrip returning a function which is true for the bytes to strip
+func bytesStripArg(args Tuple, name string) (func(rune) bool, error) {
+	var chars Object = None
+	err := UnpackTuple(args, nil, name, 0, 1, &chars)
+	if err != nil {
+		return nil, err
+	}
+	if chars == None {
+		return isASCIISpace, nil
+	}
+	cutset, err := bytesArg(chars)
+	if err != nil {
+		return nil, err
+	}
+	var strip [256]bool
+	for _, c := range cutset {
+		strip[c] = true
+	}
+	return func(r rune) bool {
+		return r < 256 && strip[r]
+	}, nil
+}
+
+// bytesTrim trims the bytes of s which strip is true for from the left
+// and or the right
+//
+// The bytes may not be valid utf-8 so each one is checked on its own.
+func bytesTrim(s Bytes, strip func(rune) bool, left, right bool) Bytes {
+	i, j := 0, len(s)
+	for left && i < j && strip(rune(s[i])) {
+		i++
+	}
+	for right && j > i && strip(rune(s[j-1])) {
+		j--
+	}
+	return s[i:j]
+}
+
+// bytesReplace replaces count occurrences of old in s with new or all
+// of them if count is negative
+func bytesReplace(s, old, new Bytes, count int) Bytes {
+	if len(old) != 0 {
+		return bytes.Replace(s, old, new, count)
+	}
+	// bytes.Replace inserts new between utf-8 sequences not bytes
+	out := make(Bytes, 0, len(s)+(len(s)+1)*len(new))
+	for i := 0; i <= len(s); i++ {
+		if count != 0 {
+			out = append(out, new...)
+			count--
+		}
+		if i < len(s) {
+			out = append(out, s[i])
+		}
+	}
+	return out
+}
+
+// bytesHasAffix implements startswith and endswith using has to test
+// each of the prefixes or suffixes against self[start:end]
+func bytesHasAffix(self Object, args Tuple, name string, has func([]byte, []byte) bool) (Object, error) {
+	var affix, start, end Object
+	err := ParseTuple(args, "O|OO:"+name, &affix, &start, &end)
+	if err != nil {
+		return nil, err
+	}
+	var affixes Tuple
+	if t, ok := affix.(Tuple); ok {
+		affixes = t
+	} else if _, ok := convertToBytes(affix); ok {
+		affixes = Tuple{affix}
+	} else {
+		return nil, ExceptionNewf(TypeError, "%s first arg must be bytes or a tuple of bytes, not %s", name, affix.Type().Name)
+	}
+	b, _ := convertToBytes(self)
+	i, j, err := indices(start, end, len(b))
+	if err != nil {
+		return nil, err
+	}
+	if i > j {
+		return False, nil
+	}
+	for _, a := range affixes {
+		aBytes, ok := convertToBytes(a)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "a bytes-like object is required, not '%s'", a.Type().Name)
+		}
+		if has(b[i:j], aBytes) {
+			return True, nil
+		}
+	}
+	return False, nil
+}
+
+// Decode returns b decoded with the named encoding
+//
+// It supports the utf-8, ascii and latin-1 codecs with the strict,
+// ignore, replace and backslashreplace error handlers.
+func (b Bytes) Decode(encoding, errors string) (String, error) {
+	codec, limit, err := lookupCodec(encoding)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for i := 0; i < len(b); {
+		var r rune
+		size := 1
+		reason := ""
+		if codec == "utf-8" {
+			r, size = utf8.DecodeRune(b[i:])
+			if r == utf8.RuneError && size <= 1 {
+				reason = utf8Error(b[i:])
+			}
+		} else {
+			r = rune(b[i])
+			if r >= limit {
+				reason = fmt.Sprintf("ordinal not in range(%d)", limit)
+			}
+		}
+		if reason == "" {
+			out.WriteRune(r)
+			i += size
+			continue
+		}
+		switch errors {
+		case "strict":
+			return "", ExceptionNewf(UnicodeDecodeError, "'%s' codec can't decode byte 0x%02x in position %d: %s", codec, b[i], i, reason)
+		case "ignore":
+		case "replace":
+			out.WriteRune(utf8.RuneError)
+		case "backslashreplace":
+			fmt.Fprintf(&out, "\\x%02x", b[i])
+		default:
+			return "", ExceptionNewf(LookupError, "unknown error handler name '%s'", errors)
+		}
+		i++
+	}
+	return String(out.String()), nil
+}
+
+// utf8Error describes why the utf-8 sequence at the start of b is
+// invalid as CPython does
+func utf8Error(b []byte) string {
+	n := 0
+	switch c := b[0]; {
+	case c >= 0xC2 && c <= 0xDF:
+		n = 2
+	case c >= 0xE0 && c <= 0xEF:
+		n = 3
+	case c >= 0xF0 && c <= 0xF4:
+		n = 4
+	default:
+		return "invalid start byte"
+	}
+	for i := 1; i < n; i++ {
+		if i >= len(b) {
+			return "unexpected end of data"
+		}
+		if b[i]&0xC0 != 0x80 {
+			break
+		}
+	}
+	return "invalid continuation byte"
+}
+
+// fromhex makes bytes from a string of hexadecimal digits
+func fromhex(s string) (Bytes, error) {
+	out := make(Bytes, 0, len(s)/2)
+	for i := 0; i < len(s); {
+		if isASCIISpace(rune(s[i])) {
+			i++
+			continue
+		}
+		if i+1 >= len(s) {
+			return nil, ExceptionNewf(ValueError, "non-hexadecimal number found in fromhex() arg at position %d", i+1)
+		}
+		c, err := hex.DecodeString(s[i : i+2])
+		if err != nil {
+			position := i
+			if _, err := hex.DecodeString(s[i:i+1] + "0"); err == nil {
+				position++
+			}
+			return nil, ExceptionNewf(ValueError, "non-hexadecimal number found in fromhex() arg at position %d", position)
+		}
+		out = append(out, c[0])
+		i += 2
+	}
+	return out, nil
+}
+
+// bytesSplitLines splits b at line boundaries, which are only \n, \r
+// and \r\n for bytes, keeping the line endings if keepends is set
+func bytesSplitLines(b Bytes, keepends bool) []string {
+	out := []string{}
+	for len(b) > 0 {
+		i := bytes.IndexAny(b, "\r\n")
+		if i < 0 {
+			out = append(out, string(b))
+			break
+		}
+		j := i + 1
+		if b[i] == '\r' && j < len(b) && b[j] == '\n' {
+			j++
+		}
+		if keepends {
+			out = append(out, string(b[:j]))
+		} else {
+			out = append(out, string(b[:i]))
+		}
+		b = b[j:]
+	}
+	return out
+}
+
+// bytesMapASCII returns a copy of b with the ASCII letters mapped by f
+func bytesMapASCII(b Bytes, f func(rune) rune) Bytes {
+	out := make(Bytes, len(b))
+	for i, c := range b {
+		if c < utf8.RuneSelf {
+			c = byte(f(rune(c)))
+		}
+		out[i] = c
+	}
+	return out
+}
+
+// bytesAll returns whether b is not empty and f is true for all its
+// bytes
+func bytesAll(b Bytes, f func(byte) bool) Object {
+	if len(b) == 0 {
+		return False
+	}
+	for _, c := range b {
+		if !f(c) {
+			return False
+		}
+	}
+	return True
+}
+
+// bytesJustifyArgs parses the width and optional fill byte arguments
+// of center, ljust and rjust returning the amount of padding needed
+func bytesJustifyArgs(b Bytes, args Tuple, name string) (int, byte, error) {
+	var width Object
+	var fill Object = Bytes(" ")
+	err := ParseTuple(args, "i|O:"+name, &width, &fill)
+	if err != nil {
+		return 0, 0, err
+	}
+	fillBytes, ok := convertToBytes(fill)
+	if !ok || len(fillBytes) != 1 {
+		return 0, 0, ExceptionNewf(TypeError, "%s() argument 2 must be a byte string of length 1, not %s", name, fill.Type().Name)
+	}
+	if err := checkSize(width.(Int), 1); err != nil {
+		return 0, 0, err
+	}
+	return int(width.(Int)) - len(b), fillBytes[0], nil
+}
+
+// bytesPad returns b with left fill bytes before it and right after
+func bytesPad(b Bytes, left, right int, fill byte) Bytes {
+	out := make(Bytes, 0, left+len(b)+right)
+	out = append(out, bytes.Repeat(Bytes{fill}, left)...)
+	out = append(out, b...)
+	return append(out, bytes.Repeat(Bytes{fill}, right)...)
+}
+
+// bytesExpandTabs replaces the tabs in b with enough spaces to reach
+// the next multiple of tabsize columns
+func bytesExpandTabs(b Bytes, tabsize int) (Bytes, error) {
+	var out Bytes
+	column := 0
+	for _, c := range b {
+		switch c {
+		case '\t':
+			if tabsize > 0 {
+				n := tabsize - column%tabsize
+				if err := checkSize(Int(len(out))+Int(n), 1); err != nil {
+					return nil, err
+				}
+				out = append(out, bytes.Repeat(Bytes(" "), n)...)
+				column += n
+			}
+		case '\n', '\r':
+			out = append(out, c)
+			column = 0
+		default:
+			out = append(out, c)
+			column++
+		}
+	}
+	return out, nil
+}
+
+// bytesMaketrans makes a translation table for bytes.translate
+func bytesMaketrans(self Object, args Tuple) (Object, error) {
+	var fromObj, toObj Object
+	err := UnpackTuple(args, nil, "maketrans", 2, 2, &fromObj, &toObj)
+	if err != nil {
+		return nil, err
+	}
+	from, err := bytesArg(fromObj)
+	if err != nil {
+		return nil, err
+	}
+	to, err := bytesArg(toObj)
+	if err != nil {
+		return nil, err
+	}
+	if len(from) != len(to) {
+		return nil, ExceptionNewf(ValueError, "maketrans arguments must have same length")
+	}
+	table := make(Bytes, 256)
+	for i := range table {
+		table[i] = byte(i)
+	}
+	for i, c := range from {
+		table[c] = to[i]
+	}
+	return table, nil
+}
+
+// bytesTranslate maps each byte of b through table, which may be None
+// to leave them alone, removing the bytes in delete
+func bytesTranslate(b Bytes, tableObj, deleteObj Object) (Bytes, error) {
+	var table Bytes
+	if tableObj != None {
+		var err error
+		table, err = bytesArg(tableObj)
+		if err != nil {
+			return nil, err
+		}
+		if len(table) != 256 {
+			return nil, ExceptionNewf(ValueError, "translation table must be 256 characters long")
+		}
+	}
+	del, err := bytesArg(deleteObj)
+	if err != nil {
+		return nil, err
+	}
+	var deleted [256]bool
+	for _, c := range del {
+		deleted[c] = true
+	}
+	out := make(Bytes, 0, len(b))
+	for _, c := range b {
+		if deleted[c] {
+			continue
+		}
+		if table != nil {
+			c = table[c]
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func init() {
+	for _, t := range []*Type{BytesType, ByteArrayType} {
+		t.Dict.Set("decode", MustNewMethod("decode", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			var encoding Object = String("utf-8")
+			var errors Object = String("strict")
+			err := ParseTupleAndKeywords(args, kwargs, "|ss:decode", []string{"encoding", "errors"}, &encoding, &errors)
+			if err != nil {
+				return nil, err
+			}
+			b, _ := convertToBytes(self)
+			return b.Decode(string(encoding.(String)), string(errors.(String)))
+		}, 0, "B.decode(encoding='utf-8', errors='strict') -> str\n\nDecode B using the codec registered for encoding."))
+
+		t.Dict.Set("hex", MustNewMethod("hex", func(self Object) (Object, error) {
+			b, _ := convertToBytes(self)
+			return String(hex.EncodeToString(b)), nil
+		}, 0, "B.hex() -> str\n\nCreate a string of hexadecimal numbers from a bytes object."))
+
+		t.Dict.Set("fromhex", &ClassMethod{
+			Callable: MustNewMethod("fromhex", func(self, arg Object) (Object, error) {
+				s, ok := arg.(String)
+				if !ok {
+					return nil, ExceptionNewf(TypeError, "fromhex() argument must be str, not %s", arg.Type().Name)
+				}
+				b, err := fromhex(string(s))
+				if err != nil {
+					return nil, err
+				}
+				if self == ByteArrayType {
+					return &ByteArray{Bytes: b}, nil
+				}
+				return b, nil
+			}, 0, "B.fromhex(string) -> bytes\n\nCreate a bytes object from a string of hexadecimal numbers.\nSpaces between two numbers are accepted.\nExample: bytes.fromhex('B9 01EF') -> b'\\xb9\\x01\\xef'."),
+			Dict: NewStringDict(),
+		})
+
+		t.Dict.Set("find", MustNewMethod("find", func(self Object, args Tuple) (Object, error) {
+			i, err := bytesFind(self, args, "find", false)
+			if err != nil {
+				return nil, err
+			}
+			return Int(i), nil
+		}, 0, "B.find(sub[, start[, end]]) -> int\n\nReturn the lowest index in B where subsection sub is found,\nsuch that sub is contained within B[start,end].\n\nReturn -1 on failure."))
+
+		t.Dict.Set("rfind", MustNewMethod("rfind", func(self Object, args Tuple) (Object, error) {
+			i, err := bytesFind(self, args, "rfind", true)
+			if err != nil {
+				return nil, err
+			}
+			return Int(i), nil
+		}, 0, "B.rfind(sub[, start[, end]]) -> int\n\nReturn the highest index in B where subsection sub is found,\nsuch that sub is contained within B[start,end].\n\nReturn -1 on failure."))
+
+		t.Dict.Set("index", MustNewMethod("index", func(self Object, args Tuple) (Object, error) {
+			i, err := bytesFind(self, args, "index", false)
+			if err != nil {
+				return nil, err
+			}
+			if i < 0 {
+				return nil, ExceptionNewf(ValueError, "subsection not found")
+			}
+			return Int(i), nil
+		}, 0, "B.index(sub[, start[, end]]) -> int\n\nLike B.find() but raise ValueError when the subsection is not found."))
+
+		t.Dict.Set("rindex", MustNewMethod("rindex", func(self Object, args Tuple) (Object, error) {
+			i, err := bytesFind(self, args, "rindex", true)
+			if err != nil {
+				return nil, err
+			}
+			if i < 0 {
+				return nil, ExceptionNewf(ValueError, "subsection not found")
+			}
+			return Int(i), nil
+		}, 0, "B.rindex(sub[, start[, end]]) -> int\n\nLike B.rfind() but raise ValueError when the subsection is not found."))
+
+		t.Dict.Set("count", MustNewMethod("count", func(self Object, args Tuple) (Object, error) {
+			sub, b, _, ok, err := bytesSub(self, args, "count")
+			if err != nil || !ok {
+				return Int(0), err
+			}
+			if len(sub) == 0 {
+				return Int(len(b) + 1), nil
+			}
+			return Int(bytes.Count(b, sub)), nil
+		}, 0, "B.count(sub[, start[, end]]) -> int\n\nReturn the number of non-overlapping occurrences of subsection sub in\nbytes B[start:end]."))
+
+		t.Dict.Set("split", MustNewMethod("split", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			sep, maxsplit, err := bytesSplitArgs(args, kwargs, "split")
+			if err != nil {
+				return nil, err
+			}
+			b, _ := convertToBytes(self)
+			if sep == nil {
+				return bytesList(self, splitWhitespace(string(b), maxsplit, isASCIISpace)), nil
+			}
+			if maxsplit >= 0 {
+				maxsplit++
+			}
+			return bytesList(self, strings.SplitN(string(b), string(sep), maxsplit)), nil
+		}, 0, "B.split(sep=None, maxsplit=-1) -> list of bytes\n\nReturn a list of the sections in B, using sep as the delimiter.\nIf sep is not specified or is None, B is split on ASCII whitespace\ncharacters (space, tab, return, newline, formfeed, vertical tab).\nIf maxsplit is given, at most maxsplit splits are done."))
+
+		t.Dict.Set("rsplit", MustNewMethod("rsplit", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			sep, maxsplit, err := bytesSplitArgs(args, kwargs, "rsplit")
+			if err != nil {
+				return nil, err
+			}
+			b, _ := convertToBytes(self)
+			if sep == nil {
+				return bytesList(self, rsplitWhitespace(string(b), maxsplit, isASCIISpace)), nil
+			}
+			return bytesList(self, rsplit(string(b), string(sep), maxsplit)), nil
+		}, 0, "B.rsplit(sep=None, maxsplit=-1) -> list of bytes\n\nReturn a list of the sections in B, using sep as the delimiter,\nstarting at the end of B and working to the front."))
+
+		t.Dict.Set("join", MustNewMethod("join", func(self, iterable Object) (Object, error) {
+			sep, _ := convertToBytes(self)
+			var out Bytes
+			i := 0
+			var itemErr error
+			err := Iterate(iterable, func(item Object) bool {
+				b, ok := convertToBytes(item)
+				if !ok {
+					itemErr = ExceptionNewf(TypeError, "sequence item %d: expected a bytes-like object, %s found", i, item.Type().Name)
+					return true
+				}
+				if i > 0 {
+					out = append(out, sep...)
+				}
+				out = append(out, b...)
+				i++
+				return false
+			})
+			if err != nil {
+				return nil, err
+			}
+			if itemErr != nil {
+				return nil, itemErr
+			}
+			return newBytesLike(self, append(Bytes{}, out...)), nil
+		}, 0, "B.join(iterable_of_bytes) -> bytes\n\nConcatenate any number of bytes objects, with B in between each pair."))
+
+		t.Dict.Set("replace", MustNewMethod("replace", func(self Object, args Tuple) (Object, error) {
+			var oldObj, newObj Object
+			var count Object = Int(-1)
+			err := ParseTuple(args, "OO|i:replace", &oldObj, &newObj, &count)
+			if err != nil {
+				return nil, err
+			}
+			old, err := bytesArg(oldObj)
+			if err != nil {
+				return nil, err
+			}
+			new, err := bytesArg(newObj)
+			if err != nil {
+				return nil, err
+			}
+			b, _ := convertToBytes(self)
+			return newBytesLike(self, append(Bytes{}, bytesReplace(b, old, new, int(count.(Int)))...)), nil
+		}, 0, "B.replace(old, new[, count]) -> bytes\n\nReturn a copy of B with all occurrences of subsection\nold replaced by new.  If the optional argument count is\ngiven, only first count occurances are replaced."))
+
+		for _, strip := range []struct {
+			name        string
+			left, right bool
+			doc         string
+		}{
+			{"strip", true, true, "B.strip([bytes]) -> bytes\n\nStrip leading and trailing bytes contained in the argument.\nIf the argument is omitted, strip leading and trailing ASCII whitespace."},
+			{"lstrip", true, false, "B.lstrip([bytes]) -> bytes\n\nStrip leading bytes contained in the argument.\nIf the argument is omitted, strip leading ASCII whitespace."},
+			{"rstrip", false, true, "B.rstrip([bytes]) -> bytes\n\nStrip trailing bytes contained in the argument.\nIf the argument is omitted, strip trailing ASCII whitespace."},
+		} {
+			strip := strip
+			t.Dict.Set(strip.name, MustNewMethod(strip.name, func(self Object, args Tuple) (Object, error) {
+				f, err := bytesStripArg(args, strip.name)
+				if err != nil {
+					return nil, err
+				}
+				b, _ := convertToBytes(self)
+				return newBytesLike(self, append(Bytes{}, bytesTrim(b, f, strip.left, strip.right)...)), nil
+			}, 0, strip.doc))
+		}
+
+		t.Dict.Set("startswith", MustNewMethod("startswith", func(self Object, args Tuple) (Object, error) {
+			return bytesHasAffix(self, args, "startswith", bytes.HasPrefix)
+		}, 0, "B.startswith(prefix[, start[, end]]) -> bool\n\nReturn True if B starts with the specified prefix, False otherwise.\nWith optional start, test B beginning at that position.\nWith optional end, stop comparing B at that position.\nprefix can also be a tuple of bytes to try."))
+
+		t.Dict.Set("endswith", MustNewMethod("endswith", func(self Object, args Tuple) (Object, error) {
+			return bytesHasAffix(self, args, "endswith", bytes.HasSuffix)
+		}, 0, "B.endswith(suffix[, start[, end]]) -> bool\n\nReturn True if B ends with the specified suffix, False otherwise.\nWith optional start, test B beginning at that position.\nWith optional end, stop comparing B at that position.\nsuffix can also be a tuple of bytes to try."))
+
+		t.Dict.Set("partition", MustNewMethod("partition", func(self, sepObj Object) (Object, error) {
+			sep, err := bytesArg(sepObj)
+			if err != nil {
+				return nil, err
+			}
+			if len(sep) == 0 {
+				return nil, ExceptionNewf(ValueError, "empty separator")
+			}
+			b, _ := convertToBytes(self)
+			i := bytes.Index(b, sep)
+			if i < 0 {
+				return Tuple{newBytesLike(self, append(Bytes{}, b...)), newBytesLike(self, Bytes{}), newBytesLike(self, Bytes{})}, nil
+			}
+			return Tuple{newBytesLike(self, append(Bytes{}, b[:i]...)), newBytesLike(self, append(Bytes{}, sep...)), newBytesLike(self, append(Bytes{}, b[i+len(sep):]...))}, nil
+		}, 0, "B.partition(sep) -> (head, sep, tail)\n\nSearch for the separator sep in B, and return the part before it,\nthe separator itself, and the part after it.  If the separator is not\nfound, returns B and two empty bytes objects."))
+
+		t.Dict.Set("rpartition", MustNewMethod("rpartition", func(self, sepObj Object) (Object, error) {
+			sep, err := bytesArg(sepObj)
+			if err != nil {
+				return nil, err
+			}
+			if len(sep) == 0 {
+				return nil, ExceptionNewf(ValueError, "empty separator")
+			}
+			b, _ := convertToBytes(self)
+			i := bytes.LastIndex(b, sep)
+			if i < 0 {
+				return Tuple{newBytesLike(self, Bytes{}), newBytesLike(self, Bytes{}), newBytesLike(self, append(Bytes{}, b...))}, nil
+			}
+			return Tuple{newBytesLike(self, append(Bytes{}, b[:i]...)), newBytesLike(self, append(Bytes{}, sep...)), newBytesLike(self, append(Bytes{}, b[i+len(sep):]...))}, nil
+		}, 0, "B.rpartition(sep) -> (head, sep, tail)\n\nSearch for the separator sep in B, starting at the end of B,\nand return the part before it, the separator itself, and the\npart after it.  If the separator is not found, returns two empty\nbytes objects and B."))
+
+		t.Dict.Set("splitlines", MustNewMethod("splitlines", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			var keepends Object = False
+			err := ParseTupleAndKeywords(args, kwargs, "|O:splitlines", []string{"keepends"}, &keepends)
+			if err != nil {
+				return nil, err
+			}
+			keep, err := MakeBool(keepends)
+			if err != nil {
+				return nil, err
+			}
+			b, _ := convertToBytes(self)
+			return bytesList(self, bytesSplitLines(b, keep == True)), nil
+		}, 0, "B.splitlines([keepends]) -> list of lines\n\nReturn a list of the lines in B, breaking at line boundaries.\nLine breaks are not included in the resulting list unless keepends\nis given and true."))
+
+		t.Dict.Set("upper", MustNewMethod("upper", func(self Object) (Object, error) {
+			b, _ := convertToBytes(self)
+			return newBytesLike(self, bytesMapASCII(b, unicode.ToUpper)), nil
+		}, 0, "B.upper() -> copy of B\n\nReturn a copy of B with all ASCII characters converted to uppercase."))
+
+		t.Dict.Set("lower", MustNewMethod("lower", func(self Object) (Object, error) {
+			b, _ := convertToBytes(self)
+			return newBytesLike(self, bytesMapASCII(b, unicode.ToLower)), nil
+		}, 0, "B.lower() -> copy of B\n\nReturn a copy of B with all ASCII characters converted to lowercase."))
+
+		t.Dict.Set("center", MustNewMethod("center", func(self Object, args Tuple) (Object, error) {
+			b, _ := convertToBytes(self)
+			margin, fill, err := bytesJustifyArgs(b, args, "center")
+			if err != nil {
+				return nil, err
+			}
+			if margin <= 0 {
+				return newBytesLike(self, append(Bytes{}, b...)), nil
+			}
+			width := margin + len(b)
+			left := margin/2 + (margin & width & 1)
+			return newBytesLike(self, bytesPad(b, left, margin-left, fill)), nil
+		}, 0, "B.center(width[, fillchar]) -> copy of B\n\nReturn B centered in a string of length width.  Padding is\ndone using the specified fill character (default is a space)."))
+
+		t.Dict.Set("ljust", MustNewMethod("ljust", func(self Object, args Tuple) (Object, error) {
+			b, _ := convertToBytes(self)
+			margin, fill, err := bytesJustifyArgs(b, args, "ljust")
+			if err != nil {
+				return nil, err
+			}
+			if margin <= 0 {
+				return newBytesLike(self, append(Bytes{}, b...)), nil
+			}
+			return newBytesLike(self, bytesPad(b, 0, margin, fill)), nil
+		}, 0, "B.ljust(width[, fillchar]) -> copy of B\n\nReturn B left justified in a string of length width. Padding is\ndone using the specified fill character (default is a space)."))
+
+		t.Dict.Set("rjust", MustNewMethod("rjust", func(self Object, args Tuple) (Object, error) {
+			b, _ := convertToBytes(self)
+			margin, fill, err := bytesJustifyArgs(b, args, "rjust")
+			if err != nil {
+				return nil, err
+			}
+			if margin <= 0 {
+				return newBytesLike(self, append(Bytes{}, b...)), nil
+			}
+			return newBytesLike(self, bytesPad(b, margin, 0, fill)), nil
+		}, 0, "B.rjust(width[, fillchar]) -> copy of B\n\nReturn B right justified in a string of length width. Padding is\ndone using the specified fill character (default is a space)"))
+
+		t.Dict.Set("zfill", MustNewMethod("zfill", func(self Object, args Tuple) (Object, error) {
+			var width Object
+			err := ParseTuple(args, "i:zfill", &width)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkSize(width.(Int), 1); err != nil {
+				return nil, err
+			}
+			b, _ := convertToBytes(self)
+			margin := int(width.(Int)) - len(b)
+			if margin <= 0 {
+				return newBytesLike(self, append(Bytes{}, b...)), nil
+			}
+			out := bytesPad(b, margin, 0, '0')
+			if len(b) > 0 && (b[0] == '+' || b[0] == '-') {
+				out[0], out[margin] = b[0], '0'
+			}
+			return newBytesLike(self, out), nil
+		}, 0, "B.zfill(width) -> copy of B\n\nPad a numeric string B with zeros on the left, to fill a field\nof the specified width.  B is never truncated."))
+
+		for _, is := range []struct {
+			name string
+			f    func(byte) bool
+			doc  string
+		}{
+			{"isdigit", func(c byte) bool { return c >= '0' && c <= '9' }, "B.isdigit() -> bool\n\nReturn True if all characters in B are digits\nand there is at least one character in B, False otherwise."},
+			{"isalpha", func(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }, "B.isalpha() -> bool\n\nReturn True if all characters in B are alphabetic\nand there is at least one character in B, False otherwise."},
+			{"isspace", func(c byte) bool { return isASCIISpace(rune(c)) }, "B.isspace() -> bool\n\nReturn True if all characters in B are whitespace\nand there is at least one character in B, False otherwise."},
+		} {
+			is := is
+			t.Dict.Set(is.name, MustNewMethod(is.name, func(self Object) (Object, error) {
+				b, _ := convertToBytes(self)
+				return bytesAll(b, is.f), nil
+			}, 0, is.doc))
+		}
+
+		t.Dict.Set("expandtabs", MustNewMethod("expandtabs", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			var tabsize Object = Int(8)
+			err := ParseTupleAndKeywords(args, kwargs, "|i:expandtabs", []string{"tabsize"}, &tabsize)
+			if err != nil {
+				return nil, err
+			}
+			b, _ := convertToBytes(self)
+			out, err := bytesExpandTabs(b, int(tabsize.(Int)))
+			if err != nil {
+				return nil, err
+			}
+			return newBytesLike(self, out), nil
+		}, 0, "B.expandtabs(tabsize=8) -> copy of B\n\nReturn a copy of B where all tab characters are expanded using spaces.\nIf tabsize is not given, a tab size of 8 characters is assumed."))
+
+		t.Dict.Set("translate", MustNewMethod("translate", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			var table Object
+			var del Object = Bytes{}
+			err := ParseTupleAndKeywords(args, kwargs, "O|O:translate", []string{"table", "delete"}, &table, &del)
+			if err != nil {
+				return nil, err
+			}
+			b, _ := convertToBytes(self)
+			out, err := bytesTranslate(b, table, del)
+			if err != nil {
+				return nil, err
+			}
+			return newBytesLike(self, out), nil
+		}, 0, "B.translate(table[, deletechars]) -> copy of B\n\nReturn a copy of B, where all characters occurring in the\noptional argument deletechars are removed, and the remaining\ncharacters have been mapped through the given translation\ntable, which must be a bytes object of length 256."))
+
+		t.Dict.Set("maketrans", &StaticMethod{
+			Callable: MustNewMethod("maketrans", bytesMaketrans, 0, "B.maketrans(frm, to) -> translation table\n\nReturn a translation table (a bytes object of length 256) suitable\nfor use in the bytes or bytearray translate method where each byte\nin frm is mapped to the byte at the same position in to.\nThe bytes objects frm and to must be of the same length."),
+			Dict:     NewStringDict(),
+		})
+	}
+}
+
+// bytesSplitArgs parses the arguments of split and rsplit returning a
+// nil sep to split on whitespace
+func bytesSplitArgs(args Tuple, kwargs StringDict, name string) (sep Bytes, maxsplit int, err error) {
+	var sepObj Object = None
+	var maxsplitObj Object = Int(-1)
+	err = ParseTupleAndKeywords(args, kwargs, "|Oi:"+name, []string{"sep", "maxsplit"}, &sepObj, &maxsplitObj)
+	if err != nil {
+		return nil, 0, err
+	}
+	if sepObj != None {
+		sep, err = bytesArg(sepObj)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(sep) == 0 {
+			return nil, 0, ExceptionNewf(ValueError, "empty separator")
+		}
+	}
+	return sep, int(maxsplitObj.(Int)), nil
+}
+
+func (a Bytes) M__len__() (Object, error) {
+	return Int(len(a)), nil
+}
+
+func (a Bytes) M__iter__() (Object, error) {
+	items := make([]Object, len(a))
+	for i, c := range a {
+		items[i] = Int(c)
+	}
+	return NewIterator(items), nil
+}
+
+// bytesGetItem indexes or slices b returning slices with the same
+// type as self
+func bytesGetItem(self Object, b Bytes, key Object) (Object, error) {
+	if slice, ok := key.(*Slice); ok {
+		start, _, step, slicelength, err := slice.GetIndices(len(b))
+		if err != nil {
+			return nil, err
+		}
+		newBytes := make(Bytes, slicelength)
+		for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
+			newBytes[j] = b[i]
+		}
+		return newBytesLike(self, newBytes), nil
+	}
+	i, err := IndexIntCheck(key, len(b))
+	if err != nil {
+		return nil, err
+	}
+	return Int(b[i]), nil
+}
+
+func (a Bytes) M__getitem__(key Object) (Object, error) {
+	return bytesGetItem(a, a, key)
+}
+
+func (a Bytes) M__contains__(item Object) (Object, error) {
+	switch item.(type) {
+	case Int, *BigInt, Bool:
+		c, err := byteValue(item)
+		if err != nil {
+			return nil, err
+		}
+		return NewBool(bytes.IndexByte(a, c) >= 0), nil
+	}
+	b, err := bytesArg(item)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(bytes.Contains(a, b)), nil
+}
+
+func (a Bytes) M__add__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		c := make(Bytes, 0, len(a)+len(b))
+		c = append(c, a...)
+		return append(c, b...), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a Bytes) M__mul__(other Object) (Object, error) {
+	if n, ok := convertToInt(other); ok {
+		if n < 0 {
+			n = 0
+		}
+		if len(a) > 0 && n > Int(GoIntMax/len(a)) {
+			return nil, ExceptionNewf(OverflowError, "repeated bytes are too long")
+		}
+		if err := checkSize(n, len(a)); err != nil {
+			return nil, err
+		}
+		return Bytes(bytes.Repeat(a, int(n))), nil
+	}
+	if _, ok := other.(*BigInt); ok {
+		return nil, ExceptionNewf(OverflowError, "cannot fit 'int' into an index-sized integer")
+	}
+	return NotImplemented, nil
+}
+
+func (a Bytes) M__rmul__(other Object) (Object, error) {
+	return a.M__mul__(other)
+}
+
+func (a Bytes) M__mod__(other Object) (Object, error) {
+	return bytesFormat(a, other)
+}
+
 // Rich comparison
 
 func (a Bytes) M__lt__(other Object) (Object, error) {
@@ -240,5 +1163,24 @@ func (a Bytes) M__ge__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a Bytes) M__hash__() (Object, error) {
+	return Int(hashBytes(a)), nil
+}
+
+// Buffer shares the bytes read only
+func (a Bytes) Buffer() ([]byte, bool, error) {
+	return a, true, nil
+}
+
 // Check interface is satisfied
 var _ richComparison = (Bytes)(nil)
+var _ I__hash__ = Bytes(nil)
+var _ IBuffer = Bytes(nil)
+var _ I__len__ = Bytes(nil)
+var _ I__iter__ = Bytes(nil)
+var _ I__getitem__ = Bytes(nil)
+var _ I__contains__ = Bytes(nil)
+var _ I__add__ = Bytes(nil)
+var _ I__mul__ = Bytes(nil)
+var _ I__rmul__ = Bytes(nil)
+var _ I__mod__ = Bytes(nil)
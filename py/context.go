@@ -0,0 +1,433 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Interpreter contexts
+//
+// A Context holds all the state of a single python interpreter - the
+// table of imported modules, the builtins and the sys module.  Code
+// running in one Context can't see or modify the modules of another,
+// so several independent interpreters can be run in the same process.
+
+package py
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+var (
+	// Registry of module implementations which can be imported
+	// into any Context
+	moduleImpls = make(map[string]*ModuleImpl)
+)
+
+const (
+	// Number of instructions the code is allowed to run after a
+	// CancelledError or ResourceError is first raised in a run, so
+	// exception handlers and finally blocks can tidy up, before it
+	// is raised again
+	interruptGrace = 10000
+
+	// DefaultRecursionLimit is the maximum depth of python calls
+	// if ContextOpts.RecursionLimit isn't set
+	DefaultRecursionLimit = 1000
+
+	// MaxRecursionLimit is the largest recursion limit which can be
+	// set.  Deeper recursion risks overflowing the Go stack which
+	// can't be recovered from.
+	MaxRecursionLimit = 100000
+)
+
+// ModuleImpl describes a module implemented in Go.
+//
+// Each Context makes its own instance of the module the first time
+// it is needed, so changes made to the module by one interpreter are
+// not seen by another.
+type ModuleImpl struct {
+	Name    string
+	Doc     string
+	Methods []*Method
+	Globals StringDict
+	// Init, if set, is called when the module has been made in a
+	// Context to set up any per interpreter state
+	Init func(m *Module) error
+}
+
+// RegisterModule registers a module implementation so it can be
+// imported into any Context.
+//
+// This is normally called from the init() function of the package
+// implementing the module.
+func RegisterModule(impl *ModuleImpl) {
+	if _, found := moduleImpls[impl.Name]; found {
+		panic(fmt.Sprintf("module %q registered twice", impl.Name))
+	}
+	moduleImpls[impl.Name] = impl
+}
+
+// GetModuleImpl returns the registered implementation for the module
+// called name or nil if there isn't one
+func GetModuleImpl(name string) *ModuleImpl {
+	return moduleImpls[name]
+}
+
+// ModuleImplNames returns the sorted names of all the registered
+// module implementations
+func ModuleImplNames() []string {
+	names := make([]string, 0, len(moduleImpls))
+	for name := range moduleImpls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ContextOpts are the options used to create a new Context
+type ContextOpts struct {
+	// Argv is used to initialise sys.argv
+	Argv []string
+	// RecursionLimit is the initial maximum depth of python calls,
+	// DefaultRecursionLimit if 0
+	RecursionLimit int
+	// MaxInstructions is the maximum number of bytecode
+	// instructions each run may execute, or 0 for no limit
+	MaxInstructions int64
+	// FS, if set, is the filesystem open() and import read files
+	// from instead of the OS filesystem.  It is read only from
+	// python.
+	FS fs.FS
+	// Policy, if set, restricts what the code may do
+	Policy *Policy
+	// DontWriteBytecode stops imports writing the compiled modules
+	// to __pycache__, for read only deployments.  It is the
+	// initial value of sys.dont_write_bytecode.
+	DontWriteBytecode bool
+	// PycInvalidation is how the __pycache__ files written by
+	// imports are checked against their source
+	PycInvalidation PycInvalidationMode
+	// Stdin, Stdout and Stderr, if set, are used for sys.stdin,
+	// sys.stdout and sys.stderr instead of os.Stdin, os.Stdout and
+	// os.Stderr
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// A Context is an isolated python interpreter
+//
+// It owns its own table of modules, including builtins and sys, so
+// code run in different Contexts can't interfere with each other.
+//
+// A Context isn't safe for concurrent use by multiple go routines but
+// different Contexts may be used concurrently.
+type Context struct {
+	// Options this Context was made with
+	Opts ContextOpts
+	// Registry of modules loaded into this Context which is
+	// sys.modules
+	modules StringDict
+	// Builtin module
+	Builtins *Module
+	// PrintExpr, if set, is called with the repr of the values of
+	// expression statements in the REPL instead of sys.displayhook
+	PrintExpr func(out string)
+	// Go context which cancels the code running in this Context or nil
+	goCtx context.Context
+	// Done channel of goCtx
+	done <-chan struct{}
+	// Maximum depth of python calls
+	recursionLimit int
+	// Current depth of python calls
+	depth int
+	// Instructions run so far in this run
+	instructions int64
+	// Instructions left before an interrupt may be raised again
+	grace int
+	// Set if the grace period has been used in this run
+	graceUsed bool
+	// Sandbox policy or nil
+	policy *policy
+	// Globals of the module doing the current import
+	importGlobals StringDict
+}
+
+// NewContext makes a new interpreter Context with its own builtins
+// and sys modules
+//
+// It panics if the builtins or sys modules can't be initialised
+func NewContext(opts ContextOpts) *Context {
+	ctx := &Context{
+		Opts:           opts,
+		modules:        NewStringDict(),
+		recursionLimit: opts.RecursionLimit,
+		policy:         newPolicy(opts.Policy),
+	}
+	if ctx.recursionLimit <= 0 {
+		ctx.recursionLimit = DefaultRecursionLimit
+	}
+	for _, name := range []string{"builtins", "sys"} {
+		if _, err := ctx.GetModule(name); err != nil {
+			panic(fmt.Sprintf("failed to initialise %q module: %v", name, err))
+		}
+	}
+	ctx.applyPolicy()
+	return ctx
+}
+
+// NewModule makes a new module in this Context and registers it so
+// it can be found with GetModule
+func (ctx *Context) NewModule(name, doc string, methods []*Method, globals StringDict) *Module {
+	m := &Module{
+		Name:    name,
+		Doc:     doc,
+		Globals: globals.Copy(),
+		Context: ctx,
+	}
+	// Insert the methods into the module dictionary bound to
+	// this module
+	for _, method := range methods {
+		boundMethod := *method
+		boundMethod.Module = m
+		m.Globals.Set(method.Name, &boundMethod)
+	}
+	// Set some module globals
+	m.Globals.Set("__name__", String(name))
+	m.Globals.Set("__doc__", String(doc))
+	m.Globals.Set("__package__", None)
+	// Register the module
+	ctx.modules.Set(name, m)
+	// Make a note of some modules
+	switch name {
+	case "builtins":
+		ctx.Builtins = m
+	}
+	return m
+}
+
+// newModuleFromImpl makes an instance of a registered module in this
+// Context
+func (ctx *Context) newModuleFromImpl(impl *ModuleImpl) (*Module, error) {
+	m := ctx.NewModule(impl.Name, impl.Doc, impl.Methods, copyGlobal(impl.Globals).(StringDict))
+	if impl.Init != nil {
+		err := impl.Init(m)
+		if err != nil {
+			ctx.DeleteModule(impl.Name)
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// copyGlobal returns a copy of obj, a value from ModuleImpl.Globals,
+// for a new instance of the module
+//
+// The mutable containers are copied all the way down so changes made
+// to them by one Context aren't seen by another.
+func copyGlobal(obj Object) Object {
+	switch x := obj.(type) {
+	case *Dict:
+		d := x.Copy()
+		for i := range d.entries {
+			d.entries[i].value = copyGlobal(d.entries[i].value)
+		}
+		return d
+	case *List:
+		l := NewListSized(len(x.Items))
+		for i, item := range x.Items {
+			l.Items[i] = copyGlobal(item)
+		}
+		return l
+	case Tuple:
+		t := make(Tuple, len(x))
+		for i, item := range x {
+			t[i] = copyGlobal(item)
+		}
+		return t
+	case *Set:
+		return &Set{items: copyGlobal(x.items).(*Dict)}
+	case *ByteArray:
+		return NewByteArray(x.Bytes)
+	}
+	return obj
+}
+
+// GetModule returns the module called name from this Context
+//
+// If it hasn't been loaded yet but has a registered implementation,
+// then it is made first.
+func (ctx *Context) GetModule(name string) (*Module, error) {
+	if obj, ok := ctx.modules.Lookup(name); ok {
+		m, ok := obj.(*Module)
+		if !ok {
+			return nil, ExceptionNewf(ImportError, "sys.modules[%q] is not a module", name)
+		}
+		return m, nil
+	}
+	if impl, ok := moduleImpls[name]; ok {
+		return ctx.newModuleFromImpl(impl)
+	}
+	return nil, ExceptionNewf(ImportError, "Module %q not found", name)
+}
+
+// MustGetModule gets a module or panics
+func (ctx *Context) MustGetModule(name string) *Module {
+	m, err := ctx.GetModule(name)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// DeleteModule removes the module called name from this Context
+func (ctx *Context) DeleteModule(name string) {
+	ctx.modules.Delete(name)
+}
+
+// Modules returns the live table of the modules loaded into this
+// Context by name which python sees as sys.modules
+//
+// Modules may be added or removed, and entries needn't be *Module.
+func (ctx *Context) Modules() StringDict {
+	return ctx.modules
+}
+
+// ModuleNames returns the sorted names of the modules loaded into
+// this Context
+func (ctx *Context) ModuleNames() []string {
+	names := make([]string, 0, ctx.modules.Len())
+	for _, name := range ctx.modules.StringKeys() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenFile opens filename as the python open() does, from
+// ContextOpts.FS if set, otherwise from the OS filesystem
+//
+// It returns a PermissionError if the Policy doesn't allow it.
+func (ctx *Context) OpenFile(filename, mode string, buffering int) (Object, error) {
+	if err := ctx.checkOpen(filename, mode); err != nil {
+		return nil, err
+	}
+	if ctx.Opts.FS != nil {
+		return OpenFileFS(ctx.Opts.FS, filename, mode, buffering)
+	}
+	return OpenFile(filename, mode, buffering)
+}
+
+// SetGoContext makes code running in this Context stop with a
+// CancelledError when goCtx is cancelled or its deadline passes.
+//
+// Passing nil stops the checks.  It returns the previous value so it
+// can be restored.
+func (ctx *Context) SetGoContext(goCtx context.Context) context.Context {
+	old := ctx.goCtx
+	ctx.goCtx = goCtx
+	ctx.done = nil
+	if goCtx != nil {
+		ctx.done = goCtx.Done()
+	}
+	return old
+}
+
+// GoContext returns the context.Context the code in this Context is
+// running under, or context.Background() if none was set.
+//
+// Go functions which block should use this so they can be cancelled.
+func (ctx *Context) GoContext() context.Context {
+	if ctx.goCtx == nil {
+		return context.Background()
+	}
+	return ctx.goCtx
+}
+
+// CheckInterrupt is called by the VM before each instruction.
+//
+// It returns a CancelledError if the Go context the code is running
+// under has been cancelled or its deadline has passed, or a
+// ResourceError if the run has used up its instruction budget.
+//
+// The first time this happens in a run the code gets a short grace
+// period to handle the exception before it is raised again.
+func (ctx *Context) CheckInterrupt() error {
+	budget := ctx.Opts.MaxInstructions
+	if budget > 0 {
+		ctx.instructions++
+	}
+	if ctx.grace > 0 {
+		ctx.grace--
+		return nil
+	}
+	if budget > 0 && ctx.instructions > budget {
+		return ctx.interrupt(ExceptionNewf(ResourceError, "instruction budget of %d exceeded", budget))
+	}
+	if ctx.done == nil {
+		return nil
+	}
+	select {
+	case <-ctx.done:
+		return ctx.interrupt(ExceptionNewf(CancelledError, "%v", ctx.goCtx.Err()))
+	default:
+	}
+	return nil
+}
+
+// interrupt returns err, starting the grace period if it hasn't
+// been used yet in this run
+func (ctx *Context) interrupt(err error) error {
+	if !ctx.graceUsed {
+		ctx.graceUsed = true
+		ctx.grace = interruptGrace
+	}
+	return err
+}
+
+// EnterCall is called by the VM when it starts running a frame.
+//
+// It returns a RecursionError if the recursion limit would be
+// exceeded, otherwise LeaveCall must be called when the frame has
+// finished.  Starting the outermost frame begins a new run which
+// resets the instruction budget.
+func (ctx *Context) EnterCall() error {
+	if ctx.depth == 0 {
+		ctx.instructions = 0
+		ctx.grace = 0
+		ctx.graceUsed = false
+	}
+	if ctx.depth >= ctx.recursionLimit {
+		return ExceptionNewf(RecursionError, "maximum recursion depth exceeded")
+	}
+	ctx.depth++
+	return nil
+}
+
+// LeaveCall is called by the VM when a frame started with EnterCall
+// has finished running
+func (ctx *Context) LeaveCall() {
+	ctx.depth--
+}
+
+// RecursionLimit returns the maximum depth of python calls
+func (ctx *Context) RecursionLimit() int {
+	return ctx.recursionLimit
+}
+
+// SetRecursionLimit sets the maximum depth of python calls
+//
+// It returns a ValueError if limit isn't positive or is bigger than
+// MaxRecursionLimit
+func (ctx *Context) SetRecursionLimit(limit int) error {
+	if limit <= 0 {
+		return ExceptionNewf(ValueError, "recursion limit must be positive")
+	}
+	if limit > MaxRecursionLimit {
+		return ExceptionNewf(ValueError, "recursion limit must be at most %d", MaxRecursionLimit)
+	}
+	ctx.recursionLimit = limit
+	return nil
+}
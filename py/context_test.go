@@ -0,0 +1,625 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	_ "github.com/go-python/gpython/builtin"
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/sys"
+	_ "github.com/go-python/gpython/time"
+	"github.com/go-python/gpython/vm"
+)
+
+// runString runs src as the __main__ module of ctx
+func runString(t *testing.T, ctx *py.Context, src string) *py.Module {
+	obj, err := compile.Compile(src, "<string>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	module := ctx.NewModule("__main__", "", nil, nil)
+	_, err = vm.Run(ctx, module.Globals, module.Globals, obj.(*py.Code), nil)
+	if err != nil {
+		py.TracebackDump(err)
+		t.Fatalf("Run failed: %v", err)
+	}
+	return module
+}
+
+func TestContextIsolation(t *testing.T) {
+	ctxA := py.NewContext(py.ContextOpts{Argv: []string{"a.py", "one"}})
+	ctxB := py.NewContext(py.ContextOpts{Argv: []string{"b.py"}})
+
+	runString(t, ctxA, `
+import sys, builtins
+sys.argv.append("two")
+builtins.len = lambda x: 42
+sys.patched = True
+`)
+	mainA := runString(t, ctxA, `
+import sys
+argv = sys.argv
+n = len("hello")
+`)
+	mainB := runString(t, ctxB, `
+import sys
+argv = sys.argv
+n = len("hello")
+patched = hasattr(sys, "patched")
+`)
+
+	for _, test := range []struct {
+		module *py.Module
+		name   string
+		want   string
+	}{
+		{mainA, "argv", "['a.py', 'one', 'two']"},
+		{mainA, "n", "42"},
+		{mainB, "argv", "['b.py']"},
+		{mainB, "n", "5"},
+		{mainB, "patched", "False"},
+	} {
+		got, err := py.Repr(test.module.Globals.Get(test.name))
+		if err != nil {
+			t.Fatalf("Repr failed: %v", err)
+		}
+		if string(got.(py.String)) != test.want {
+			t.Errorf("%s: want %s got %s", test.name, test.want, got)
+		}
+	}
+
+	if ctxA.MustGetModule("sys") == ctxB.MustGetModule("sys") {
+		t.Errorf("sys module shared between contexts")
+	}
+	ctxA.NewModule("onlyA", "", nil, nil)
+	if _, err := ctxB.GetModule("onlyA"); err == nil {
+		t.Errorf("module registered in one context visible in another")
+	}
+
+	// Mutable globals of a ModuleImpl aren't shared
+	py.RegisterModule(&py.ModuleImpl{
+		Name: "isolated",
+		Globals: py.NewStringDictFromMap(map[string]py.Object{
+			"items": py.NewListFromItems([]py.Object{py.Int(1)}),
+			"table": py.NewStringDictFromMap(map[string]py.Object{"inner": py.NewList()}),
+		}),
+	})
+	runString(t, ctxA, `
+import isolated
+isolated.items.append(2)
+isolated.table["inner"].append(3)
+isolated.table["new"] = 4
+`)
+	mainB = runString(t, ctxB, `
+import isolated
+items = isolated.items
+table = isolated.table
+`)
+	for name, want := range map[string]string{
+		"items": "[1]",
+		"table": "{'inner': []}",
+	} {
+		got, err := py.ReprAsString(mainB.Globals.Get(name))
+		if err != nil {
+			t.Fatalf("Repr failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("%s: want %s got %s", name, want, got)
+		}
+	}
+}
+
+func TestModuleMethodUnbound(t *testing.T) {
+	// Methods which need their module's Context raise a TypeError
+	// rather than panicking if called unbound
+	for _, test := range []struct {
+		module string
+		method string
+		args   py.Tuple
+	}{
+		{"builtins", "print", nil},
+		{"builtins", "open", py.Tuple{py.String("file")}},
+		{"builtins", "input", nil},
+		{"builtins", "compile", py.Tuple{py.String("1"), py.String("<string>"), py.String("eval")}},
+		{"sys", "displayhook", py.Tuple{py.Int(1)}},
+		{"sys", "excepthook", py.Tuple{py.ValueError, py.ExceptionNewf(py.ValueError, "oops"), py.None}},
+		{"sys", "setrecursionlimit", py.Tuple{py.Int(100)}},
+		{"sys", "getrecursionlimit", nil},
+		{"time", "sleep", py.Tuple{py.Float(0)}},
+	} {
+		var method *py.Method
+		for _, m := range py.GetModuleImpl(test.module).Methods {
+			if m.Name == test.method {
+				method = m
+			}
+		}
+		if method == nil {
+			t.Fatalf("%s.%s not found", test.module, test.method)
+		}
+		_, err := method.Call(py.None, test.args)
+		if !py.IsException(py.TypeError, err) {
+			t.Errorf("%s.%s: want TypeError got %v", test.module, test.method, err)
+		}
+	}
+
+	for _, self := range []py.Object{nil, (*py.Module)(nil), &py.Module{}} {
+		if _, err := py.ModuleContext(self); !py.IsException(py.TypeError, err) {
+			t.Errorf("ModuleContext(%#v): want TypeError got %v", self, err)
+		}
+	}
+}
+
+func TestContextFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data.txt":            {Data: []byte("hello from fs")},
+		"helper.py":           {Data: []byte("VALUE = 42\n")},
+		"pkg/__init__.py":     {Data: []byte("NAME = 'pkg'\n")},
+		"scripts/sibling.py":  {Data: []byte("WHERE = 'scripts'\n")},
+		"scripts/importer.py": {Data: []byte("import sibling\nWHERE = sibling.WHERE\n")},
+	}
+	ctx := py.NewContext(py.ContextOpts{FS: fsys})
+	module := runString(t, ctx, `
+with open("data.txt") as f:
+    data = f.read()
+with open("/data.txt", "rb") as f:
+    raw = f.read()
+import helper, pkg
+value = helper.VALUE
+name = pkg.NAME
+
+try:
+    open("data.txt", "w")
+except OSError as e:
+    write_error = e.args[0]
+
+try:
+    open("../../../etc/passwd")
+except FileNotFoundError:
+    escaped = False
+`)
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"data", "'hello from fs'"},
+		{"raw", "b'hello from fs'"},
+		{"value", "42"},
+		{"name", "'pkg'"},
+		{"write_error", "\"Read-only file system: 'data.txt'\""},
+		{"escaped", "False"},
+	} {
+		got, err := py.ReprAsString(module.Globals.Get(test.name))
+		if err != nil {
+			t.Fatalf("%s: Repr failed: %v", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: want %s got %s", test.name, test.want, got)
+		}
+	}
+
+	// Imports are relative to the importing file
+	importer, err := py.ImportModuleLevelObject(ctx, "scripts.importer", module.Globals, nil, py.Tuple{py.String("WHERE")}, 0)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if got := importer.(*py.Module).Globals.Get("WHERE"); got != py.String("scripts") {
+		t.Errorf("want WHERE = 'scripts' got %v", got)
+	}
+
+	// Another context still uses the OS
+	if _, err := py.NewContext(py.ContextOpts{}).OpenFile("data.txt", "r", -1); !py.IsException(py.FileNotFoundError, err) {
+		t.Errorf("want FileNotFoundError got %v", err)
+	}
+}
+
+func TestContextSysPath(t *testing.T) {
+	old, set := os.LookupEnv("PYTHONPATH")
+	os.Setenv("PYTHONPATH", "/extra/one"+string(os.PathListSeparator)+"/extra/two")
+	defer func() {
+		if set {
+			os.Setenv("PYTHONPATH", old)
+		} else {
+			os.Unsetenv("PYTHONPATH")
+		}
+	}()
+
+	scripts, _ := filepath.Abs("/scripts")
+	path := py.NewContext(py.ContextOpts{Argv: []string{"/scripts/main.py"}}).DefaultPath()
+	if len(path) < 3 || path[0] != scripts || path[1] != "/extra/one" || path[2] != "/extra/two" {
+		t.Errorf("unexpected default path %q", path)
+	}
+	path = py.NewContext(py.ContextOpts{FS: fstest.MapFS{}}).DefaultPath()
+	if strings.Join(path, ",") != ",." {
+		t.Errorf("unexpected FS default path %q", path)
+	}
+
+	// The importer uses sys.path and sys.modules
+	fsys := fstest.MapFS{
+		"lib/found.py": {Data: []byte("WHERE = 'lib'\n")},
+	}
+	ctx := py.NewContext(py.ContextOpts{FS: fsys})
+	module := runString(t, ctx, `
+import sys
+sys.path.append("lib")
+import found
+where = found.WHERE
+sys.modules["seeded"] = 42
+import seeded
+`)
+	if got := module.Globals.Get("where"); got != py.String("lib") {
+		t.Errorf("want where = 'lib' got %v", got)
+	}
+	if got := module.Globals.Get("seeded"); got != py.Int(42) {
+		t.Errorf("want seeded = 42 got %v", got)
+	}
+	if _, ok := ctx.Modules().Lookup("found"); !ok {
+		t.Errorf("found not in Modules")
+	}
+	ctx.Modules().Set("seeded", py.Int(43))
+	module = runString(t, ctx, "import seeded\n")
+	if got := module.Globals.Get("seeded"); got != py.Int(43) {
+		t.Errorf("want seeded = 43 got %v", got)
+	}
+}
+
+func TestContextFinder(t *testing.T) {
+	code, err := compile.Compile("WHERE = 'code'\n", "<gofinder.code>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	py.RegisterFinder(py.FinderFunc(func(ctx *py.Context, name string) (*py.FoundModule, error) {
+		switch name {
+		case "gofinder":
+			return &py.FoundModule{Source: "WHERE = 'package'\n", Package: true}, nil
+		case "gofinder.source":
+			return &py.FoundModule{Source: "from . import code\nWHERE = 'source ' + code.WHERE\n", Filename: "/virtual/source.py"}, nil
+		case "gofinder.code":
+			return &py.FoundModule{Code: code.(*py.Code)}, nil
+		case "gofinder.native":
+			return &py.FoundModule{Module: &py.Module{
+				Name:    name,
+				Globals: py.NewStringDictFromMap(map[string]py.Object{"WHERE": py.String("native")}),
+				Context: ctx,
+			}}, nil
+		case "gofinder.broken":
+			return nil, py.ExceptionNewf(py.ValueError, "broken finder")
+		}
+		return nil, nil
+	}))
+
+	// No filesystem is needed to import the modules
+	ctx := py.NewContext(py.ContextOpts{FS: fstest.MapFS{}})
+	module := runString(t, ctx, `
+import sys
+import gofinder.source, gofinder.native
+package = gofinder.WHERE
+source = gofinder.source.WHERE
+native = gofinder.native.WHERE
+native_name = gofinder.native.__name__
+source_file = gofinder.source.__file__
+path = gofinder.__path__
+try:
+    import gofinder.broken
+except ValueError:
+    broken = True
+try:
+    import gofinder.missing
+except ImportError:
+    missing = True
+`)
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"package", "'package'"},
+		{"source", "'source code'"},
+		{"native", "'native'"},
+		{"native_name", "'gofinder.native'"},
+		{"source_file", "'/virtual/source.py'"},
+		{"path", "[]"},
+		{"broken", "True"},
+		{"missing", "True"},
+	} {
+		got, err := py.ReprAsString(module.Globals.Get(test.name))
+		if err != nil {
+			t.Fatalf("%s: Repr failed: %v", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: want %s got %s", test.name, test.want, got)
+		}
+	}
+	if _, ok := ctx.Modules().Lookup("gofinder.code"); !ok {
+		t.Errorf("gofinder.code not in Modules")
+	}
+	if _, ok := ctx.Modules().Lookup("gofinder.broken"); ok {
+		t.Errorf("gofinder.broken in Modules")
+	}
+
+	// Removing the finder from sys.meta_path stops it being used
+	ctx = py.NewContext(py.ContextOpts{FS: fstest.MapFS{}})
+	runString(t, ctx, `
+import sys
+del sys.meta_path[0]
+try:
+    import gofinder
+except ImportError:
+    pass
+else:
+    raise AssertionError("gofinder imported")
+`)
+}
+
+func TestContextBytecodeCache(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "cachedmod.py")
+	cached := py.CacheFromSource(source)
+	if want := filepath.Join(dir, "__pycache__", "cachedmod."+py.CacheTag+".pyc"); cached != want {
+		t.Fatalf("want cache file %q got %q", want, cached)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeSource := func(src string) {
+		if err := ioutil.WriteFile(source, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(source, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	importValue := func(opts py.ContextOpts) py.Object {
+		opts.Argv = []string{filepath.Join(dir, "main.py")}
+		module := runString(t, py.NewContext(opts), "import cachedmod\nvalue = cachedmod.VALUE\ncached = cachedmod.__cached__\n")
+		if got := module.Globals.Get("cached"); got != py.String(cached) {
+			t.Errorf("want __cached__ %q got %v", cached, got)
+		}
+		return module.Globals.Get("value")
+	}
+
+	// Nothing is written if disabled
+	writeSource("VALUE = 1\n")
+	if got := importValue(py.ContextOpts{DontWriteBytecode: true}); got != py.Int(1) {
+		t.Errorf("want 1 got %v", got)
+	}
+	if _, err := os.Stat(cached); !os.IsNotExist(err) {
+		t.Fatalf("cache file written when disabled: %v", err)
+	}
+
+	// The cache is written then used while the source's mtime and
+	// size are unchanged
+	importValue(py.ContextOpts{})
+	if _, err := os.Stat(cached); err != nil {
+		t.Fatalf("cache file not written: %v", err)
+	}
+	writeSource("VALUE = 2\n")
+	if got := importValue(py.ContextOpts{}); got != py.Int(1) {
+		t.Errorf("want cached 1 got %v", got)
+	}
+	writeSource("VALUE = 33\n")
+	if got := importValue(py.ContextOpts{}); got != py.Int(33) {
+		t.Errorf("want recompiled 33 got %v", got)
+	}
+
+	// Checked hash based caches notice any change to the source
+	removeCache := func() {
+		if err := os.Remove(cached); err != nil {
+			t.Fatal(err)
+		}
+	}
+	removeCache()
+	importValue(py.ContextOpts{PycInvalidation: py.PycCheckedHash})
+	writeSource("VALUE = 44\n")
+	if got := importValue(py.ContextOpts{PycInvalidation: py.PycCheckedHash}); got != py.Int(44) {
+		t.Errorf("want recompiled 44 got %v", got)
+	}
+
+	// Unchecked hash based caches are always used
+	removeCache()
+	importValue(py.ContextOpts{PycInvalidation: py.PycUncheckedHash})
+	writeSource("VALUE = 5\n")
+	if got := importValue(py.ContextOpts{}); got != py.Int(44) {
+		t.Errorf("want cached 44 got %v", got)
+	}
+
+	// A corrupt cache is ignored and rewritten
+	if err := ioutil.WriteFile(cached, []byte("rubbish"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := importValue(py.ContextOpts{}); got != py.Int(5) {
+		t.Errorf("want recompiled 5 got %v", got)
+	}
+	writeSource("VALUE = 6\n")
+	if got := importValue(py.ContextOpts{}); got != py.Int(5) {
+		t.Errorf("want cached 5 got %v", got)
+	}
+}
+
+func TestContextPolicy(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data.txt": {Data: []byte("data")},
+	}
+	ctx := py.NewContext(py.ContextOpts{
+		FS: fsys,
+		Policy: &py.Policy{
+			DenyModules:    []string{"sys"},
+			DenyBuiltins:   []string{"eval", "compile"},
+			FileAccess:     py.FileAccessReadOnly,
+			DenyAttributes: py.UnsafeAttributes,
+		},
+	})
+	module := runString(t, ctx, `
+errors = []
+def check(fn):
+    try:
+        fn()
+    except PermissionError as e:
+        errors.append(e.args[0])
+    except NameError as e:
+        errors.append(e.args[0])
+
+def import_sys():
+    import sys
+check(import_sys)
+check(lambda: eval("1"))
+check(lambda: compile("1", "", "eval"))
+check(lambda: open("data.txt", "w"))
+check(lambda: check.__code__)
+check(lambda: getattr(check, "__globals__"))
+def set_code():
+    check.__code__ = None
+check(set_code)
+check(lambda: "{0.__globals__}".format(check))
+check(lambda: sorted([check], key="{0.__code__}".format))
+check(lambda: getattr("{0.__code__}", "format")(check))
+formatted = "{0.__name__}".format(check)
+data = open("data.txt").read()
+`)
+	want := []string{
+		"import of module 'sys' is not permitted",
+		"name 'eval' is not defined",
+		"name 'compile' is not defined",
+		"opening files for writing is not permitted: 'data.txt'",
+		"access to attribute '__code__' is not permitted",
+		"access to attribute '__globals__' is not permitted",
+		"access to attribute '__code__' is not permitted",
+		"access to attribute '__globals__' is not permitted",
+		"access to attribute '__code__' is not permitted",
+		"access to attribute '__code__' is not permitted",
+	}
+	errors := module.Globals.Get("errors").(*py.List)
+	if len(errors.Items) != len(want) {
+		t.Fatalf("want %d errors got %d: %v", len(want), len(errors.Items), errors.Items)
+	}
+	for i, w := range want {
+		if got := errors.Items[i]; got != py.String(w) {
+			t.Errorf("error %d: want %q got %q", i, w, got)
+		}
+	}
+	if got := module.Globals.Get("formatted"); got != py.String("check") {
+		t.Errorf("want check got %v", got)
+	}
+	if got := module.Globals.Get("data"); got != py.String("data") {
+		t.Errorf("want data got %v", got)
+	}
+
+	// Only allowed builtins and modules are available
+	ctx = py.NewContext(py.ContextOpts{
+		Policy: &py.Policy{
+			AllowModules:  []string{"math"},
+			AllowBuiltins: []string{"len", "PermissionError"},
+			FileAccess:    py.FileAccessNone,
+		},
+	})
+	if _, ok := ctx.Builtins.Globals.Lookup("open"); ok {
+		t.Errorf("open should have been removed from builtins")
+	}
+	if _, ok := ctx.Builtins.Globals.Lookup("__import__"); !ok {
+		t.Errorf("__import__ should have been kept in builtins")
+	}
+	if err := ctx.CheckImport("time"); !py.IsException(py.PermissionError, err) {
+		t.Errorf("want PermissionError importing time got %v", err)
+	}
+	if err := ctx.CheckImport("math"); err != nil {
+		t.Errorf("want math import allowed got %v", err)
+	}
+	if _, err := ctx.OpenFile("data.txt", "r", -1); !py.IsException(py.PermissionError, err) {
+		t.Errorf("want PermissionError opening file got %v", err)
+	}
+}
+
+func TestContextStdio(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	ctx := py.NewContext(py.ContextOpts{
+		Stdin:  strings.NewReader("alice\nbob"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	runString(t, ctx, `
+import sys
+name = input("name? ")
+print("hello", name)
+print("oops", file=sys.stderr)
+rest = input()
+try:
+    input()
+except EOFError:
+    eof = True
+`)
+	if got, want := stdout.String(), "name? hello alice\n"; got != want {
+		t.Errorf("stdout want %q got %q", want, got)
+	}
+	if got, want := stderr.String(), "oops\n"; got != want {
+		t.Errorf("stderr want %q got %q", want, got)
+	}
+
+	// Expression statements go to sys.displayhook
+	stdout.Reset()
+	obj, err := compile.Compile("6*7\n", "<stdin>", "single", 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := ctx.NewModule("__main__", "", nil, nil)
+	if _, err = vm.Run(ctx, module.Globals, module.Globals, obj.(*py.Code), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "42\n"; got != want {
+		t.Errorf("displayhook want %q got %q", want, got)
+	}
+	if got := ctx.Builtins.Globals.Get("_"); got != py.Int(42) {
+		t.Errorf("want _ = 42 got %v", got)
+	}
+
+	// Tracebacks go to sys.stderr, honouring redirection from python
+	runString(t, ctx, `
+import sys
+class Capture:
+    def __init__(self):
+        self.out = ""
+    def write(self, s):
+        self.out += s
+sys.stderr = Capture()
+`)
+	stderr.Reset()
+	obj, err = compile.Compile("1/0", "<string>", "exec", 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = vm.Run(ctx, module.Globals, module.Globals, obj.(*py.Code), nil)
+	if err == nil {
+		t.Fatal("want ZeroDivisionError got nil")
+	}
+	ctx.TracebackDump(err)
+	sys, err := ctx.GetModule("sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := py.GetAttrString(sys.Globals.Get("stderr"), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out.(py.String)); !strings.HasPrefix(got, "Traceback (most recent call last):") || !strings.Contains(got, "ZeroDivisionError") {
+		t.Errorf("traceback not captured: %q", out)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("traceback written to original stderr: %q", stderr.String())
+	}
+
+	// SetStdio gives the next run new streams
+	var stdout2 bytes.Buffer
+	if err := ctx.SetStdio(nil, &stdout2, nil); err != nil {
+		t.Fatal(err)
+	}
+	runString(t, ctx, `print("again")`)
+	if got, want := stdout2.String(), "again\n"; got != want {
+		t.Errorf("SetStdio want %q got %q", want, got)
+	}
+}
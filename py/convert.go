@@ -0,0 +1,605 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Conversion between Go values and python objects using reflection
+
+package py
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Types which need special treatment
+var (
+	objectGoType   = reflect.TypeOf((*Object)(nil)).Elem()
+	bigIntGoType   = reflect.TypeOf((*big.Int)(nil))
+	timeGoType     = reflect.TypeOf(time.Time{})
+	durationGoType = reflect.TypeOf(time.Duration(0))
+	bytesGoType    = reflect.TypeOf([]byte(nil))
+)
+
+// FromGo converts a Go value into a python object
+//
+// The conversions are
+//
+//	nil, nil pointers           -> None
+//	Object                      -> unchanged
+//	bool                        -> bool
+//	integers                    -> int
+//	*big.Int                    -> int
+//	floats                      -> float
+//	complex numbers             -> complex
+//	string                      -> str
+//	[]byte                      -> bytes
+//	time.Time                   -> float seconds since the epoch
+//	time.Duration               -> float seconds
+//	slices                      -> list
+//	arrays                      -> tuple
+//	maps                        -> dict
+//	structs                     -> dict of the exported fields
+//	pointers and interfaces     -> the converted value pointed to
+//
+// Values of types registered with RegisterGoType, and pointers to
+// them, are wrapped as live python objects instead.
+//
+// Struct fields are named by their `py:"name"` tag if present or by
+// the Go field name otherwise.  Fields tagged `py:"-"` are skipped.
+//
+// Anything else returns a TypeError.
+func FromGo(x interface{}) (Object, error) {
+	if x == nil {
+		return None, nil
+	}
+	if obj, ok := x.(Object); ok {
+		return obj, nil
+	}
+	return fromGo(reflect.ValueOf(x))
+}
+
+// fromGo converts the Go value v into a python object
+func fromGo(v reflect.Value) (Object, error) {
+	if obj, ok := wrapGo(v); ok {
+		return obj, nil
+	}
+	if v.Type().Implements(objectGoType) {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return None, nil
+		}
+		return v.Interface().(Object), nil
+	}
+	switch v.Type() {
+	case bigIntGoType:
+		if v.IsNil() {
+			return None, nil
+		}
+		return (*BigInt)(new(big.Int).Set(v.Interface().(*big.Int))).MaybeInt(), nil
+	case timeGoType:
+		t := v.Interface().(time.Time)
+		return Float(float64(t.UnixNano()) / 1e9), nil
+	case durationGoType:
+		return Float(v.Interface().(time.Duration).Seconds()), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return NewBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > math.MaxInt64 {
+			return (*BigInt)(new(big.Int).SetUint64(u)), nil
+		}
+		return Int(u), nil
+	case reflect.Float32, reflect.Float64:
+		return Float(v.Float()), nil
+	case reflect.Complex64, reflect.Complex128:
+		return Complex(v.Complex()), nil
+	case reflect.String:
+		return String(v.String()), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return None, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return Bytes(append([]byte(nil), v.Bytes()...)), nil
+		}
+		items, err := fromGoItems(v)
+		if err != nil {
+			return nil, err
+		}
+		return NewListFromItems(items), nil
+	case reflect.Array:
+		items, err := fromGoItems(v)
+		if err != nil {
+			return nil, err
+		}
+		return Tuple(items), nil
+	case reflect.Map:
+		if v.IsNil() {
+			return None, nil
+		}
+		d := NewDictSized(v.Len())
+		for _, key := range sortedMapKeys(v) {
+			pyKey, err := fromGo(key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := fromGo(v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			err = d.SetItem(pyKey, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return d, nil
+	case reflect.Struct:
+		d := NewStringDict()
+		for _, field := range structFields(v.Type()) {
+			value, err := fromGo(v.Field(field.index))
+			if err != nil {
+				return nil, err
+			}
+			d.Set(field.name, value)
+		}
+		return d, nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return None, nil
+		}
+		return fromGo(v.Elem())
+	}
+	return nil, ExceptionNewf(TypeError, "can't convert Go %v to a python object", v.Type())
+}
+
+// fromGoItems converts the items of the slice or array v
+func fromGoItems(v reflect.Value) ([]Object, error) {
+	items := make([]Object, v.Len())
+	for i := range items {
+		item, err := fromGo(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// structField describes a struct field visible from python
+type structField struct {
+	name  string
+	index int
+}
+
+// structFields returns the exported fields of the struct type t
+// along with their python names
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("py"); ok {
+			tag = strings.Split(tag, ",")[0]
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, structField{name: name, index: i})
+	}
+	return fields
+}
+
+// sortedMapKeys returns the keys of the map v sorted by their text so
+// maps are converted in a consistent order
+func sortedMapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+// ToGo converts the python object obj into the Go value pointed to
+// by target
+//
+// It does the reverse of the conversions done by FromGo.  In
+// addition
+//
+//	int                         -> floats and complex numbers
+//	float                       -> complex numbers
+//	any iterable except str     -> slices and arrays
+//	str in RFC 3339 format      -> time.Time
+//	int                         -> time.Time and time.Duration
+//	bytearray, memoryview       -> []byte sharing the bytes if writable
+//	Object                      -> interface{} holding a natural Go
+//	                               value (int64, float64, string,
+//	                               []interface{}, map[string]interface{}...)
+//	                               or the object itself if there isn't one
+//
+// If target points to an Object or one of the python types then obj
+// is stored there as is.  Objects wrapping Go values registered with
+// RegisterGoType are stored as the Go value, or a pointer to it,
+// depending on the target.
+//
+// It returns a TypeError if obj can't be converted to the type of the
+// target, an OverflowError if a number won't fit and a ValueError if
+// the length of a sequence doesn't match an array.
+func ToGo(obj Object, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ExceptionNewf(TypeError, "ToGo needs a non nil pointer to store the result in, got %T", target)
+	}
+	return toGo(obj, v.Elem())
+}
+
+// cantConvertToGo returns a TypeError for obj not being convertible to t
+func cantConvertToGo(obj Object, t reflect.Type) error {
+	return ExceptionNewf(TypeError, "can't convert %s to Go %v", obj.Type().Name, t)
+}
+
+// toGo converts obj and stores it in v
+func toGo(obj Object, v reflect.Value) error {
+	t := v.Type()
+
+	// Python objects are stored as is unless the target is an
+	// empty interface
+	isEmptyInterface := t.Kind() == reflect.Interface && t.NumMethod() == 0
+	if objValue := reflect.ValueOf(obj); objValue.Type().AssignableTo(t) && !isEmptyInterface {
+		v.Set(objValue)
+		return nil
+	}
+
+	// Go values registered with RegisterGoType are unwrapped
+	if o := asGoObject(obj); o != nil {
+		if o.value.Type().AssignableTo(t) {
+			v.Set(o.value)
+			return nil
+		}
+		if o.value.Elem().Type().AssignableTo(t) {
+			v.Set(o.value.Elem())
+			return nil
+		}
+	}
+
+	switch t {
+	case bigIntGoType:
+		if obj == None {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		x, ok := ConvertToBigInt(obj)
+		if !ok {
+			return cantConvertToGo(obj, t)
+		}
+		v.Set(reflect.ValueOf(new(big.Int).Set((*big.Int)(x))))
+		return nil
+	case timeGoType:
+		switch x := obj.(type) {
+		case Int:
+			v.Set(reflect.ValueOf(time.Unix(int64(x), 0)))
+		case Float:
+			sec, frac := math.Modf(float64(x))
+			v.Set(reflect.ValueOf(time.Unix(int64(sec), int64(frac*1e9))))
+		case String:
+			tm, err := time.Parse(time.RFC3339Nano, string(x))
+			if err != nil {
+				return ExceptionNewf(ValueError, "can't convert %q to Go time.Time: %v", string(x), err)
+			}
+			v.Set(reflect.ValueOf(tm))
+		default:
+			return cantConvertToGo(obj, t)
+		}
+		return nil
+	case durationGoType:
+		switch x := obj.(type) {
+		case Int:
+			if x > Int(math.MaxInt64/time.Second) || x < Int(math.MinInt64/time.Second) {
+				return ExceptionNewf(OverflowError, "Python int too large to convert to Go %v", t)
+			}
+			v.SetInt(int64(time.Duration(x) * time.Second))
+		case Float:
+			ns := float64(x) * float64(time.Second)
+			if !(ns >= math.MinInt64 && ns < math.MaxInt64) {
+				return ExceptionNewf(OverflowError, "Python float too large to convert to Go %v", t)
+			}
+			v.SetInt(int64(ns))
+		default:
+			return cantConvertToGo(obj, t)
+		}
+		return nil
+	case bytesGoType:
+		switch x := obj.(type) {
+		case IBuffer:
+			buf, readonly, err := x.Buffer()
+			if err != nil {
+				return err
+			}
+			if readonly {
+				buf = append([]byte(nil), buf...)
+			}
+			v.SetBytes(buf)
+			return nil
+		case NoneType:
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		return cantConvertToGo(obj, t)
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if !isEmptyInterface {
+			return cantConvertToGo(obj, t)
+		}
+		x, err := toGoInterface(obj)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(t))
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	case reflect.Bool:
+		x, ok := obj.(Bool)
+		if !ok {
+			return cantConvertToGo(obj, t)
+		}
+		v.SetBool(bool(x))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, ok := ConvertToBigInt(obj)
+		if _, isBool := obj.(Bool); !ok || isBool {
+			return cantConvertToGo(obj, t)
+		}
+		bx := (*big.Int)(x)
+		if !bx.IsInt64() || v.OverflowInt(bx.Int64()) {
+			return ExceptionNewf(OverflowError, "Python int too large to convert to Go %v", t)
+		}
+		v.SetInt(bx.Int64())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, ok := ConvertToBigInt(obj)
+		if _, isBool := obj.(Bool); !ok || isBool {
+			return cantConvertToGo(obj, t)
+		}
+		bx := (*big.Int)(x)
+		if bx.Sign() < 0 {
+			return ExceptionNewf(OverflowError, "can't convert negative int to Go %v", t)
+		}
+		if !bx.IsUint64() || v.OverflowUint(bx.Uint64()) {
+			return ExceptionNewf(OverflowError, "Python int too large to convert to Go %v", t)
+		}
+		v.SetUint(bx.Uint64())
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var x float64
+		switch obj.(type) {
+		case Float, Int, *BigInt:
+			var err error
+			x, err = FloatAsFloat64(obj)
+			if err != nil {
+				return err
+			}
+		default:
+			return cantConvertToGo(obj, t)
+		}
+		v.SetFloat(x)
+		return nil
+	case reflect.Complex64, reflect.Complex128:
+		switch x := obj.(type) {
+		case Complex:
+			v.SetComplex(complex128(x))
+		case Float, Int, *BigInt:
+			f, err := FloatAsFloat64(x)
+			if err != nil {
+				return err
+			}
+			v.SetComplex(complex(f, 0))
+		default:
+			return cantConvertToGo(obj, t)
+		}
+		return nil
+	case reflect.String:
+		x, ok := obj.(String)
+		if !ok {
+			return cantConvertToGo(obj, t)
+		}
+		v.SetString(string(x))
+		return nil
+	case reflect.Slice:
+		if obj == None {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		items, err := toGoItems(obj, t)
+		if err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			err = toGo(item, s.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Array:
+		items, err := toGoItems(obj, t)
+		if err != nil {
+			return err
+		}
+		if len(items) != t.Len() {
+			return ExceptionNewf(ValueError, "need %d items to convert to Go %v, got %d", t.Len(), t, len(items))
+		}
+		for i, item := range items {
+			err = toGo(item, v.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if obj == None {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		if d, ok := obj.(*Dict); ok {
+			m := reflect.MakeMapWithSize(t, d.Len())
+			for _, item := range d.Items() {
+				key := reflect.New(t.Key()).Elem()
+				err := toGo(item[0], key)
+				if err != nil {
+					return err
+				}
+				elem := reflect.New(t.Elem()).Elem()
+				err = toGo(item[1], elem)
+				if err != nil {
+					return err
+				}
+				m.SetMapIndex(key, elem)
+			}
+			v.Set(m)
+			return nil
+		}
+		d, ok := obj.(StringDict)
+		if !ok || t.Key().Kind() != reflect.String {
+			return cantConvertToGo(obj, t)
+		}
+		m := reflect.MakeMapWithSize(t, d.Len())
+		for _, key := range d.StringKeys() {
+			value := d.Get(key)
+			elem := reflect.New(t.Elem()).Elem()
+			err := toGo(value, elem)
+			if err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+		v.Set(m)
+		return nil
+	case reflect.Struct:
+		d, err := DictAsStringDict(obj)
+		if err != nil {
+			return cantConvertToGo(obj, t)
+		}
+		fields := structFields(t)
+		byName := make(map[string]int, len(fields))
+		for _, field := range fields {
+			byName[field.name] = field.index
+		}
+		for _, key := range d.StringKeys() {
+			value := d.Get(key)
+			index, ok := byName[key]
+			if !ok {
+				return ExceptionNewf(TypeError, "Go %v has no field %q", t, key)
+			}
+			err := toGo(value, v.Field(index))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Ptr:
+		if obj == None {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return toGo(obj, v.Elem())
+	}
+	return cantConvertToGo(obj, t)
+}
+
+// toGoItems returns the items of obj for converting into a slice or
+// array of type t
+func toGoItems(obj Object, t reflect.Type) ([]Object, error) {
+	switch x := obj.(type) {
+	case Tuple:
+		return x, nil
+	case *List:
+		return x.Items, nil
+	case String, Bytes, *Dict:
+		return nil, cantConvertToGo(obj, t)
+	}
+	var items []Object
+	err := Iterate(obj, func(item Object) bool {
+		items = append(items, item)
+		return false
+	})
+	if err != nil {
+		if IsException(TypeError, err) {
+			return nil, cantConvertToGo(obj, t)
+		}
+		return nil, err
+	}
+	return items, nil
+}
+
+// toGoInterface converts obj to the natural Go type for it
+func toGoInterface(obj Object) (interface{}, error) {
+	switch x := obj.(type) {
+	case NoneType:
+		return nil, nil
+	case Bool:
+		return bool(x), nil
+	case Int:
+		return int64(x), nil
+	case *BigInt:
+		return new(big.Int).Set((*big.Int)(x)), nil
+	case Float:
+		return float64(x), nil
+	case Complex:
+		return complex128(x), nil
+	case String:
+		return string(x), nil
+	case Bytes:
+		return append([]byte(nil), x...), nil
+	case Tuple, *List:
+		var items []interface{}
+		err := ToGo(obj, &items)
+		if err != nil {
+			return nil, err
+		}
+		return items, nil
+	case *GoObject:
+		return x.Interface(), nil
+	case *GoContainer:
+		return x.Interface(), nil
+	case *Dict:
+		// Only dicts with string keys have a natural Go type
+		if !x.stringKeyed() {
+			break
+		}
+		m := make(map[string]interface{}, x.Len())
+		for _, key := range x.StringKeys() {
+			value := x.Get(key)
+			goValue, err := toGoInterface(value)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = goValue
+		}
+		return m, nil
+	}
+	return obj, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type convertPoint struct {
+	X, Y    int
+	Label   string `py:"label"`
+	Skip    int    `py:"-"`
+	private int
+}
+
+func TestFromGo(t *testing.T) {
+	big1e30, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+	x := 3
+	for _, test := range []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, "None"},
+		{(*int)(nil), "None"},
+		{True, "True"},
+		{true, "True"},
+		{42, "42"},
+		{int8(-8), "-8"},
+		{uint64(1 << 63), "9223372036854775808"},
+		{&x, "3"},
+		{big.NewInt(7), "7"},
+		{big1e30, "1000000000000000000000000000000"},
+		{1.5, "1.5"},
+		{complex(1, 2), "(1+2j)"},
+		{"hello", "'hello'"},
+		{[]byte("hi"), "b'hi'"},
+		{[]int{1, 2}, "[1, 2]"},
+		{[2]string{"a", "b"}, "('a', 'b')"},
+		{map[string]int{"a": 1}, "{'a': 1}"},
+		{map[string]int{"c": 3, "a": 1, "b": 2, "d": 4}, "{'a': 1, 'b': 2, 'c': 3, 'd': 4}"},
+		{[]interface{}{1, "a", nil}, "[1, 'a', None]"},
+		{time.Unix(10, 500000000), "10.5"},
+		{1500 * time.Millisecond, "1.5"},
+	} {
+		got, err := FromGo(test.in)
+		if err != nil {
+			t.Errorf("FromGo(%#v) failed: %v", test.in, err)
+			continue
+		}
+		gotRepr, err := ReprAsString(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotRepr != test.want {
+			t.Errorf("FromGo(%#v) want %s got %s", test.in, test.want, gotRepr)
+		}
+	}
+
+	got, err := FromGo(&convertPoint{X: 1, Y: 2, Label: "p", Skip: 3})
+	if err != nil {
+		t.Fatalf("FromGo struct failed: %v", err)
+	}
+	want := NewStringDictFromMap(map[string]Object{"X": Int(1), "Y": Int(2), "label": String("p")})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromGo struct want %v got %v", want, got)
+	}
+
+	got, err = FromGo(map[int]string{1: "a"})
+	if err != nil {
+		t.Fatalf("FromGo map failed: %v", err)
+	}
+	if d, ok := got.(*Dict); !ok {
+		t.Errorf("FromGo map[int]string want *Dict got %T", got)
+	} else if v, found, err := d.GetItem(Float(1)); err != nil || !found || v != String("a") {
+		t.Errorf("FromGo map[int]string lookup got %v %v %v", v, found, err)
+	}
+	var back map[int]string
+	if err := ToGo(got, &back); err != nil || !reflect.DeepEqual(back, map[int]string{1: "a"}) {
+		t.Errorf("ToGo map[int]string got %v %v", back, err)
+	}
+
+	for _, in := range []interface{}{
+		make(chan int),
+		map[convertPoint]int{{}: 1},
+		func() {},
+	} {
+		_, err := FromGo(in)
+		if !IsException(TypeError, err) {
+			t.Errorf("FromGo(%T) want TypeError got %v", in, err)
+		}
+	}
+}
+
+func TestToGo(t *testing.T) {
+	var (
+		b     bool
+		i     int
+		i8    int8
+		u     uint
+		f     float64
+		c     complex128
+		s     string
+		bs    []byte
+		ints  []int
+		arr   [2]string
+		m     map[string]float64
+		p     convertPoint
+		pp    *convertPoint
+		bi    *big.Int
+		tm    time.Time
+		dur   time.Duration
+		iface interface{}
+		obj   Object
+		list  *List
+	)
+	for _, test := range []struct {
+		in     Object
+		target interface{}
+		want   interface{}
+	}{
+		{True, &b, true},
+		{Int(42), &i, 42},
+		{Int(-8), &i8, int8(-8)},
+		{Int(8), &u, uint(8)},
+		{Int(2), &f, 2.0},
+		{Float(1.5), &f, 1.5},
+		{Float(1.5), &c, complex(1.5, 0)},
+		{String("hello"), &s, "hello"},
+		{Bytes("hi"), &bs, []byte("hi")},
+		{NewByteArray([]byte("ba")), &bs, []byte("ba")},
+		{NewMemoryViewFromBytes([]byte("mv"), true), &bs, []byte("mv")},
+		{Tuple{Int(1), Int(2)}, &ints, []int{1, 2}},
+		{NewListFromItems([]Object{Int(3)}), &ints, []int{3}},
+		{NewListFromItems([]Object{String("a"), String("b")}), &arr, [2]string{"a", "b"}},
+		{NewStringDictFromMap(map[string]Object{"a": Float(1)}), &m, map[string]float64{"a": 1}},
+		{NewStringDictFromMap(map[string]Object{"X": Int(1), "label": String("p")}), &p, convertPoint{X: 1, Label: "p"}},
+		{NewStringDictFromMap(map[string]Object{"Y": Int(2)}), &pp, &convertPoint{Y: 2}},
+		{None, &pp, (*convertPoint)(nil)},
+		{Int(7), &bi, big.NewInt(7)},
+		{Int(10), &tm, time.Unix(10, 0)},
+		{String("2018-01-02T03:04:05Z"), &tm, time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Float(1.5), &dur, 1500 * time.Millisecond},
+		{Int(5), &iface, int64(5)},
+		{Tuple{String("a"), None}, &iface, []interface{}{"a", nil}},
+		{NewStringDictFromMap(map[string]Object{"a": True}), &iface, map[string]interface{}{"a": true}},
+		{Int(5), &obj, Int(5)},
+		{NewList(), &list, NewList()},
+	} {
+		err := ToGo(test.in, test.target)
+		if err != nil {
+			t.Errorf("ToGo(%v, %T) failed: %v", test.in, test.target, err)
+			continue
+		}
+		got := reflect.ValueOf(test.target).Elem().Interface()
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ToGo(%v, %T) want %#v got %#v", test.in, test.target, test.want, got)
+		}
+	}
+
+	for _, test := range []struct {
+		in     Object
+		target interface{}
+		want   *Type
+	}{
+		{String("1"), &i, TypeError},
+		{True, &i, TypeError},
+		{Int(1), &b, TypeError},
+		{Int(1000), &i8, OverflowError},
+		{Int(-1), &u, OverflowError},
+		{Int(1 << 40), &dur, OverflowError},
+		{Int(-1 << 40), &dur, OverflowError},
+		{Float(1e19), &dur, OverflowError},
+		{Float(math.NaN()), &dur, OverflowError},
+		{String("abc"), &ints, TypeError},
+		{Tuple{Int(1), String("x")}, &ints, TypeError},
+		{Tuple{String("a")}, &arr, ValueError},
+		{NewStringDictFromMap(map[string]Object{"Z": Int(1)}), &p, TypeError},
+		{NewStringDictFromMap(map[string]Object{"Skip": Int(1)}), &p, TypeError},
+		{Int(1), i, TypeError},
+		{Int(1), nil, TypeError},
+	} {
+		err := ToGo(test.in, test.target)
+		if !IsException(test.want, err) {
+			t.Errorf("ToGo(%v, %T) want %s got %v", test.in, test.target, test.want.Name, err)
+		}
+	}
+}
+
+func TestToGoBuffer(t *testing.T) {
+	a := NewByteArray([]byte("abc"))
+	m, err := NewMemoryView(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		in    Object
+		share bool
+	}{
+		{a, true},
+		{m, true},
+		{NewMemoryViewFromBytes(a.Bytes, true), false},
+		{Bytes("abc"), false},
+	} {
+		var bs []byte
+		if err := ToGo(test.in, &bs); err != nil {
+			t.Fatalf("ToGo(%s) failed: %v", test.in.Type().Name, err)
+		}
+		bs[0] = 'X'
+		if shared := a.Bytes[0] == 'X'; shared != test.share {
+			t.Errorf("ToGo(%s) want shared %v got %v", test.in.Type().Name, test.share, shared)
+		}
+		a.Bytes[0] = 'a'
+	}
+}
@@ -4,12 +4,18 @@
 
 // Dict and StringDict type
 //
-// The idea is that most dicts just have strings for keys so we use
-// the simpler StringDict and promote it into a Dict when necessary
+// The idea is that most dicts just have strings for keys so a Dict
+// indexes those by the Go string as well as by their python hash and
+// StringDict names a Dict used through its string keyed methods.
 
 package py
 
-import "bytes"
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"sort"
+)
 
 const dictDoc = `dict() -> new empty dictionary
 dict(mapping) -> new dictionary initialized from a mapping object's
@@ -22,73 +28,222 @@ dict(**kwargs) -> new dictionary initialized with the name=value pairs
     in the keyword argument list.  For example:  dict(one=1, two=2)`
 
 var (
-	StringDictType = NewType("dict", dictDoc)
-	DictType       = NewType("dict", dictDoc)
+	DictType       = ObjectType.NewType("dict", dictDoc, DictNew, nil)
+	StringDictType = DictType // a StringDict is a *Dict
 	expectingDict  = ExceptionNewf(TypeError, "a dict is required")
 )
 
 func init() {
-	StringDictType.Dict["items"] = MustNewMethod("items", func(self Object, args Tuple) (Object, error) {
+	DictType.Dict.Set("keys", MustNewMethod("keys", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "keys", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &DictKeys{dict: self.(*Dict)}, nil
+	}, 0, "D.keys() -> a set-like object providing a view on D's keys"))
+
+	DictType.Dict.Set("values", MustNewMethod("values", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "values", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &DictValues{dict: self.(*Dict)}, nil
+	}, 0, "D.values() -> an object providing a view on D's values"))
+
+	DictType.Dict.Set("items", MustNewMethod("items", func(self Object, args Tuple) (Object, error) {
 		err := UnpackTuple(args, nil, "items", 0, 0)
 		if err != nil {
 			return nil, err
 		}
-		sMap := self.(StringDict)
-		o := make([]Object, 0, len(sMap))
-		for k, v := range sMap {
-			o = append(o, Tuple{String(k), v})
+		return &DictItems{dict: self.(*Dict)}, nil
+	}, 0, "D.items() -> a set-like object providing a view on D's items"))
+
+	DictType.Dict.Set("get", MustNewMethod("get", func(self Object, args Tuple) (Object, error) {
+		var key Object
+		var def Object = None
+		err := UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
 		}
-		return NewIterator(o), nil
-	}, 0, "items() -> list of D's (key, value) pairs, as 2-tuples")
+		res, found, err := self.(*Dict).GetItem(key)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return def, nil
+		}
+		return res, nil
+	}, 0, "D.get(k[,d]) -> D[k] if k in D, else d.  d defaults to None."))
 
-	StringDictType.Dict["get"] = MustNewMethod("get", func(self Object, args Tuple) (Object, error) {
-		var length = len(args)
-		switch {
-		case length == 0:
-			return nil, ExceptionNewf(TypeError, "%s expected at least 1 arguments, got %d", "items()", length)
-		case length > 2:
-			return nil, ExceptionNewf(TypeError, "%s expected at most 2 arguments, got %d", "items()", length)
+	DictType.Dict.Set("pop", MustNewMethod("pop", func(self Object, args Tuple) (Object, error) {
+		var key, def Object
+		err := UnpackTuple(args, nil, "pop", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
 		}
-		sMap := self.(StringDict)
-		if str, ok := args[0].(String); ok {
-			if res, ok := sMap[string(str)]; ok {
-				return res, nil
+		d := self.(*Dict)
+		res, found, err := d.GetItem(key)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if def == nil {
+				return nil, exceptionNew(KeyError, Tuple{key})
 			}
+			return def, nil
+		}
+		_, err = d.DelItem(key)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}, 0, "D.pop(k[,d]) -> v, remove specified key and return the corresponding value.\nIf key is not found, d is returned if given, otherwise KeyError is raised"))
+
+	DictType.Dict.Set("popitem", MustNewMethod("popitem", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "popitem", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(*Dict)
+		items := d.Items()
+		if len(items) == 0 {
+			return nil, ExceptionNewf(KeyError, "popitem(): dictionary is empty")
+		}
+		item := items[len(items)-1]
+		_, err = d.DelItem(item[0])
+		if err != nil {
+			return nil, err
+		}
+		return item, nil
+	}, 0, "D.popitem() -> (k, v), remove and return the last (key, value) pair as a\n2-tuple; but raise KeyError if D is empty."))
 
-			switch length {
-			case 2:
-				return args[1], nil
-			default:
-				return None, nil
+	DictType.Dict.Set("setdefault", MustNewMethod("setdefault", func(self Object, args Tuple) (Object, error) {
+		var key Object
+		var def Object = None
+		err := UnpackTuple(args, nil, "setdefault", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(*Dict)
+		res, found, err := d.GetItem(key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return res, nil
+		}
+		err = d.SetItem(key, def)
+		if err != nil {
+			return nil, err
+		}
+		return def, nil
+	}, 0, "D.setdefault(k[,d]) -> D.get(k,d), also set D[k]=d if k not in D"))
+
+	DictType.Dict.Set("update", MustNewMethod("update", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var arg Object
+		err := UnpackTuple(args, nil, "update", 0, 1, &arg)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(*Dict)
+		if arg != nil {
+			err = d.Update(arg)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, k := range kwargs.StringKeys() {
+			err = d.SetItem(String(k), kwargs.Get(k))
+			if err != nil {
+				return nil, err
 			}
 		}
-		return nil, ExceptionNewf(KeyError, "%v", args[0])
-	}, 0, "gets(key, default) -> If there is a val corresponding to key, return val, otherwise default")
+		return None, nil
+	}, 0, "D.update([E, ]**F) -> None.  Update D from dict/iterable E and F.\nIf E is present and has a .keys() method, then does:  for k in E: D[k] = E[k]\nIf E is present and lacks a .keys() method, then does:  for k, v in E: D[k] = v\nIn either case, this is followed by: for k in F: D[k] = F[k]"))
+
+	DictType.Dict.Set("clear", MustNewMethod("clear", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "clear", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		self.(*Dict).Clear()
+		return None, nil
+	}, 0, "D.clear() -> None.  Remove all items from D."))
+
+	DictType.Dict.Set("copy", MustNewMethod("copy", func(self Object, args Tuple) (Object, error) {
+		err := UnpackTuple(args, nil, "copy", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Dict).Copy(), nil
+	}, 0, "D.copy() -> a shallow copy of D"))
+
+	DictType.Dict.Set("fromkeys", &ClassMethod{
+		Callable: MustNewMethod("fromkeys", func(self Object, args Tuple) (Object, error) {
+			var iterable Object
+			var value Object = None
+			err := UnpackTuple(args, nil, "fromkeys", 1, 2, &iterable, &value)
+			if err != nil {
+				return nil, err
+			}
+			d := NewDict()
+			var setErr error
+			err = Iterate(iterable, func(key Object) bool {
+				setErr = d.SetItem(key, value)
+				return setErr != nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if setErr != nil {
+				return nil, setErr
+			}
+			return d, nil
+		}, 0, "dict.fromkeys(iterable, value=None) -> new dict with keys from iterable and values equal to value."),
+		Dict: NewStringDict(),
+	})
 }
 
 // String to object dictionary
 //
-// Used for variables etc where the keys can only be strings
-type StringDict map[string]Object
-
-// Type of this StringDict object
-func (o StringDict) Type() *Type {
-	return StringDictType
-}
+// Used for variables etc where the keys are usually strings.  It is a
+// *Dict so python sees namespaces such as module globals and keyword
+// arguments as ordinary dicts, but its methods taking a Go string
+// look the key up without hashing it.
+//
+// Like a Go map a nil StringDict is empty and may be read but not
+// written.
+type StringDict = *Dict
 
 // Make a new dictionary
 func NewStringDict() StringDict {
-	return make(StringDict)
+	return NewDict()
 }
 
 // Make a new dictionary with reservation for n entries
 func NewStringDictSized(n int) StringDict {
-	return make(StringDict, n)
+	return NewDictSized(n)
+}
+
+// NewStringDictFromMap makes a new dictionary with the contents of m
+//
+// As a Go map isn't ordered the items are inserted in key order.
+func NewStringDictFromMap(m map[string]Object) StringDict {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	d := NewStringDictSized(len(keys))
+	for _, k := range keys {
+		d.Set(k, m[k])
+	}
+	return d
 }
 
 // Checks that obj is exactly a dictionary and returns an error if not
 func DictCheckExact(obj Object) (StringDict, error) {
-	dict, ok := obj.(StringDict)
+	dict, ok := obj.(*Dict)
 	if !ok {
 		return nil, expectingDict
 	}
@@ -101,87 +256,602 @@ func DictCheck(obj Object) (StringDict, error) {
 	return DictCheckExact(obj)
 }
 
-// Copy a dictionary
-func (d StringDict) Copy() StringDict {
-	e := make(StringDict, len(d))
-	for k, v := range d {
-		e[k] = v
+// DictAsStringDict returns the python dict obj as a StringDict
+//
+// It raises a TypeError if obj isn't a dict or has keys which aren't
+// strings.
+func DictAsStringDict(obj Object) (StringDict, error) {
+	d, err := DictCheck(obj)
+	if err != nil {
+		return nil, err
+	}
+	if !d.stringKeyed() {
+		return nil, ExceptionNewf(TypeError, "keywords must be strings")
+	}
+	return d, nil
+}
+
+// Lookup returns the value stored under the str key and whether it
+// was found
+func (d *Dict) Lookup(key string) (Object, bool) {
+	if d == nil {
+		return nil, false
+	}
+	i, ok := d.strings[key]
+	if !ok {
+		return nil, false
+	}
+	return d.entries[i].value, true
+}
+
+// Get returns the value stored under the str key or nil if it isn't
+// found
+func (d *Dict) Get(key string) Object {
+	value, _ := d.Lookup(key)
+	return value
+}
+
+// Set stores value under the str key
+//
+// A new key goes at the end of the dictionary but replacing the value
+// of an existing key leaves it where it is.
+func (d *Dict) Set(key string, value Object) {
+	if i, ok := d.strings[key]; ok {
+		d.entries[i].value = value
+		return
+	}
+	d.add(hashString(key), String(key), value)
+}
+
+// Delete removes the str key from the dictionary returning whether it
+// was there
+func (d *Dict) Delete(key string) bool {
+	if d == nil {
+		return false
+	}
+	i, ok := d.strings[key]
+	if !ok {
+		return false
+	}
+	d.remove(i)
+	return true
+}
+
+// StringKeys returns the str keys of the dictionary in order
+func (d *Dict) StringKeys() []string {
+	keys := make([]string, 0, d.Len())
+	if d == nil {
+		return keys
+	}
+	for _, entry := range d.entries {
+		if key, ok := entry.key.(String); ok {
+			keys = append(keys, string(key))
+		}
+	}
+	return keys
+}
+
+// A python dictionary with keys of any hashable type
+//
+// The keys are looked up with their __hash__ and __eq__ methods so,
+// as in python, keys which compare equal such as 1, 1.0 and True are
+// the same key.
+//
+// The items are kept in insertion order as in CPython 3.7.
+//
+// The zero Dict is empty and ready to use.
+type Dict struct {
+	entries []dictEntry     // entries in insertion order, deleted ones have a nil key
+	index   map[int64][]int // indexes of entries by hash of key
+	strings map[string]int  // index of the entry for each str key
+	length  int             // number of live entries
+	version int             // changed whenever a key is added or removed
+	inRepr  bool            // set while the Dict's repr is being made
+}
+
+// An entry in a Dict
+type dictEntry struct {
+	hash  int64
+	key   Object
+	value Object
+}
+
+// Type of this Dict object
+func (d *Dict) Type() *Type {
+	return DictType
+}
+
+// NewDict makes a new empty Dict
+func NewDict() *Dict {
+	return NewDictSized(0)
+}
+
+// NewDictSized makes a new empty Dict with room for n entries
+func NewDictSized(n int) *Dict {
+	return &Dict{
+		entries: make([]dictEntry, 0, n),
+		index:   make(map[int64][]int, n),
+		strings: make(map[string]int, n),
+	}
+}
+
+// NewDictFromStringDict makes a new Dict with the contents of sd
+func NewDictFromStringDict(sd StringDict) *Dict {
+	return sd.Copy()
+}
+
+// DictNew implements dict() with its various arguments
+func DictNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var arg Object
+	err := UnpackTuple(args, nil, "dict", 0, 1, &arg)
+	if err != nil {
+		return nil, err
+	}
+	d := NewDict()
+	if arg != nil {
+		err = d.Update(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range kwargs.StringKeys() {
+		err = d.SetItem(String(k), kwargs.Get(k))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// keysEqual returns true if the dict keys a and b are equal
+//
+// As in python a key is always equal to itself, even a NaN, and then
+// the result of __eq__ is used for its truth value.
+func keysEqual(a, b Object) (bool, error) {
+	if identical(a, b) {
+		return true, nil
+	}
+	res, err := Eq(a, b)
+	if err != nil {
+		return false, err
+	}
+	res, err = MakeBool(res)
+	if err != nil {
+		return false, err
+	}
+	return res == True, nil
+}
+
+// identical returns true if a and b are the same object
+//
+// Floats and complexes are values in Go so they are the same if their
+// bits are.  Tuples and bytes are the same if they share their
+// contents.
+func identical(a, b Object) bool {
+	switch x := a.(type) {
+	case Float:
+		y, ok := b.(Float)
+		return ok && math.Float64bits(float64(x)) == math.Float64bits(float64(y))
+	case Complex:
+		y, ok := b.(Complex)
+		return ok && math.Float64bits(real(x)) == math.Float64bits(real(y)) && math.Float64bits(imag(x)) == math.Float64bits(imag(y))
+	case Tuple:
+		y, ok := b.(Tuple)
+		return ok && len(x) == len(y) && (len(x) == 0 || &x[0] == &y[0])
+	case Bytes:
+		y, ok := b.(Bytes)
+		return ok && len(x) == len(y) && (len(x) == 0 || &x[0] == &y[0])
+	}
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// find returns the hash of key and the index of its entry or -1 if
+// not found
+func (d *Dict) find(key Object) (int64, int, error) {
+	if str, ok := key.(String); ok {
+		if i, ok := d.strings[string(str)]; ok {
+			return d.entries[i].hash, i, nil
+		}
+		if len(d.strings) == d.length {
+			// Only a str can equal a str
+			return hashString(string(str)), -1, nil
+		}
+	}
+	h, err := Hash(key)
+	if err != nil {
+		return 0, -1, err
+	}
+	for _, i := range d.index[h] {
+		eq, err := keysEqual(d.entries[i].key, key)
+		if err != nil {
+			return 0, -1, err
+		}
+		if eq {
+			return h, i, nil
+		}
+	}
+	return h, -1, nil
+}
+
+// add appends a new entry which mustn't already be in the Dict
+func (d *Dict) add(h int64, key, value Object) {
+	if d.index == nil {
+		d.index = make(map[int64][]int)
+		d.strings = make(map[string]int)
+	}
+	d.index[h] = append(d.index[h], len(d.entries))
+	if str, ok := key.(String); ok {
+		d.strings[string(str)] = len(d.entries)
+	}
+	d.entries = append(d.entries, dictEntry{hash: h, key: key, value: value})
+	d.length++
+	d.version++
+}
+
+// compact removes the deleted entries and rebuilds the index
+func (d *Dict) compact() {
+	entries := make([]dictEntry, 0, d.length)
+	for _, entry := range d.entries {
+		if entry.key != nil {
+			entries = append(entries, entry)
+		}
+	}
+	d.entries = entries
+	d.index = make(map[int64][]int, len(entries))
+	d.strings = make(map[string]int, len(d.strings))
+	for i, entry := range entries {
+		d.index[entry.hash] = append(d.index[entry.hash], i)
+		if str, ok := entry.key.(String); ok {
+			d.strings[string(str)] = i
+		}
+	}
+}
+
+// GetItem returns the value stored under key and whether it was found
+//
+// It returns an error if key is unhashable.
+func (d *Dict) GetItem(key Object) (Object, bool, error) {
+	if d == nil {
+		_, err := Hash(key)
+		return nil, false, err
+	}
+	_, i, err := d.find(key)
+	if err != nil || i < 0 {
+		return nil, false, err
+	}
+	return d.entries[i].value, true, nil
+}
+
+// SetItem stores value under key
+//
+// A new key goes at the end of the Dict but replacing the value of an
+// existing key leaves it where it is.  It returns an error if key is
+// unhashable or d is nil.
+func (d *Dict) SetItem(key, value Object) error {
+	if d == nil {
+		return ExceptionNewf(TypeError, "can't set items of a nil dict")
+	}
+	h, i, err := d.find(key)
+	if err != nil {
+		return err
+	}
+	if i >= 0 {
+		d.entries[i].value = value
+		return nil
+	}
+	d.add(h, key, value)
+	return nil
+}
+
+// DelItem removes key returning whether it was found
+//
+// It returns an error if key is unhashable.
+func (d *Dict) DelItem(key Object) (bool, error) {
+	if d == nil {
+		_, err := Hash(key)
+		return false, err
+	}
+	_, i, err := d.find(key)
+	if err != nil || i < 0 {
+		return false, err
+	}
+	d.remove(i)
+	return true, nil
+}
+
+// remove deletes the i-th entry
+func (d *Dict) remove(i int) {
+	entry := d.entries[i]
+	h := entry.hash
+	indexes := d.index[h]
+	if len(indexes) == 1 {
+		delete(d.index, h)
+	} else {
+		for j, index := range indexes {
+			if index == i {
+				d.index[h] = append(indexes[:j:j], indexes[j+1:]...)
+				break
+			}
+		}
+	}
+	if str, ok := entry.key.(String); ok {
+		delete(d.strings, string(str))
+	}
+	d.entries[i] = dictEntry{}
+	d.length--
+	d.version++
+	// Compact when mostly deleted entries so iterating stays O(n)
+	if len(d.entries) > 8 && d.length < len(d.entries)/2 {
+		d.compact()
+	}
+}
+
+// Len returns the number of items in the Dict
+func (d *Dict) Len() int {
+	if d == nil {
+		return 0
+	}
+	return d.length
+}
+
+// Keys returns the keys of the Dict in order
+func (d *Dict) Keys() []Object {
+	if d == nil {
+		return nil
+	}
+	keys := make([]Object, 0, d.length)
+	for _, entry := range d.entries {
+		if entry.key != nil {
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
+}
+
+// Values returns the values of the Dict in order
+func (d *Dict) Values() []Object {
+	if d == nil {
+		return nil
+	}
+	values := make([]Object, 0, d.length)
+	for _, entry := range d.entries {
+		if entry.key != nil {
+			values = append(values, entry.value)
+		}
+	}
+	return values
+}
+
+// Items returns the (key, value) pairs of the Dict in order
+func (d *Dict) Items() []Tuple {
+	if d == nil {
+		return nil
+	}
+	items := make([]Tuple, 0, d.length)
+	for _, entry := range d.entries {
+		if entry.key != nil {
+			items = append(items, Tuple{entry.key, entry.value})
+		}
+	}
+	return items
+}
+
+// Copy returns a shallow copy of the Dict
+func (d *Dict) Copy() *Dict {
+	e := NewDictSized(d.Len())
+	if d == nil {
+		return e
+	}
+	for _, entry := range d.entries {
+		if entry.key != nil {
+			e.add(entry.hash, entry.key, entry.value)
+		}
 	}
 	return e
 }
 
-func (a StringDict) M__str__() (Object, error) {
-	return a.M__repr__()
+// Clear removes all the items from the Dict
+func (d *Dict) Clear() {
+	if d == nil {
+		return
+	}
+	d.entries = nil
+	d.index = make(map[int64][]int)
+	d.strings = make(map[string]int)
+	d.length = 0
+	d.version++
 }
 
-func (a StringDict) M__repr__() (Object, error) {
+// keysVersion returns a number which changes whenever a key is added
+// to or removed from the Dict
+func (d *Dict) keysVersion() int {
+	if d == nil {
+		return 0
+	}
+	return d.version
+}
+
+// StringDict returns a StringDict with the contents of the Dict
+//
+// It raises a TypeError if any of the keys aren't strings.
+// StringDict returns a copy of the Dict for use as keyword arguments
+//
+// It raises a TypeError if any of the keys aren't strings.
+func (d *Dict) StringDict() (StringDict, error) {
+	if !d.stringKeyed() {
+		return nil, ExceptionNewf(TypeError, "keywords must be strings")
+	}
+	return d.Copy(), nil
+}
+
+// stringKeyed returns true if all the keys of d are strings
+func (d *Dict) stringKeyed() bool {
+	return d == nil || len(d.strings) == d.length
+}
+
+// Update sets the items of d from a mapping or an iterable of
+// (key, value) pairs as dict.update does
+func (d *Dict) Update(arg Object) error {
+	if x, ok := arg.(*Dict); ok {
+		for _, item := range x.Items() {
+			err := d.SetItem(item[0], item[1])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// A mapping with a keys() method
+	if keys, err := GetAttrString(arg, "keys"); err == nil {
+		keysIterable, err := Call(keys, nil, nil)
+		if err != nil {
+			return err
+		}
+		var innerErr error
+		err = Iterate(keysIterable, func(key Object) bool {
+			var value Object
+			value, innerErr = GetItem(arg, key)
+			if innerErr == nil {
+				innerErr = d.SetItem(key, value)
+			}
+			return innerErr != nil
+		})
+		if innerErr != nil {
+			return innerErr
+		}
+		return err
+	}
+
+	// Otherwise an iterable of pairs
+	var innerErr error
+	i := 0
+	err := Iterate(arg, func(item Object) bool {
+		var pair Tuple
+		pair, innerErr = SequenceTuple(item)
+		if innerErr != nil {
+			innerErr = ExceptionNewf(TypeError, "cannot convert dictionary update sequence element #%d to a sequence", i)
+			return true
+		}
+		if len(pair) != 2 {
+			innerErr = ExceptionNewf(ValueError, "dictionary update sequence element #%d has length %d; 2 is required", i, len(pair))
+			return true
+		}
+		innerErr = d.SetItem(pair[0], pair[1])
+		i++
+		return innerErr != nil
+	})
+	if innerErr != nil {
+		return innerErr
+	}
+	return err
+}
+
+func (d *Dict) M__str__() (Object, error) {
+	return d.M__repr__()
+}
+
+func (d *Dict) M__repr__() (Object, error) {
+	if d == nil {
+		return String("{}"), nil
+	}
+	// Stop a Dict which contains itself recursing
+	if d.inRepr {
+		return String("{...}"), nil
+	}
+	d.inRepr = true
+	defer func() { d.inRepr = false }()
 	var out bytes.Buffer
 	out.WriteRune('{')
-	spacer := false
-	for key, value := range a {
-		if spacer {
+	for i, item := range d.Items() {
+		if i != 0 {
 			out.WriteString(", ")
 		}
-		keyStr, err := ReprAsString(String(key))
+		keyStr, err := ReprAsString(item[0])
 		if err != nil {
 			return nil, err
 		}
-		valueStr, err := ReprAsString(value)
+		valueStr, err := ReprAsString(item[1])
 		if err != nil {
 			return nil, err
 		}
 		out.WriteString(keyStr)
 		out.WriteString(": ")
 		out.WriteString(valueStr)
-		spacer = true
 	}
 	out.WriteRune('}')
 	return String(out.String()), nil
 }
 
-// Returns a list of keys from the dict
-func (d StringDict) M__iter__() (Object, error) {
-	o := make([]Object, 0, len(d))
-	for k := range d {
-		o = append(o, String(k))
+func (d *Dict) M__len__() (Object, error) {
+	return Int(d.Len()), nil
+}
+
+func (d *Dict) M__bool__() (Object, error) {
+	return NewBool(d.Len() != 0), nil
+}
+
+// Returns an iterator over the keys of the dict
+func (d *Dict) M__iter__() (Object, error) {
+	return newDictIterator(DictKeyIteratorType, d), nil
+}
+
+func (d *Dict) M__getitem__(key Object) (Object, error) {
+	res, found, err := d.GetItem(key)
+	if err != nil {
+		return nil, err
 	}
-	return NewIterator(o), nil
+	if !found {
+		return nil, exceptionNew(KeyError, Tuple{key})
+	}
+	return res, nil
 }
 
-func (d StringDict) M__getitem__(key Object) (Object, error) {
-	str, ok := key.(String)
-	if ok {
-		res, ok := d[string(str)]
-		if ok {
-			return res, nil
-		}
+func (d *Dict) M__setitem__(key, value Object) (Object, error) {
+	err := d.SetItem(key, value)
+	if err != nil {
+		return nil, err
 	}
-	return nil, ExceptionNewf(KeyError, "%v", key)
+	return None, nil
 }
 
-func (d StringDict) M__setitem__(key, value Object) (Object, error) {
-	str, ok := key.(String)
-	if !ok {
-		return nil, ExceptionNewf(KeyError, "FIXME can only have string keys!: %v", key)
+func (d *Dict) M__delitem__(key Object) (Object, error) {
+	found, err := d.DelItem(key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exceptionNew(KeyError, Tuple{key})
 	}
-	d[string(str)] = value
 	return None, nil
 }
 
-func (a StringDict) M__eq__(other Object) (Object, error) {
-	b, ok := other.(StringDict)
+func (d *Dict) M__contains__(key Object) (Object, error) {
+	_, found, err := d.GetItem(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(found), nil
+}
+
+func (a *Dict) M__eq__(other Object) (Object, error) {
+	b, ok := other.(*Dict)
 	if !ok {
 		return NotImplemented, nil
 	}
-	if len(a) != len(b) {
+	if a.Len() != b.Len() {
 		return False, nil
 	}
-	for k, av := range a {
-		bv, ok := b[k]
-		if !ok {
+	for _, item := range a.Items() {
+		bv, found, err := b.GetItem(item[0])
+		if err != nil {
+			return nil, err
+		}
+		if !found {
 			return False, nil
 		}
-		res, err := Eq(av, bv)
+		res, err := Eq(item[1], bv)
 		if err != nil {
 			return nil, err
 		}
@@ -192,28 +862,19 @@ func (a StringDict) M__eq__(other Object) (Object, error) {
 	return True, nil
 }
 
-func (a StringDict) M__ne__(other Object) (Object, error) {
-	res, err := a.M__eq__(other)
-	if err != nil {
-		return nil, err
-	}
-	if res == NotImplemented {
-		return res, nil
-	}
-	if res == True {
-		return False, nil
-	}
-	return True, nil
+func (a *Dict) M__ne__(other Object) (Object, error) {
+	return notEq(a.M__eq__(other))
 }
 
-func (a StringDict) M__contains__(other Object) (Object, error) {
-	key, ok := other.(String)
-	if !ok {
-		return nil, ExceptionNewf(KeyError, "FIXME can only have string keys!: %v", key)
-	}
-
-	if _, ok := a[string(key)]; ok {
-		return True, nil
-	}
-	return False, nil
-}
+// Check interface is satisfied
+var _ I__str__ = (*Dict)(nil)
+var _ I__repr__ = (*Dict)(nil)
+var _ I__len__ = (*Dict)(nil)
+var _ I__bool__ = (*Dict)(nil)
+var _ I__iter__ = (*Dict)(nil)
+var _ I__getitem__ = (*Dict)(nil)
+var _ I__setitem__ = (*Dict)(nil)
+var _ I__delitem__ = (*Dict)(nil)
+var _ I__contains__ = (*Dict)(nil)
+var _ I__eq__ = (*Dict)(nil)
+var _ I__ne__ = (*Dict)(nil)
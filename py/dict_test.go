@@ -0,0 +1,105 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStringDict(t *testing.T) {
+	var nilDict StringDict
+	if nilDict.Len() != 0 || nilDict.Get("a") != nil || len(nilDict.StringKeys()) != 0 {
+		t.Errorf("nil StringDict isn't empty")
+	}
+	if nilDict.Delete("a") {
+		t.Errorf("deleted from nil StringDict")
+	}
+
+	d := NewStringDict()
+	d.Set("b", Int(1))
+	if err := d.SetItem(Int(1), Int(3)); err != nil {
+		t.Fatalf("SetItem failed: %v", err)
+	}
+	d.Set("a", Int(2))
+	if got, want := d.StringKeys(), []string{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("str keys want %v got %v", want, got)
+	}
+	if got, want := d.Keys(), []Object{String("b"), Int(1), String("a")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys want %v got %v", want, got)
+	}
+	if _, err := d.StringDict(); !errors.Is(err, TypeError) {
+		t.Errorf("StringDict with int key want TypeError got %v", err)
+	}
+	if !d.Delete("b") || d.Delete("b") {
+		t.Errorf("Delete didn't delete b once")
+	}
+	value, found, err := d.GetItem(String("a"))
+	if err != nil || !found || value != Int(2) {
+		t.Errorf("GetItem a want 2 got %v, %v, %v", value, found, err)
+	}
+	if d.Len() != 2 {
+		t.Errorf("want 2 items got %d", d.Len())
+	}
+}
+
+func TestDictIteratorChanged(t *testing.T) {
+	for _, d := range []*Dict{NewStringDict(), new(Dict)} {
+		for _, k := range []string{"a", "b", "c"} {
+			if err := d.SetItem(String(k), Int(0)); err != nil {
+				t.Fatalf("SetItem failed: %v", err)
+			}
+		}
+
+		// Changing the values is allowed
+		it := newDictIterator(DictItemIteratorType, d)
+		if err := d.SetItem(String("b"), Int(1)); err != nil {
+			t.Fatalf("SetItem failed: %v", err)
+		}
+		var got []Object
+		for {
+			item, err := it.M__next__()
+			if err == StopIteration {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%T: next failed: %v", d, err)
+			}
+			got = append(got, item)
+		}
+		want := []Object{Tuple{String("a"), Int(0)}, Tuple{String("b"), Int(1)}, Tuple{String("c"), Int(0)}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%T: items want %v got %v", d, want, got)
+		}
+
+		// Changing the size isn't, even if it is changed back
+		it = newDictIterator(DictKeyIteratorType, d)
+		if err := d.SetItem(String("d"), Int(0)); err != nil {
+			t.Fatalf("SetItem failed: %v", err)
+		}
+		if _, err := it.M__next__(); !errors.Is(err, RuntimeError) {
+			t.Errorf("%T: next after add want RuntimeError got %v", d, err)
+		}
+		if _, err := d.DelItem(String("d")); err != nil {
+			t.Fatalf("DelItem failed: %v", err)
+		}
+		if _, err := it.M__next__(); !errors.Is(err, RuntimeError) {
+			t.Errorf("%T: next after add and delete want RuntimeError got %v", d, err)
+		}
+
+		// Nor is replacing a key
+		it = newDictIterator(DictKeyIteratorType, d)
+		if _, err := d.DelItem(String("a")); err != nil {
+			t.Fatalf("DelItem failed: %v", err)
+		}
+		if err := d.SetItem(String("e"), Int(0)); err != nil {
+			t.Fatalf("SetItem failed: %v", err)
+		}
+		if _, err := it.M__next__(); !errors.Is(err, RuntimeError) {
+			t.Errorf("%T: next after replacing a key want RuntimeError got %v", d, err)
+		}
+	}
+}
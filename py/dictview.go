@@ -0,0 +1,355 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Dict view objects
+//
+// These are returned by dict.keys(), dict.values() and dict.items().
+// They don't copy the dict so they see any later changes to it.
+
+package py
+
+var (
+	DictKeysType          = NewType("dict_keys", "")
+	DictValuesType        = NewType("dict_values", "")
+	DictItemsType         = NewType("dict_items", "")
+	DictKeyIteratorType   = NewType("dict_keyiterator", "")
+	DictValueIteratorType = NewType("dict_valueiterator", "")
+	DictItemIteratorType  = NewType("dict_itemiterator", "")
+)
+
+// A view on the keys of a dict
+type DictKeys struct {
+	dict *Dict
+}
+
+// A view on the values of a dict
+type DictValues struct {
+	dict *Dict
+}
+
+// A view on the (key, value) pairs of a dict
+type DictItems struct {
+	dict *Dict
+}
+
+// Type of this DictKeys object
+func (v *DictKeys) Type() *Type {
+	return DictKeysType
+}
+
+// Type of this DictValues object
+func (v *DictValues) Type() *Type {
+	return DictValuesType
+}
+
+// Type of this DictItems object
+func (v *DictItems) Type() *Type {
+	return DictItemsType
+}
+
+// items returns the items of the DictItems as Objects
+func (v *DictItems) items() []Object {
+	items := v.dict.Items()
+	o := make([]Object, len(items))
+	for i := range items {
+		o[i] = items[i]
+	}
+	return o
+}
+
+// viewRepr returns the repr of a view called name with items
+func viewRepr(name string, items []Object) (Object, error) {
+	str, err := ReprAsString(NewListFromItems(items))
+	if err != nil {
+		return nil, err
+	}
+	return String(name + "(" + str + ")"), nil
+}
+
+func (v *DictKeys) M__repr__() (Object, error) {
+	return viewRepr("dict_keys", v.dict.Keys())
+}
+
+func (v *DictValues) M__repr__() (Object, error) {
+	return viewRepr("dict_values", v.dict.Values())
+}
+
+func (v *DictItems) M__repr__() (Object, error) {
+	return viewRepr("dict_items", v.items())
+}
+
+func (v *DictKeys) M__len__() (Object, error) {
+	return Int(v.dict.Len()), nil
+}
+
+func (v *DictValues) M__len__() (Object, error) {
+	return Int(v.dict.Len()), nil
+}
+
+func (v *DictItems) M__len__() (Object, error) {
+	return Int(v.dict.Len()), nil
+}
+
+func (v *DictKeys) M__iter__() (Object, error) {
+	return newDictIterator(DictKeyIteratorType, v.dict), nil
+}
+
+func (v *DictValues) M__iter__() (Object, error) {
+	return newDictIterator(DictValueIteratorType, v.dict), nil
+}
+
+func (v *DictItems) M__iter__() (Object, error) {
+	return newDictIterator(DictItemIteratorType, v.dict), nil
+}
+
+// An iterator over the keys, values or items of a dict
+//
+// As in CPython it raises a RuntimeError if keys are added to or
+// removed from the dict while it is being iterated, but values may be
+// changed.
+type dictIterator struct {
+	kind    *Type
+	dict    *Dict
+	keys    []Object
+	pos     int
+	length  int
+	version int
+}
+
+// newDictIterator returns an iterator of type kind over dict
+func newDictIterator(kind *Type, dict *Dict) *dictIterator {
+	return &dictIterator{
+		kind:    kind,
+		dict:    dict,
+		keys:    dict.Keys(),
+		length:  dict.Len(),
+		version: dict.keysVersion(),
+	}
+}
+
+// Type of this dictIterator object
+func (it *dictIterator) Type() *Type {
+	return it.kind
+}
+
+func (it *dictIterator) M__iter__() (Object, error) {
+	return it, nil
+}
+
+// Get next one from the iteration
+func (it *dictIterator) M__next__() (Object, error) {
+	if it.dict.Len() != it.length {
+		// Keep raising the error if the size is changed back
+		it.length = -1
+		return nil, ExceptionNewf(RuntimeError, "dictionary changed size during iteration")
+	}
+	if it.dict.keysVersion() != it.version {
+		return nil, ExceptionNewf(RuntimeError, "dictionary keys changed during iteration")
+	}
+	if it.pos >= len(it.keys) {
+		return nil, StopIteration
+	}
+	key := it.keys[it.pos]
+	it.pos++
+	if it.kind == DictKeyIteratorType {
+		return key, nil
+	}
+	value, _, err := it.dict.GetItem(key)
+	if err != nil {
+		return nil, err
+	}
+	if it.kind == DictValueIteratorType {
+		return value, nil
+	}
+	return Tuple{key, value}, nil
+}
+
+func (v *DictKeys) M__contains__(key Object) (Object, error) {
+	_, found, err := v.dict.GetItem(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(found), nil
+}
+
+func (v *DictValues) M__contains__(value Object) (Object, error) {
+	for _, item := range v.dict.Values() {
+		eq, err := Eq(item, value)
+		if err != nil {
+			return nil, err
+		}
+		if eq == True {
+			return True, nil
+		}
+	}
+	return False, nil
+}
+
+func (v *DictItems) M__contains__(item Object) (Object, error) {
+	pair, ok := item.(Tuple)
+	if !ok || len(pair) != 2 {
+		return False, nil
+	}
+	value, found, err := v.dict.GetItem(pair[0])
+	if err != nil || !found {
+		return False, err
+	}
+	return Eq(value, pair[1])
+}
+
+// viewAsSet returns obj as a Set if it is a set, frozenset or set like
+// dict view
+func viewAsSet(obj Object) (*Set, bool, error) {
+	switch x := obj.(type) {
+	case *Set:
+		return x, true, nil
+	case *FrozenSet:
+		return &x.Set, true, nil
+	case *DictKeys:
+		s, err := NewSetFromItems(x.dict.Keys())
+		return s, true, err
+	case *DictItems:
+		s, err := NewSetFromItems(x.items())
+		return s, true, err
+	}
+	return nil, false, nil
+}
+
+// viewSetOp applies the set operation op to the view v and the
+// iterable other, the other way round if reversed
+func viewSetOp(v, other Object, op func(a, b *Set) (*Set, error), reversed bool) (Object, error) {
+	a, _, err := viewAsSet(v)
+	if err != nil {
+		return nil, err
+	}
+	b, err := SequenceSet(other)
+	if err != nil {
+		return nil, err
+	}
+	if reversed {
+		a, b = b, a
+	}
+	res, err := op(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// viewEq compares the view v with other as sets
+func viewEq(v, other Object) (Object, error) {
+	b, ok, err := viewAsSet(other)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return NotImplemented, nil
+	}
+	a, _, err := viewAsSet(v)
+	if err != nil {
+		return nil, err
+	}
+	return a.M__eq__(b)
+}
+
+func (v *DictKeys) M__and__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).and, false)
+}
+
+func (v *DictKeys) M__rand__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).and, true)
+}
+
+func (v *DictKeys) M__or__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).or, false)
+}
+
+func (v *DictKeys) M__ror__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).or, true)
+}
+
+func (v *DictKeys) M__sub__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).sub, false)
+}
+
+func (v *DictKeys) M__rsub__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).sub, true)
+}
+
+func (v *DictKeys) M__xor__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).xor, false)
+}
+
+func (v *DictKeys) M__rxor__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).xor, true)
+}
+
+func (v *DictKeys) M__eq__(other Object) (Object, error) {
+	return viewEq(v, other)
+}
+
+func (v *DictKeys) M__ne__(other Object) (Object, error) {
+	return notEq(viewEq(v, other))
+}
+
+func (v *DictItems) M__and__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).and, false)
+}
+
+func (v *DictItems) M__rand__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).and, true)
+}
+
+func (v *DictItems) M__or__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).or, false)
+}
+
+func (v *DictItems) M__ror__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).or, true)
+}
+
+func (v *DictItems) M__sub__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).sub, false)
+}
+
+func (v *DictItems) M__rsub__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).sub, true)
+}
+
+func (v *DictItems) M__xor__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).xor, false)
+}
+
+func (v *DictItems) M__rxor__(other Object) (Object, error) {
+	return viewSetOp(v, other, (*Set).xor, true)
+}
+
+func (v *DictItems) M__eq__(other Object) (Object, error) {
+	return viewEq(v, other)
+}
+
+func (v *DictItems) M__ne__(other Object) (Object, error) {
+	return notEq(viewEq(v, other))
+}
+
+// Check interface is satisfied
+var _ I__repr__ = (*DictKeys)(nil)
+var _ I__len__ = (*DictKeys)(nil)
+var _ I__iter__ = (*DictKeys)(nil)
+var _ I__contains__ = (*DictKeys)(nil)
+var _ I__and__ = (*DictKeys)(nil)
+var _ I__rand__ = (*DictKeys)(nil)
+var _ I__eq__ = (*DictKeys)(nil)
+var _ I__repr__ = (*DictValues)(nil)
+var _ I__len__ = (*DictValues)(nil)
+var _ I__iter__ = (*DictValues)(nil)
+var _ I__contains__ = (*DictValues)(nil)
+var _ I__repr__ = (*DictItems)(nil)
+var _ I__len__ = (*DictItems)(nil)
+var _ I__iter__ = (*DictItems)(nil)
+var _ I__contains__ = (*DictItems)(nil)
+var _ I__and__ = (*DictItems)(nil)
+var _ I__rand__ = (*DictItems)(nil)
+var _ I__eq__ = (*DictItems)(nil)
+var _ I_iterator = (*dictIterator)(nil)
@@ -7,9 +7,12 @@
 package py
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"strings"
 )
 
 // A python Exception object
@@ -21,6 +24,7 @@ type Exception struct {
 	Cause           Object
 	SuppressContext bool
 	Dict            StringDict // anything else that we want to stuff in
+	Err             error      // Go error this was made from, if any
 }
 
 // A python exception info block
@@ -38,6 +42,8 @@ var (
 	SystemExit                = BaseException.NewType("SystemExit", "Request to exit from the interpreter.", nil, nil)
 	KeyboardInterrupt         = BaseException.NewType("KeyboardInterrupt", "Program interrupted by user.", nil, nil)
 	GeneratorExit             = BaseException.NewType("GeneratorExit", "Request that a generator exit.", nil, nil)
+	CancelledError            = BaseException.NewType("CancelledError", "Execution cancelled by the host program.", nil, nil)
+	ResourceError             = BaseException.NewType("ResourceError", "Resource budget of the interpreter exhausted.", nil, nil)
 	ExceptionType             = BaseException.NewType("Exception", "Common base class for all non-exit exceptions.", nil, nil)
 	StopIteration             = ExceptionType.NewType("StopIteration", "Signal the end from iterator.__next__().", nil, nil)
 	ArithmeticError           = ExceptionType.NewType("ArithmeticError", "Base class for arithmetic errors.", nil, nil)
@@ -49,6 +55,7 @@ var (
 	BufferError               = ExceptionType.NewType("BufferError", "Buffer error.", nil, nil)
 	EOFError                  = ExceptionType.NewType("EOFError", "Read beyond end of file.", nil, nil)
 	ImportError               = ExceptionType.NewType("ImportError", "Import can't find module, or can't find name in module.", nil, nil)
+	ModuleNotFoundError       = ImportError.NewType("ModuleNotFoundError", "Module not found.", nil, nil)
 	LookupError               = ExceptionType.NewType("LookupError", "Base class for lookup errors.", nil, nil)
 	IndexError                = LookupError.NewType("IndexError", "Sequence index out of range.", nil, nil)
 	KeyError                  = LookupError.NewType("KeyError", "Mapping key not found.", nil, nil)
@@ -74,6 +81,7 @@ var (
 	ReferenceError            = ExceptionType.NewType("ReferenceError", "Weak ref proxy used after referent went away.", nil, nil)
 	RuntimeError              = ExceptionType.NewType("RuntimeError", "Unspecified run-time error.", nil, nil)
 	NotImplementedError       = RuntimeError.NewType("NotImplementedError", "Method or function hasn't been implemented yet.", nil, nil)
+	RecursionError            = RuntimeError.NewType("RecursionError", "Recursion limit exceeded.", nil, nil)
 	SyntaxError               = ExceptionType.NewType("SyntaxError", "Invalid syntax.", nil, nil)
 	IndentationError          = SyntaxError.NewType("IndentationError", "Improper indentation.", nil, nil)
 	TabError                  = IndentationError.NewType("TabError", "Improper mixture of spaces and tabs.", nil, nil)
@@ -133,8 +141,8 @@ func (e *Exception) Error() string {
 		}
 	}
 	// FIXME Print out special stuff for things which look like SyntaxErrors
-	if e.Dict["lineno"] != nil {
-		message = fmt.Sprintf("\n  File \"%v\", line %v, offset %v\n    %s\n\n", e.Dict["filename"], e.Dict["lineno"], e.Dict["offset"], e.Dict["line"]) + message
+	if e.Dict.Get("lineno") != nil {
+		message = fmt.Sprintf("\n  File \"%v\", line %v, offset %v\n    %s\n\n", e.Dict.Get("filename"), e.Dict.Get("lineno"), e.Dict.Get("offset"), e.Dict.Get("line")) + message
 	}
 	return message
 }
@@ -150,6 +158,61 @@ func (e ExceptionInfo) Error() string {
 	return e.Value.Type().Name
 }
 
+// Unwrap returns the Go error the exception was made from or nil
+//
+// This lets errors.Is and errors.As see through python exceptions
+// raised for Go errors.
+func (e *Exception) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is the exception type of e or one of its
+// base classes, so errors.Is(err, KeyError) works
+func (e *Exception) Is(target error) bool {
+	t, ok := target.(*Type)
+	return ok && IsException(t, e)
+}
+
+// Unwrap returns the exception value if it is an *Exception so
+// errors.As can find it
+func (e ExceptionInfo) Unwrap() error {
+	if exception, ok := e.Value.(*Exception); ok {
+		return exception
+	}
+	return nil
+}
+
+// Is returns true if target is the exception type of e or one of its
+// base classes, so errors.Is(err, KeyError) works
+func (e ExceptionInfo) Is(target error) bool {
+	t, ok := target.(*Type)
+	return ok && e.Type != nil && IsException(t, e.Type)
+}
+
+// TracebackText returns the python traceback of err as text.
+//
+// err may be wrapped by other Go errors.  If the python exception
+// was made from a Go error then that is shown too, formatted with
+// %+v so any Go stack trace it carries is included.
+func TracebackText(err error) string {
+	var buf bytes.Buffer
+	var exc ExceptionInfo
+	var exception *Exception
+	switch {
+	case errors.As(err, &exc):
+		exc.TracebackDump(&buf)
+		exception, _ = exc.Value.(*Exception)
+	case errors.As(err, &exception):
+		fmt.Fprintf(&buf, "%v\n", exception)
+	default:
+		fmt.Fprintf(&buf, "%v\n", err)
+	}
+	if exception != nil && exception.Err != nil {
+		fmt.Fprintf(&buf, "\nCaused by Go error:\n%+v\n", exception.Err)
+	}
+	return buf.String()
+}
+
 // Dump a traceback for exc to w
 func (exc *ExceptionInfo) TracebackDump(w io.Writer) {
 	if exc == nil {
@@ -171,13 +234,13 @@ func exceptionNew(metatype *Type, args Tuple) *Exception {
 	return &Exception{
 		Base: metatype,
 		Args: args.Copy(),
-		Dict: make(StringDict),
+		Dict: NewStringDict(),
 	}
 }
 
 // ExceptionNew
 func ExceptionNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
-	if len(kwargs) != 0 {
+	if kwargs.Len() != 0 {
 		// FIXME this causes an initialization loop
 		// return nil, ExceptionNewf(TypeError, "%s does not take keyword arguments", metatype.Name)
 		return nil, fmt.Errorf("TypeError: %s does not take keyword arguments", metatype.Name)
@@ -186,13 +249,22 @@ func ExceptionNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error)
 }
 
 // ExceptionNewf - make a new exception with fmt parameters
+//
+// As with fmt.Errorf, if format contains a %w verb then the Go error
+// it formats is kept in Err so errors.Is and errors.As can find it.
 func ExceptionNewf(metatype *Type, format string, a ...interface{}) *Exception {
-	message := fmt.Sprintf(format, a...)
-	return &Exception{
+	exc := &Exception{
 		Base: metatype,
-		Args: Tuple{String(message)},
-		Dict: make(StringDict),
+		Dict: NewStringDict(),
 	}
+	if !strings.Contains(format, "%w") {
+		exc.Args = Tuple{String(fmt.Sprintf(format, a...))}
+	} else {
+		err := fmt.Errorf(format, a...)
+		exc.Args = Tuple{String(err.Error())}
+		exc.Err = errors.Unwrap(err)
+	}
+	return exc
 }
 
 /*
@@ -228,7 +300,13 @@ func MakeException(r interface{}) *Exception {
 			return ExceptionNewf(TypeError, "exceptions must derive from BaseException")
 		}
 	case error:
-		return exceptionNew(SystemError, Tuple{String(x.Error())})
+		var exception *Exception
+		if errors.As(x, &exception) {
+			return exception
+		}
+		e := exceptionNew(SystemError, Tuple{String(x.Error())})
+		e.Err = x
+		return e
 	case string:
 		return exceptionNew(SystemError, Tuple{String(x)})
 	default:
@@ -241,10 +319,10 @@ func MakeSyntaxError(r interface{}, filename string, lineno int, offset int, lin
 	// FIXME add more stuff to make it a SyntaxError!
 	// see Python/errors.c PyErr_SyntaxLocationObject
 	e := MakeException(r)
-	e.Dict["filename"] = String(filename)
-	e.Dict["lineno"] = Int(lineno)
-	e.Dict["offset"] = Int(offset)
-	e.Dict["line"] = String(line)
+	e.Dict.Set("filename", String(filename))
+	e.Dict.Set("lineno", Int(lineno))
+	e.Dict.Set("offset", Int(offset))
+	e.Dict.Set("line", String(line))
 	return e
 }
 
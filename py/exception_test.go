@@ -0,0 +1,132 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
+)
+
+var errNotFound = errors.New("not found")
+
+// runError runs src in ctx returning the error it raises
+func runError(t *testing.T, ctx *py.Context, src string) error {
+	obj, err := compile.Compile(src, "<string>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	module := ctx.NewModule("__main__", "", nil, nil)
+	_, err = vm.Run(ctx, module.Globals, module.Globals, obj.(*py.Code), nil)
+	if err == nil {
+		t.Fatalf("Run didn't raise an exception")
+	}
+	return err
+}
+
+func TestExceptionErrorsIs(t *testing.T) {
+	err := error(py.ExceptionNewf(py.KeyError, "x"))
+	for _, test := range []struct {
+		target *py.Type
+		want   bool
+	}{
+		{py.KeyError, true},
+		{py.LookupError, true},
+		{py.BaseException, true},
+		{py.IndexError, false},
+	} {
+		if got := errors.Is(err, test.target); got != test.want {
+			t.Errorf("errors.Is(KeyError, %s) want %v got %v", test.target.Name, test.want, got)
+		}
+	}
+
+	ctx := py.NewContext(py.ContextOpts{})
+	err = runError(t, ctx, `d = {}
+d["missing"]
+`)
+	err = fmt.Errorf("running: %w", err)
+	if !errors.Is(err, py.KeyError) || errors.Is(err, py.IndexError) {
+		t.Errorf("errors.Is failed on raised KeyError: %v", err)
+	}
+	var exception *py.Exception
+	if !errors.As(err, &exception) || exception.Type() != py.KeyError {
+		t.Errorf("errors.As failed: %v", exception)
+	}
+
+	err = py.ExceptionNewf(py.OSError, "%w", fs.ErrNotExist)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("errors.Is didn't find wrapped Go error")
+	}
+	if got := err.(*py.Exception).Args.(py.Tuple)[0]; got != py.String(fs.ErrNotExist.Error()) {
+		t.Errorf("wrong message %q", got)
+	}
+
+	// Only %w wraps the Go error
+	exc := py.ExceptionNewf(py.OSError, "failed: %v", fs.ErrNotExist)
+	if exc.Err != nil {
+		t.Errorf("%%v wrapped the Go error")
+	}
+	if got, want := exc.Args.(py.Tuple)[0], py.String("failed: "+fs.ErrNotExist.Error()); got != want {
+		t.Errorf("want message %q got %q", want, got)
+	}
+}
+
+func TestExceptionGoError(t *testing.T) {
+	ctx := py.NewContext(py.ContextOpts{})
+	ctx.Builtins.Globals.Set("lookup", py.MustNewGoFunction("lookup", func(key string) (string, error) {
+		return "", fmt.Errorf("lookup %q: %w", key, errNotFound)
+	}))
+
+	ctx.Builtins.Globals.Set("wrapped", py.MustNewGoFunction("wrapped", func() error {
+		return fmt.Errorf("wrapped: %w", py.ExceptionNewf(py.KeyError, "inner"))
+	}))
+
+	module := runString(t, ctx, `
+try:
+    lookup("a")
+except RuntimeError as e:
+    caught = e.args[0]
+try:
+    wrapped()
+except KeyError as e:
+    inner = e.args[0]
+`)
+	if got, want := module.Globals.Get("inner"), py.String("inner"); got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+	if got, want := module.Globals.Get("caught"), py.String(`lookup "a": not found`); got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+
+	err := runError(t, ctx, `
+def f():
+    lookup("b")
+f()
+`)
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("errors.Is didn't find Go error in %v", err)
+	}
+	if !errors.Is(err, py.RuntimeError) {
+		t.Errorf("errors.Is didn't match RuntimeError in %v", err)
+	}
+
+	text := py.TracebackText(fmt.Errorf("wrapped: %w", err))
+	for _, want := range []string{
+		"Traceback (most recent call last):",
+		`in f`,
+		`RuntimeError: 'lookup "b": not found'`,
+		"Caused by Go error:\nlookup \"b\": not found",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("traceback text missing %q:\n%s", want, text)
+		}
+	}
+}
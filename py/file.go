@@ -10,28 +10,36 @@
 package py
 
 import (
+	"bufio"
+	"errors"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
+	"path"
+	"strings"
 )
 
 var FileType = NewType("file", `represents an open file`)
 var errClosed = ExceptionNewf(ValueError, "I/O operation on closed file.")
 
 func init() {
-	FileType.Dict["write"] = MustNewMethod("write", func(self Object, value Object) (Object, error) {
+	FileType.Dict.Set("write", MustNewMethod("write", func(self Object, value Object) (Object, error) {
 		return self.(*File).Write(value)
-	}, 0, "write(arg) -> writes the contents of arg to the file, returning the number of characters written.")
+	}, 0, "write(arg) -> writes the contents of arg to the file, returning the number of characters written."))
 
-	FileType.Dict["read"] = MustNewMethod("read", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	FileType.Dict.Set("read", MustNewMethod("read", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
 		return self.(*File).Read(args, kwargs)
-	}, 0, "read([size]) -> read at most size bytes, returned as a string.\n\nIf the size argument is negative or omitted, read until EOF is reached.\nNotice that when in non-blocking mode, less data than what was requested\nmay be returned, even if no size parameter was given.")
-	FileType.Dict["close"] = MustNewMethod("close", func(self Object) (Object, error) {
+	}, 0, "read([size]) -> read at most size bytes, returned as a string.\n\nIf the size argument is negative or omitted, read until EOF is reached.\nNotice that when in non-blocking mode, less data than what was requested\nmay be returned, even if no size parameter was given."))
+	FileType.Dict.Set("readline", MustNewMethod("readline", func(self Object, args Tuple) (Object, error) {
+		return self.(*File).Readline(args)
+	}, 0, "readline([size]) -> next line from the file, as a string.\n\nRetain newline.  A non-negative size argument limits the maximum\nnumber of bytes to return (an incomplete line may be returned then).\nReturn an empty string at EOF."))
+	FileType.Dict.Set("close", MustNewMethod("close", func(self Object) (Object, error) {
 		return self.(*File).Close()
-	}, 0, "close() -> None or (perhaps) an integer.  Close the file.\n\nSets data attribute .closed to True.  A closed file cannot be used for\nfurther I/O operations.  close() may be called more than once without\nerror.  Some kinds of file objects (for example, opened by popen())\nmay return an exit status upon closing.")
-	FileType.Dict["flush"] = MustNewMethod("flush", func(self Object) (Object, error) {
+	}, 0, "close() -> None or (perhaps) an integer.  Close the file.\n\nSets data attribute .closed to True.  A closed file cannot be used for\nfurther I/O operations.  close() may be called more than once without\nerror.  Some kinds of file objects (for example, opened by popen())\nmay return an exit status upon closing."))
+	FileType.Dict.Set("flush", MustNewMethod("flush", func(self Object) (Object, error) {
 		return self.(*File).Flush()
-	}, 0, "flush() -> Flush the write buffers of the stream if applicable. This does nothing for read-only and non-blocking streams.")
+	}, 0, "flush() -> Flush the write buffers of the stream if applicable. This does nothing for read-only and non-blocking streams."))
 }
 
 type FileMode int
@@ -45,9 +53,23 @@ const (
 	FileReadWrite = FileRead + FileWrite
 )
 
+// A python file object
 type File struct {
-	*os.File
+	// Go stream this reads from and writes to.  It should be an
+	// io.Reader if the file is readable and an io.Writer if it is
+	// writable.  If it is an io.Closer it is closed when the file
+	// is.
+	Stream interface{}
 	FileMode
+	closed bool
+	// Buffered reader made by readline which reads must use too
+	buffered *bufio.Reader
+}
+
+// NewFile makes a python file object from the Go stream which should
+// be an io.Reader and/or io.Writer as mode requires.
+func NewFile(stream interface{}, mode FileMode) *File {
+	return &File{Stream: stream, FileMode: mode}
 }
 
 // Type of this object
@@ -74,11 +96,21 @@ func (o *File) Write(value Object) (Object, error) {
 		return nil, ExceptionNewf(TypeError, "expected a string or other character buffer object")
 	}
 
-	n, err := o.File.Write(b)
-	if err != nil && err.(*os.PathError).Err == os.ErrClosed {
+	if o.closed {
 		return nil, errClosed
 	}
-	return Int(n), err
+	w, ok := o.Stream.(io.Writer)
+	if !ok || !o.Can(FileWrite) {
+		return nil, ExceptionNewf(OSError, "not writable")
+	}
+	n, err := w.Write(b)
+	if err != nil {
+		if errors.Is(err, os.ErrClosed) {
+			return nil, errClosed
+		}
+		return nil, ExceptionNewf(OSError, "%w", err)
+	}
+	return Int(n), nil
 }
 
 func (o *File) readResult(b []byte) (Object, error) {
@@ -105,7 +137,10 @@ func (o *File) Read(args Tuple, kwargs StringDict) (Object, error) {
 		return nil, err
 	}
 
-	var r io.Reader = o.File
+	r, err := o.reader()
+	if err != nil {
+		return nil, err
+	}
 
 	switch pyN, ok := arg.(Int); {
 	case arg == None:
@@ -134,26 +169,92 @@ func (o *File) Read(args Tuple, kwargs StringDict) (Object, error) {
 		if err == io.EOF {
 			return o.readResult(nil)
 		}
-		if perr, ok := err.(*os.PathError); ok && perr.Err == os.ErrClosed {
+		if errors.Is(err, os.ErrClosed) {
 			return nil, errClosed
 		}
 
+		return nil, ExceptionNewf(OSError, "%w", err)
+	}
+
+	return o.readResult(b)
+}
+
+// reader returns the Go reader to read the file from
+func (o *File) reader() (io.Reader, error) {
+	if o.closed {
+		return nil, errClosed
+	}
+	r, ok := o.Stream.(io.Reader)
+	if !ok || !o.Can(FileRead) {
+		return nil, ExceptionNewf(OSError, "not readable")
+	}
+	if o.buffered != nil {
+		return o.buffered, nil
+	}
+	return r, nil
+}
+
+func (o *File) Readline(args Tuple) (Object, error) {
+	var arg Object = Int(-1)
+	err := UnpackTuple(args, nil, "readline", 0, 1, &arg)
+	if err != nil {
+		return nil, err
+	}
+	var size int64 = -1
+	switch pyN := arg.(type) {
+	case Int:
+		size, _ = pyN.GoInt64()
+	default:
+		if arg != None {
+			return nil, ExceptionNewf(TypeError, "readline() argument 1 must be int, not %s", arg.Type().Name)
+		}
+	}
+
+	r, err := o.reader()
+	if err != nil {
 		return nil, err
 	}
+	if o.buffered == nil {
+		o.buffered = bufio.NewReader(r)
+	}
 
+	var b []byte
+	for size < 0 || int64(len(b)) < size {
+		c, err := o.buffered.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fileError(err)
+		}
+		b = append(b, c)
+		if c == '\n' {
+			break
+		}
+	}
 	return o.readResult(b)
 }
 
 func (o *File) Close() (Object, error) {
-	_ = o.File.Close()
+	if !o.closed {
+		o.closed = true
+		if c, ok := o.Stream.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
 	return None, nil
 }
 
 func (o *File) Flush() (Object, error) {
-	err := o.File.Sync()
-	if perr, ok := err.(*os.PathError); ok && perr.Err == os.ErrClosed {
+	if o.closed {
 		return nil, errClosed
 	}
+	switch s := o.Stream.(type) {
+	case interface{ Flush() error }:
+		_ = s.Flush()
+	case interface{ Sync() error }:
+		_ = s.Sync()
+	}
 
 	return None, nil
 }
@@ -166,7 +267,88 @@ func (o *File) M__exit__(exc_type, exc_value, traceback Object) (Object, error)
 	return o.Close()
 }
 
+// OpenFile opens filename on the OS filesystem as the python open()
+// does
 func OpenFile(filename, mode string, buffering int) (Object, error) {
+	fileMode, flag, err := parseFileMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filename, flag, 0666)
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	if finfo, err := f.Stat(); err == nil {
+		if finfo.IsDir() {
+			f.Close()
+			return nil, ExceptionNewf(IsADirectoryError, "Is a directory: '%s'", filename)
+		}
+	}
+
+	return NewFile(f, fileMode), nil
+}
+
+// OpenFileFS opens filename in fsys as the python open() does
+//
+// fsys is read only, so trying to open a file for writing raises an
+// OSError.
+func OpenFileFS(fsys fs.FS, filename, mode string, buffering int) (Object, error) {
+	fileMode, _, err := parseFileMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	if fileMode&FileWrite != 0 {
+		return nil, ExceptionNewf(OSError, "Read-only file system: '%s'", filename)
+	}
+
+	f, err := fsys.Open(FSPath(filename))
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	if finfo, err := f.Stat(); err == nil {
+		if finfo.IsDir() {
+			f.Close()
+			return nil, ExceptionNewf(IsADirectoryError, "Is a directory: '%s'", filename)
+		}
+	}
+
+	return NewFile(f, fileMode), nil
+}
+
+// FSPath converts a python file name into a path for an fs.FS
+//
+// The name is taken to be relative to the root of the fs.FS whether
+// or not it starts with "/" and can't refer to files outside it.
+func FSPath(name string) string {
+	p := strings.TrimPrefix(path.Clean("/"+name), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// fileError converts an error from opening a file into a python exception
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrExist):
+		return ExceptionNewf(FileExistsError, "%w", err)
+
+	case errors.Is(err, fs.ErrNotExist):
+		return ExceptionNewf(FileNotFoundError, "%w", err)
+
+	case errors.Is(err, fs.ErrPermission):
+		return ExceptionNewf(PermissionError, "%w", err)
+	}
+
+	return ExceptionNewf(OSError, "%w", err)
+}
+
+// parseFileMode parses a python file mode string returning the
+// FileMode and the flags for os.OpenFile
+func parseFileMode(mode string) (FileMode, int, error) {
 	var fileMode FileMode
 	var truncate bool
 	var exclusive bool
@@ -175,34 +357,34 @@ func OpenFile(filename, mode string, buffering int) (Object, error) {
 		switch m {
 		case 'r':
 			if fileMode&FileReadWrite != 0 {
-				return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
+				return 0, 0, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
 			}
 			fileMode |= FileRead
 
 		case 'w':
 			if fileMode&FileReadWrite != 0 {
-				return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
+				return 0, 0, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
 			}
 			fileMode |= FileWrite
 			truncate = true
 
 		case 'x':
 			if fileMode&FileReadWrite != 0 {
-				return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
+				return 0, 0, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
 			}
 			fileMode |= FileWrite
 			exclusive = true
 
 		case 'a':
 			if fileMode&FileReadWrite != 0 {
-				return nil, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
+				return 0, 0, ExceptionNewf(ValueError, "must have exactly one of create/read/write/append mode")
 			}
 			fileMode |= FileWrite
 			truncate = false
 
 		case '+':
 			if fileMode&FileReadWrite == 0 {
-				return nil, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
+				return 0, 0, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
 			}
 
 			truncate = (fileMode & FileWrite) != 0
@@ -210,22 +392,22 @@ func OpenFile(filename, mode string, buffering int) (Object, error) {
 
 		case 'b':
 			if fileMode&FileReadWrite == 0 {
-				return nil, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
+				return 0, 0, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
 			}
 
 			if fileMode&FileText != 0 {
-				return nil, ExceptionNewf(ValueError, "can't have text and binary mode at once")
+				return 0, 0, ExceptionNewf(ValueError, "can't have text and binary mode at once")
 			}
 
 			fileMode |= FileBinary
 
 		case 't':
 			if fileMode&FileReadWrite == 0 {
-				return nil, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
+				return 0, 0, ExceptionNewf(ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
 			}
 
 			if fileMode&FileBinary != 0 {
-				return nil, ExceptionNewf(ValueError, "can't have text and binary mode at once")
+				return 0, 0, ExceptionNewf(ValueError, "can't have text and binary mode at once")
 			}
 
 			fileMode |= FileText
@@ -255,27 +437,7 @@ func OpenFile(filename, mode string, buffering int) (Object, error) {
 		fmode |= os.O_APPEND
 	}
 
-	f, err := os.OpenFile(filename, fmode, 0666)
-	if err != nil {
-		switch {
-		case os.IsExist(err):
-			return nil, ExceptionNewf(FileExistsError, err.Error())
-
-		case os.IsNotExist(err):
-			return nil, ExceptionNewf(FileNotFoundError, err.Error())
-		}
-
-		return nil, ExceptionNewf(OSError, err.Error())
-	}
-
-	if finfo, err := f.Stat(); err == nil {
-		if finfo.IsDir() {
-			f.Close()
-			return nil, ExceptionNewf(IsADirectoryError, "Is a directory: '%s'", filename)
-		}
-	}
-
-	return &File{f, fileMode}, nil
+	return fileMode, fmode, nil
 }
 
 // Check interface is satisfied
@@ -58,6 +58,14 @@ func (a Float) M__repr__() (Object, error) {
 	return a.M__str__()
 }
 
+func (a Float) M__format__(spec Object) (Object, error) {
+	s, err := formatSpecString(spec)
+	if err != nil {
+		return nil, err
+	}
+	return formatFloat(float64(a), s, a.M__str__)
+}
+
 // FloatFromString turns a string into a Float
 func FloatFromString(str string) (Object, error) {
 	str = strings.TrimSpace(str)
@@ -394,9 +402,13 @@ func (a Float) M__ge__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a Float) M__hash__() (Object, error) {
+	return Int(hashFloat(float64(a))), nil
+}
+
 // Properties
 func init() {
-	FloatType.Dict["is_integer"] = MustNewMethod("is_integer", func(self Object) (Object, error) {
+	FloatType.Dict.Set("is_integer", MustNewMethod("is_integer", func(self Object) (Object, error) {
 		if a, ok := convertToFloat(self); ok {
 			f, err := FloatAsFloat64(a)
 			if err != nil {
@@ -405,7 +417,7 @@ func init() {
 			return NewBool(math.Floor(f) == f), nil
 		}
 		return cantConvert(self, "float")
-	}, 0, "is_integer() -> Return True if the float instance is finite with integral value, and False otherwise.")
+	}, 0, "is_integer() -> Return True if the float instance is finite with integral value, and False otherwise."))
 }
 
 // Check interface is satisfied
@@ -413,3 +425,5 @@ var _ floatArithmetic = Float(0)
 var _ conversionBetweenTypes = Float(0)
 var _ I__bool__ = Float(0)
 var _ richComparison = Float(0)
+var _ I__hash__ = Float(0)
+var _ I__format__ = Float(0)
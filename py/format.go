@@ -0,0 +1,1025 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Formatting with format(), __format__, str.format and bytes %
+//
+// The format specification mini-language is
+//
+//	[[fill]align][sign][#][0][width][grouping][.precision][type]
+//
+// where align is one of "<>=^", sign one of "+- ", grouping one of
+// ",_" and type one of "bcdeEfFgGnosxX%".
+//
+// The printf style conversions used by bytes % are translated into
+// the same formatSpec.
+
+package py
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format returns obj formatted according to spec as format() does
+//
+// It calls __format__ if defined, otherwise an empty spec formats obj
+// with str().
+func Format(obj Object, spec string) (Object, error) {
+	var res Object
+	var err error
+	if I, ok := obj.(I__format__); ok {
+		res, err = I.M__format__(String(spec))
+	} else if res, ok, err = TypeCall1(obj, "__format__", String(spec)); !ok {
+		if spec != "" {
+			return nil, ExceptionNewf(TypeError, "unsupported format string passed to %s.__format__", obj.Type().Name)
+		}
+		return Str(obj)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := res.(String); !ok {
+		return nil, ExceptionNewf(TypeError, "__format__ must return a str, not %s", res.Type().Name)
+	}
+	return res, nil
+}
+
+// formatSpecString checks the argument of __format__ is a string
+func formatSpecString(spec Object) (string, error) {
+	s, ok := spec.(String)
+	if !ok {
+		return "", ExceptionNewf(TypeError, "__format__() argument must be str, not %s", spec.Type().Name)
+	}
+	return string(s), nil
+}
+
+// A parsed format specification
+type formatSpec struct {
+	fill      rune // fill character or 0 for the default
+	align     byte // one of "<>=^" or 0 for the default
+	sign      byte // one of "+- " or 0 for the default
+	alternate bool // the # flag
+	zero      bool // the 0 flag
+	width     int  // minimum width or -1 if not set
+	grouping  byte // ',' or '_' or 0 for none
+	precision int  // precision or -1 if not set
+	typ       byte // presentation type or 0 for the default
+}
+
+// isAlign returns whether r is an alignment character
+func isAlign(r rune) bool {
+	return r == '<' || r == '>' || r == '=' || r == '^'
+}
+
+// parseDigits parses the decimal number at the start of r returning
+// it and the number of runes used, or -1 if there isn't one
+func parseDigits(r []rune) (int, int, error) {
+	i := 0
+	for i < len(r) && r[i] >= '0' && r[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return -1, 0, nil
+	}
+	n, err := strconv.Atoi(string(r[:i]))
+	if err != nil {
+		return 0, 0, ExceptionNewf(ValueError, "Too many decimal digits in format string")
+	}
+	return n, i, nil
+}
+
+// parseFormatSpec parses a format specification
+func parseFormatSpec(spec string) (*formatSpec, error) {
+	fs := &formatSpec{width: -1, precision: -1}
+	r := []rune(spec)
+	switch {
+	case len(r) >= 2 && isAlign(r[1]):
+		fs.fill, fs.align = r[0], byte(r[1])
+		r = r[2:]
+	case len(r) >= 1 && isAlign(r[0]):
+		fs.align = byte(r[0])
+		r = r[1:]
+	}
+	if len(r) > 0 && (r[0] == '+' || r[0] == '-' || r[0] == ' ') {
+		fs.sign = byte(r[0])
+		r = r[1:]
+	}
+	if len(r) > 0 && r[0] == '#' {
+		fs.alternate = true
+		r = r[1:]
+	}
+	if len(r) > 0 && r[0] == '0' {
+		fs.zero = true
+		r = r[1:]
+	}
+	width, n, err := parseDigits(r)
+	if err != nil {
+		return nil, err
+	}
+	if width > math.MaxInt32 {
+		return nil, ExceptionNewf(ValueError, "Too many decimal digits in format string")
+	}
+	fs.width = width
+	r = r[n:]
+	if len(r) > 0 && (r[0] == ',' || r[0] == '_') {
+		fs.grouping = byte(r[0])
+		r = r[1:]
+	}
+	if len(r) > 0 && r[0] == '.' {
+		precision, n, err := parseDigits(r[1:])
+		if err != nil {
+			return nil, err
+		}
+		if precision < 0 {
+			return nil, ExceptionNewf(ValueError, "Format specifier missing precision")
+		}
+		if precision > math.MaxInt32 {
+			return nil, ExceptionNewf(ValueError, "precision too big")
+		}
+		fs.precision = precision
+		r = r[1+n:]
+	}
+	if len(r) > 0 && r[0] < utf8.RuneSelf {
+		fs.typ = byte(r[0])
+		r = r[1:]
+	}
+	if len(r) > 0 {
+		return nil, ExceptionNewf(ValueError, "Invalid format specifier")
+	}
+	return fs, nil
+}
+
+// unknownFormat returns the error for a presentation type which
+// isn't supported by typeName
+func (fs *formatSpec) unknownFormat(typeName string) error {
+	return ExceptionNewf(ValueError, "Unknown format code '%c' for object of type '%s'", fs.typ, typeName)
+}
+
+// pad pads prefix and body out to the width putting any fill for '='
+// alignment between them
+//
+// The 0 flag makes the default fill '0' whatever the alignment.
+func (fs *formatSpec) pad(prefix, body string, defaultAlign byte) string {
+	align, fill := fs.align, fs.fill
+	if fill == 0 {
+		fill = ' '
+		if fs.zero {
+			fill = '0'
+		}
+	}
+	if align == 0 {
+		align = defaultAlign
+		if fs.zero && align == '>' {
+			align = '='
+		}
+	}
+	n := fs.width - utf8.RuneCountInString(prefix) - utf8.RuneCountInString(body)
+	if n <= 0 {
+		return prefix + body
+	}
+	padding := func(n int) string {
+		return strings.Repeat(string(fill), n)
+	}
+	switch align {
+	case '<':
+		return prefix + body + padding(n)
+	case '=':
+		return prefix + padding(n) + body
+	case '^':
+		return padding(n/2) + prefix + body + padding(n-n/2)
+	}
+	return padding(n) + prefix + body
+}
+
+// zeroGrouped returns whether the digits need padding with zeros
+// before grouping them, which is when they are grouped and padded
+// with '0' after the sign
+func (fs *formatSpec) zeroGrouped() bool {
+	fill, align := fs.fill, fs.align
+	if fill == 0 && fs.zero {
+		fill = '0'
+	}
+	if align == 0 && fs.zero {
+		align = '='
+	}
+	return fs.grouping != 0 && fill == '0' && align == '='
+}
+
+// checkGrouping returns an error if the grouping isn't allowed with
+// the presentation type
+func (fs *formatSpec) checkGrouping(allowUnderscore, allowComma bool) error {
+	if (fs.grouping == '_' && !allowUnderscore) || (fs.grouping == ',' && !allowComma) {
+		if fs.typ == 0 {
+			return ExceptionNewf(ValueError, "Cannot specify '%c' with 's'.", fs.grouping)
+		}
+		return ExceptionNewf(ValueError, "Cannot specify '%c' with '%c'.", fs.grouping, fs.typ)
+	}
+	return nil
+}
+
+// group inserts sep between every n digits counting from the right
+func group(digits string, sep byte, n int) string {
+	if sep == 0 || len(digits) <= n {
+		return digits
+	}
+	var out strings.Builder
+	first := len(digits) % n
+	if first == 0 {
+		first = n
+	}
+	out.WriteString(digits[:first])
+	for i := first; i < len(digits); i += n {
+		out.WriteByte(sep)
+		out.WriteString(digits[i : i+n])
+	}
+	return out.String()
+}
+
+// groupPadded groups the digits, first padding them with zeros so
+// the result fills width runes if zero padding is in effect
+func (fs *formatSpec) groupPadded(digits string, n int, used int) string {
+	grouped := group(digits, fs.grouping, n)
+	if !fs.zeroGrouped() {
+		return grouped
+	}
+	for used+len(grouped) < fs.width {
+		digits = "0" + digits
+		grouped = group(digits, fs.grouping, n)
+	}
+	return grouped
+}
+
+// signString returns the sign to show for a number
+func (fs *formatSpec) signString(negative bool) string {
+	switch {
+	case negative:
+		return "-"
+	case fs.sign == '+':
+		return "+"
+	case fs.sign == ' ':
+		return " "
+	}
+	return ""
+}
+
+// formatInt formats the integer x according to spec
+func formatInt(x *big.Int, spec string, typeName string) (Object, error) {
+	fs, err := parseFormatSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	base := 10
+	groupSize := 3
+	prefix := ""
+	switch fs.typ {
+	case 0, 'd':
+		err = fs.checkGrouping(true, true)
+	case 'n':
+		err = fs.checkGrouping(false, false)
+	case 'b', 'o', 'x', 'X':
+		base = map[byte]int{'b': 2, 'o': 8, 'x': 16, 'X': 16}[fs.typ]
+		groupSize = 4
+		if fs.alternate {
+			prefix = "0" + string(fs.typ)
+		}
+		err = fs.checkGrouping(true, false)
+	case 'c':
+		return formatChar(x, fs)
+	case 'e', 'E', 'f', 'F', 'g', 'G', '%':
+		f, _ := new(big.Float).SetInt(x).Float64()
+		if math.IsInf(f, 0) {
+			return nil, ExceptionNewf(OverflowError, "int too large to convert to float")
+		}
+		return formatFloatSpec(f, fs, nil)
+	default:
+		return nil, fs.unknownFormat(typeName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fs.precision >= 0 {
+		return nil, ExceptionNewf(ValueError, "Precision not allowed in integer format specifier")
+	}
+	digits := new(big.Int).Abs(x).Text(base)
+	if fs.typ == 'X' {
+		digits = strings.ToUpper(digits)
+	}
+	prefix = fs.signString(x.Sign() < 0) + prefix
+	digits = fs.groupPadded(digits, groupSize, len(prefix))
+	return String(fs.pad(prefix, digits, '>')), nil
+}
+
+// formatChar formats x as a character for the 'c' presentation type
+func formatChar(x *big.Int, fs *formatSpec) (Object, error) {
+	if fs.sign != 0 {
+		return nil, ExceptionNewf(ValueError, "Sign not allowed with integer format specifier 'c'")
+	}
+	if fs.alternate {
+		return nil, ExceptionNewf(ValueError, "Alternate form (#) not allowed with integer format specifier 'c'")
+	}
+	if fs.grouping != 0 {
+		return nil, ExceptionNewf(ValueError, "Cannot specify '%c' with 'c'.", fs.grouping)
+	}
+	if !x.IsInt64() || x.Int64() < 0 || x.Int64() > utf8.MaxRune {
+		return nil, ExceptionNewf(OverflowError, "%%c arg not in range(0x110000)")
+	}
+	return String(fs.pad("", string(rune(x.Int64())), '>')), nil
+}
+
+// formatFloat formats the float f according to spec
+//
+// str is used to format f when there is no type or precision so
+// format(f) is str(f).
+func formatFloat(f float64, spec string, str func() (Object, error)) (Object, error) {
+	fs, err := parseFormatSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch fs.typ {
+	case 0, 'e', 'E', 'f', 'F', 'g', 'G', '%':
+	case 'n':
+		err = fs.checkGrouping(false, false)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fs.unknownFormat("float")
+	}
+	return formatFloatSpec(f, fs, str)
+}
+
+// formatFloatSpec formats f according to the parsed fs
+func formatFloatSpec(f float64, fs *formatSpec, str func() (Object, error)) (Object, error) {
+	err := fs.checkGrouping(true, true)
+	if err != nil {
+		return nil, err
+	}
+	negative := math.Signbit(f) && !math.IsNaN(f)
+	f = math.Abs(f)
+	precision := fs.precision
+	if precision < 0 {
+		precision = 6
+	}
+	suffix := ""
+	var body string
+	switch {
+	case math.IsInf(f, 0):
+		body = "inf"
+	case math.IsNaN(f):
+		body = "nan"
+	}
+	switch fs.typ {
+	case 0:
+		if body != "" {
+			break
+		}
+		if fs.precision < 0 && str != nil {
+			res, err := str()
+			if err != nil {
+				return nil, err
+			}
+			body = strings.TrimPrefix(string(res.(String)), "-")
+			break
+		}
+		if fs.precision < 0 {
+			body = strconv.FormatFloat(f, 'g', -1, 64)
+		} else {
+			body = formatGeneral(f, precision, fs.alternate)
+		}
+		if !strings.ContainsAny(body, ".e") {
+			body += ".0"
+		}
+	case 'e', 'E':
+		if body == "" {
+			body = strconv.FormatFloat(f, 'e', precision, 64)
+			if fs.alternate && precision == 0 {
+				body = strings.Replace(body, "e", ".e", 1)
+			}
+		}
+	case 'f', 'F', '%':
+		if fs.typ == '%' {
+			f *= 100
+			suffix = "%"
+		}
+		if body == "" {
+			body = strconv.FormatFloat(f, 'f', precision, 64)
+			if fs.alternate && precision == 0 {
+				body += "."
+			}
+		}
+	case 'g', 'G', 'n':
+		if body == "" {
+			body = formatGeneral(f, precision, fs.alternate)
+		}
+	}
+	if fs.typ == 'E' || fs.typ == 'F' || fs.typ == 'G' {
+		body = strings.ToUpper(body)
+	}
+	sign := fs.signString(negative)
+	if body != "inf" && body != "nan" && body != "INF" && body != "NAN" {
+		i := strings.IndexAny(body, ".eE")
+		if i < 0 {
+			i = len(body)
+		}
+		body = fs.groupPadded(body[:i], 3, len(sign)+len(body)-i+len(suffix)) + body[i:]
+	}
+	return String(fs.pad(sign, body+suffix, '>')), nil
+}
+
+// formatGeneral formats the non negative f with the 'g' presentation
+// type to precision significant digits
+func formatGeneral(f float64, precision int, alternate bool) string {
+	if precision == 0 {
+		precision = 1
+	}
+	body := strconv.FormatFloat(f, 'e', precision-1, 64)
+	exp, _ := strconv.Atoi(body[strings.IndexByte(body, 'e')+1:])
+	if exp >= -4 && exp < precision {
+		body = strconv.FormatFloat(f, 'f', precision-1-exp, 64)
+		if alternate && !strings.Contains(body, ".") {
+			body += "."
+		}
+	}
+	if alternate {
+		return body
+	}
+	// Remove trailing zeros from the fraction
+	mantissa, exponent := body, ""
+	if i := strings.IndexByte(body, 'e'); i >= 0 {
+		mantissa, exponent = body[:i], body[i:]
+	}
+	if strings.Contains(mantissa, ".") {
+		mantissa = strings.TrimRight(strings.TrimRight(mantissa, "0"), ".")
+	}
+	return mantissa + exponent
+}
+
+// formatString formats s according to spec
+func formatString(s string, spec string) (Object, error) {
+	fs, err := parseFormatSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if fs.typ != 0 && fs.typ != 's' {
+		return nil, fs.unknownFormat("str")
+	}
+	switch {
+	case fs.sign != 0:
+		return nil, ExceptionNewf(ValueError, "Sign not allowed in string format specifier")
+	case fs.alternate:
+		return nil, ExceptionNewf(ValueError, "Alternate form (#) not allowed in string format specifier")
+	case fs.align == '=':
+		return nil, ExceptionNewf(ValueError, "'=' alignment not allowed in string format specifier")
+	case fs.grouping != 0:
+		return nil, ExceptionNewf(ValueError, "Cannot specify '%c' with 's'.", fs.grouping)
+	}
+	if fs.precision >= 0 && utf8.RuneCountInString(s) > fs.precision {
+		s = string([]rune(s)[:fs.precision])
+	}
+	return String(fs.pad("", s, '<')), nil
+}
+
+// A formatter holds the state of a str.format call
+type formatter struct {
+	ctx    *Context // Context whose policy attribute lookups obey or nil
+	args   Tuple
+	kwargs StringDict
+	next   int  // next automatically numbered argument
+	auto   bool // set if automatic numbering is in use
+	manual bool // set if manual numbering is in use
+}
+
+// format implements str.format
+func (s String) format(ctx *Context, args Tuple, kwargs StringDict) (Object, error) {
+	f := &formatter{ctx: ctx, args: args, kwargs: kwargs}
+	out, err := f.vformat(string(s), 2)
+	if err != nil {
+		return nil, err
+	}
+	return String(out), nil
+}
+
+// vformat substitutes the replacement fields in s allowing depth
+// more levels of nested fields in format specs
+func (f *formatter) vformat(s string, depth int) (string, error) {
+	if depth <= 0 {
+		return "", ExceptionNewf(ValueError, "Max string recursion exceeded")
+	}
+	var out strings.Builder
+	for {
+		i := strings.IndexAny(s, "{}")
+		if i < 0 {
+			out.WriteString(s)
+			return out.String(), nil
+		}
+		out.WriteString(s[:i])
+		c := s[i]
+		s = s[i+1:]
+		if len(s) > 0 && s[0] == c {
+			// Doubled braces are literals
+			out.WriteByte(c)
+			s = s[1:]
+			continue
+		}
+		if c == '}' {
+			return "", ExceptionNewf(ValueError, "Single '}' encountered in format string")
+		}
+		if len(s) == 0 {
+			return "", ExceptionNewf(ValueError, "Single '{' encountered in format string")
+		}
+		// Find the matching '}' allowing for nested fields
+		nesting := 1
+		j := 0
+		for ; j < len(s); j++ {
+			if s[j] == '{' {
+				nesting++
+			} else if s[j] == '}' {
+				nesting--
+				if nesting == 0 {
+					break
+				}
+			}
+		}
+		if nesting != 0 {
+			return "", ExceptionNewf(ValueError, "expected '}' before end of string")
+		}
+		field, err := f.formatField(s[:j], depth)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(field)
+		s = s[j+1:]
+	}
+}
+
+// formatField formats a replacement field of the form
+// name!conversion:spec
+func (f *formatter) formatField(field string, depth int) (string, error) {
+	// Find the end of the name skipping over any [index]
+	i := 0
+	for i < len(field) && field[i] != '!' && field[i] != ':' {
+		if field[i] == '[' {
+			for i < len(field) && field[i] != ']' {
+				i++
+			}
+			if i == len(field) {
+				break
+			}
+		}
+		i++
+	}
+	name, rest := field[:i], field[i:]
+	var conversion byte
+	if strings.HasPrefix(rest, "!") {
+		if len(rest) < 2 {
+			return "", ExceptionNewf(ValueError, "end of string while looking for conversion specifier")
+		}
+		conversion = rest[1]
+		rest = rest[2:]
+		if rest != "" && rest[0] != ':' {
+			return "", ExceptionNewf(ValueError, "expected ':' after conversion specifier")
+		}
+	}
+	obj, err := f.getField(name)
+	if err != nil {
+		return "", err
+	}
+	spec := strings.TrimPrefix(rest, ":")
+	if strings.Contains(spec, "{") {
+		spec, err = f.vformat(spec, depth-1)
+		if err != nil {
+			return "", err
+		}
+	}
+	switch conversion {
+	case 0:
+	case 'r':
+		obj, err = Repr(obj)
+	case 's':
+		obj, err = Str(obj)
+	case 'a':
+		obj, err = Repr(obj)
+		if err == nil {
+			obj = String(StringEscape(obj.(String), true))
+		}
+	default:
+		return "", ExceptionNewf(ValueError, "Unknown conversion specifier %c", conversion)
+	}
+	if err != nil {
+		return "", err
+	}
+	res, err := Format(obj, spec)
+	if err != nil {
+		return "", err
+	}
+	return string(res.(String)), nil
+}
+
+// isDigits returns whether s is a non empty string of decimal digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// getField looks up a field name like 0, name, 0.attr or name[key]
+func (f *formatter) getField(name string) (Object, error) {
+	i := strings.IndexAny(name, ".[")
+	if i < 0 {
+		i = len(name)
+	}
+	first, rest := name[:i], name[i:]
+	var obj Object
+	switch {
+	case first == "" || isDigits(first):
+		index := f.next
+		if first == "" {
+			if f.manual {
+				return nil, ExceptionNewf(ValueError, "cannot switch from manual field specification to automatic field numbering")
+			}
+			f.auto = true
+			f.next++
+		} else {
+			if f.auto {
+				return nil, ExceptionNewf(ValueError, "cannot switch from automatic field numbering to manual field specification")
+			}
+			f.manual = true
+			var err error
+			index, err = strconv.Atoi(first)
+			if err != nil {
+				return nil, ExceptionNewf(ValueError, "Too many decimal digits in format string")
+			}
+		}
+		if index >= len(f.args) {
+			return nil, ExceptionNewf(IndexError, "tuple index out of range")
+		}
+		obj = f.args[index]
+	default:
+		var ok bool
+		obj, ok = f.kwargs.Lookup(first)
+		if !ok {
+			return nil, exceptionNew(KeyError, Tuple{String(first)})
+		}
+	}
+	for rest != "" {
+		var err error
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			i := strings.IndexAny(rest, ".[")
+			if i < 0 {
+				i = len(rest)
+			}
+			if i == 0 {
+				return nil, ExceptionNewf(ValueError, "Empty attribute in format string")
+			}
+			err = f.ctx.CheckAttr(rest[:i])
+			if err == nil {
+				obj, err = GetAttrString(obj, rest[:i])
+			}
+			rest = rest[i:]
+		case '[':
+			i := strings.IndexByte(rest, ']')
+			if i < 0 {
+				return nil, ExceptionNewf(ValueError, "Missing ']' in format string")
+			}
+			key := rest[1:i]
+			if key == "" {
+				return nil, ExceptionNewf(ValueError, "Empty attribute in format string")
+			}
+			if isDigits(key) {
+				var index int
+				index, err = strconv.Atoi(key)
+				if err == nil {
+					obj, err = GetItem(obj, Int(index))
+				}
+			} else {
+				obj, err = GetItem(obj, String(key))
+			}
+			rest = rest[i+1:]
+		default:
+			return nil, ExceptionNewf(ValueError, "Only '.' or '[' may follow ']' in format field specifier")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// padBytes pads b with spaces out to the width counting bytes rather
+// than characters
+func (fs *formatSpec) padBytes(b Bytes) Bytes {
+	n := fs.width - len(b)
+	if n <= 0 {
+		return append(Bytes{}, b...)
+	}
+	padding := bytes.Repeat(Bytes{' '}, n)
+	if fs.align == '<' {
+		return append(append(Bytes{}, b...), padding...)
+	}
+	return append(padding, b...)
+}
+
+// printfInt converts arg for the integer conversion conv of bytes %
+func printfInt(arg Object, conv byte) (*big.Int, error) {
+	switch x := arg.(type) {
+	case Int:
+		return big.NewInt(int64(x)), nil
+	case Bool:
+		if x {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	case *BigInt:
+		return (*big.Int)(x), nil
+	case Float:
+		if conv == 'd' || conv == 'i' || conv == 'u' {
+			if math.IsInf(float64(x), 0) || math.IsNaN(float64(x)) {
+				return nil, ExceptionNewf(OverflowError, "cannot convert float %v to integer", x)
+			}
+			i, _ := big.NewFloat(float64(x)).Int(nil)
+			return i, nil
+		}
+	}
+	i, err := Index(arg)
+	if err != nil {
+		if conv == 'd' || conv == 'i' || conv == 'u' {
+			return nil, ExceptionNewf(TypeError, "%%%c format: a real number is required, not %s", conv, arg.Type().Name)
+		}
+		return nil, ExceptionNewf(TypeError, "%%%c format: an integer is required, not %s", conv, arg.Type().Name)
+	}
+	return big.NewInt(int64(i)), nil
+}
+
+// printfBytes converts arg for the %s and %b conversions of bytes %
+func printfBytes(arg Object) (Bytes, error) {
+	if b, ok := convertToBytes(arg); ok {
+		return b, nil
+	}
+	var res Object
+	var err error
+	if I, ok := arg.(I__bytes__); ok {
+		res, err = I.M__bytes__()
+	} else if res, ok, err = TypeCall0(arg, "__bytes__"); !ok {
+		return nil, ExceptionNewf(TypeError, "%%b requires a bytes-like object, or an object that implements __bytes__, not '%s'", arg.Type().Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b, ok := res.(Bytes)
+	if !ok {
+		return nil, ExceptionNewf(TypeError, "__bytes__ returned non-bytes (type %s)", res.Type().Name)
+	}
+	return b, nil
+}
+
+// printfConvert formats arg with the conversion conv of bytes %
+func printfConvert(arg Object, conv byte, fs *formatSpec) (Bytes, error) {
+	switch conv {
+	case 's', 'b', 'r', 'a':
+		var b Bytes
+		if conv == 's' || conv == 'b' {
+			var err error
+			b, err = printfBytes(arg)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			repr, err := Repr(arg)
+			if err != nil {
+				return nil, err
+			}
+			b = Bytes(StringEscape(repr.(String), true))
+		}
+		if fs.precision >= 0 && len(b) > fs.precision {
+			b = b[:fs.precision]
+		}
+		return fs.padBytes(b), nil
+	case 'c':
+		if b, ok := convertToBytes(arg); ok && len(b) == 1 {
+			return fs.padBytes(b), nil
+		}
+		c, err := IndexInt(arg)
+		if err != nil {
+			return nil, ExceptionNewf(TypeError, "%%c requires an integer in range(256) or a single byte")
+		}
+		if c < 0 || c >= 256 {
+			return nil, ExceptionNewf(OverflowError, "%%c arg not in range(256)")
+		}
+		return fs.padBytes(Bytes{byte(c)}), nil
+	case 'd', 'i', 'u', 'x', 'X', 'o':
+		x, err := printfInt(arg, conv)
+		if err != nil {
+			return nil, err
+		}
+		base := 10
+		prefix := ""
+		switch conv {
+		case 'x', 'X':
+			base = 16
+			if fs.alternate {
+				prefix = "0" + string(conv)
+			}
+		case 'o':
+			base = 8
+			if fs.alternate {
+				prefix = "0o"
+			}
+		}
+		digits := new(big.Int).Abs(x).Text(base)
+		if conv == 'X' {
+			digits = strings.ToUpper(digits)
+		}
+		if n := fs.precision - len(digits); n > 0 {
+			digits = strings.Repeat("0", n) + digits
+		}
+		return Bytes(fs.pad(fs.signString(x.Sign() < 0)+prefix, digits, '>')), nil
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		f, err := MakeFloat(arg)
+		if err != nil {
+			return nil, err
+		}
+		fs.typ = conv
+		res, err := formatFloatSpec(float64(f.(Float)), fs, nil)
+		if err != nil {
+			return nil, err
+		}
+		return Bytes(res.(String)), nil
+	}
+	return nil, nil
+}
+
+// bytesFormat implements the printf style formatting of bytes % args
+func bytesFormat(format Bytes, args Object) (Bytes, error) {
+	values, ok := args.(Tuple)
+	if !ok {
+		values = Tuple{args}
+	}
+	var mapping Object
+	switch args.(type) {
+	case Tuple, Bytes, String:
+	default:
+		if _, ok := args.(I__getitem__); ok {
+			mapping = args
+		}
+	}
+	next := 0
+	nextArg := func() (Object, error) {
+		if next >= len(values) {
+			return nil, ExceptionNewf(TypeError, "not enough arguments for format string")
+		}
+		next++
+		return values[next-1], nil
+	}
+	incomplete := ExceptionNewf(ValueError, "incomplete format")
+	out := make(Bytes, 0, len(format))
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out = append(out, format[i])
+			continue
+		}
+		i++
+		if i >= len(format) {
+			return nil, incomplete
+		}
+		var arg Object
+		if format[i] == '(' {
+			if mapping == nil {
+				return nil, ExceptionNewf(TypeError, "format requires a mapping")
+			}
+			// Find the matching ')' allowing for nested brackets
+			nesting := 1
+			j := i + 1
+			for ; j < len(format) && nesting > 0; j++ {
+				if format[j] == '(' {
+					nesting++
+				} else if format[j] == ')' {
+					nesting--
+				}
+			}
+			if nesting > 0 {
+				return nil, ExceptionNewf(ValueError, "incomplete format key")
+			}
+			var err error
+			arg, err = GetItem(mapping, append(Bytes{}, format[i+1:j-1]...))
+			if err != nil {
+				return nil, err
+			}
+			i = j
+		}
+		fs := &formatSpec{width: -1, precision: -1}
+	flags:
+		for ; i < len(format); i++ {
+			switch format[i] {
+			case '-':
+				fs.align = '<'
+			case '+':
+				fs.sign = '+'
+			case ' ':
+				if fs.sign == 0 {
+					fs.sign = ' '
+				}
+			case '#':
+				fs.alternate = true
+			case '0':
+				fs.zero = true
+			default:
+				break flags
+			}
+		}
+		if fs.align == '<' {
+			fs.zero = false
+		}
+		// number parses a width or precision which may be '*'
+		number := func(name string) (int, error) {
+			tooBig := ExceptionNewf(ValueError, "%s too big", name)
+			if i < len(format) && format[i] == '*' {
+				i++
+				n, err := nextArg()
+				if err != nil {
+					return 0, err
+				}
+				if _, ok := n.(Int); !ok {
+					return 0, ExceptionNewf(TypeError, "* wants int")
+				}
+				if n.(Int) > math.MaxInt32 || n.(Int) < -math.MaxInt32 {
+					return 0, tooBig
+				}
+				return int(n.(Int)), nil
+			}
+			n := 0
+			for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+				n = n*10 + int(format[i]-'0')
+				if n > math.MaxInt32 {
+					return 0, tooBig
+				}
+			}
+			return n, nil
+		}
+		if i < len(format) && (format[i] == '*' || (format[i] >= '0' && format[i] <= '9')) {
+			width, err := number("width")
+			if err != nil {
+				return nil, err
+			}
+			if width < 0 {
+				fs.align = '<'
+				fs.zero = false
+				width = -width
+			}
+			fs.width = width
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			precision, err := number("prec")
+			if err != nil {
+				return nil, err
+			}
+			if precision < 0 {
+				precision = 0
+			}
+			fs.precision = precision
+		}
+		for i < len(format) && (format[i] == 'h' || format[i] == 'l' || format[i] == 'L') {
+			i++
+		}
+		if i >= len(format) {
+			return nil, incomplete
+		}
+		conv := format[i]
+		if conv == '%' {
+			out = append(out, '%')
+			continue
+		}
+		if !strings.ContainsRune("sbracdiuxXoeEfFgG", rune(conv)) {
+			return nil, ExceptionNewf(ValueError, "unsupported format character '%c' (0x%x) at index %d", conv, conv, i)
+		}
+		if arg == nil {
+			var err error
+			arg, err = nextArg()
+			if err != nil {
+				return nil, err
+			}
+		}
+		b, err := printfConvert(arg, conv, fs)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+	}
+	if next < len(values) && mapping == nil {
+		return nil, ExceptionNewf(TypeError, "not all arguments converted during bytes formatting")
+	}
+	return out, nil
+}
@@ -26,6 +26,7 @@ type TryBlock struct {
 // A python Frame object
 type Frame struct {
 	// Back       *Frame        // previous frame, or nil
+	Context         *Context   // interpreter this frame is running in
 	Code            *Code      // code segment
 	Builtins        StringDict // builtin symbol table
 	Globals         StringDict // global symbol table
@@ -76,8 +77,8 @@ func (o *Frame) Type() *Type {
 	return FrameType
 }
 
-// Make a new frame for a code object
-func NewFrame(globals, locals StringDict, code *Code, closure Tuple) *Frame {
+// Make a new frame for a code object running in ctx
+func NewFrame(ctx *Context, globals, locals StringDict, code *Code, closure Tuple) *Frame {
 	nlocals := int(code.Nlocals)
 	ncells := len(code.Cellvars)
 	nfrees := len(code.Freevars)
@@ -90,12 +91,13 @@ func NewFrame(globals, locals StringDict, code *Code, closure Tuple) *Frame {
 	cellAndFreeVars := allocation[nlocals:varsize]
 
 	return &Frame{
+		Context:         ctx,
 		Globals:         globals,
 		Locals:          locals,
 		Code:            code,
 		LocalVars:       localVars,
 		CellAndFreeVars: cellAndFreeVars,
-		Builtins:        Builtins.Globals,
+		Builtins:        ctx.Builtins.Globals,
 		Localsplus:      allocation,
 		Stack:           make([]Object, 0, code.Stacksize),
 	}
@@ -108,13 +110,12 @@ func NewFrame(globals, locals StringDict, code *Code, closure Tuple) *Frame {
 func (f *Frame) LookupGlobal(name string) (obj Object, ok bool) {
 	// Lookup in globals
 	// fmt.Printf("globals = %v\n", f.Globals)
-	if obj, ok = f.Globals[name]; ok {
+	if obj, ok = f.Globals.Lookup(name); ok {
 		return
 	}
 
 	// Lookup in builtins
-	// fmt.Printf("builtins = %v\n", Builtins.Globals)
-	if obj, ok = f.Builtins[name]; ok {
+	if obj, ok = f.Builtins.Lookup(name); ok {
 		return
 	}
 
@@ -129,7 +130,7 @@ func (f *Frame) LookupGlobal(name string) (obj Object, ok bool) {
 func (f *Frame) Lookup(name string) (obj Object, ok bool) {
 	// Lookup in locals
 	// fmt.Printf("locals = %v\n", f.Locals)
-	if obj, ok = f.Locals[name]; ok {
+	if obj, ok = f.Locals.Lookup(name); ok {
 		return
 	}
 
@@ -180,9 +181,9 @@ func map_to_dict(mapping []string, nmap int, dict StringDict, values []Object, d
 			value = cell.Get()
 		}
 		if value == nil {
-			delete(dict, key)
+			dict.Delete(key)
 		} else {
-			dict[key] = value
+			dict.Set(key, value)
 		}
 	}
 }
@@ -210,7 +211,7 @@ func map_to_dict(mapping []string, nmap int, dict StringDict, values []Object, d
 func dict_to_map(mapping []string, nmap int, dict StringDict, values []Object, deref bool, clear bool) {
 	for j := nmap - 1; j >= 0; j-- {
 		key := mapping[j]
-		value := dict[key]
+		value := dict.Get(key)
 		/* We only care about nils if clear is true. */
 		if value == nil {
 			if !clear {
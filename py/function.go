@@ -17,6 +17,7 @@ package py
 
 // A python Function object
 type Function struct {
+	Context     *Context   // The interpreter the function was defined in
 	Code        *Code      // A code object, the __code__ attribute
 	Globals     StringDict // A dictionary (other mappings won't do)
 	Defaults    Tuple      // NULL or a tuple
@@ -46,7 +47,7 @@ func (f *Function) GetDict() StringDict {
 // Define a new function
 //
 // Return a new function object associated with the code object
-// code. globals must be a dictionary with the global variables
+// code which will run in the interpreter ctx. globals must be a dictionary with the global variables
 // accessible to the function.
 //
 // The function’s docstring, name and __module__ are retrieved from
@@ -56,7 +57,7 @@ func (f *Function) GetDict() StringDict {
 // attribute. qualname should be a unicode object or ""; if "", the
 // __qualname__ attribute is set to the same value as its __name__
 // attribute.
-func NewFunction(code *Code, globals StringDict, qualname string) *Function {
+func NewFunction(ctx *Context, code *Code, globals StringDict, qualname string) *Function {
 	var doc Object
 	var module Object = None
 	if len(code.Consts) >= 1 {
@@ -69,7 +70,7 @@ func NewFunction(code *Code, globals StringDict, qualname string) *Function {
 	}
 
 	// __module__: If module name is in globals, use it. Otherwise, use None.
-	if moduleobj, ok := globals["__name__"]; ok {
+	if moduleobj, ok := globals.Lookup("__name__"); ok {
 		module = moduleobj
 	}
 
@@ -78,19 +79,20 @@ func NewFunction(code *Code, globals StringDict, qualname string) *Function {
 	}
 
 	return &Function{
+		Context:  ctx,
 		Code:     code,
 		Qualname: qualname,
 		Globals:  globals,
 		Name:     code.Name,
 		Doc:      doc,
 		Module:   module,
-		Dict:     make(StringDict),
+		Dict:     NewStringDict(),
 	}
 }
 
 // Call a function
 func (f *Function) M__call__(args Tuple, kwargs StringDict) (Object, error) {
-	result, err := VmEvalCodeEx(f.Code, f.Globals, NewStringDict(), args, kwargs, f.Defaults, f.KwDefaults, f.Closure)
+	result, err := VmEvalCodeEx(f.Context, f.Code, f.Globals, NewStringDict(), args, kwargs, f.Defaults, f.KwDefaults, f.Closure)
 	if err != nil {
 		return nil, err
 	}
@@ -105,9 +107,14 @@ func (f *Function) M__get__(instance, owner Object) (Object, error) {
 	return f, nil
 }
 
+// Hashes the identity of the function
+func (f *Function) M__hash__() (Object, error) {
+	return Int(hashPointer(f)), nil
+}
+
 // Properties
 func init() {
-	FunctionType.Dict["__code__"] = &Property{
+	FunctionType.Dict.Set("__code__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return self.(*Function).Code, nil
 		},
@@ -126,8 +133,8 @@ func init() {
 			f.Code = code
 			return nil
 		},
-	}
-	FunctionType.Dict["__defaults__"] = &Property{
+	})
+	FunctionType.Dict.Set("__defaults__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return self.(*Function).Defaults, nil
 		},
@@ -144,15 +151,15 @@ func init() {
 			self.(*Function).Defaults = nil
 			return nil
 		},
-	}
-	FunctionType.Dict["__kwdefaults__"] = &Property{
+	})
+	FunctionType.Dict.Set("__kwdefaults__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return self.(*Function).KwDefaults, nil
 		},
 		Fset: func(self, value Object) error {
 			f := self.(*Function)
-			kwdefaults, ok := value.(StringDict)
-			if !ok {
+			kwdefaults, err := DictAsStringDict(value)
+			if err != nil {
 				return ExceptionNewf(TypeError, "__kwdefaults__ must be set to a dict object")
 			}
 			f.KwDefaults = kwdefaults
@@ -162,15 +169,15 @@ func init() {
 			self.(*Function).KwDefaults = nil
 			return nil
 		},
-	}
-	FunctionType.Dict["__annotations__"] = &Property{
+	})
+	FunctionType.Dict.Set("__annotations__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return self.(*Function).Annotations, nil
 		},
 		Fset: func(self, value Object) error {
 			f := self.(*Function)
-			annotations, ok := value.(StringDict)
-			if !ok {
+			annotations, err := DictAsStringDict(value)
+			if err != nil {
 				return ExceptionNewf(TypeError, "__annotations__ must be set to a dict object")
 			}
 			f.Annotations = annotations
@@ -180,22 +187,22 @@ func init() {
 			self.(*Function).Annotations = nil
 			return nil
 		},
-	}
-	FunctionType.Dict["__dict__"] = &Property{
+	})
+	FunctionType.Dict.Set("__dict__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return self.(*Function).Dict, nil
 		},
 		Fset: func(self, value Object) error {
 			f := self.(*Function)
-			dict, ok := value.(StringDict)
-			if !ok {
+			dict, err := DictAsStringDict(value)
+			if err != nil {
 				return ExceptionNewf(TypeError, "__dict__ must be set to a dict object")
 			}
 			f.Dict = dict
 			return nil
 		},
-	}
-	FunctionType.Dict["__name__"] = &Property{
+	})
+	FunctionType.Dict.Set("__name__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return String(self.(*Function).Name), nil
 		},
@@ -208,8 +215,8 @@ func init() {
 			f.Name = string(name)
 			return nil
 		},
-	}
-	FunctionType.Dict["__qualname__"] = &Property{
+	})
+	FunctionType.Dict.Set("__qualname__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return String(self.(*Function).Qualname), nil
 		},
@@ -222,7 +229,7 @@ func init() {
 			f.Qualname = string(qualname)
 			return nil
 		},
-	}
+	})
 }
 
 // Make sure it satisfies the interface
@@ -230,3 +237,4 @@ var _ Object = (*Function)(nil)
 var _ I__call__ = (*Function)(nil)
 var _ IGetDict = (*Function)(nil)
 var _ I__get__ = (*Function)(nil)
+var _ I__hash__ = (*Function)(nil)
@@ -25,15 +25,15 @@ var GeneratorType = NewType("generator", "generator object")
 
 func init() {
 	// FIXME would like to do this with introspection
-	GeneratorType.Dict["send"] = MustNewMethod("send", func(self Object, value Object) (Object, error) {
+	GeneratorType.Dict.Set("send", MustNewMethod("send", func(self Object, value Object) (Object, error) {
 		return self.(*Generator).Send(value)
-	}, 0, "send(arg) -> send 'arg' into generator,\nreturn next yielded value or raise StopIteration.")
-	GeneratorType.Dict["throw"] = MustNewMethod("throw", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	}, 0, "send(arg) -> send 'arg' into generator,\nreturn next yielded value or raise StopIteration."))
+	GeneratorType.Dict.Set("throw", MustNewMethod("throw", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
 		return self.(*Generator).Throw(args, kwargs)
-	}, 0, "throw(typ[,val[,tb]]) -> raise exception in generator,\nreturn next yielded value or raise StopIteration.")
-	GeneratorType.Dict["close"] = MustNewMethod("close", func(self Object) (Object, error) {
+	}, 0, "throw(typ[,val[,tb]]) -> raise exception in generator,\nreturn next yielded value or raise StopIteration."))
+	GeneratorType.Dict.Set("close", MustNewMethod("close", func(self Object) (Object, error) {
 		return self.(*Generator).Close()
-	}, 0, "close() -> raise GeneratorExit inside generator.")
+	}, 0, "close() -> raise GeneratorExit inside generator."))
 }
 
 // Type of this object
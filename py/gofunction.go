@@ -0,0 +1,226 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Go functions wrapped as python callables using reflection
+
+package py
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var errorGoType = reflect.TypeOf((*error)(nil)).Elem()
+
+// NewGoFunction makes a python callable called name from the Go
+// function fn using reflection.
+//
+// Positional arguments are converted to the types of the parameters
+// of fn with ToGo.  If fn is variadic then any extra arguments are
+// converted to the type of its last parameter.
+//
+// If argNames are given they name the non variadic parameters of fn
+// so they can be passed as keyword arguments too.
+//
+// The results of fn are converted with FromGo.  No results returns
+// None and more than one returns a tuple.  If the last result is an
+// error then it is raised if it isn't nil.  Python exceptions are
+// raised as is and any other errors are raised as a RuntimeError
+// which wraps them.  A panic in fn is raised the same way.
+//
+// The __doc__ of the result is the Go signature of fn.
+func NewGoFunction(name string, fn interface{}, argNames ...string) (*Method, error) {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func || fnValue.IsNil() {
+		return nil, ExceptionNewf(TypeError, "NewGoFunction %q needs a Go function, got %T", name, fn)
+	}
+	t := fnValue.Type()
+	nfixed := t.NumIn()
+	if t.IsVariadic() {
+		nfixed--
+	}
+	if len(argNames) != 0 && len(argNames) != nfixed {
+		return nil, ExceptionNewf(TypeError, "NewGoFunction %q got %d argument names for %d arguments", name, len(argNames), nfixed)
+	}
+	call := func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return goFunctionCall(name, fnValue, argNames, args, kwargs)
+	}
+	return NewMethod(name, call, 0, goFunctionDoc(name, t, argNames))
+}
+
+// MustNewGoFunction is as NewGoFunction but panics on error
+func MustNewGoFunction(name string, fn interface{}, argNames ...string) *Method {
+	m, err := NewGoFunction(name, fn, argNames...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// goFunctionCall calls the Go function fnValue with the python args
+// and kwargs and converts the results
+func goFunctionCall(name string, fnValue reflect.Value, argNames []string, args Tuple, kwargs StringDict) (Object, error) {
+	t := fnValue.Type()
+	in, err := goFunctionArgs(name, t, argNames, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	out, err := goFunctionInvoke(name, fnValue, in)
+	if err != nil {
+		return nil, err
+	}
+	if t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorGoType {
+		errValue := out[len(out)-1]
+		out = out[:len(out)-1]
+		if !errValue.IsNil() {
+			return nil, goFunctionError(errValue.Interface().(error))
+		}
+	}
+	switch len(out) {
+	case 0:
+		return None, nil
+	case 1:
+		return fromGo(out[0])
+	}
+	results := make(Tuple, len(out))
+	for i := range out {
+		results[i], err = fromGo(out[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// goFunctionArgs converts the python args and kwargs into arguments
+// for calling a Go function of type t
+func goFunctionArgs(name string, t reflect.Type, argNames []string, args Tuple, kwargs StringDict) ([]reflect.Value, error) {
+	nfixed := t.NumIn()
+	if t.IsVariadic() {
+		nfixed--
+	}
+	nargs := len(args)
+	if kwargs.Len() != 0 && len(argNames) == 0 {
+		return nil, ExceptionNewf(TypeError, "%s() takes no keyword arguments", name)
+	}
+	if nargs > nfixed && !t.IsVariadic() {
+		return nil, ExceptionNewf(TypeError, "%s() takes exactly %d arguments (%d given)", name, nfixed, nargs+kwargs.Len())
+	}
+
+	// Bind the arguments to the parameters
+	values := make([]Object, nfixed, nargs+nfixed)
+	copy(values, args)
+	for _, key := range kwargs.StringKeys() {
+		value := kwargs.Get(key)
+		i := 0
+		for i < len(argNames) && argNames[i] != key {
+			i++
+		}
+		if i >= len(argNames) {
+			return nil, ExceptionNewf(TypeError, "%s() got an unexpected keyword argument '%s'", name, key)
+		}
+		if i < nargs {
+			return nil, ExceptionNewf(TypeError, "%s() got multiple values for argument '%s'", name, key)
+		}
+		values[i] = value
+	}
+	for i := 0; i < nfixed; i++ {
+		if values[i] != nil {
+			continue
+		}
+		if len(argNames) != 0 {
+			return nil, ExceptionNewf(TypeError, "%s() missing required argument '%s' (pos %d)", name, argNames[i], i+1)
+		}
+		if t.IsVariadic() {
+			return nil, ExceptionNewf(TypeError, "%s() takes at least %d arguments (%d given)", name, nfixed, nargs)
+		}
+		return nil, ExceptionNewf(TypeError, "%s() takes exactly %d arguments (%d given)", name, nfixed, nargs)
+	}
+	if nargs > nfixed {
+		values = append(values, args[nfixed:]...)
+	}
+
+	// Convert them to Go
+	in := make([]reflect.Value, len(values))
+	for i, value := range values {
+		var argType reflect.Type
+		if i < nfixed {
+			argType = t.In(i)
+		} else {
+			argType = t.In(nfixed).Elem()
+		}
+		arg := reflect.New(argType).Elem()
+		err := toGo(value, arg)
+		if err != nil {
+			if IsException(TypeError, err) {
+				return nil, ExceptionNewf(TypeError, "%s() argument %d must be Go %v, not %s", name, i+1, argType, value.Type().Name)
+			}
+			return nil, err
+		}
+		in[i] = arg
+	}
+	return in, nil
+}
+
+// goFunctionInvoke calls the Go function fnValue with in, recovering
+// a panic in it as a python exception
+func goFunctionInvoke(name string, fnValue reflect.Value, in []reflect.Value) (out []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch x := r.(type) {
+			case error:
+				err = goFunctionError(x)
+			default:
+				err = ExceptionNewf(RuntimeError, "%s() panicked: %v", name, r)
+			}
+		}
+	}()
+	return fnValue.Call(in), nil
+}
+
+// goFunctionError converts an error returned from a Go function into
+// one which can be raised
+//
+// A python exception wrapped in a Go error is raised as is.
+func goFunctionError(err error) error {
+	var info ExceptionInfo
+	if errors.As(err, &info) {
+		return info
+	}
+	var exc *Exception
+	if errors.As(err, &exc) {
+		return exc
+	}
+	return ExceptionNewf(RuntimeError, "%w", err)
+}
+
+// goFunctionDoc makes the doc string for a Go function of type t
+func goFunctionDoc(name string, t reflect.Type, argNames []string) string {
+	params := make([]string, t.NumIn())
+	for i := range params {
+		if i == t.NumIn()-1 && t.IsVariadic() {
+			params[i] = "..." + t.In(i).Elem().String()
+		} else {
+			params[i] = t.In(i).String()
+		}
+		if i < len(argNames) {
+			params[i] = argNames[i] + " " + params[i]
+		}
+	}
+	doc := fmt.Sprintf("%s(%s)", name, strings.Join(params, ", "))
+	switch t.NumOut() {
+	case 0:
+	case 1:
+		doc += " " + t.Out(0).String()
+	default:
+		results := make([]string, t.NumOut())
+		for i := range results {
+			results[i] = t.Out(i).String()
+		}
+		doc += " (" + strings.Join(results, ", ") + ")"
+	}
+	return doc
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewGoFunction(t *testing.T) {
+	add := MustNewGoFunction("add", func(a, b int) int { return a + b }, "a", "b")
+	join := MustNewGoFunction("join", func(sep string, parts ...string) string {
+		result := ""
+		for i, part := range parts {
+			if i > 0 {
+				result += sep
+			}
+			result += part
+		}
+		return result
+	})
+	divmod := MustNewGoFunction("divmod", func(a, b int) (int, int, error) {
+		if b == 0 {
+			return 0, 0, ExceptionNewf(ZeroDivisionError, "division by zero")
+		}
+		return a / b, a % b, nil
+	})
+	fail := MustNewGoFunction("fail", func() error { return errors.New("it broke") })
+	nothing := MustNewGoFunction("nothing", func() {})
+	panics := MustNewGoFunction("panics", func(i int) int {
+		switch i {
+		case 0:
+			panic("oops")
+		case 1:
+			panic(ExceptionNewf(ValueError, "bad value"))
+		}
+		return []int{}[i]
+	})
+
+	for _, test := range []struct {
+		fn     *Method
+		args   Tuple
+		kwargs StringDict
+		want   string
+	}{
+		{add, Tuple{Int(1), Int(2)}, nil, "3"},
+		{add, Tuple{Int(1)}, NewStringDictFromMap(map[string]Object{"b": Int(5)}), "6"},
+		{add, nil, NewStringDictFromMap(map[string]Object{"a": Int(2), "b": Int(5)}), "7"},
+		{join, Tuple{String(",")}, nil, "''"},
+		{join, Tuple{String(","), String("a"), String("b")}, nil, "'a,b'"},
+		{divmod, Tuple{Int(7), Int(2)}, nil, "(3, 1)"},
+		{nothing, nil, nil, "None"},
+	} {
+		got, err := Call(test.fn, test.args, test.kwargs)
+		if err != nil {
+			t.Errorf("%s%v failed: %v", test.fn.Name, test.args, err)
+			continue
+		}
+		gotRepr, err := ReprAsString(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotRepr != test.want {
+			t.Errorf("%s%v want %s got %s", test.fn.Name, test.args, test.want, gotRepr)
+		}
+	}
+
+	for _, test := range []struct {
+		fn      *Method
+		args    Tuple
+		kwargs  StringDict
+		wantExc *Type
+		wantMsg string
+	}{
+		{add, Tuple{Int(1)}, nil, TypeError, "add() missing required argument 'b' (pos 2)"},
+		{add, Tuple{Int(1), Int(2), Int(3)}, nil, TypeError, "add() takes exactly 2 arguments (3 given)"},
+		{add, Tuple{Int(1)}, NewStringDictFromMap(map[string]Object{"a": Int(1)}), TypeError, "add() got multiple values for argument 'a'"},
+		{add, Tuple{Int(1)}, NewStringDictFromMap(map[string]Object{"c": Int(1)}), TypeError, "add() got an unexpected keyword argument 'c'"},
+		{add, Tuple{Int(1), String("x")}, nil, TypeError, "add() argument 2 must be Go int, not str"},
+		{join, nil, nil, TypeError, "join() takes at least 1 arguments (0 given)"},
+		{join, Tuple{String(",")}, NewStringDictFromMap(map[string]Object{"sep": String(",")}), TypeError, "join() takes no keyword arguments"},
+		{join, Tuple{String(","), Int(1)}, nil, TypeError, "join() argument 2 must be Go string, not int"},
+		{divmod, Tuple{Int(1), Int(0)}, nil, ZeroDivisionError, "division by zero"},
+		{fail, nil, nil, RuntimeError, "it broke"},
+		{panics, Tuple{Int(0)}, nil, RuntimeError, "panics() panicked: oops"},
+		{panics, Tuple{Int(1)}, nil, ValueError, "bad value"},
+		{panics, Tuple{Int(2)}, nil, RuntimeError, "runtime error: index out of range [2] with length 0"},
+	} {
+		_, err := Call(test.fn, test.args, test.kwargs)
+		exc, ok := err.(*Exception)
+		if !ok || !IsException(test.wantExc, exc) {
+			t.Errorf("%s%v want %s got %v", test.fn.Name, test.args, test.wantExc.Name, err)
+			continue
+		}
+		if msg := string(exc.Args.(Tuple)[0].(String)); msg != test.wantMsg {
+			t.Errorf("%s%v want %q got %q", test.fn.Name, test.args, test.wantMsg, msg)
+		}
+	}
+
+	for _, test := range []struct {
+		fn   *Method
+		want string
+	}{
+		{add, "add(a int, b int) int"},
+		{join, "join(string, ...string) string"},
+		{divmod, "divmod(int, int) (int, int, error)"},
+		{nothing, "nothing()"},
+	} {
+		name, err := GetAttrString(test.fn, "__name__")
+		if err != nil || name != String(test.fn.Name) {
+			t.Errorf("%s: __name__ = %v, %v", test.fn.Name, name, err)
+		}
+		doc, err := GetAttrString(test.fn, "__doc__")
+		if err != nil || doc != String(test.want) {
+			t.Errorf("%s: want __doc__ %q got %v, %v", test.fn.Name, test.want, doc, err)
+		}
+	}
+
+	if _, err := NewGoFunction("bad", 42); !IsException(TypeError, err) {
+		t.Errorf("want TypeError for non function got %v", err)
+	}
+	if _, err := NewGoFunction("bad", func(a int) {}, "a", "b"); !IsException(TypeError, err) {
+		t.Errorf("want TypeError for wrong number of names got %v", err)
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Go types exposed as python classes using reflection
+
+package py
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// Registry of Go types exposed to python keyed by Go type
+	goTypesMu sync.RWMutex
+	goTypes   = make(map[reflect.Type]*goTypeInfo)
+
+	stringerGoType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// goTypeInfo describes a Go type registered with RegisterGoType
+type goTypeInfo struct {
+	pyType   *Type
+	goType   reflect.Type
+	stringer bool
+}
+
+// A GoObject is a live Go value exposed to python as an instance of a
+// type made by RegisterGoType
+//
+// Changes made to it from python are seen by the Go code and vice
+// versa.
+type GoObject struct {
+	info  *goTypeInfo
+	value reflect.Value // pointer to the Go value
+}
+
+// A GoContainer is a GoObject holding a Go slice, array or map which
+// can be indexed from python
+type GoContainer struct {
+	GoObject
+}
+
+// Type of this GoObject
+func (o *GoObject) Type() *Type {
+	return o.info.pyType
+}
+
+// Interface returns a pointer to the Go value held in the GoObject
+func (o *GoObject) Interface() interface{} {
+	return o.value.Interface()
+}
+
+// RegisterGoType makes a python type called name with docstring doc
+// for the Go type of example, so Go values of that type can be passed
+// to python as live objects.
+//
+// Exported fields of structs become attributes which can be read and
+// set.  Setting a field converts the value with ToGo so it must be of
+// the right type.  Fields are named as described in FromGo.
+//
+// Exported methods, including those with pointer receivers, become
+// python methods which are called as described in NewGoFunction.
+//
+// If the Go type implements fmt.Stringer then that is used for
+// __repr__.
+//
+// Slices, arrays and maps support len(), indexing and iteration.
+//
+// Calling the type from python makes a new zero value, setting any
+// attributes passed as keyword arguments.
+//
+// Once registered FromGo wraps values of the type, or pointers to
+// it, as live objects rather than copying them, and ToGo unwraps
+// them.
+func RegisterGoType(name, doc string, example interface{}) (*Type, error) {
+	goType := reflect.TypeOf(example)
+	if goType == nil {
+		return nil, ExceptionNewf(TypeError, "RegisterGoType %q needs an example value", name)
+	}
+	if goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+	goTypesMu.Lock()
+	defer goTypesMu.Unlock()
+	if _, found := goTypes[goType]; found {
+		return nil, ExceptionNewf(TypeError, "Go type %v registered twice", goType)
+	}
+	info := &goTypeInfo{
+		goType:   goType,
+		stringer: reflect.PtrTo(goType).Implements(stringerGoType),
+	}
+	t := &Type{
+		ObjectType: TypeType,
+		Name:       name,
+		Doc:        doc,
+		Dict:       NewStringDict(),
+		Bases:      Tuple{ObjectType},
+		Flags:      ObjectType.Flags,
+		New: func(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+			if len(args) != 0 {
+				return nil, ExceptionNewf(TypeError, "%s() takes no positional arguments", name)
+			}
+			v := reflect.New(goType)
+			if goType.Kind() == reflect.Map {
+				v.Elem().Set(reflect.MakeMap(goType))
+			}
+			obj := info.wrap(v)
+			for _, key := range kwargs.StringKeys() {
+				value := kwargs.Get(key)
+				_, err := SetAttrString(obj, key, value)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return obj, nil
+		},
+	}
+	info.pyType = t
+	if goType.Kind() == reflect.Struct {
+		for _, field := range structFields(goType) {
+			t.Dict.Set(field.name, info.fieldProperty(field))
+		}
+	}
+	ptrType := reflect.PtrTo(goType)
+	for i := 0; i < ptrType.NumMethod(); i++ {
+		method := ptrType.Method(i)
+		t.Dict.Set(method.Name, info.method(i, method.Name))
+	}
+	err := t.Ready()
+	if err != nil {
+		return nil, err
+	}
+	goTypes[goType] = info
+	return t, nil
+}
+
+// MustRegisterGoType is as RegisterGoType but panics on error
+func MustRegisterGoType(name, doc string, example interface{}) *Type {
+	t, err := RegisterGoType(name, doc, example)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// lookupGoType returns the registration for the Go type t or nil
+func lookupGoType(t reflect.Type) *goTypeInfo {
+	goTypesMu.RLock()
+	defer goTypesMu.RUnlock()
+	return goTypes[t]
+}
+
+// wrapGo returns v as a live python object if its type, or the type
+// it points to, is registered
+func wrapGo(v reflect.Value) (Object, bool) {
+	if v.Kind() == reflect.Ptr {
+		if info := lookupGoType(v.Type().Elem()); info != nil {
+			if v.IsNil() {
+				return None, true
+			}
+			return info.wrap(v), true
+		}
+		return nil, false
+	}
+	if info := lookupGoType(v.Type()); info != nil {
+		if !v.CanAddr() {
+			p := reflect.New(v.Type())
+			p.Elem().Set(v)
+			v = p.Elem()
+		}
+		return info.wrap(v.Addr()), true
+	}
+	return nil, false
+}
+
+// wrap makes a python object for the pointer v
+func (info *goTypeInfo) wrap(v reflect.Value) Object {
+	o := GoObject{info: info, value: v}
+	switch info.goType.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return &GoContainer{GoObject: o}
+	}
+	return &o
+}
+
+// asGoObject returns the GoObject obj holds or nil
+func asGoObject(obj Object) *GoObject {
+	switch o := obj.(type) {
+	case *GoObject:
+		return o
+	case *GoContainer:
+		return &o.GoObject
+	}
+	return nil
+}
+
+// goObject returns self as a GoObject of this type or an error
+func (info *goTypeInfo) goObject(self Object, name string) (*GoObject, error) {
+	if o := asGoObject(self); o != nil && o.info == info {
+		return o, nil
+	}
+	return nil, ExceptionNewf(TypeError, "descriptor '%s' requires a '%s' object but received a '%s'", name, info.pyType.Name, self.Type().Name)
+}
+
+// fieldProperty makes a Property to read and write a struct field
+func (info *goTypeInfo) fieldProperty(field structField) *Property {
+	return &Property{
+		Fget: func(self Object) (Object, error) {
+			o, err := info.goObject(self, field.name)
+			if err != nil {
+				return nil, err
+			}
+			return fromGo(o.value.Elem().Field(field.index))
+		},
+		Fset: func(self, value Object) error {
+			o, err := info.goObject(self, field.name)
+			if err != nil {
+				return err
+			}
+			fieldValue := o.value.Elem().Field(field.index)
+			newValue := reflect.New(fieldValue.Type()).Elem()
+			err = toGo(value, newValue)
+			if err != nil {
+				if IsException(TypeError, err) {
+					return ExceptionNewf(TypeError, "attribute '%s' of '%s' must be Go %v, not %s", field.name, info.pyType.Name, fieldValue.Type(), value.Type().Name)
+				}
+				return err
+			}
+			fieldValue.Set(newValue)
+			return nil
+		},
+	}
+}
+
+// method makes a python method to call the i-th method of the
+// pointer to the Go type
+func (info *goTypeInfo) method(i int, name string) *Method {
+	methodType := reflect.New(info.goType).Method(i).Type()
+	return &Method{
+		Name: name,
+		Doc:  goFunctionDoc(name, methodType, nil),
+		method: func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+			o, err := info.goObject(self, name)
+			if err != nil {
+				return nil, err
+			}
+			return goFunctionCall(name, o.value.Method(i), nil, args, kwargs)
+		},
+	}
+}
+
+func (o *GoObject) M__repr__() (Object, error) {
+	if o.info.stringer {
+		return String(o.value.Interface().(fmt.Stringer).String()), nil
+	}
+	return String(fmt.Sprintf("<%s object at %p>", o.info.pyType.Name, o.value.Interface())), nil
+}
+
+// index converts key into an index of the slice or array v
+func (o *GoContainer) index(key Object) (reflect.Value, error) {
+	v := o.value.Elem()
+	i, err := IndexInt(key)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if i < 0 {
+		i += v.Len()
+	}
+	if i < 0 || i >= v.Len() {
+		return reflect.Value{}, ExceptionNewf(IndexError, "%s index out of range", o.info.pyType.Name)
+	}
+	return v.Index(i), nil
+}
+
+// mapKey converts key into a key of the map v
+func (o *GoContainer) mapKey(key Object) (reflect.Value, error) {
+	k := reflect.New(o.info.goType.Key()).Elem()
+	err := toGo(key, k)
+	if err != nil {
+		if IsException(TypeError, err) {
+			return reflect.Value{}, ExceptionNewf(KeyError, "%s", DebugRepr(key))
+		}
+		return reflect.Value{}, err
+	}
+	return k, nil
+}
+
+func (o *GoContainer) M__len__() (Object, error) {
+	return Int(o.value.Elem().Len()), nil
+}
+
+func (o *GoContainer) M__bool__() (Object, error) {
+	return NewBool(o.value.Elem().Len() != 0), nil
+}
+
+func (o *GoContainer) M__getitem__(key Object) (Object, error) {
+	v := o.value.Elem()
+	if v.Kind() == reflect.Map {
+		k, err := o.mapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		item := v.MapIndex(k)
+		if !item.IsValid() {
+			return nil, ExceptionNewf(KeyError, "%s", DebugRepr(key))
+		}
+		return fromGo(item)
+	}
+	item, err := o.index(key)
+	if err != nil {
+		return nil, err
+	}
+	return fromGo(item)
+}
+
+func (o *GoContainer) M__setitem__(key, value Object) (Object, error) {
+	v := o.value.Elem()
+	newValue := reflect.New(o.info.goType.Elem()).Elem()
+	err := toGo(value, newValue)
+	if err != nil {
+		return nil, err
+	}
+	if v.Kind() == reflect.Map {
+		k, err := o.mapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(o.info.goType))
+		}
+		v.SetMapIndex(k, newValue)
+		return None, nil
+	}
+	item, err := o.index(key)
+	if err != nil {
+		return nil, err
+	}
+	item.Set(newValue)
+	return None, nil
+}
+
+func (o *GoContainer) M__delitem__(key Object) (Object, error) {
+	v := o.value.Elem()
+	if v.Kind() != reflect.Map {
+		return nil, ExceptionNewf(TypeError, "'%s' object does not support item deletion", o.info.pyType.Name)
+	}
+	k, err := o.mapKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !v.MapIndex(k).IsValid() {
+		return nil, ExceptionNewf(KeyError, "%s", DebugRepr(key))
+	}
+	v.SetMapIndex(k, reflect.Value{})
+	return None, nil
+}
+
+// items returns the python values of the items of a slice or array
+// or the keys of a map
+func (o *GoContainer) items() ([]Object, error) {
+	v := o.value.Elem()
+	if v.Kind() != reflect.Map {
+		return fromGoItems(v)
+	}
+	keys := sortedMapKeys(v)
+	items := make([]Object, len(keys))
+	for i, key := range keys {
+		item, err := fromGo(key)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func (o *GoContainer) M__iter__() (Object, error) {
+	items, err := o.items()
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator(items), nil
+}
+
+func (o *GoContainer) M__contains__(item Object) (Object, error) {
+	v := o.value.Elem()
+	if v.Kind() == reflect.Map {
+		k := reflect.New(o.info.goType.Key()).Elem()
+		if toGo(item, k) != nil {
+			return False, nil
+		}
+		return NewBool(v.MapIndex(k).IsValid()), nil
+	}
+	items, err := o.items()
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range items {
+		eq, err := Eq(x, item)
+		if err != nil {
+			return nil, err
+		}
+		if eq == True {
+			return True, nil
+		}
+	}
+	return False, nil
+}
+
+// Check interface is satisfied
+var _ I__repr__ = (*GoObject)(nil)
+var _ I__len__ = (*GoContainer)(nil)
+var _ I__bool__ = (*GoContainer)(nil)
+var _ I__getitem__ = (*GoContainer)(nil)
+var _ I__setitem__ = (*GoContainer)(nil)
+var _ I__delitem__ = (*GoContainer)(nil)
+var _ I__iter__ = (*GoContainer)(nil)
+var _ I__contains__ = (*GoContainer)(nil)
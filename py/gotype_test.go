@@ -0,0 +1,205 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testConfig struct {
+	Name    string
+	Retries int `py:"retries"`
+	Tags    testTags
+	secret  string
+}
+
+func (c *testConfig) String() string {
+	return fmt.Sprintf("Config(%s)", c.Name)
+}
+
+func (c *testConfig) Greet(greeting string) string {
+	return greeting + " " + c.Name
+}
+
+func (c testConfig) Check() error {
+	if c.Retries < 0 {
+		return errors.New("negative retries")
+	}
+	return nil
+}
+
+type testTags []string
+
+type testEnv map[string]string
+
+var (
+	testConfigType = MustRegisterGoType("Config", "Test config", (*testConfig)(nil))
+	testTagsType   = MustRegisterGoType("Tags", "", testTags(nil))
+	testEnvType    = MustRegisterGoType("Env", "", testEnv(nil))
+)
+
+// reprer returns a function which returns the repr of an object
+// or fails the test if there was an error
+func reprer(t *testing.T) func(Object, error) string {
+	return func(obj Object, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("failed: %v", err)
+		}
+		s, err := ReprAsString(obj)
+		if err != nil {
+			t.Fatalf("repr failed: %v", err)
+		}
+		return s
+	}
+}
+
+func TestGoTypeStruct(t *testing.T) {
+	repr := reprer(t)
+	config := &testConfig{Name: "test", Tags: testTags{"a"}}
+	obj, err := FromGo(config)
+	if got := repr(obj, err); got != "Config(test)" {
+		t.Errorf("repr want Config(test) got %s", got)
+	}
+	if obj.Type() != testConfigType {
+		t.Errorf("wrong type %v", obj.Type())
+	}
+
+	// Read and write fields
+	if got := repr(GetAttrString(obj, "retries")); got != "0" {
+		t.Errorf("retries want 0 got %s", got)
+	}
+	if _, err := SetAttrString(obj, "retries", Int(3)); err != nil {
+		t.Fatal(err)
+	}
+	if config.Retries != 3 {
+		t.Errorf("setting retries didn't change Go value: %d", config.Retries)
+	}
+	_, err = SetAttrString(obj, "retries", String("x"))
+	if !IsException(TypeError, err) {
+		t.Errorf("want TypeError got %v", err)
+	}
+	for _, name := range []string{"secret", "Retries"} {
+		if _, err := GetAttrString(obj, name); !IsException(AttributeError, err) {
+			t.Errorf("%s: want AttributeError got %v", name, err)
+		}
+	}
+
+	// Nested registered values are live
+	tags, err := GetAttrString(obj, "Tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetItem(tags, Int(0), String("b")); err != nil {
+		t.Fatal(err)
+	}
+	if config.Tags[0] != "b" {
+		t.Errorf("setting tag didn't change Go value: %v", config.Tags)
+	}
+
+	// Methods
+	greet, err := GetAttrString(obj, "Greet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := repr(Call(greet, Tuple{String("hello")}, nil)); got != "'hello test'" {
+		t.Errorf("Greet want 'hello test' got %s", got)
+	}
+	config.Retries = -1
+	check, err := GetAttrString(obj, "Check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Call(check, nil, nil); !IsException(RuntimeError, err) {
+		t.Errorf("Check want RuntimeError got %v", err)
+	}
+
+	// Round trip back to Go
+	var p *testConfig
+	if err := ToGo(obj, &p); err != nil || p != config {
+		t.Errorf("ToGo pointer failed: %v %p != %p", err, p, config)
+	}
+	var c testConfig
+	if err := ToGo(obj, &c); err != nil || !reflect.DeepEqual(c, *config) {
+		t.Errorf("ToGo value failed: %v %v", err, c)
+	}
+
+	// Make a new one from python
+	obj, err = Call(testConfigType, nil, NewStringDictFromMap(map[string]Object{"Name": String("new"), "retries": Int(2)}))
+	if got := repr(obj, err); got != "Config(new)" {
+		t.Errorf("want Config(new) got %s", got)
+	}
+	if got := obj.(*GoObject).Interface().(*testConfig); got.Retries != 2 {
+		t.Errorf("want Retries 2 got %d", got.Retries)
+	}
+	if _, err := Call(testConfigType, Tuple{Int(1)}, nil); !IsException(TypeError, err) {
+		t.Errorf("want TypeError got %v", err)
+	}
+
+	if _, err := RegisterGoType("Config", "", testConfig{}); !IsException(TypeError, err) {
+		t.Errorf("registering twice want TypeError got %v", err)
+	}
+}
+
+func TestGoTypeContainer(t *testing.T) {
+	repr := reprer(t)
+	tags := testTags{"a", "b"}
+	obj, err := FromGo(&tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := repr(Len(obj)); got != "2" {
+		t.Errorf("len want 2 got %s", got)
+	}
+	if got := repr(GetItem(obj, Int(-1))); got != "'b'" {
+		t.Errorf("[-1] want 'b' got %s", got)
+	}
+	if _, err := GetItem(obj, Int(2)); !IsException(IndexError, err) {
+		t.Errorf("want IndexError got %v", err)
+	}
+	if _, err := SetItem(obj, Int(0), Int(1)); !IsException(TypeError, err) {
+		t.Errorf("want TypeError got %v", err)
+	}
+	list, err := SequenceList(obj)
+	if got := repr(list, err); got != "['a', 'b']" {
+		t.Errorf("list want ['a', 'b'] got %s", got)
+	}
+
+	env := testEnv{"HOME": "/root"}
+	obj, err = FromGo(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Type() != testEnvType {
+		t.Errorf("wrong type %v", obj.Type())
+	}
+	if _, err := SetItem(obj, String("USER"), String("me")); err != nil {
+		t.Fatal(err)
+	}
+	if env["USER"] != "me" {
+		t.Errorf("map not updated: %v", env)
+	}
+	list, err = SequenceList(obj)
+	if got := repr(list, err); got != "['HOME', 'USER']" {
+		t.Errorf("keys want ['HOME', 'USER'] got %s", got)
+	}
+	if _, err := DelItem(obj, String("HOME")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetItem(obj, String("HOME")); !IsException(KeyError, err) {
+		t.Errorf("want KeyError got %v", err)
+	}
+	found, err := SequenceContains(obj, String("USER"))
+	if err != nil || !found {
+		t.Errorf("want USER in env got %v %v", found, err)
+	}
+
+	if testTagsType.Name != "Tags" {
+		t.Errorf("wrong name %q", testTagsType.Name)
+	}
+}
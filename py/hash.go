@@ -0,0 +1,179 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Hashing
+//
+// The numeric hashes follow CPython so that numbers which compare
+// equal, such as 1, 1.0 and True, hash equal too.  They are all
+// reduced modulo the prime hashModulus.
+
+package py
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+)
+
+const (
+	hashBits    = 61
+	hashModulus = (1 << hashBits) - 1
+	hashInf     = 314159
+	hashNaN     = 0
+
+	// Primes used by the xxHash based tuple hash
+	xxPrime1 = 11400714785074694791
+	xxPrime2 = 14029467366897019727
+	xxPrime5 = 2870177450012600261
+)
+
+var bigHashModulus = big.NewInt(hashModulus)
+
+// Hash returns the hash of obj
+//
+// It raises a TypeError if obj is unhashable.
+func Hash(obj Object) (int64, error) {
+	I, ok := obj.(I__hash__)
+	if !ok {
+		return 0, ExceptionNewf(TypeError, "unhashable type: '%s'", obj.Type().Name)
+	}
+	res, err := I.M__hash__()
+	if err != nil {
+		return 0, err
+	}
+	switch h := res.(type) {
+	case Int:
+		return int64(h), nil
+	case *BigInt:
+		// Reduce big hashes as CPython does
+		return hashBigInt((*big.Int)(h)), nil
+	}
+	return 0, ExceptionNewf(TypeError, "__hash__ method should return an integer")
+}
+
+// fixHash makes sure a hash is never -1 which CPython reserves for
+// errors
+func fixHash(h int64) int64 {
+	if h == -1 {
+		return -2
+	}
+	return h
+}
+
+// hashInt64 returns the hash of the integer i
+func hashInt64(i int64) int64 {
+	if i < 0 {
+		return fixHash(-int64(uint64(-i) % hashModulus))
+	}
+	return fixHash(int64(uint64(i) % hashModulus))
+}
+
+// hashBigInt returns the hash of the integer i
+func hashBigInt(i *big.Int) int64 {
+	if i.IsInt64() {
+		return hashInt64(i.Int64())
+	}
+	var r big.Int
+	r.Mod(new(big.Int).Abs(i), bigHashModulus)
+	h := r.Int64()
+	if i.Sign() < 0 {
+		h = -h
+	}
+	return fixHash(h)
+}
+
+// hashFloat returns the hash of the float f
+func hashFloat(f float64) int64 {
+	switch {
+	case math.IsInf(f, 1):
+		return hashInf
+	case math.IsInf(f, -1):
+		return -hashInf
+	case math.IsNaN(f):
+		return hashNaN
+	}
+	m, e := math.Frexp(f)
+	sign := int64(1)
+	if m < 0 {
+		sign = -1
+		m = -m
+	}
+	// Process 28 bits at a time
+	var x uint64
+	for m != 0 {
+		x = ((x << 28) & hashModulus) | x>>(hashBits-28)
+		m *= 1 << 28
+		e -= 28
+		y := uint64(m)
+		m -= float64(y)
+		x += y
+		if x >= hashModulus {
+			x -= hashModulus
+		}
+	}
+	// Multiply by 2**e
+	if e >= 0 {
+		e = e % hashBits
+	} else {
+		e = hashBits - 1 - ((-1 - e) % hashBits)
+	}
+	x = ((x << uint(e)) & hashModulus) | x>>uint(hashBits-e)
+	return fixHash(int64(x) * sign)
+}
+
+// hashPointer returns the identity hash of the pointer p
+//
+// The bottom bits are always 0 because of alignment so they are
+// dropped as CPython does.
+func hashPointer(p interface{}) int64 {
+	return hashInt64(int64(reflect.ValueOf(p).Pointer() >> 4))
+}
+
+// hashComplex returns the hash of the complex c combining the hashes
+// of its parts as CPython does
+func hashComplex(c complex128) int64 {
+	h := uint64(hashFloat(real(c))) + 1000003*uint64(hashFloat(imag(c)))
+	return fixHash(int64(h))
+}
+
+// hashBytes returns the hash of b using FNV-1a
+func hashBytes(b []byte) int64 {
+	h := uint64(14695981039346656037)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= 1099511628211
+	}
+	return fixHash(int64(h))
+}
+
+// hashString returns the hash of the str s, the same as hashBytes of
+// its UTF-8 encoding
+func hashString(s string) int64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return fixHash(int64(h))
+}
+
+// hashTuple returns the hash of a tuple of items using the xxHash
+// based algorithm CPython uses
+func hashTuple(items Tuple) (int64, error) {
+	acc := uint64(xxPrime5)
+	for _, item := range items {
+		lane, err := Hash(item)
+		if err != nil {
+			return 0, err
+		}
+		acc += uint64(lane) * xxPrime2
+		acc = acc<<31 | acc>>33
+		acc *= xxPrime1
+	}
+	acc += uint64(len(items)) ^ (xxPrime5 ^ 3527539)
+	if acc == math.MaxUint64 {
+		return 1546275796, nil
+	}
+	return int64(acc), nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	big61, _ := new(big.Int).SetString("2305843009213693951", 10)
+	bigNeg, _ := new(big.Int).SetString("-1180591620717411303424", 10)
+	for _, test := range []struct {
+		in   Object
+		want int64
+	}{
+		// Values from CPython 3
+		{Int(0), 0},
+		{Int(1), 1},
+		{Int(-1), -2},
+		{Int(-2), -2},
+		{True, 1},
+		{False, 0},
+		{(*BigInt)(big61), 0},
+		{(*BigInt)(bigNeg), -512},
+		{Float(1), 1},
+		{Float(-1), -2},
+		{Float(1.5), 1152921504606846977},
+		{Float(0.1), 230584300921369408},
+		{Float(-1180591620717411303424), -512},
+		{Float(math.Inf(1)), 314159},
+		{Float(math.Inf(-1)), -314159},
+		{Complex(1), 1},
+		{Complex(1 + 2i), 2000007},
+		{Complex(complex(0, -1)), -2000006},
+		{Tuple{}, 5740354900026072187},
+		{Tuple{Int(1), Int(2)}, -3550055125485641917},
+	} {
+		got, err := Hash(test.in)
+		if err != nil {
+			t.Errorf("Hash(%v) failed: %v", test.in, err)
+		} else if got != test.want {
+			t.Errorf("Hash(%v) want %d got %d", test.in, test.want, got)
+		}
+	}
+
+	for _, in := range []Object{NewList(), NewStringDict(), NewDict(), NewSet(), Tuple{NewList()}} {
+		if _, err := Hash(in); !IsException(TypeError, err) {
+			t.Errorf("Hash(%v) want TypeError got %v", in, err)
+		}
+	}
+}
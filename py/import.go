@@ -7,6 +7,7 @@
 package py
 
 import (
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path"
@@ -15,10 +16,95 @@ import (
 )
 
 var (
-	// This will become sys.path one day ;-)
-	modulePath = []string{"", "/usr/lib/python3.4", "/usr/local/lib/python3.4/dist-packages", "/usr/lib/python3/dist-packages"}
+	// Directories searched for modules after PYTHONPATH
+	defaultModulePath = []string{"/usr/lib/python3.4", "/usr/local/lib/python3.4/dist-packages", "/usr/lib/python3/dist-packages"}
 )
 
+// DefaultPath returns the initial value of sys.path
+//
+// The first entry is the directory of the script in ContextOpts.Argv
+// or "" which means the directory of the importing module.  This is
+// followed by the directories in PYTHONPATH and the standard ones,
+// unless ContextOpts.FS is set when only the root of the FS is used.
+func (ctx *Context) DefaultPath() []string {
+	first := ""
+	if argv := ctx.Opts.Argv; len(argv) > 0 && argv[0] != "" && argv[0] != "-c" {
+		first = path.Dir(argv[0])
+		if ctx.Opts.FS == nil {
+			if dir, err := filepath.Abs(filepath.Dir(argv[0])); err == nil {
+				first = dir
+			}
+		}
+	}
+	dirs := []string{first}
+	if ctx.Opts.FS != nil {
+		return append(dirs, ".")
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PYTHONPATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, defaultModulePath...)
+}
+
+// sysAttr returns the attribute called name of the sys module
+func (ctx *Context) sysAttr(name string) (Object, error) {
+	sys, ok := ctx.modules.Get("sys").(*Module)
+	if !ok {
+		return nil, ExceptionNewf(ImportError, "sys module not loaded")
+	}
+	value, ok := sys.Globals.Lookup(name)
+	if !ok {
+		return nil, ExceptionNewf(ImportError, "sys.%s not set", name)
+	}
+	return value, nil
+}
+
+// modulePath returns the directories to search for modules from
+// sys.path ignoring any entries which aren't strings
+func (ctx *Context) modulePath() ([]string, error) {
+	pathObj, err := ctx.sysAttr("path")
+	if err != nil {
+		return nil, err
+	}
+	return stringItems(pathObj)
+}
+
+// stringItems returns the strings in the iterable obj ignoring any
+// other items
+func stringItems(obj Object) ([]string, error) {
+	var items []string
+	err := Iterate(obj, func(item Object) bool {
+		if s, ok := item.(String); ok {
+			items = append(items, string(s))
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// statFile returns information about the file name from
+// ContextOpts.FS if set, otherwise from the OS filesystem
+func (ctx *Context) statFile(name string) (fs.FileInfo, error) {
+	if ctx.Opts.FS != nil {
+		return fs.Stat(ctx.Opts.FS, FSPath(name))
+	}
+	return os.Stat(name)
+}
+
+// readFile reads the file name from ContextOpts.FS if set, otherwise
+// from the OS filesystem
+func (ctx *Context) readFile(name string) ([]byte, error) {
+	if ctx.Opts.FS != nil {
+		return fs.ReadFile(ctx.Opts.FS, FSPath(name))
+	}
+	return ioutil.ReadFile(name)
+}
+
 // The workings of __import__
 //
 // __import__(name, globals=None, locals=None, fromlist=(), level=0)
@@ -78,84 +164,376 @@ var (
 //
 // Changed in version 3.3: Negative values for level are no longer
 // supported (which also changes the default value to 0).
-func ImportModuleLevelObject(name string, globals, locals StringDict, fromlist Tuple, level int) (Object, error) {
-	// Module already loaded - return that
-	if module, ok := modules[name]; ok {
+func ImportModuleLevelObject(ctx *Context, name string, globals, locals StringDict, fromlist Tuple, level int) (Object, error) {
+	absName, err := resolveName(name, globals, level)
+	if err != nil {
+		return nil, err
+	}
+	module, err := ctx.importModule(absName, globals)
+	if err != nil {
+		return nil, err
+	}
+	if len(fromlist) != 0 {
+		err = ctx.handleFromlist(module, fromlist, globals, false)
+		if err != nil {
+			return nil, err
+		}
+		return module, nil
+	}
+	if name == "" {
+		return module, nil
+	}
+	// Return the top level package of name which is what gets bound
+	// by "import a.b.c"
+	dot := strings.IndexByte(name, '.')
+	if dot < 0 {
 		return module, nil
 	}
+	topName := absName[:len(absName)-len(name)+dot]
+	top, ok := ctx.modules.Lookup(topName)
+	if !ok {
+		return nil, ExceptionNewf(KeyError, "%q not in sys.modules as expected", topName)
+	}
+	return top, nil
+}
+
+// resolveName returns the absolute name of the module name imported
+// level packages up from the module with globals as in PEP 328
+func resolveName(name string, globals StringDict, level int) (string, error) {
+	if level < 0 {
+		return "", ExceptionNewf(ValueError, "level must be >= 0")
+	}
+	if level == 0 {
+		if name == "" {
+			return "", ExceptionNewf(ValueError, "Empty module name")
+		}
+		return name, nil
+	}
+	var pkg string
+	if pkgObj, ok := globals.Lookup("__package__"); ok && pkgObj != None {
+		s, ok := pkgObj.(String)
+		if !ok {
+			return "", ExceptionNewf(TypeError, "package must be a string")
+		}
+		pkg = string(s)
+	} else if nameObj, ok := globals.Lookup("__name__"); ok {
+		s, ok := nameObj.(String)
+		if !ok {
+			return "", ExceptionNewf(TypeError, "__name__ must be a string")
+		}
+		pkg = string(s)
+		// A module rather than a package is relative to its parent
+		if _, ok := globals.Lookup("__path__"); !ok {
+			pkg = parentName(pkg)
+		}
+	}
+	if pkg == "" {
+		return "", ExceptionNewf(ImportError, "attempted relative import with no known parent package")
+	}
+	base := pkg
+	for i := 1; i < level; i++ {
+		dot := strings.LastIndexByte(base, '.')
+		if dot < 0 {
+			return "", ExceptionNewf(ImportError, "attempted relative import beyond top-level package")
+		}
+		base = base[:dot]
+	}
+	if name == "" {
+		return base, nil
+	}
+	return base + "." + name, nil
+}
 
-	if level != 0 {
-		return nil, ExceptionNewf(SystemError, "Relative import not supported yet")
+// parentName returns the name of the package containing the module
+// called name or "" for a top level module
+func parentName(name string) string {
+	dot := strings.LastIndexByte(name, '.')
+	if dot < 0 {
+		return ""
 	}
+	return name[:dot]
+}
 
-	parts := strings.Split(name, ".")
-	pathParts := path.Join(parts...)
+// moduleNotFound makes the ImportError raised when the module called
+// name can't be found
+func moduleNotFound(name string) error {
+	err := ExceptionNewf(ModuleNotFoundError, "No module named '%s'", name)
+	err.Dict.Set("name", String(name))
+	return err
+}
 
-	for _, mpath := range modulePath {
-		if mpath == "" {
-			mpathObj, ok := globals["__file__"]
-			if !ok {
-				var err error
-				mpath, err = os.Getwd()
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				mpath = path.Dir(string(mpathObj.(String)))
-			}
-		}
-		fullPath := path.Join(mpath, pathParts)
-		// FIXME Read pyc/pyo too
-		fullPath, err := filepath.Abs(fullPath)
+// isModuleNotFound returns true if err says the module called name
+// couldn't be found
+func isModuleNotFound(err error, name string) bool {
+	exc, ok := err.(*Exception)
+	return ok && IsException(ModuleNotFoundError, exc) && exc.Dict.Get("name") == String(name)
+}
+
+// importModule returns the module with the absolute name, importing
+// each of its parent packages in turn first if necessary
+//
+// globals are those of the importing module which are used to find
+// top level modules relative to it.
+//
+// The module may be any object put in sys.modules.
+func (ctx *Context) importModule(name string, globals StringDict) (Object, error) {
+	if err := ctx.CheckImport(name); err != nil {
+		return nil, err
+	}
+	// Module already loaded or built in - return that
+	if m, ok, err := ctx.loadedModule(name); ok || err != nil {
+		return m, err
+	}
+	if impl, ok := moduleImpls[name]; ok {
+		return ctx.newModuleFromImpl(impl)
+	}
+
+	var parent Object
+	var searchPath Object = None
+	parentName := parentName(name)
+	if parentName != "" {
+		var err error
+		parent, err = ctx.importModule(parentName, globals)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		if fi, err := os.Stat(fullPath); err == nil && fi.IsDir() {
-			// FIXME this is a massive simplification!
-			fullPath = path.Join(fullPath, "__init__.py")
-		} else {
-			fullPath += ".py"
+		// Importing the parent may have imported this module
+		if m, ok, err := ctx.loadedModule(name); ok || err != nil {
+			return m, err
 		}
-		// Check if file exists
-		if _, err := os.Stat(fullPath); err == nil {
-			str, err := ioutil.ReadFile(fullPath)
-			if err != nil {
-				return nil, ExceptionNewf(OSError, "Couldn't read %q: %v", fullPath, err)
-			}
-			codeObj, err := Compile(string(str), fullPath, "exec", 0, true)
-			if err != nil {
-				return nil, err
-			}
-			code, ok := codeObj.(*Code)
-			if !ok {
-				return nil, ExceptionNewf(ImportError, "Compile didn't return code object")
-			}
-			module := NewModule(name, "", nil, nil)
-			_, err = VmRun(module.Globals, module.Globals, code, nil)
-			if err != nil {
-				return nil, err
+		searchPath, err = GetAttrString(parent, "__path__")
+		if err != nil {
+			if IsException(AttributeError, err) {
+				err = ExceptionNewf(ModuleNotFoundError, "No module named '%s'; '%s' is not a package", name, parentName)
 			}
-			module.Globals["__file__"] = String(fullPath)
-			return module, nil
+			return nil, err
 		}
 	}
-	return nil, ExceptionNewf(ImportError, "No module named '%s'", name)
-
-	// Convert to absolute path if relative
-	// Use __file__ from globals to work out what we are relative to
 
-	// '' in path seems to mean use the current __file__
+	spec, err := ctx.findSpec(name, searchPath, globals)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.moduleFromSpec(name, spec)
+	if err != nil {
+		return nil, err
+	}
+	// The module may have replaced itself in sys.modules
+	m, ok := ctx.modules.Lookup(name)
+	if !ok {
+		return nil, ExceptionNewf(KeyError, "%q not in sys.modules as expected", name)
+	}
+	if parent != nil {
+		_, err = SetAttrString(parent, name[len(parentName)+1:], m)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
 
-	// Find a valid path which we need to check for the correct __init__.py in subdirectories etc
+// findSpec asks each finder in sys.meta_path in turn for the spec of
+// the module called name in the package with searchPath, which is
+// None for a top level module
+func (ctx *Context) findSpec(name string, searchPath Object, globals StringDict) (Object, error) {
+	metaPath, err := ctx.sysAttr("meta_path")
+	if err != nil {
+		return nil, err
+	}
+	// The path finder finds top level modules relative to globals
+	oldGlobals := ctx.importGlobals
+	ctx.importGlobals = globals
+	defer func() { ctx.importGlobals = oldGlobals }()
+	var spec Object = None
+	var findErr error
+	err = Iterate(metaPath, func(metaFinder Object) bool {
+		if f, ok := metaFinder.(*finder); ok {
+			spec, findErr = f.findSpec(name, searchPath)
+		} else {
+			spec, findErr = callFindSpec(metaFinder, Tuple{String(name), searchPath, None})
+		}
+		return findErr != nil || spec != None
+	})
+	if err == nil {
+		err = findErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if spec == None {
+		return nil, moduleNotFound(name)
+	}
+	return spec, nil
+}
 
-	// Look for .py and .pyc files
+// moduleFromSpec makes the module called name with the loader of
+// spec and runs it
+//
+// The module is registered before it runs so circular imports work,
+// and removed again if it fails.  A spec without a loader makes a
+// namespace package.
+func (ctx *Context) moduleFromSpec(name string, spec Object) error {
+	loader := specAttr(spec, "loader")
+	var module Object = None
+	if loader != None {
+		createModule, err := GetAttrString(loader, "create_module")
+		if err == nil {
+			module, err = Call(createModule, Tuple{spec}, nil)
+		} else if IsException(AttributeError, err) {
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+	} else if specAttr(spec, "submodule_search_locations") == None {
+		return ExceptionNewf(ImportError, "missing loader")
+	}
+	if module == None {
+		m := ctx.NewModule(name, "", nil, nil)
+		m.Globals.Delete("__doc__")
+		module = m
+	}
+	err := initModuleAttrs(module, name, loader, spec)
+	if err != nil {
+		ctx.DeleteModule(name)
+		return err
+	}
+	ctx.modules.Set(name, module)
+	if loader == None {
+		return nil
+	}
+	execModule, err := GetAttrString(loader, "exec_module")
+	if err == nil {
+		_, err = Call(execModule, Tuple{module}, nil)
+	}
+	if err != nil {
+		ctx.DeleteModule(name)
+		return err
+	}
+	return nil
+}
 
-	// Make absolute module path too if we can for sys.modules
+// initModuleAttrs sets the import related attributes of module from
+// spec
+func initModuleAttrs(module Object, name string, loader, spec Object) error {
+	attrs := NewStringDictFromMap(map[string]Object{
+		"__name__":    String(name),
+		"__loader__":  loader,
+		"__package__": specAttr(spec, "parent"),
+		"__spec__":    spec,
+	})
+	if locations := specAttr(spec, "submodule_search_locations"); locations != None {
+		attrs.Set("__path__", locations)
+	}
+	if ObjectIsTrue(specAttr(spec, "has_location")) {
+		attrs.Set("__file__", specAttr(spec, "origin"))
+		if cached := specAttr(spec, "cached"); cached != None {
+			attrs.Set("__cached__", cached)
+		}
+	}
+	for _, attr := range attrs.StringKeys() {
+		value := attrs.Get(attr)
+		_, err := SetAttrString(module, attr, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	//How do we uniquely identify modules?
+// loadedModule returns the module called name from sys.modules and
+// whether it was found
+//
+// It returns an ImportError if the entry is None which stops the
+// module being imported.
+func (ctx *Context) loadedModule(name string) (Object, bool, error) {
+	m, ok := ctx.modules.Lookup(name)
+	if ok && m == None {
+		return nil, false, ExceptionNewf(ImportError, "import of %s halted; None in sys.modules", name)
+	}
+	return m, ok, nil
+}
 
-	// SystemError: Parent module '' not loaded, cannot perform relative import
+// topLevelPath returns the directories to search for top level
+// modules.  The "" entry is the directory of the importing module.
+func (ctx *Context) topLevelPath(globals StringDict) ([]string, error) {
+	mpaths, err := ctx.modulePath()
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, mpath := range mpaths {
+		if mpath == "" {
+			mpathObj, ok := globals.Lookup("__file__")
+			if ok {
+				mpath = path.Dir(string(mpathObj.(String)))
+			} else if ctx.Opts.FS != nil {
+				mpath = "."
+			} else {
+				mpath, err = os.Getwd()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		dirs = append(dirs, mpath)
+	}
+	return dirs, nil
+}
 
+// handleFromlist imports the submodules named in fromlist which
+// aren't already attributes of the package module
+//
+// A "*" imports the names in the package's __all__.  Submodules which
+// don't exist are ignored as the names are looked up on the module
+// afterwards.
+func (ctx *Context) handleFromlist(module Object, fromlist Tuple, globals StringDict, recursive bool) error {
+	if _, err := GetAttrString(module, "__path__"); err != nil {
+		return nil
+	}
+	nameObj, err := GetAttrString(module, "__name__")
+	if err != nil {
+		return err
+	}
+	name, ok := nameObj.(String)
+	if !ok {
+		return ExceptionNewf(TypeError, "__name__ must be a string")
+	}
+	for _, item := range fromlist {
+		x, ok := item.(String)
+		if !ok {
+			where := "``from list''"
+			if recursive {
+				where = "``" + string(name) + ".__all__''"
+			}
+			return ExceptionNewf(TypeError, "Item in %s must be str, not %s", where, item.Type().Name)
+		}
+		if x == "*" {
+			if recursive {
+				continue
+			}
+			if all, err := GetAttrString(module, "__all__"); err == nil {
+				names, err := SequenceTuple(all)
+				if err != nil {
+					return err
+				}
+				err = ctx.handleFromlist(module, names, globals, true)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if _, err := GetAttrString(module, string(x)); err == nil {
+			continue
+		}
+		subName := string(name) + "." + string(x)
+		_, err := ctx.importModule(subName, globals)
+		if err != nil && !isModuleNotFound(err, subName) {
+			return err
+		}
+	}
+	return nil
 }
 
 // Straight port of the python code
@@ -163,7 +541,7 @@ func ImportModuleLevelObject(name string, globals, locals StringDict, fromlist T
 // This calls functins from _bootstrap.py which is a frozen module
 //
 // Too much functionality for the moment
-func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Object, level int) (Object, error) {
+func XImportModuleLevelObject(ctx *Context, nameObj, given_globals, locals, given_fromlist Object, level int) (Object, error) {
 	var abs_name string
 	var builtins_import Object
 	var final_mod Object
@@ -180,7 +558,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 	// PyObject_CallMethodObjArgs() truncate the parameter list because of a
 	// nil argument.
 	if given_globals == nil {
-		globals = StringDict{}
+		globals = NewStringDict()
 	} else {
 		// Only have to care what given_globals is if it will be used
 		// for something.
@@ -214,14 +592,14 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 	if level < 0 {
 		return nil, ExceptionNewf(ValueError, "level must be >= 0")
 	} else if level > 0 {
-		PackageObj, ok = globals["__package__"]
+		PackageObj, ok = globals.Lookup("__package__")
 		if ok && PackageObj != None {
 			if _, ok = PackageObj.(String); !ok {
 				return nil, ExceptionNewf(TypeError, "package must be a string")
 			}
 			Package = string(PackageObj.(String))
 		} else {
-			PackageObj, ok = globals["__name__"]
+			PackageObj, ok = globals.Lookup("__name__")
 			if !ok {
 				return nil, ExceptionNewf(KeyError, "'__name__' not in globals")
 			} else if _, ok = PackageObj.(String); !ok {
@@ -229,7 +607,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 			}
 			Package = string(PackageObj.(String))
 
-			if _, ok = globals["__path__"]; !ok {
+			if _, ok = globals.Lookup("__path__"); !ok {
 				i := strings.LastIndex(string(Package), ".")
 				if i < 0 {
 					Package = ""
@@ -239,7 +617,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 			}
 		}
 
-		if _, ok = modules[string(Package)]; !ok {
+		if _, ok = ctx.modules.Lookup(string(Package)); !ok {
 			return nil, ExceptionNewf(SystemError, "Parent module %q not loaded, cannot perform relative import", Package)
 		}
 	} else { // level == 0 */
@@ -275,15 +653,19 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 	// FIXME _PyImport_AcquireLock()
 
 	// From this point forward, goto error_with_unlock!
-	builtins_import, ok = globals["__import__"]
+	builtins_import, ok = globals.Lookup("__import__")
 	if !ok {
-		builtins_import, ok = Builtins.Globals["__import__"]
+		builtins_import, ok = ctx.Builtins.Globals.Lookup("__import__")
 		if !ok {
 			return nil, ExceptionNewf(ImportError, "__import__ not found")
 		}
 	}
 
-	mod, ok = modules[abs_name]
+	mod, ok = ctx.modules.Lookup(abs_name)
+	importlib, err := ctx.GetModule("importlib")
+	if err != nil {
+		return nil, err
+	}
 	if mod == None {
 		return nil, ExceptionNewf(ImportError, "import of %q halted; None in sys.modules", abs_name)
 	} else if ok {
@@ -306,7 +688,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 		}
 		if initializing {
 			// _bootstrap._lock_unlock_module() releases the import lock */
-			value, err = Importlib.Call("_lock_unlock_module", Tuple{String(abs_name)}, nil)
+			value, err = importlib.Call("_lock_unlock_module", Tuple{String(abs_name)}, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -318,7 +700,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 		}
 	} else {
 		// _bootstrap._find_and_load() releases the import lock
-		mod, err = Importlib.Call("_find_and_load", Tuple{String(abs_name), builtins_import}, nil)
+		mod, err = importlib.Call("_find_and_load", Tuple{String(abs_name), builtins_import}, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -345,7 +727,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 				cut_off := len(name) - len(front)
 				abs_name_len := len(abs_name)
 				to_return := abs_name[:abs_name_len-cut_off]
-				final_mod, ok = modules[to_return]
+				final_mod, ok = ctx.modules.Lookup(to_return)
 				if !ok {
 					return nil, ExceptionNewf(KeyError, "%q not in sys.modules as expected", to_return)
 				}
@@ -354,7 +736,7 @@ func XImportModuleLevelObject(nameObj, given_globals, locals, given_fromlist Obj
 			final_mod = mod
 		}
 	} else {
-		final_mod, err = Importlib.Call("_handle_fromlist", Tuple{mod, fromlist, builtins_import}, nil)
+		final_mod, err = importlib.Call("_handle_fromlist", Tuple{mod, fromlist, builtins_import}, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -376,7 +758,7 @@ error:
 }
 
 // The actual import code
-func BuiltinImport(self Object, args Tuple, kwargs StringDict, currentGlobal StringDict) (Object, error) {
+func BuiltinImport(ctx *Context, self Object, args Tuple, kwargs StringDict, currentGlobal StringDict) (Object, error) {
 	kwlist := []string{"name", "globals", "locals", "fromlist", "level"}
 	var name Object
 	var globals Object = currentGlobal
@@ -388,8 +770,13 @@ func BuiltinImport(self Object, args Tuple, kwargs StringDict, currentGlobal Str
 	if err != nil {
 		return nil, err
 	}
-	if fromlist == None {
-		fromlist = Tuple{}
+	globalsDict, _ := globals.(StringDict)
+	var fromlistTuple Tuple
+	if fromlist != None {
+		fromlistTuple, err = SequenceTuple(fromlist)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return ImportModuleLevelObject(string(name.(String)), globals.(StringDict), locals.(StringDict), fromlist.(Tuple), int(level.(Int)))
+	return ImportModuleLevelObject(ctx, string(name.(String)), globalsDict, nil, fromlistTuple, int(level.(Int)))
 }
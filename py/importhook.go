@@ -0,0 +1,480 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Import hooks
+//
+// The importer asks each finder in sys.meta_path for a ModuleSpec
+// saying how to load a module, then uses the spec's loader to make
+// and run it, as in PEP 302 and PEP 451.
+//
+// The default sys.meta_path holds the finders registered from Go
+// with RegisterFinder followed by the path finder, which searches
+// sys.path or the __path__ of the parent package using the path
+// entry finders made by the hooks in sys.path_hooks and cached in
+// sys.path_importer_cache.
+
+package py
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// A Finder lets a Go program supply modules to the importer without
+// them being on the filesystem
+type Finder interface {
+	// FindModule returns the module with the absolute dotted name
+	// or nil if this Finder doesn't supply it
+	FindModule(ctx *Context, name string) (*FoundModule, error)
+}
+
+// FinderFunc adapts an ordinary function to be a Finder
+type FinderFunc func(ctx *Context, name string) (*FoundModule, error)
+
+// FindModule calls f(ctx, name)
+func (f FinderFunc) FindModule(ctx *Context, name string) (*FoundModule, error) {
+	return f(ctx, name)
+}
+
+// FoundModule is a module supplied by a Finder
+//
+// The module is the first of Module, Code or Source which is set,
+// otherwise the python file Filename is read from the Context's
+// filesystem and its code cached in __pycache__.
+type FoundModule struct {
+	// Module is a module built in Go which is used as it is
+	Module *Module
+	// Code is run to make the module
+	Code *Code
+	// Source is compiled and run to make the module
+	Source string
+	// Filename is the module's __file__ if set, and the file name
+	// used when compiling Source
+	Filename string
+	// Package is set if the module is a package whose submodules
+	// may be imported
+	Package bool
+}
+
+var (
+	// Finders registered with RegisterFinder
+	finders []Finder
+)
+
+// RegisterFinder adds f to the sys.meta_path of every Context made
+// afterwards, after the other registered finders but before the
+// path finder
+//
+// This is normally called from the init() function of the package
+// implementing the Finder.
+func RegisterFinder(f Finder) {
+	finders = append(finders, f)
+}
+
+// ModuleSpecType is the type of the module specs which finders return
+var ModuleSpecType = ObjectType.NewType("ModuleSpec", `ModuleSpec(name, loader, *, origin=None, loader_state=None, is_package=None)
+
+The specification for a module, used for loading.`, ModuleSpecNew, nil)
+
+// A ModuleSpec says how to load a module
+//
+// Its attributes are name, loader, origin, loader_state,
+// submodule_search_locations, cached, parent and has_location.
+// Finders written in python may return any object with them.
+type ModuleSpec struct {
+	Dict StringDict
+}
+
+// Type of this ModuleSpec object
+func (s *ModuleSpec) Type() *Type {
+	return ModuleSpecType
+}
+
+// Get the Dict
+func (s *ModuleSpec) GetDict() StringDict {
+	return s.Dict
+}
+
+// NewModuleSpec makes a spec for the module called name which is
+// loaded by loader
+//
+// origin is normally the file name of the module and isPackage
+// gives it an empty list of submodule_search_locations.
+func NewModuleSpec(name string, loader Object, origin string, isPackage bool) *ModuleSpec {
+	s := &ModuleSpec{Dict: NewStringDictFromMap(map[string]Object{
+		"name":                       String(name),
+		"loader":                     loader,
+		"origin":                     None,
+		"loader_state":               None,
+		"submodule_search_locations": None,
+		"cached":                     None,
+		"has_location":               False,
+	})}
+	if origin != "" {
+		s.Dict.Set("origin", String(origin))
+	}
+	if isPackage {
+		s.Dict.Set("submodule_search_locations", NewList())
+		s.Dict.Set("parent", String(name))
+	} else {
+		s.Dict.Set("parent", String(parentName(name)))
+	}
+	return s
+}
+
+// ModuleSpecNew
+func ModuleSpecNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var name, loader Object
+	var origin, loaderState, isPackage Object = None, None, None
+	kwlist := []string{"name", "loader", "origin", "loader_state", "is_package"}
+	err := ParseTupleAndKeywords(args, kwargs, "UO|OOO:ModuleSpec", kwlist, &name, &loader, &origin, &loaderState, &isPackage)
+	if err != nil {
+		return nil, err
+	}
+	s := NewModuleSpec(string(name.(String)), loader, "", ObjectIsTrue(isPackage))
+	s.Dict.Set("origin", origin)
+	s.Dict.Set("loader_state", loaderState)
+	return s, nil
+}
+
+func (s *ModuleSpec) M__repr__() (Object, error) {
+	var out strings.Builder
+	out.WriteString("ModuleSpec(")
+	for i, attr := range []string{"name", "loader", "origin", "submodule_search_locations"} {
+		value := s.Dict.Get(attr)
+		if i >= 2 && (value == nil || value == None) {
+			continue
+		}
+		repr, err := ReprAsString(value)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(attr + "=" + repr)
+	}
+	out.WriteString(")")
+	return String(out.String()), nil
+}
+
+// specAttr returns the attribute called name of spec or None if it
+// isn't set
+func specAttr(spec Object, name string) Object {
+	value, err := GetAttrString(spec, name)
+	if err != nil {
+		return None
+	}
+	return value
+}
+
+// FinderType is the type of the finders made by the importer
+var FinderType = NewType("finder", "finds modules for the importer")
+
+// A finder is a meta path or path entry finder implemented in Go
+type finder struct {
+	desc     string
+	findSpec func(name string, path Object) (Object, error)
+}
+
+// Type of this finder object
+func (f *finder) Type() *Type {
+	return FinderType
+}
+
+func (f *finder) M__repr__() (Object, error) {
+	return String(fmt.Sprintf("<finder %s>", f.desc)), nil
+}
+
+// LoaderType is the type of the loaders made by the importer
+var LoaderType = NewType("loader", "loads modules for the importer")
+
+// A loader makes and runs a module
+type loader struct {
+	ctx   *Context
+	name  string
+	found *FoundModule
+}
+
+// Type of this loader object
+func (l *loader) Type() *Type {
+	return LoaderType
+}
+
+func (l *loader) M__repr__() (Object, error) {
+	return String(fmt.Sprintf("<loader for '%s'>", l.name)), nil
+}
+
+func init() {
+	FinderType.Dict.Set("find_spec", MustNewMethod("find_spec", func(self Object, args Tuple) (Object, error) {
+		var name Object
+		var path, target Object = None, None
+		err := ParseTuple(args, "U|OO:find_spec", &name, &path, &target)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*finder).findSpec(string(name.(String)), path)
+	}, 0, "find_spec(fullname, path=None, target=None) -> spec or None\n\nFind the spec of the module called fullname."))
+
+	LoaderType.Dict.Set("create_module", MustNewMethod("create_module", func(self, spec Object) (Object, error) {
+		if m := self.(*loader).found.Module; m != nil {
+			return m, nil
+		}
+		return None, nil
+	}, 0, "create_module(spec) -> module or None\n\nReturn the module to load or None to make a new one."))
+
+	LoaderType.Dict.Set("exec_module", MustNewMethod("exec_module", func(self, module Object) (Object, error) {
+		err := self.(*loader).execModule(module)
+		if err != nil {
+			return nil, err
+		}
+		return None, nil
+	}, 0, "exec_module(module) -> None\n\nRun the code of the module in its namespace."))
+}
+
+// execModule runs the code of the found module in the namespace of
+// module
+func (l *loader) execModule(module Object) error {
+	found := l.found
+	if found.Module != nil {
+		// Already built in Go
+		return nil
+	}
+	I, ok := module.(IGetDict)
+	if !ok {
+		return ExceptionNewf(TypeError, "exec_module() argument must be a module, not %s", module.Type().Name)
+	}
+	code := found.Code
+	if code == nil && found.Source == "" && found.Filename != "" {
+		var err error
+		code, err = l.ctx.compileFile(found.Filename)
+		if err != nil {
+			return err
+		}
+	} else if code == nil {
+		filename := found.Filename
+		if filename == "" {
+			filename = "<" + l.name + ">"
+		}
+		codeObj, err := Compile(found.Source, filename, "exec", 0, true)
+		if err != nil {
+			return err
+		}
+		code, ok = codeObj.(*Code)
+		if !ok {
+			return ExceptionNewf(ImportError, "Compile didn't return code object")
+		}
+	}
+	globals := I.GetDict()
+	_, err := VmRun(l.ctx, globals, globals, code, nil)
+	return err
+}
+
+// specFromFound makes the spec of a module supplied by a Finder
+func (ctx *Context) specFromFound(name string, found *FoundModule) *ModuleSpec {
+	spec := NewModuleSpec(name, &loader{ctx: ctx, name: name, found: found}, found.Filename, found.Package)
+	if found.Filename != "" {
+		spec.Dict.Set("has_location", True)
+		if found.Module == nil && found.Code == nil && found.Source == "" {
+			spec.Dict.Set("cached", String(CacheFromSource(found.Filename)))
+		}
+	}
+	return spec
+}
+
+// FinderObject makes a python meta path finder from f which may be
+// added to sys.meta_path
+func (ctx *Context) FinderObject(f Finder) Object {
+	return &finder{
+		desc: fmt.Sprintf("%T", f),
+		findSpec: func(name string, path Object) (Object, error) {
+			found, err := f.FindModule(ctx, name)
+			if err != nil || found == nil {
+				return None, err
+			}
+			return ctx.specFromFound(name, found), nil
+		},
+	}
+}
+
+// DefaultMetaPath returns the initial value of sys.meta_path
+func (ctx *Context) DefaultMetaPath() []Object {
+	metaPath := make([]Object, 0, len(finders)+1)
+	for _, f := range finders {
+		metaPath = append(metaPath, ctx.FinderObject(f))
+	}
+	return append(metaPath, &finder{desc: "PathFinder", findSpec: ctx.pathFinderFindSpec})
+}
+
+// DefaultPathHooks returns the initial value of sys.path_hooks
+func (ctx *Context) DefaultPathHooks() []Object {
+	return []Object{MustNewMethod("path_hook_for_FileFinder", func(self, arg Object) (Object, error) {
+		dir, ok := arg.(String)
+		if !ok {
+			return nil, ExceptionNewf(ImportError, "only str paths are supported")
+		}
+		if fi, err := ctx.statFile(string(dir)); err != nil || !fi.IsDir() {
+			return nil, ExceptionNewf(ImportError, "only directories are supported")
+		}
+		if ctx.Opts.FS == nil {
+			abs, err := filepath.Abs(string(dir))
+			if err != nil {
+				return nil, ExceptionNewf(ImportError, "%v", err)
+			}
+			dir = String(abs)
+		}
+		return ctx.fileFinder(string(dir)), nil
+	}, 0, "path_hook_for_FileFinder(path) -> finder\n\nMake a finder for the modules in the directory path.")}
+}
+
+// pathFinderFindSpec finds the spec of the module called name in the
+// directories of path, or sys.path if path is None
+//
+// Directories without an __init__.py make a namespace package only
+// if no module or package is found in any of them.
+func (ctx *Context) pathFinderFindSpec(name string, path Object) (Object, error) {
+	var dirs []string
+	var err error
+	if path == None {
+		dirs, err = ctx.topLevelPath(ctx.importGlobals)
+	} else {
+		dirs, err = stringItems(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	namespace := NewList()
+	for _, dir := range dirs {
+		entryFinder, err := ctx.pathEntryFinder(dir)
+		if err != nil {
+			return nil, err
+		}
+		if entryFinder == None {
+			continue
+		}
+		var spec Object
+		if f, ok := entryFinder.(*finder); ok {
+			spec, err = f.findSpec(name, None)
+		} else {
+			spec, err = callFindSpec(entryFinder, Tuple{String(name)})
+		}
+		if err != nil {
+			return nil, err
+		}
+		if spec == None {
+			continue
+		}
+		if specAttr(spec, "loader") != None {
+			return spec, nil
+		}
+		locations := specAttr(spec, "submodule_search_locations")
+		if locations == None {
+			return nil, ExceptionNewf(ImportError, "spec missing loader")
+		}
+		err = namespace.ExtendSequence(locations)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(namespace.Items) == 0 {
+		return None, nil
+	}
+	spec := NewModuleSpec(name, None, "", true)
+	spec.Dict.Set("submodule_search_locations", namespace)
+	return spec, nil
+}
+
+// pathEntryFinder returns the finder for the directory dir from
+// sys.path_importer_cache, making it with the first hook in
+// sys.path_hooks which accepts it if necessary
+//
+// It returns None if no hook accepts dir.
+func (ctx *Context) pathEntryFinder(dir string) (Object, error) {
+	cache, err := ctx.sysAttr("path_importer_cache")
+	if err != nil {
+		return nil, err
+	}
+	key := String(dir)
+	if entryFinder, err := GetItem(cache, key); err == nil {
+		return entryFinder, nil
+	} else if !IsException(KeyError, err) {
+		return nil, err
+	}
+	hooks, err := ctx.sysAttr("path_hooks")
+	if err != nil {
+		return nil, err
+	}
+	var entryFinder Object = None
+	var hookErr error
+	err = Iterate(hooks, func(hook Object) bool {
+		entryFinder, hookErr = Call(hook, Tuple{key}, nil)
+		if errors.Is(hookErr, ImportError) {
+			entryFinder, hookErr = None, nil
+			return false
+		}
+		return true
+	})
+	if err == nil {
+		err = hookErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = SetItem(cache, key, entryFinder)
+	if err != nil {
+		return nil, err
+	}
+	return entryFinder, nil
+}
+
+// callFindSpec calls the find_spec method of a finder written in
+// python with args
+func callFindSpec(f Object, args Tuple) (Object, error) {
+	findSpec, err := GetAttrString(f, "find_spec")
+	if err != nil {
+		return nil, err
+	}
+	return Call(findSpec, args, nil)
+}
+
+// fileFinder makes the path entry finder for the python files in the
+// directory dir
+func (ctx *Context) fileFinder(dir string) *finder {
+	return &finder{
+		desc: fmt.Sprintf("FileFinder(%q)", dir),
+		findSpec: func(name string, _ Object) (Object, error) {
+			fullPath := path.Join(dir, name[strings.LastIndexByte(name, '.')+1:])
+			isDir := false
+			if fi, err := ctx.statFile(fullPath); err == nil && fi.IsDir() {
+				isDir = true
+				initPath := path.Join(fullPath, "__init__.py")
+				if _, err := ctx.statFile(initPath); err == nil {
+					spec := ctx.specFromFound(name, &FoundModule{Filename: initPath, Package: true})
+					spec.Dict.Set("submodule_search_locations", NewListFromItems([]Object{String(fullPath)}))
+					return spec, nil
+				}
+			}
+			// FIXME Read pyc/pyo too
+			if _, err := ctx.statFile(fullPath + ".py"); err == nil {
+				return ctx.specFromFound(name, &FoundModule{Filename: fullPath + ".py"}), nil
+			}
+			if isDir {
+				// A portion of a namespace package
+				spec := NewModuleSpec(name, None, "", true)
+				spec.Dict.Set("submodule_search_locations", NewListFromItems([]Object{String(fullPath)}))
+				return spec, nil
+			}
+			return None, nil
+		},
+	}
+}
+
+// Check interface is satisfied
+var _ IGetDict = (*ModuleSpec)(nil)
+var _ I__repr__ = (*ModuleSpec)(nil)
+var _ I__repr__ = (*finder)(nil)
+var _ I__repr__ = (*loader)(nil)
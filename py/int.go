@@ -201,6 +201,14 @@ func (a Int) M__repr__() (Object, error) {
 	return a.M__str__()
 }
 
+func (a Int) M__format__(spec Object) (Object, error) {
+	s, err := formatSpecString(spec)
+	if err != nil {
+		return nil, err
+	}
+	return formatInt(big.NewInt(int64(a)), s, "int")
+}
+
 // Arithmetic
 
 // Errors
@@ -704,6 +712,10 @@ func (a Int) M__ge__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a Int) M__hash__() (Object, error) {
+	return Int(hashInt64(int64(a))), nil
+}
+
 func (a Int) M__ceil__() (Object, error) {
 	return a, nil
 }
@@ -725,3 +737,5 @@ var _ I__index__ = Int(0)
 var _ richComparison = Int(0)
 var _ IGoInt = Int(0)
 var _ IGoInt64 = Int(0)
+var _ I__hash__ = Int(0)
+var _ I__format__ = Int(0)
@@ -237,8 +237,18 @@ func GetAttrString(self Object, key string) (res Object, err error) {
 	// Look in the instance dictionary if it exists
 	if I, ok := self.(IGetDict); ok {
 		dict := I.GetDict()
-		res, ok = dict[key]
+		res, ok = dict.Lookup(key)
 		if ok {
+			// A classmethod read from its class binds to the class
+			// and a staticmethod unwraps
+			if _, isType := self.(*Type); isType {
+				switch c := res.(type) {
+				case *ClassMethod:
+					return c.M__get__(None, self)
+				case *StaticMethod:
+					return c.M__get__(None, self)
+				}
+			}
 			return res, err
 		}
 	}
@@ -302,7 +312,7 @@ func SetAttrString(self Object, key string, value Object) (Object, error) {
 		if dict == nil {
 			return nil, ExceptionNewf(SystemError, "nil Dict in %s", self.Type().Name)
 		}
-		dict[key] = value
+		dict.Set(key, value)
 		return None, nil
 	}
 
@@ -347,8 +357,8 @@ func DeleteAttrString(self Object, key string) error {
 		if dict == nil {
 			return ExceptionNewf(SystemError, "nil Dict in %s", self.Type().Name)
 		}
-		if _, ok := dict[key]; ok {
-			delete(dict, key)
+		if _, ok := dict.Lookup(key); ok {
+			dict.Delete(key)
 			return nil
 		}
 	}
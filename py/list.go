@@ -14,32 +14,153 @@ var ListType = ObjectType.NewType("list", "list() -> new empty list\nlist(iterab
 
 // FIXME lists are mutable so this should probably be struct { Tuple } then can use the sub methods on Tuple
 type List struct {
-	Items []Object
+	Items  []Object
+	inRepr bool // set while the List's repr is being made
 }
 
 func init() {
 	// FIXME: all methods should be callable using list.method([], *args, **kwargs) or [].method(*args, **kwargs)
-	ListType.Dict["append"] = MustNewMethod("append", func(self Object, args Tuple) (Object, error) {
+	ListType.Dict.Set("append", MustNewMethod("append", func(self Object, args Tuple) (Object, error) {
 		listSelf := self.(*List)
 		if len(args) != 1 {
 			return nil, ExceptionNewf(TypeError, "append() takes exactly one argument (%d given)", len(args))
 		}
 		listSelf.Items = append(listSelf.Items, args[0])
 		return NoneType{}, nil
-	}, 0, "append(item)")
+	}, 0, "append(item)"))
 
-	ListType.Dict["extend"] = MustNewMethod("extend", func(self Object, args Tuple) (Object, error) {
-		listSelf := self.(*List)
-		if len(args) != 1 {
-			return nil, ExceptionNewf(TypeError, "append() takes exactly one argument (%d given)", len(args))
+	ListType.Dict.Set("extend", MustNewMethod("extend", func(self, iterable Object) (Object, error) {
+		err := self.(*List).ExtendSequence(iterable)
+		if err != nil {
+			return nil, err
+		}
+		return NoneType{}, nil
+	}, 0, "extend(iterable)"))
+
+	ListType.Dict.Set("insert", MustNewMethod("insert", func(self Object, args Tuple) (Object, error) {
+		l := self.(*List)
+		var index, item Object
+		err := UnpackTuple(args, nil, "insert", 2, 2, &index, &item)
+		if err != nil {
+			return nil, err
+		}
+		i, err := IndexInt(index)
+		if err != nil {
+			return nil, err
 		}
-		if oList, ok := args[0].(*List); ok {
-			listSelf.Items = append(listSelf.Items, oList.Items...)
+		if i < 0 {
+			i += len(l.Items)
+			if i < 0 {
+				i = 0
+			}
+		} else if i > len(l.Items) {
+			i = len(l.Items)
 		}
+		l.Items = append(l.Items, nil)
+		copy(l.Items[i+1:], l.Items[i:])
+		l.Items[i] = item
 		return NoneType{}, nil
-	}, 0, "extend([item])")
+	}, 0, "insert(index, item)"))
 
-	ListType.Dict["sort"] = MustNewMethod("sort", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+	ListType.Dict.Set("pop", MustNewMethod("pop", func(self Object, args Tuple) (Object, error) {
+		l := self.(*List)
+		var index Object = Int(-1)
+		err := UnpackTuple(args, nil, "pop", 0, 1, &index)
+		if err != nil {
+			return nil, err
+		}
+		if len(l.Items) == 0 {
+			return nil, ExceptionNewf(IndexError, "pop from empty list")
+		}
+		i, err := IndexInt(index)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			i += len(l.Items)
+		}
+		if i < 0 || i >= len(l.Items) {
+			return nil, ExceptionNewf(IndexError, "pop index out of range")
+		}
+		item := l.Items[i]
+		l.DelItem(i)
+		return item, nil
+	}, 0, "pop([index]) -> item\n\nRemove and return item at index (default last).\nRaises IndexError if list is empty or index is out of range."))
+
+	ListType.Dict.Set("remove", MustNewMethod("remove", func(self, value Object) (Object, error) {
+		l := self.(*List)
+		i, err := l.find(value, 0, len(l.Items))
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, ExceptionNewf(ValueError, "list.remove(x): x not in list")
+		}
+		l.DelItem(i)
+		return NoneType{}, nil
+	}, 0, "remove(value)\n\nRemove first occurrence of value.\nRaises ValueError if the value is not present."))
+
+	ListType.Dict.Set("index", MustNewMethod("index", func(self Object, args Tuple) (Object, error) {
+		l := self.(*List)
+		var value, start, stop Object
+		err := UnpackTuple(args, nil, "index", 1, 3, &value, &start, &stop)
+		if err != nil {
+			return nil, err
+		}
+		i, j, err := indices(start, stop, len(l.Items))
+		if err != nil {
+			return nil, err
+		}
+		i, err = l.find(value, i, j)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			r, err := ReprAsString(value)
+			if err != nil {
+				return nil, err
+			}
+			return nil, ExceptionNewf(ValueError, "%s is not in list", r)
+		}
+		return Int(i), nil
+	}, 0, "index(value, [start, [stop]]) -> integer\n\nReturn first index of value.\nRaises ValueError if the value is not present."))
+
+	ListType.Dict.Set("count", MustNewMethod("count", func(self, value Object) (Object, error) {
+		l := self.(*List)
+		n := 0
+		for _, item := range l.Items {
+			eq, err := Eq(item, value)
+			if err != nil {
+				return nil, err
+			}
+			if ObjectIsTrue(eq) {
+				n++
+			}
+		}
+		return Int(n), nil
+	}, 0, "count(value) -> integer\n\nReturn number of occurrences of value."))
+
+	ListType.Dict.Set("reverse", MustNewMethod("reverse", func(self Object) (Object, error) {
+		l := self.(*List)
+		for i, j := 0, len(l.Items)-1; i < j; i, j = i+1, j-1 {
+			l.Items[i], l.Items[j] = l.Items[j], l.Items[i]
+		}
+		return NoneType{}, nil
+	}, 0, "reverse()\n\nReverse the list in place."))
+
+	ListType.Dict.Set("copy", MustNewMethod("copy", func(self Object) (Object, error) {
+		return self.(*List).Copy(), nil
+	}, 0, "copy() -> list\n\nReturn a shallow copy of the list."))
+
+	ListType.Dict.Set("clear", MustNewMethod("clear", func(self Object) (Object, error) {
+		// Leave an empty list alone as it might be being sorted
+		if l := self.(*List); len(l.Items) != 0 {
+			l.Items = nil
+		}
+		return NoneType{}, nil
+	}, 0, "clear()\n\nRemove all items from the list."))
+
+	ListType.Dict.Set("sort", MustNewMethod("sort", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
 		const funcName = "sort"
 		var l *List
 		if self == None {
@@ -67,7 +188,7 @@ func init() {
 			return nil, err
 		}
 		return NoneType{}, nil
-	}, 0, "sort(key=None, reverse=False)")
+	}, 0, "sort(key=None, reverse=False)"))
 
 }
 
@@ -154,11 +275,32 @@ func (l *List) Len() int {
 	return len(l.Items)
 }
 
+// find returns the index of the first item in l.Items[start:stop]
+// equal to value or -1 if there isn't one
+func (l *List) find(value Object, start, stop int) (int, error) {
+	for i := start; i < stop && i < len(l.Items); i++ {
+		eq, err := Eq(l.Items[i], value)
+		if err != nil {
+			return 0, err
+		}
+		if ObjectIsTrue(eq) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
 func (l *List) M__str__() (Object, error) {
 	return l.M__repr__()
 }
 
 func (l *List) M__repr__() (Object, error) {
+	// Stop a List which contains itself recursing
+	if l.inRepr {
+		return String("[...]"), nil
+	}
+	l.inRepr = true
+	defer func() { l.inRepr = false }()
 	return Tuple(l.Items).repr("[", "]")
 }
 
@@ -199,29 +341,25 @@ func (l *List) M__setitem__(key, value Object) (Object, error) {
 		if err != nil {
 			return nil, err
 		}
+		// Copy the new items first in case value is l
+		newItems, err := SequenceTuple(value)
+		if err != nil {
+			return nil, err
+		}
 		if step == 1 {
-			// Make a copy of the tail
-			tailSlice := l.Items[stop:]
-			tail := make([]Object, len(tailSlice))
-			copy(tail, tailSlice)
-			l.Items = l.Items[:start]
-			err = l.ExtendSequence(value)
-			if err != nil {
-				return nil, err
+			if stop < start {
+				stop = start
 			}
-			l.Items = append(l.Items, tail...)
+			items := make([]Object, 0, len(l.Items)-(stop-start)+len(newItems))
+			items = append(items, l.Items[:start]...)
+			items = append(items, newItems...)
+			l.Items = append(items, l.Items[stop:]...)
 		} else {
-			newItems, err := SequenceTuple(value)
-			if err != nil {
-				return nil, err
-			}
 			if len(newItems) != slicelength {
 				return nil, ExceptionNewf(ValueError, "attempt to assign sequence of size %d to extended slice of size %d", len(newItems), slicelength)
 			}
-			j := 0
-			for i := start; i < stop; i += step {
+			for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
 				l.Items[i] = newItems[j]
-				j++
 			}
 		}
 	} else {
@@ -242,18 +380,33 @@ func (a *List) DelItem(i int) {
 // Removes items from a list
 func (a *List) M__delitem__(key Object) (Object, error) {
 	if slice, ok := key.(*Slice); ok {
-		start, stop, step, _, err := slice.GetIndices(len(a.Items))
+		start, stop, step, slicelength, err := slice.GetIndices(len(a.Items))
 		if err != nil {
 			return nil, err
 		}
 		if step == 1 {
-			a.Items = append(a.Items[:start], a.Items[stop:]...)
-		} else {
-			j := 0
-			for i := start; i < stop; i += step {
-				a.DelItem(i - j)
+			if stop > start {
+				a.Items = append(a.Items[:start], a.Items[stop:]...)
+			}
+		} else if slicelength > 0 {
+			if step < 0 {
+				// Delete the same items working forwards
+				start += (slicelength - 1) * step
+				step = -step
+			}
+			end := start + (slicelength-1)*step
+			j := start
+			for i := start; i < len(a.Items); i++ {
+				if i <= end && (i-start)%step == 0 {
+					continue
+				}
+				a.Items[j] = a.Items[i]
 				j++
 			}
+			for i := j; i < len(a.Items); i++ {
+				a.Items[i] = nil
+			}
+			a.Items = a.Items[:j]
 		}
 	} else {
 		i, err := IndexIntCheck(key, len(a.Items))
@@ -367,106 +520,49 @@ func (a *List) M__ne__(other Object) (Object, error) {
 	return False, nil
 }
 
+// sortable sorts items by their keys recording the first error
 type sortable struct {
-	l        *List
-	keyFunc  Object
-	reverse  bool
-	firstErr error
-}
-
-type ptrSortable struct {
-	s *sortable
+	items   []Object
+	keys    []Object
+	reverse bool
+	err     error
 }
 
-func (s ptrSortable) Len() int {
-	return s.s.l.Len()
+func (s *sortable) Len() int {
+	return len(s.items)
 }
 
-func (s ptrSortable) Swap(i, j int) {
-	itemI, err := s.s.l.M__getitem__(Int(i))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return
-	}
-	itemJ, err := s.s.l.M__getitem__(Int(j))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return
-	}
-	_, err = s.s.l.M__setitem__(Int(i), itemJ)
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-	}
-	_, err = s.s.l.M__setitem__(Int(j), itemI)
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-	}
+func (s *sortable) Swap(i, j int) {
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
 }
 
-func (s ptrSortable) Less(i, j int) bool {
-	itemI, err := s.s.l.M__getitem__(Int(i))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
+func (s *sortable) Less(i, j int) bool {
+	if s.err != nil {
 		return false
 	}
-	itemJ, err := s.s.l.M__getitem__(Int(j))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return false
+	a, b := s.keys[i], s.keys[j]
+	if s.reverse {
+		a, b = b, a
 	}
-
-	if s.s.keyFunc != None {
-		itemI, err = Call(s.s.keyFunc, Tuple{itemI}, nil)
-		if err != nil {
-			if s.s.firstErr == nil {
-				s.s.firstErr = err
-			}
-			return false
-		}
-		itemJ, err = Call(s.s.keyFunc, Tuple{itemJ}, nil)
-		if err != nil {
-			if s.s.firstErr == nil {
-				s.s.firstErr = err
-			}
-			return false
-		}
+	res, err := Lt(a, b)
+	if err == nil {
+		res, err = MakeBool(res)
 	}
-
-	var cmpResult Object
-	if s.s.reverse {
-		cmpResult, err = Lt(itemJ, itemI)
-	} else {
-		cmpResult, err = Lt(itemI, itemJ)
-	}
-
 	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
+		s.err = err
 		return false
 	}
-
-	if boolResult, ok := cmpResult.(Bool); ok {
-		return bool(boolResult)
-	}
-
-	return false
+	return res == True
 }
 
 // SortInPlace sorts the given List in place using a stable sort.
 // kwargs can have the keys "key" and "reverse".
+//
+// The key function is called once for each item. If it or a
+// comparison raises an exception the list is left unchanged.  The
+// list is empty while it is being sorted and a ValueError is raised
+// if it is modified.
 func SortInPlace(l *List, kwargs StringDict, funcName string) error {
 	var keyFunc Object
 	var reverse Object
@@ -481,7 +577,42 @@ func SortInPlace(l *List, kwargs StringDict, funcName string) error {
 		reverse = False
 	}
 	// FIXME: requires the same bool-check like CPython (or better "|$Op" that doesn't panic on nil).
-	s := ptrSortable{&sortable{l, keyFunc, ObjectIsTrue(reverse), nil}}
+	s := &sortable{
+		items:   Tuple(l.Items).Copy(),
+		reverse: ObjectIsTrue(reverse),
+	}
+
+	// Empty the list while sorting it.  Its storage holds a
+	// placeholder which any change to the list moves or overwrites.
+	saved := l.Items
+	placeholder := NewList()
+	storage := []Object{placeholder}
+	l.Items = storage[:0]
+	modified := func() bool {
+		return len(l.Items) != 0 || cap(l.Items) != 1 || &l.Items[:1][0] != &storage[0] || storage[0] != placeholder
+	}
+
+	if keyFunc == None {
+		s.keys = Tuple(saved).Copy()
+	} else {
+		s.keys = make([]Object, len(saved))
+		for i, item := range saved {
+			s.keys[i], err = Call(keyFunc, Tuple{item}, nil)
+			if err != nil {
+				l.Items = saved
+				return err
+			}
+		}
+	}
 	sort.Stable(s)
-	return s.s.firstErr
+	if s.err != nil {
+		l.Items = saved
+		return s.err
+	}
+	changed := modified()
+	l.Items = s.items
+	if changed {
+		return ExceptionNewf(ValueError, "list modified during sort")
+	}
+	return nil
 }
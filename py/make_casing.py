@@ -0,0 +1,71 @@
+#!/usr/bin/env python3
+
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+"""
+Write casing.go
+
+This holds the case mappings which the Go unicode package doesn't
+have, that is the ones from SpecialCasing.txt which map a character to
+more than one character and the ones from CaseFolding.txt which differ
+from lowercasing.  They are read from the str methods of the python
+running this.
+"""
+
+import subprocess
+import sys
+import unicodedata
+
+def quote(s):
+    """Return s as a Go string literal"""
+    out = []
+    for c in s:
+        if " " <= c < "\x7f" and c not in '"\\':
+            out.append(c)
+        elif ord(c) <= 0xffff:
+            out.append("\\u%04x" % ord(c))
+        else:
+            out.append("\\U%08x" % ord(c))
+    return '"' + "".join(out) + '"'
+
+def write_map(out, name, doc, mapping):
+    out.write("\n// %s\n" % doc)
+    out.write("var %s = map[rune]string{\n" % name)
+    for c in sorted(mapping):
+        out.write("\t0x%04x: %s, // %s\n" % (ord(c), quote(mapping[c]), unicodedata.name(c, "")))
+    out.write("}\n")
+
+def main():
+    upper, lower, title, fold = {}, {}, {}, {}
+    for i in range(sys.maxunicode + 1):
+        c = chr(i)
+        if unicodedata.category(c) == "Cs":
+            continue
+        if len(c.upper()) > 1:
+            upper[c] = c.upper()
+        if len(c.lower()) > 1:
+            lower[c] = c.lower()
+        if len(c.title()) > 1:
+            title[c] = c.title()
+        if c.casefold() != c.lower():
+            fold[c] = c.casefold()
+    with open("casing.go", "w") as out:
+        out.write("""\
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by make_casing.py from Unicode %s. DO NOT EDIT.
+
+package py
+""" % unicodedata.unidata_version)
+        write_map(out, "upperSpecial", "Characters which uppercase to more than one character", upper)
+        write_map(out, "lowerSpecial", "Characters which lowercase to more than one character", lower)
+        write_map(out, "titleSpecial", "Characters which titlecase to more than one character", title)
+        write_map(out, "caseFold", "Characters which casefold to something other than their lowercase", fold)
+    subprocess.check_call(["gofmt", "-w", "casing.go"])
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,631 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// MemoryView objects
+//
+// A memoryview shares the bytes of an object supporting the buffer
+// protocol (see IBuffer) without copying them.  Only one dimensional
+// views are supported and the items of a cast view are stored little
+// endian.
+//
+// As in CPython a bytearray can't be resized while a memoryview of it
+// exists, and doing so raises BufferError.  gpython doesn't count
+// references so a view only stops existing when it is released, with
+// release() or a with statement.  Slices and casts of a view share its
+// export rather than making their own, so releasing the view made
+// from the bytearray lets it be resized again.  The bytes are fetched
+// from the exporting object on each access so a slice which outlives
+// its parent sees any resizing, and raises IndexError if it no longer
+// fits.
+
+package py
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+var MemoryViewType = ObjectType.NewType("memoryview", `memoryview(object)
+
+Create a new memoryview object which references the given object.`, MemoryViewNew, nil)
+
+type MemoryView struct {
+	obj      Object // object exporting the bytes or nil for Go bytes
+	buf      []byte // the shared bytes if obj is nil
+	offset   int    // offset of the first item in buf
+	stride   int    // bytes from one item to the next
+	length   int    // number of items
+	format   string // struct format of the items
+	itemsize int
+	readonly bool
+	released bool
+	exported bool // set if m holds the export of a bytearray
+}
+
+// memoryViewFormats maps the formats a memoryview may be cast to onto
+// the size of their items
+var memoryViewFormats = map[string]int{
+	"B": 1, "b": 1, "c": 1, "?": 1,
+	"H": 2, "h": 2,
+	"I": 4, "i": 4, "f": 4,
+	"Q": 8, "q": 8, "d": 8,
+}
+
+// Type of this MemoryView object
+func (m *MemoryView) Type() *Type {
+	return MemoryViewType
+}
+
+// MemoryViewNew
+func MemoryViewNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var obj Object
+	err := ParseTupleAndKeywords(args, kwargs, "O:memoryview", []string{"object"}, &obj)
+	if err != nil {
+		return nil, err
+	}
+	return NewMemoryView(obj)
+}
+
+// NewMemoryView makes a view of the bytes of obj which must
+// implement IBuffer
+func NewMemoryView(obj Object) (*MemoryView, error) {
+	if m, ok := obj.(*MemoryView); ok {
+		if err := m.check(); err != nil {
+			return nil, err
+		}
+		return m.view(), nil
+	}
+	I, ok := obj.(IBuffer)
+	if !ok {
+		return nil, ExceptionNewf(TypeError, "memoryview: a bytes-like object is required, not '%s'", obj.Type().Name)
+	}
+	buf, readonly, err := I.Buffer()
+	if err != nil {
+		return nil, err
+	}
+	m := NewMemoryViewFromBytes(buf, readonly)
+	m.obj = obj
+	m.buf = nil
+	if a, ok := obj.(*ByteArray); ok {
+		a.exports++
+		m.exported = true
+	}
+	return m, nil
+}
+
+// NewMemoryViewFromBytes makes a view of b without copying it so a Go
+// program can share b with python code
+func NewMemoryViewFromBytes(b []byte, readonly bool) *MemoryView {
+	return &MemoryView{
+		buf:      b,
+		stride:   1,
+		length:   len(b),
+		format:   "B",
+		itemsize: 1,
+		readonly: readonly,
+	}
+}
+
+// view makes a new view sharing the bytes, and any export, of m
+func (m *MemoryView) view() *MemoryView {
+	v := *m
+	v.exported = false
+	return &v
+}
+
+// Release stops m sharing the bytes of the exporting object
+func (m *MemoryView) Release() {
+	if m.released {
+		return
+	}
+	m.released = true
+	m.buf = nil
+	if m.exported {
+		m.obj.(*ByteArray).exports--
+		m.exported = false
+	}
+}
+
+// check returns an error if m has been released
+func (m *MemoryView) check() error {
+	if m.released {
+		return ExceptionNewf(ValueError, "operation forbidden on released memoryview object")
+	}
+	return nil
+}
+
+// data returns the bytes shared by m, reading them from the exporting
+// object in case it has been resized since m was made
+func (m *MemoryView) data() ([]byte, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	buf := m.buf
+	if I, ok := m.obj.(IBuffer); ok {
+		var err error
+		buf, _, err = I.Buffer()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m.length > 0 {
+		end := m.offset + m.itemsize
+		if last := m.offset + (m.length-1)*m.stride + m.itemsize; last > end {
+			end = last
+		}
+		if end > len(buf) {
+			return nil, ExceptionNewf(IndexError, "memoryview: underlying buffer was resized")
+		}
+	}
+	return buf, nil
+}
+
+// contiguous reports whether the items of m are next to each other
+func (m *MemoryView) contiguous() bool {
+	return m.stride == m.itemsize || m.length <= 1
+}
+
+// item returns the bytes of the i-th item from buf returned by data
+func (m *MemoryView) item(buf []byte, i int) []byte {
+	start := m.offset + i*m.stride
+	return buf[start : start+m.itemsize]
+}
+
+// Bytes returns a copy of the bytes of the items of m
+func (m *MemoryView) Bytes() (Bytes, error) {
+	buf, err := m.data()
+	if err != nil {
+		return nil, err
+	}
+	b := make(Bytes, 0, m.length*m.itemsize)
+	for i := 0; i < m.length; i++ {
+		b = append(b, m.item(buf, i)...)
+	}
+	return b, nil
+}
+
+// items returns the unpacked items of m
+func (m *MemoryView) items() (Tuple, error) {
+	buf, err := m.data()
+	if err != nil {
+		return nil, err
+	}
+	t := make(Tuple, m.length)
+	for i := range t {
+		t[i] = m.unpack(m.item(buf, i))
+	}
+	return t, nil
+}
+
+// unpack converts the bytes of an item into a python object
+func (m *MemoryView) unpack(b []byte) Object {
+	le := binary.LittleEndian
+	switch m.format {
+	case "b":
+		return Int(int8(b[0]))
+	case "c":
+		return Bytes{b[0]}
+	case "?":
+		return NewBool(b[0] != 0)
+	case "H":
+		return Int(le.Uint16(b))
+	case "h":
+		return Int(int16(le.Uint16(b)))
+	case "I":
+		return Int(le.Uint32(b))
+	case "i":
+		return Int(int32(le.Uint32(b)))
+	case "Q":
+		x := le.Uint64(b)
+		if x > math.MaxInt64 {
+			return (*BigInt)(new(big.Int).SetUint64(x))
+		}
+		return Int(x)
+	case "q":
+		return Int(int64(le.Uint64(b)))
+	case "f":
+		return Float(math.Float32frombits(le.Uint32(b)))
+	case "d":
+		return Float(math.Float64frombits(le.Uint64(b)))
+	}
+	return Int(b[0])
+}
+
+// pack converts value into the bytes of an item
+func (m *MemoryView) pack(b []byte, value Object) error {
+	invalidType := ExceptionNewf(TypeError, "memoryview: invalid type for format '%s'", m.format)
+	invalidValue := ExceptionNewf(ValueError, "memoryview: invalid value for format '%s'", m.format)
+	le := binary.LittleEndian
+	switch m.format {
+	case "c":
+		c, ok := value.(Bytes)
+		if !ok {
+			return invalidType
+		}
+		if len(c) != 1 {
+			return invalidValue
+		}
+		b[0] = c[0]
+		return nil
+	case "?":
+		if ObjectIsTrue(value) {
+			b[0] = 1
+		} else {
+			b[0] = 0
+		}
+		return nil
+	case "f", "d":
+		f, err := MakeFloat(value)
+		if err != nil {
+			return invalidType
+		}
+		x := float64(f.(Float))
+		if m.format == "f" {
+			le.PutUint32(b, math.Float32bits(float32(x)))
+		} else {
+			le.PutUint64(b, math.Float64bits(x))
+		}
+		return nil
+	}
+	x, ok := ConvertToBigInt(value)
+	if !ok {
+		return invalidType
+	}
+	bits := uint(8 * m.itemsize)
+	signed := m.format == "b" || m.format == "h" || m.format == "i" || m.format == "q"
+	lo, hi := new(big.Int), new(big.Int).Lsh(big.NewInt(1), bits)
+	if signed {
+		hi.Rsh(hi, 1)
+		lo.Neg(hi)
+	}
+	hi.Sub(hi, big.NewInt(1))
+	if (*big.Int)(x).Cmp(lo) < 0 || (*big.Int)(x).Cmp(hi) > 0 {
+		return invalidValue
+	}
+	var u uint64
+	if signed {
+		u = uint64((*big.Int)(x).Int64())
+	} else {
+		u = (*big.Int)(x).Uint64()
+	}
+	switch m.itemsize {
+	case 1:
+		b[0] = byte(u)
+	case 2:
+		le.PutUint16(b, uint16(u))
+	case 4:
+		le.PutUint32(b, uint32(u))
+	case 8:
+		le.PutUint64(b, u)
+	}
+	return nil
+}
+
+func init() {
+	MemoryViewType.Dict.Set("tobytes", MustNewMethod("tobytes", func(self Object) (Object, error) {
+		return self.(*MemoryView).Bytes()
+	}, 0, "tobytes($self, /)\n--\n\nReturn the data in the buffer as a byte string."))
+
+	MemoryViewType.Dict.Set("tolist", MustNewMethod("tolist", func(self Object) (Object, error) {
+		items, err := self.(*MemoryView).items()
+		if err != nil {
+			return nil, err
+		}
+		return NewListFromItems(items), nil
+	}, 0, "tolist($self, /)\n--\n\nReturn the data in the buffer as a list of elements."))
+
+	MemoryViewType.Dict.Set("hex", MustNewMethod("hex", func(self Object) (Object, error) {
+		b, err := self.(*MemoryView).Bytes()
+		if err != nil {
+			return nil, err
+		}
+		return String(hex.EncodeToString(b)), nil
+	}, 0, "hex($self, /)\n--\n\nReturn the data in the buffer as a str of hexadecimal numbers."))
+
+	MemoryViewType.Dict.Set("release", MustNewMethod("release", func(self Object) (Object, error) {
+		self.(*MemoryView).Release()
+		return None, nil
+	}, 0, "release($self, /)\n--\n\nRelease the underlying buffer exposed by the memoryview object."))
+
+	MemoryViewType.Dict.Set("cast", MustNewMethod("cast", func(self Object, args Tuple) (Object, error) {
+		m := self.(*MemoryView)
+		var format Object
+		err := ParseTuple(args, "U:cast", &format)
+		if err != nil {
+			return nil, err
+		}
+		if err = m.check(); err != nil {
+			return nil, err
+		}
+		if !m.contiguous() {
+			return nil, ExceptionNewf(TypeError, "memoryview: casts are restricted to C-contiguous views")
+		}
+		f := string(format.(String))
+		if len(f) == 2 && f[0] == '@' {
+			f = f[1:]
+		}
+		itemsize, ok := memoryViewFormats[f]
+		if !ok {
+			return nil, ExceptionNewf(ValueError, "memoryview: destination format must be a native single character format prefixed with an optional '@'")
+		}
+		if m.itemsize != 1 && itemsize != 1 && m.format != "B" && m.format != "b" && m.format != "c" {
+			return nil, ExceptionNewf(TypeError, "memoryview: cannot cast between two non-byte formats")
+		}
+		nbytes := m.length * m.itemsize
+		if nbytes%itemsize != 0 {
+			return nil, ExceptionNewf(TypeError, "memoryview: length is not a multiple of itemsize")
+		}
+		v := m.view()
+		v.format = f
+		v.itemsize = itemsize
+		v.stride = itemsize
+		v.length = nbytes / itemsize
+		return v, nil
+	}, 0, "cast($self, /, format)\n--\n\nCast a memoryview to a new format."))
+
+	properties := map[string]func(m *MemoryView) Object{
+		"obj": func(m *MemoryView) Object {
+			if m.obj == nil {
+				return None
+			}
+			return m.obj
+		},
+		"nbytes":     func(m *MemoryView) Object { return Int(m.length * m.itemsize) },
+		"readonly":   func(m *MemoryView) Object { return NewBool(m.readonly) },
+		"itemsize":   func(m *MemoryView) Object { return Int(m.itemsize) },
+		"format":     func(m *MemoryView) Object { return String(m.format) },
+		"ndim":       func(m *MemoryView) Object { return Int(1) },
+		"shape":      func(m *MemoryView) Object { return Tuple{Int(m.length)} },
+		"strides":    func(m *MemoryView) Object { return Tuple{Int(m.stride)} },
+		"contiguous": func(m *MemoryView) Object { return NewBool(m.contiguous()) },
+	}
+	for name, get := range properties {
+		get := get
+		MemoryViewType.Dict.Set(name, &Property{
+			Fget: func(self Object) (Object, error) {
+				m := self.(*MemoryView)
+				if err := m.check(); err != nil {
+					return nil, err
+				}
+				return get(m), nil
+			},
+		})
+	}
+	MemoryViewType.Dict.Set("c_contiguous", MemoryViewType.Dict.Get("contiguous"))
+}
+
+func (m *MemoryView) M__repr__() (Object, error) {
+	if m.released {
+		return String(fmt.Sprintf("<released memory at %p>", m)), nil
+	}
+	return String(fmt.Sprintf("<memory at %p>", m)), nil
+}
+
+func (m *MemoryView) M__len__() (Object, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	return Int(m.length), nil
+}
+
+func (m *MemoryView) M__iter__() (Object, error) {
+	items, err := m.items()
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator(items), nil
+}
+
+func (m *MemoryView) M__getitem__(key Object) (Object, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	if slice, ok := key.(*Slice); ok {
+		start, _, step, slicelength, err := slice.GetIndices(m.length)
+		if err != nil {
+			return nil, err
+		}
+		v := m.view()
+		v.offset += start * m.stride
+		v.stride *= step
+		v.length = slicelength
+		return v, nil
+	}
+	i, err := m.index(key)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := m.data()
+	if err != nil {
+		return nil, err
+	}
+	return m.unpack(m.item(buf, i)), nil
+}
+
+func (m *MemoryView) M__setitem__(key, value Object) (Object, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	if m.readonly {
+		return nil, ExceptionNewf(TypeError, "cannot modify read-only memory")
+	}
+	if slice, ok := key.(*Slice); ok {
+		start, _, step, slicelength, err := slice.GetIndices(m.length)
+		if err != nil {
+			return nil, err
+		}
+		format := "B"
+		var b Bytes
+		if v, ok := value.(*MemoryView); ok {
+			format = v.format
+			b, err = v.Bytes()
+		} else if I, ok := value.(IBuffer); ok {
+			var buf []byte
+			buf, _, err = I.Buffer()
+			b = append(Bytes{}, buf...)
+		} else {
+			return nil, ExceptionNewf(TypeError, "a bytes-like object is required, not '%s'", value.Type().Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if format != m.format || len(b) != slicelength*m.itemsize {
+			return nil, ExceptionNewf(ValueError, "memoryview assignment: lvalue and rvalue have different structures")
+		}
+		buf, err := m.data()
+		if err != nil {
+			return nil, err
+		}
+		for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
+			copy(m.item(buf, i), b[j*m.itemsize:])
+		}
+		return None, nil
+	}
+	i, err := m.index(key)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := m.data()
+	if err != nil {
+		return nil, err
+	}
+	err = m.pack(m.item(buf, i), value)
+	if err != nil {
+		return nil, err
+	}
+	return None, nil
+}
+
+// index converts key into the index of an item
+func (m *MemoryView) index(key Object) (int, error) {
+	i, err := IndexInt(key)
+	if err != nil {
+		if IsException(TypeError, err) {
+			return 0, ExceptionNewf(TypeError, "memoryview: invalid slice key")
+		}
+		return 0, err
+	}
+	if i < 0 {
+		i += m.length
+	}
+	if i < 0 || i >= m.length {
+		return 0, ExceptionNewf(IndexError, "index out of bounds on dimension 1")
+	}
+	return i, nil
+}
+
+func (m *MemoryView) M__eq__(other Object) (Object, error) {
+	if other == Object(m) {
+		return True, nil
+	}
+	if m.released {
+		return False, nil
+	}
+	var b Tuple
+	switch o := other.(type) {
+	case *MemoryView:
+		if o.released {
+			return False, nil
+		}
+		var err error
+		b, err = o.items()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		buf, ok := convertToBytes(other)
+		if !ok {
+			return NotImplemented, nil
+		}
+		b = make(Tuple, len(buf))
+		for i, c := range buf {
+			b[i] = Int(c)
+		}
+	}
+	a, err := m.items()
+	if err != nil {
+		return nil, err
+	}
+	if len(a) != len(b) {
+		return False, nil
+	}
+	for i := range a {
+		eq, err := Eq(a[i], b[i])
+		if err != nil {
+			return nil, err
+		}
+		if eq != True {
+			return False, nil
+		}
+	}
+	return True, nil
+}
+
+func (m *MemoryView) M__ne__(other Object) (Object, error) {
+	eq, err := m.M__eq__(other)
+	if err != nil || eq == NotImplemented {
+		return eq, err
+	}
+	return Not(eq)
+}
+
+func (m *MemoryView) M__hash__() (Object, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	if !m.readonly {
+		return nil, ExceptionNewf(ValueError, "cannot hash writable memoryview object")
+	}
+	if m.format != "B" && m.format != "b" && m.format != "c" {
+		return nil, ExceptionNewf(ValueError, "memoryview: hashing is restricted to formats 'B', 'b' or 'c'")
+	}
+	b, err := m.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return Int(hashBytes(b)), nil
+}
+
+func (m *MemoryView) M__enter__() (Object, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *MemoryView) M__exit__(exc_type, exc_value, traceback Object) (Object, error) {
+	m.Release()
+	return None, nil
+}
+
+// Buffer shares the bytes of the view which must be contiguous
+func (m *MemoryView) Buffer() ([]byte, bool, error) {
+	buf, err := m.data()
+	if err != nil {
+		return nil, false, err
+	}
+	if !m.contiguous() {
+		return nil, false, ExceptionNewf(BufferError, "memoryview: underlying buffer is not C-contiguous")
+	}
+	if m.length == 0 {
+		return []byte{}, m.readonly, nil
+	}
+	return buf[m.offset : m.offset+m.length*m.itemsize], m.readonly, nil
+}
+
+// Check interface is satisfied
+var _ IBuffer = (*MemoryView)(nil)
+var _ I__repr__ = (*MemoryView)(nil)
+var _ I__len__ = (*MemoryView)(nil)
+var _ I__iter__ = (*MemoryView)(nil)
+var _ I__getitem__ = (*MemoryView)(nil)
+var _ I__setitem__ = (*MemoryView)(nil)
+var _ I__eq__ = (*MemoryView)(nil)
+var _ I__ne__ = (*MemoryView)(nil)
+var _ I__hash__ = (*MemoryView)(nil)
+var _ I__enter__ = (*MemoryView)(nil)
+var _ I__exit__ = (*MemoryView)(nil)
@@ -0,0 +1,82 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMemoryViewFromBytes(t *testing.T) {
+	buf := []byte("hello")
+	m := NewMemoryViewFromBytes(buf, false)
+
+	// Writes through the view and its slices change buf
+	_, err := m.M__setitem__(Int(0), Int('j'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.M__getitem__(NewSlice(Int(1), Int(5), Int(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = v.(*MemoryView).M__setitem__(Int(1), Int('L'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "jelLo" {
+		t.Errorf("want jelLo got %q", buf)
+	}
+
+	// Writes to buf are seen by the view
+	buf[4] = '!'
+	got, err := m.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "jelL!" {
+		t.Errorf("want jelL! got %q", got)
+	}
+
+	// The buffer of a contiguous view is buf itself
+	b, readonly, err := m.Buffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readonly || &b[0] != &buf[0] {
+		t.Errorf("buffer was copied or is read only")
+	}
+	_, _, err = v.(*MemoryView).Buffer()
+	if !IsException(BufferError, err) {
+		t.Errorf("want BufferError from strided view got %v", err)
+	}
+
+	// bytes-like arguments use the view without converting it
+	res, err := Bytes("-").M__add__(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res.(Bytes), []byte("-jelL!")) {
+		t.Errorf("want -jelL! got %q", res)
+	}
+
+	m.Release()
+	_, err = m.M__len__()
+	if !IsException(ValueError, err) {
+		t.Errorf("want ValueError from released view got %v", err)
+	}
+}
+
+func TestMemoryViewReadOnly(t *testing.T) {
+	buf := []byte{1, 2, 3}
+	m := NewMemoryViewFromBytes(buf, true)
+	_, err := m.M__setitem__(Int(0), Int(0))
+	if !IsException(TypeError, err) {
+		t.Errorf("want TypeError got %v", err)
+	}
+	if buf[0] != 1 {
+		t.Errorf("read only buffer was written")
+	}
+}
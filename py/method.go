@@ -27,6 +27,11 @@ type PyCFunctionNoArgs func(Object) (Object, error)
 // Called with one (unnamed) parameter only
 type PyCFunction1Arg func(Object, Object) (Object, error)
 
+// Called with the Context the method was looked up in, self, a tuple
+// of args and a stringdict of kwargs.  The Context is nil if the
+// method wasn't looked up by the interpreter.
+type PyCFunctionContext func(ctx *Context, self Object, args Tuple, kwargs StringDict) (Object, error)
+
 const (
 	// These two constants are not used to indicate the calling convention
 	// but the binding when use with methods of classes. These may not be
@@ -70,6 +75,8 @@ type Method struct {
 	Flags int
 	// Go function implementation
 	method interface{}
+	// Module this method is bound to or nil
+	Module *Module
 }
 
 // Internal method types implemented within eval.go
@@ -86,6 +93,23 @@ const (
 
 var MethodType = NewType("method", "method object")
 
+func init() {
+	MethodType.Dict.Set("__name__", &Property{
+		Fget: func(self Object) (Object, error) {
+			return String(self.(*Method).Name), nil
+		},
+	})
+	MethodType.Dict.Set("__doc__", &Property{
+		Fget: func(self Object) (Object, error) {
+			m := self.(*Method)
+			if m.Doc == "" {
+				return None, nil
+			}
+			return String(m.Doc), nil
+		},
+	})
+}
+
 // Type of this object
 func (o *Method) Type() *Type {
 	return MethodType
@@ -100,6 +124,7 @@ func NewMethod(name string, method interface{}, flags int, doc string) (*Method,
 	case func(self Object, args Tuple, kwargs StringDict) (Object, error):
 	case func(Object) (Object, error):
 	case func(Object, Object) (Object, error):
+	case func(ctx *Context, self Object, args Tuple, kwargs StringDict) (Object, error):
 	case InternalMethod:
 	default:
 		return nil, ExceptionNewf(SystemError, "Unknown function type for NewMethod %q, %T", name, method)
@@ -146,18 +171,22 @@ func (m *Method) Call(self Object, args Tuple) (Object, error) {
 			return nil, ExceptionNewf(TypeError, "%s() takes exactly 1 argument (%d given)", m.Name, len(args))
 		}
 		return f(self, args[0])
+	case func(ctx *Context, self Object, args Tuple, kwargs StringDict) (Object, error):
+		return f(nil, self, args, NewStringDict())
 	}
 	panic(fmt.Sprintf("Unknown method type: %T", m.method))
 }
 
 // Call the method with the given arguments
 func (m *Method) CallWithKeywords(self Object, args Tuple, kwargs StringDict) (Object, error) {
-	if len(kwargs) == 0 {
+	if kwargs.Len() == 0 {
 		return m.Call(self, args)
 	}
 	switch f := m.method.(type) {
 	case func(self Object, args Tuple, kwargs StringDict) (Object, error):
 		return f(self, args, kwargs)
+	case func(ctx *Context, self Object, args Tuple, kwargs StringDict) (Object, error):
+		return f(nil, self, args, kwargs)
 	case func(self Object, args Tuple) (Object, error),
 		func(Object) (Object, error),
 		func(Object, Object) (Object, error):
@@ -166,6 +195,27 @@ func (m *Method) CallWithKeywords(self Object, args Tuple, kwargs StringDict) (O
 	panic(fmt.Sprintf("Unknown method type: %T", m.method))
 }
 
+// Returns whether the method is passed the Context it was looked up in
+func (m *Method) needsContext() bool {
+	_, ok := m.method.(func(ctx *Context, self Object, args Tuple, kwargs StringDict) (Object, error))
+	return ok
+}
+
+// Call the method with the given arguments passing ctx to methods
+// which need the Context
+func (m *Method) CallContext(ctx *Context, self Object, args Tuple, kwargs StringDict) (Object, error) {
+	if f, ok := m.method.(func(ctx *Context, self Object, args Tuple, kwargs StringDict) (Object, error)); ok {
+		if kwargs == nil {
+			kwargs = NewStringDict()
+		}
+		return f(ctx, self, args, kwargs)
+	}
+	if kwargs != nil {
+		return m.CallWithKeywords(self, args, kwargs)
+	}
+	return m.Call(self, args)
+}
+
 // Return a new Method with the bound method passed in, or an error
 //
 // This needs to convert the methods into internally callable python
@@ -231,7 +281,10 @@ func newBoundMethod(name string, fn interface{}) (Object, error) {
 
 // Call a method
 func (m *Method) M__call__(args Tuple, kwargs StringDict) (Object, error) {
-	self := None // FIXME should be the module
+	var self Object = None
+	if m.Module != nil {
+		self = m.Module
+	}
 	if kwargs != nil {
 		return m.CallWithKeywords(self, args, kwargs)
 	}
@@ -262,9 +315,15 @@ func (m *Method) M__ne__(other Object) (Object, error) {
 	return True, nil
 }
 
+// Hashes the identity of the method
+func (m *Method) M__hash__() (Object, error) {
+	return Int(hashPointer(m)), nil
+}
+
 // Make sure it satisfies the interface
 var _ Object = (*Method)(nil)
 var _ I__call__ = (*Method)(nil)
 var _ I__get__ = (*Method)(nil)
 var _ I__eq__ = (*Method)(nil)
 var _ I__ne__ = (*Method)(nil)
+var _ I__hash__ = (*Method)(nil)
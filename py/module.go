@@ -8,22 +8,13 @@ package py
 
 import "fmt"
 
-var (
-	// Registry of installed modules
-	modules = make(map[string]*Module)
-	// Builtin module
-	Builtins *Module
-	// this should be the frozen module importlib/_bootstrap.py generated
-	// by Modules/_freeze_importlib.c into Python/importlib.h
-	Importlib *Module
-)
-
 // A python Module object
 type Module struct {
 	Name    string
 	Doc     string
 	Globals StringDict
 	//	dict Dict
+	Context *Context // the interpreter this module belongs to
 }
 
 var ModuleType = NewType("module", "module object")
@@ -42,50 +33,18 @@ func (m *Module) GetDict() StringDict {
 	return m.Globals
 }
 
-// Define a new module
-func NewModule(name, doc string, methods []*Method, globals StringDict) *Module {
-	m := &Module{
-		Name:    name,
-		Doc:     doc,
-		Globals: globals.Copy(),
-	}
-	// Insert the methods into the module dictionary
-	for _, method := range methods {
-		m.Globals[method.Name] = method
-	}
-	// Set some module globals
-	m.Globals["__name__"] = String(name)
-	m.Globals["__doc__"] = String(doc)
-	m.Globals["__package__"] = None
-	// Register the module
-	modules[name] = m
-	// Make a note of some modules
-	switch name {
-	case "builtins":
-		Builtins = m
-	case "importlib":
-		Importlib = m
+// ModuleContext returns the Context of self, the module passed to the
+// Go function of a module method, or a TypeError if the method wasn't
+// bound to a module
+func ModuleContext(self Object) (*Context, error) {
+	if m, ok := self.(*Module); ok && m != nil && m.Context != nil {
+		return m.Context, nil
 	}
-	// fmt.Printf("Registering module %q\n", name)
-	return m
-}
-
-// Gets a module
-func GetModule(name string) (*Module, error) {
-	m, ok := modules[name]
-	if !ok {
-		return nil, ExceptionNewf(ImportError, "Module %q not found", name)
-	}
-	return m, nil
-}
-
-// Gets a module or panics
-func MustGetModule(name string) *Module {
-	m, err := GetModule(name)
-	if err != nil {
-		panic(err)
+	name := "nil"
+	if self != nil {
+		name = self.Type().Name
 	}
-	return m
+	return nil, ExceptionNewf(TypeError, "module method needs a module, not %s", name)
 }
 
 // Calls a named method of a module
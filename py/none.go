@@ -56,9 +56,17 @@ func (a NoneType) M__ne__(other Object) (Object, error) {
 	return True, nil
 }
 
+// Hash of None, a constant as the address of None isn't meaningful
+const noneHash = 0x7bd5c66f
+
+func (a NoneType) M__hash__() (Object, error) {
+	return Int(noneHash), nil
+}
+
 // Check interface is satisfied
 var _ I__bool__ = None
 var _ I__str__ = None
 var _ I__repr__ = None
 var _ I__eq__ = None
 var _ I__ne__ = None
+var _ I__hash__ = None
@@ -0,0 +1,189 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Sandbox policies
+//
+// A Policy restricts what the code running in a Context may do, for
+// running untrusted scripts.  Anything the script does outside its
+// grant raises a PermissionError.
+
+package py
+
+import (
+	"strings"
+)
+
+// FileAccess controls what files the code may open with open()
+type FileAccess int
+
+const (
+	FileAccessReadWrite FileAccess = iota // files may be read and written
+	FileAccessReadOnly                    // files may only be read
+	FileAccessNone                        // files may not be opened
+)
+
+// UnsafeAttributes are attributes which give access to the internals
+// of the interpreter, such as the globals of functions and the frames
+// of tracebacks.  They are a good starting point for
+// Policy.DenyAttributes.
+var UnsafeAttributes = []string{
+	"__builtins__",
+	"__closure__",
+	"__code__",
+	"__globals__",
+	"__subclasses__",
+	"__tb_frame__",
+	"co_code",
+	"f_back",
+	"f_builtins",
+	"f_code",
+	"f_globals",
+	"f_locals",
+	"gi_code",
+	"gi_frame",
+	"tb_frame",
+}
+
+// A Policy restricts what the code running in a Context may do
+//
+// The zero Policy doesn't restrict anything.
+type Policy struct {
+	// AllowModules, if not nil, lists the only modules which may
+	// be imported.  Allowing a package allows its submodules too.
+	AllowModules []string
+	// DenyModules lists modules, and their submodules, which may
+	// not be imported.
+	DenyModules []string
+	// AllowBuiltins, if not nil, lists the only builtins which are
+	// available.  The __dunder__ builtins the interpreter needs,
+	// such as __import__ and __build_class__, are kept unless they
+	// are denied.
+	AllowBuiltins []string
+	// DenyBuiltins lists builtins which are not available
+	DenyBuiltins []string
+	// FileAccess controls what files may be opened
+	FileAccess FileAccess
+	// DenyAttributes lists attributes which may not be read,
+	// written or deleted, for example UnsafeAttributes
+	DenyAttributes []string
+}
+
+// policy is the compiled form of a Policy used by a Context
+type policy struct {
+	*Policy
+	allowBuiltins  map[string]struct{}
+	denyBuiltins   map[string]struct{}
+	denyAttributes map[string]struct{}
+}
+
+// stringSet makes a set from names, returning nil if names is nil
+func stringSet(names []string) map[string]struct{} {
+	if names == nil {
+		return nil
+	}
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	return set
+}
+
+// newPolicy compiles p or returns nil if p is nil
+func newPolicy(p *Policy) *policy {
+	if p == nil {
+		return nil
+	}
+	return &policy{
+		Policy:         p,
+		allowBuiltins:  stringSet(p.AllowBuiltins),
+		denyBuiltins:   stringSet(p.DenyBuiltins),
+		denyAttributes: stringSet(p.DenyAttributes),
+	}
+}
+
+// moduleMatches returns true if name is one of modules or a submodule
+// of one of them
+func moduleMatches(name string, modules []string) bool {
+	for _, module := range modules {
+		if name == module || strings.HasPrefix(name, module+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinAllowed returns true if the builtin called name is allowed
+func (p *policy) builtinAllowed(name string) bool {
+	if _, found := p.denyBuiltins[name]; found {
+		return false
+	}
+	if p.allowBuiltins == nil || strings.HasPrefix(name, "__") {
+		return true
+	}
+	_, found := p.allowBuiltins[name]
+	return found
+}
+
+// applyPolicy removes the builtins the policy doesn't allow
+func (ctx *Context) applyPolicy() {
+	if ctx.policy == nil {
+		return
+	}
+	for _, name := range ctx.Builtins.Globals.StringKeys() {
+		if !ctx.policy.builtinAllowed(name) {
+			ctx.Builtins.Globals.Delete(name)
+		}
+	}
+}
+
+// CheckImport returns a PermissionError if the module called name may
+// not be imported
+func (ctx *Context) CheckImport(name string) error {
+	p := ctx.policy
+	if p == nil {
+		return nil
+	}
+	if moduleMatches(name, p.DenyModules) || (p.AllowModules != nil && !moduleMatches(name, p.AllowModules)) {
+		return ExceptionNewf(PermissionError, "import of module '%s' is not permitted", name)
+	}
+	return nil
+}
+
+// CheckBuiltin returns a PermissionError if the builtin called name
+// may not be used
+func (ctx *Context) CheckBuiltin(name string) error {
+	if ctx.policy == nil || ctx.policy.builtinAllowed(name) {
+		return nil
+	}
+	return ExceptionNewf(PermissionError, "use of builtin '%s' is not permitted", name)
+}
+
+// CheckAttr returns a PermissionError if the attribute called name
+// may not be accessed.  A nil Context allows everything.
+func (ctx *Context) CheckAttr(name string) error {
+	if ctx == nil || ctx.policy == nil {
+		return nil
+	}
+	if _, found := ctx.policy.denyAttributes[name]; found {
+		return ExceptionNewf(PermissionError, "access to attribute '%s' is not permitted", name)
+	}
+	return nil
+}
+
+// checkOpen returns a PermissionError if filename may not be opened
+// with the file mode
+func (ctx *Context) checkOpen(filename string, mode string) error {
+	if ctx.policy == nil {
+		return nil
+	}
+	switch ctx.policy.FileAccess {
+	case FileAccessNone:
+		return ExceptionNewf(PermissionError, "opening files is not permitted: '%s'", filename)
+	case FileAccessReadOnly:
+		if strings.ContainsAny(mode, "wxa+") {
+			return ExceptionNewf(PermissionError, "opening files for writing is not permitted: '%s'", filename)
+		}
+	}
+	return nil
+}
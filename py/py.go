@@ -5,6 +5,8 @@
 // Python global definitions
 package py
 
+import "io"
+
 // Generate arithmetic boilerplate
 //go:generate go run gen.go
 
@@ -24,15 +26,26 @@ type IGoInt64 interface {
 	GoInt64() (int64, error)
 }
 
+// IBuffer is implemented by objects supporting the buffer protocol
+// which shares their bytes without copying them.  Buffer returns the
+// bytes and whether they are read only.
+type IBuffer interface {
+	Buffer() (buf []byte, readonly bool, err error)
+}
+
 // Some well known objects
 var (
 	// Set in vm/eval.go - to avoid circular import
-	VmRun        func(globals, locals StringDict, code *Code, closure Tuple) (res Object, err error)
+	VmRun        func(ctx *Context, globals, locals StringDict, code *Code, closure Tuple) (res Object, err error)
 	VmRunFrame   func(frame *Frame) (res Object, err error)
-	VmEvalCodeEx func(co *Code, globals, locals StringDict, args []Object, kws StringDict, defs []Object, kwdefs StringDict, closure Tuple) (retval Object, err error)
+	VmEvalCodeEx func(ctx *Context, co *Code, globals, locals StringDict, args []Object, kws StringDict, defs []Object, kwdefs StringDict, closure Tuple) (retval Object, err error)
 
 	// See compile/compile.go - set to avoid circular import
 	Compile func(str, filename, mode string, flags int, dont_inherit bool) (Object, error)
+
+	// See marshal/marshal.go - set to avoid circular import
+	MarshalReadObject  func(r io.Reader) (Object, error)
+	MarshalWriteObject func(w io.Writer, obj Object) error
 )
 
 // Called to create a new instance of class cls. __new__() is a static method (special-cased so you need not declare it as such) that takes the class of which an instance was requested as its first argument. The remaining arguments are those passed to the object constructor expression (the call to the class). The return value of __new__() should be the new object instance (usually an instance of cls).
@@ -0,0 +1,242 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bytecode cache
+//
+// Python files which are imported are compiled once and their code
+// cached in __pycache__/<name>.gpython-34.pyc next to the source as
+// in PEP 3147.  The cache file starts with a 16 byte header as in
+// PEP 552, all little endian
+//
+//	magic  4 bytes  PycMagic
+//	flags  4 bytes  bit 0 set if hash based, bit 1 set to check the hash
+//	mtime  4 bytes  modification time of the source in seconds
+//	size   4 bytes  size of the source
+//
+// where mtime and size are replaced by the 8 byte FNV-1a hash of the
+// source if the file is hash based.  The marshalled code object
+// follows.  PycHeader describes the header and is used by the marshal
+// package to read and write .pyc files too.
+
+package py
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// CacheTag names the __pycache__ files written by this implementation
+const CacheTag = "gpython-34"
+
+// PycMagic starts the __pycache__ files.  It is "gp\r\n" so it can't
+// be mistaken for the magic number of a CPython .pyc file, and it must
+// change whenever the bytecode or the way it is marshalled does.
+const PycMagic uint32 = 'g' | 'p'<<8 | '\r'<<16 | '\n'<<24
+
+// PycInvalidationMode is how a __pycache__ file is checked against
+// the source it was compiled from
+type PycInvalidationMode int
+
+const (
+	PycTimestamp     PycInvalidationMode = iota // the modification time and size of the source are compared
+	PycCheckedHash                              // the hash of the source is compared
+	PycUncheckedHash                            // the cache is assumed to be up to date
+)
+
+// Flags in the pyc header
+const (
+	PycHashBased   = 1 << 0 // Mtime and Size hold the hash of the source
+	PycCheckSource = 1 << 1 // the hash should be checked against the source
+)
+
+// PycHeader is the header at the start of a .pyc file
+type PycHeader struct {
+	Magic uint32
+	Flags uint32
+	// Modification time and size of the source, or the low and high
+	// halves of its hash if the header is hash based
+	Mtime uint32
+	Size  uint32
+}
+
+// newPycHeader returns the header for code compiled from source which
+// was modified at mtime, to be checked against the source with mode
+func newPycHeader(mode PycInvalidationMode, mtime time.Time, source []byte) PycHeader {
+	header := PycHeader{Magic: PycMagic}
+	switch mode {
+	case PycCheckedHash, PycUncheckedHash:
+		header.Flags = PycHashBased
+		if mode == PycCheckedHash {
+			header.Flags |= PycCheckSource
+		}
+		hash := sourceHash(source)
+		header.Mtime = uint32(hash)
+		header.Size = uint32(hash >> 32)
+	default:
+		header.Mtime = uint32(mtime.Unix())
+		header.Size = uint32(len(source))
+	}
+	return header
+}
+
+// Check returns nil if the header is one this implementation can read
+func (header *PycHeader) Check() error {
+	if header.Magic != PycMagic {
+		return ExceptionNewf(ImportError, "bad magic number 0x%08x in .pyc file", header.Magic)
+	}
+	if header.Flags&^(PycHashBased|PycCheckSource) != 0 {
+		return ExceptionNewf(ImportError, "invalid flags 0x%x in .pyc file", header.Flags)
+	}
+	return nil
+}
+
+// upToDate returns true if the header matches the source described by
+// info, reading the source with readSource only if its hash is needed
+func (header *PycHeader) upToDate(info fs.FileInfo, readSource func() ([]byte, error)) bool {
+	switch {
+	case header.Flags&PycHashBased == 0:
+		return header.Mtime == uint32(info.ModTime().Unix()) && header.Size == uint32(info.Size())
+	case header.Flags&PycCheckSource != 0:
+		source, err := readSource()
+		return err == nil && uint64(header.Mtime)|uint64(header.Size)<<32 == sourceHash(source)
+	}
+	return true
+}
+
+// CacheFromSource returns the name of the __pycache__ file for the
+// python file source
+func CacheFromSource(source string) string {
+	dir, file := path.Split(source)
+	file = strings.TrimSuffix(file, ".py")
+	return path.Join(dir, "__pycache__", file+"."+CacheTag+".pyc")
+}
+
+// sourceHash returns the hash of source stored in hash based
+// __pycache__ files
+func sourceHash(source []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(source)
+	return h.Sum64()
+}
+
+// compileFile returns the code of the python file filename, loading
+// it from __pycache__ if that is up to date, otherwise compiling it
+// and writing it there
+//
+// Problems with the cache are ignored so using it is never worse
+// than compiling the source.
+func (ctx *Context) compileFile(filename string) (*Code, error) {
+	info, err := ctx.statFile(filename)
+	if err != nil {
+		return nil, ExceptionNewf(OSError, "Couldn't read %q: %v", filename, err)
+	}
+	var source []byte
+	readSource := func() ([]byte, error) {
+		if source == nil {
+			source, err = ctx.readFile(filename)
+		}
+		return source, err
+	}
+	cached := CacheFromSource(filename)
+	if code := ctx.readCache(cached, info, readSource); code != nil {
+		return code, nil
+	}
+	if _, err = readSource(); err != nil {
+		return nil, ExceptionNewf(OSError, "Couldn't read %q: %v", filename, err)
+	}
+	codeObj, err := Compile(string(source), filename, "exec", 0, true)
+	if err != nil {
+		return nil, err
+	}
+	code, ok := codeObj.(*Code)
+	if !ok {
+		return nil, ExceptionNewf(ImportError, "Compile didn't return code object")
+	}
+	ctx.writeCache(cached, code, info, source)
+	return code, nil
+}
+
+// readCache returns the code from the __pycache__ file cached or nil
+// if it can't be read or is out of date with the source described by
+// info
+func (ctx *Context) readCache(cached string, info fs.FileInfo, readSource func() ([]byte, error)) *Code {
+	if MarshalReadObject == nil {
+		return nil
+	}
+	data, err := ctx.readFile(cached)
+	if err != nil {
+		return nil
+	}
+	r := bytes.NewReader(data)
+	var header PycHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil
+	}
+	if header.Check() != nil || !header.upToDate(info, readSource) {
+		return nil
+	}
+	obj, err := MarshalReadObject(r)
+	if err != nil {
+		return nil
+	}
+	code, _ := obj.(*Code)
+	return code
+}
+
+// writeCache writes code compiled from source described by info to
+// the __pycache__ file cached
+//
+// Nothing is written if sys.dont_write_bytecode is set or the
+// Context can't write files.
+func (ctx *Context) writeCache(cached string, code *Code, info fs.FileInfo, source []byte) {
+	if MarshalWriteObject == nil || ctx.Opts.FS != nil || ctx.dontWriteBytecode() {
+		return
+	}
+	if ctx.policy != nil && ctx.policy.FileAccess != FileAccessReadWrite {
+		return
+	}
+	header := newPycHeader(ctx.Opts.PycInvalidation, info.ModTime(), source)
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, &header); err != nil {
+		return
+	}
+	if err := MarshalWriteObject(&buf, code); err != nil {
+		return
+	}
+	dir := path.Dir(cached)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	// Write a temporary file and rename it so a partly written
+	// cache file is never read
+	f, err := os.CreateTemp(dir, path.Base(cached)+".*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(buf.Bytes())
+	if err == nil {
+		err = f.Chmod(info.Mode().Perm() | 0200)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), cached)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
+
+// dontWriteBytecode returns true if sys.dont_write_bytecode is set
+func (ctx *Context) dontWriteBytecode() bool {
+	value, err := ctx.sysAttr("dont_write_bytecode")
+	return err == nil && ObjectIsTrue(value)
+}
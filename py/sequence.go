@@ -6,6 +6,20 @@
 
 package py
 
+// maxSize is the largest number of bytes or items a single sequence
+// may be made with.  Go can't recover from failing to allocate memory
+// so bigger requests raise MemoryError instead.
+const maxSize Int = 1 << 34
+
+// checkSize returns a MemoryError if a sequence of n items, each of
+// itemSize bytes or items, would be bigger than maxSize
+func checkSize(n Int, itemSize int) error {
+	if itemSize > 0 && n > maxSize/Int(itemSize) {
+		return ExceptionNewf(MemoryError, "sequence too big to allocate")
+	}
+	return nil
+}
+
 // Converts a sequence object v into a Tuple
 func SequenceTuple(v Object) (Tuple, error) {
 	switch x := v.(type) {
@@ -47,18 +61,22 @@ func SequenceList(v Object) (*List, error) {
 func SequenceSet(v Object) (*Set, error) {
 	switch x := v.(type) {
 	case Tuple:
-		return NewSetFromItems(x), nil
+		return NewSetFromItems(x)
 	case *List:
-		return NewSetFromItems(x.Items), nil
+		return NewSetFromItems(x.Items)
 	default:
 		s := NewSet()
+		var addErr error
 		err := Iterate(v, func(item Object) bool {
-			s.Add(item)
-			return false
+			addErr = s.Add(item)
+			return addErr != nil
 		})
 		if err != nil {
 			return nil, err
 		}
+		if addErr != nil {
+			return nil, addErr
+		}
 		return s, nil
 	}
 }
@@ -170,3 +188,39 @@ func SequenceContains(seq, obj Object) (found bool, err error) {
 	}
 	return found, err
 }
+
+// indices converts the optional start and end arguments of methods
+// like find and index into positions as slicing would
+//
+// start may be greater than end if the result is empty.
+func indices(start, end Object, length int) (int, int, error) {
+	i, j := 0, length
+	var err error
+	if start != nil && start != None {
+		i, err = IndexInt(start)
+		if err != nil {
+			return 0, 0, err
+		}
+		if i < 0 {
+			i += length
+			if i < 0 {
+				i = 0
+			}
+		}
+	}
+	if end != nil && end != None {
+		j, err = IndexInt(end)
+		if err != nil {
+			return 0, 0, err
+		}
+		if j < 0 {
+			j += length
+			if j < 0 {
+				j = 0
+			}
+		} else if j > length {
+			j = length
+		}
+	}
+	return i, j, nil
+}
@@ -4,7 +4,8 @@
 
 // Set and FrozenSet types
 //
-// FIXME preliminary implementation only - doesn't work properly!
+// The items are stored in a Dict so they are looked up with their
+// __hash__ and __eq__ methods, and iterate in insertion order.
 
 package py
 
@@ -12,10 +13,8 @@ import "bytes"
 
 var SetType = NewTypeX("set", "set() -> new empty set object\nset(iterable) -> new set object\n\nBuild an unordered collection of unique elements.", SetNew, nil)
 
-type SetValue struct{}
-
 type Set struct {
-	items map[Object]SetValue
+	items *Dict // the items of the set are the keys, the values are None
 }
 
 // Type of this Set object
@@ -26,29 +25,69 @@ func (o *Set) Type() *Type {
 // Make a new empty set
 func NewSet() *Set {
 	return &Set{
-		items: make(map[Object]SetValue),
+		items: NewDict(),
 	}
 }
 
 // Make a new empty set with capacity for n items
 func NewSetWithCapacity(n int) *Set {
 	return &Set{
-		items: make(map[Object]SetValue, n),
+		items: NewDictSized(n),
 	}
 }
 
 // Make a new set with the items passed in
-func NewSetFromItems(items []Object) *Set {
+//
+// It returns an error if any of the items are unhashable.
+func NewSetFromItems(items []Object) (*Set, error) {
 	s := NewSetWithCapacity(len(items))
-	for _, item := range items {
-		s.items[item] = SetValue{}
+	err := s.Update(items)
+	if err != nil {
+		return nil, err
 	}
-	return s
+	return s, nil
 }
 
 // Add an item to the set
-func (s *Set) Add(item Object) {
-	s.items[item] = SetValue{}
+//
+// It returns an error if item is unhashable.
+func (s *Set) Add(item Object) error {
+	_, found, err := s.items.GetItem(item)
+	if err != nil || found {
+		return err
+	}
+	return s.items.SetItem(item, None)
+}
+
+// Extend the set with items
+//
+// It returns an error if any of the items are unhashable.
+func (s *Set) Update(items []Object) error {
+	for _, item := range items {
+		err := s.Add(item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Contains returns whether item is in the set
+//
+// It returns an error if item is unhashable.
+func (s *Set) Contains(item Object) (bool, error) {
+	_, found, err := s.items.GetItem(item)
+	return found, err
+}
+
+// Len returns the number of items in the set
+func (s *Set) Len() int {
+	return s.items.Len()
+}
+
+// Items returns the items of the set in insertion order
+func (s *Set) Items() []Object {
+	return s.items.Keys()
 }
 
 // SetNew
@@ -64,7 +103,7 @@ func SetNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	return NewSet(), nil
 }
 
-var FrozenSetType = NewType("frozenset", "frozenset() -> empty frozenset object\nfrozenset(iterable) -> frozenset object\n\nBuild an immutable unordered collection of unique elements.")
+var FrozenSetType = NewTypeX("frozenset", "frozenset() -> empty frozenset object\nfrozenset(iterable) -> frozenset object\n\nBuild an immutable unordered collection of unique elements.", FrozenSetNew, nil)
 
 type FrozenSet struct {
 	Set
@@ -82,153 +121,331 @@ func NewFrozenSet() *FrozenSet {
 	}
 }
 
-// Make a new set with the items passed in
-func NewFrozenSetFromItems(items []Object) *FrozenSet {
-	return &FrozenSet{
-		Set: *NewSetFromItems(items),
+// Make a new frozen set with the items passed in
+//
+// It returns an error if any of the items are unhashable.
+func NewFrozenSetFromItems(items []Object) (*FrozenSet, error) {
+	s, err := NewSetFromItems(items)
+	if err != nil {
+		return nil, err
 	}
+	return &FrozenSet{Set: *s}, nil
 }
 
-// Extend the set with items
-func (s *Set) Update(items []Object) {
-	for _, item := range items {
-		s.items[item] = SetValue{}
+// FrozenSetNew
+func FrozenSetNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var iterable Object
+	err := UnpackTuple(args, kwargs, "frozenset", 0, 1, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	if iterable == nil {
+		return NewFrozenSet(), nil
+	}
+	if fs, ok := iterable.(*FrozenSet); ok {
+		return fs, nil
+	}
+	s, err := SequenceSet(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &FrozenSet{Set: *s}, nil
+}
+
+// asSet returns the Set inside a set or frozenset
+func asSet(obj Object) (*Set, bool) {
+	switch x := obj.(type) {
+	case *Set:
+		return x, true
+	case *FrozenSet:
+		return &x.Set, true
 	}
+	return nil, false
 }
 
 func (s *Set) M__len__() (Object, error) {
-	return Int(len(s.items)), nil
+	return Int(s.Len()), nil
 }
 
 func (s *Set) M__bool__() (Object, error) {
-	return NewBool(len(s.items) > 0), nil
+	return NewBool(s.Len() > 0), nil
 }
 
-func (s *Set) M__repr__() (Object, error) {
+// repr returns the items of the set as "{a, b}" or "" if empty
+func (s *Set) repr() (string, error) {
+	if s.Len() == 0 {
+		return "", nil
+	}
 	var out bytes.Buffer
 	out.WriteRune('{')
-	spacer := false
-	for item := range s.items {
-		if spacer {
+	for i, item := range s.Items() {
+		if i != 0 {
 			out.WriteString(", ")
 		}
 		str, err := ReprAsString(item)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		out.WriteString(str)
-		spacer = true
 	}
 	out.WriteRune('}')
-	return String(out.String()), nil
+	return out.String(), nil
+}
+
+func (s *Set) M__repr__() (Object, error) {
+	str, err := s.repr()
+	if err != nil {
+		return nil, err
+	}
+	if str == "" {
+		return String("set()"), nil
+	}
+	return String(str), nil
+}
+
+func (s *FrozenSet) M__repr__() (Object, error) {
+	str, err := s.repr()
+	if err != nil {
+		return nil, err
+	}
+	return String("frozenset(" + str + ")"), nil
 }
 
 func (s *Set) M__iter__() (Object, error) {
-	items := make(Tuple, 0, len(s.items))
-	for item := range s.items {
-		items = append(items, item)
+	return NewIterator(s.Items()), nil
+}
+
+func (s *Set) M__contains__(item Object) (Object, error) {
+	// A set looks for the frozenset with the same items as in python
+	if other, ok := item.(*Set); ok {
+		item = &FrozenSet{Set: *other}
+	}
+	found, err := s.Contains(item)
+	if err != nil {
+		return nil, err
 	}
-	return NewIterator(items), nil
+	return NewBool(found), nil
 }
 
-func (s *Set) M__and__(other Object) (Object, error) {
+// and returns a new Set with the items in both s and b
+func (s *Set) and(b *Set) (*Set, error) {
 	ret := NewSet()
-	b, ok := other.(*Set)
-	if !ok {
-		return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", s.Type().Name, other.Type().Name)
-	}
-	for i := range b.items {
-		if _, ok := s.items[i]; ok {
-			ret.items[i] = SetValue{}
+	for _, item := range b.Items() {
+		found, err := s.Contains(item)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			err = ret.Add(item)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 	return ret, nil
 }
 
-func (s *Set) M__or__(other Object) (Object, error) {
-	ret := NewSet()
-	b, ok := other.(*Set)
-	if !ok {
-		return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", s.Type().Name, other.Type().Name)
-	}
-	for j := range s.items {
-		ret.items[j] = SetValue{}
-	}
-	for i := range b.items {
-		if _, ok := s.items[i]; !ok {
-			ret.items[i] = SetValue{}
-		}
+// or returns a new Set with the items in either s or b
+func (s *Set) or(b *Set) (*Set, error) {
+	ret := &Set{items: s.items.Copy()}
+	err := ret.Update(b.Items())
+	if err != nil {
+		return nil, err
 	}
 	return ret, nil
 }
 
-func (s *Set) M__sub__(other Object) (Object, error) {
+// sub returns a new Set with the items in s but not in b
+func (s *Set) sub(b *Set) (*Set, error) {
 	ret := NewSet()
-	b, ok := other.(*Set)
-	if !ok {
-		return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", s.Type().Name, other.Type().Name)
+	for _, item := range s.Items() {
+		found, err := b.Contains(item)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			err = ret.Add(item)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
-	for j := range s.items {
-		ret.items[j] = SetValue{}
+	return ret, nil
+}
+
+// xor returns a new Set with the items in exactly one of s and b
+func (s *Set) xor(b *Set) (*Set, error) {
+	ret, err := s.sub(b)
+	if err != nil {
+		return nil, err
 	}
-	for i := range b.items {
-		if _, ok := s.items[i]; ok {
-			delete(ret.items, i)
+	for _, item := range b.Items() {
+		found, err := s.Contains(item)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			err = ret.Add(item)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 	return ret, nil
 }
 
-func (s *Set) M__xor__(other Object) (Object, error) {
-	ret := NewSet()
-	b, ok := other.(*Set)
+// setOp applies op to s and other returning NotImplemented if other
+// isn't a set or frozenset
+func setOp(s *Set, other Object, op func(a, b *Set) (*Set, error)) (*Set, Object, error) {
+	b, ok := asSet(other)
 	if !ok {
-		return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", s.Type().Name, other.Type().Name)
+		return nil, NotImplemented, nil
+	}
+	ret, err := op(s, b)
+	if err != nil {
+		return nil, nil, err
 	}
-	for j := range s.items {
-		ret.items[j] = SetValue{}
+	return ret, ret, nil
+}
+
+// frozenSetOp applies op to s and other returning a FrozenSet
+func frozenSetOp(s *FrozenSet, other Object, op func(a, b *Set) (*Set, error)) (Object, error) {
+	ret, res, err := setOp(&s.Set, other, op)
+	if ret == nil {
+		return res, err
 	}
-	for i := range b.items {
-		_, ok := s.items[i]
-		if ok {
-			delete(ret.items, i)
-		} else {
-			ret.items[i] = SetValue{}
+	return &FrozenSet{Set: *ret}, nil
+}
+
+func (s *Set) M__and__(other Object) (Object, error) {
+	_, res, err := setOp(s, other, (*Set).and)
+	return res, err
+}
+
+func (s *Set) M__or__(other Object) (Object, error) {
+	_, res, err := setOp(s, other, (*Set).or)
+	return res, err
+}
+
+func (s *Set) M__sub__(other Object) (Object, error) {
+	_, res, err := setOp(s, other, (*Set).sub)
+	return res, err
+}
+
+func (s *Set) M__xor__(other Object) (Object, error) {
+	_, res, err := setOp(s, other, (*Set).xor)
+	return res, err
+}
+
+func (s *FrozenSet) M__and__(other Object) (Object, error) {
+	return frozenSetOp(s, other, (*Set).and)
+}
+
+func (s *FrozenSet) M__or__(other Object) (Object, error) {
+	return frozenSetOp(s, other, (*Set).or)
+}
+
+func (s *FrozenSet) M__sub__(other Object) (Object, error) {
+	return frozenSetOp(s, other, (*Set).sub)
+}
+
+func (s *FrozenSet) M__xor__(other Object) (Object, error) {
+	return frozenSetOp(s, other, (*Set).xor)
+}
+
+// shuffleBits spreads the bits of an item hash for the frozenset hash
+func shuffleBits(h uint64) uint64 {
+	return ((h ^ 89869747) ^ (h << 16)) * 3644798167
+}
+
+// M__hash__ hashes a frozenset with the algorithm CPython uses
+//
+// The result doesn't depend on the order of the items so equal
+// frozensets hash equal.
+func (s *FrozenSet) M__hash__() (Object, error) {
+	var h uint64
+	for _, entry := range s.items.entries {
+		if entry.key != nil {
+			h ^= shuffleBits(uint64(entry.hash))
 		}
 	}
-	return ret, nil
+	h ^= (uint64(s.Len()) + 1) * 1927868237
+	h ^= (h >> 11) ^ (h >> 25)
+	h = h*69069 + 907133923
+	if int64(h) == -1 {
+		h = 590923713
+	}
+	return Int(int64(h)), nil
 }
 
 // Check interface is satisfied
 var _ I__len__ = (*Set)(nil)
 var _ I__bool__ = (*Set)(nil)
 var _ I__iter__ = (*Set)(nil)
+var _ I__contains__ = (*Set)(nil)
+var _ I__hash__ = (*FrozenSet)(nil)
 
-// var _ richComparison = (*Set)(nil)
+var _ richComparison = (*Set)(nil)
 
-func (a *Set) M__eq__(other Object) (Object, error) {
-	b, ok := other.(*Set)
+// isSubset returns whether all the items of a are in b
+func (a *Set) isSubset(b *Set) (bool, error) {
+	if a.Len() > b.Len() {
+		return false, nil
+	}
+	for _, item := range a.Items() {
+		found, err := b.Contains(item)
+		if err != nil || !found {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// subsetCompare compares a and other with isSubset, flipping the
+// arguments if reversed and requiring the sizes to differ if strict
+func (a *Set) subsetCompare(other Object, reversed, strict bool) (Object, error) {
+	b, ok := asSet(other)
 	if !ok {
 		return NotImplemented, nil
 	}
-	if len(a.items) != len(b.items) {
+	if reversed {
+		a, b = b, a
+	}
+	if strict && a.Len() == b.Len() {
 		return False, nil
 	}
-	// FIXME nasty O(n**2) algorithm, waiting for proper hashing!
-	for i := range a.items {
-		for j := range b.items {
-			eq, err := Eq(i, j)
-			if err != nil {
-				return nil, err
-			}
-			if eq == True {
-				goto found
-			}
-		}
+	res, err := a.isSubset(b)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(res), nil
+}
+
+func (a *Set) M__lt__(other Object) (Object, error) {
+	return a.subsetCompare(other, false, true)
+}
+
+func (a *Set) M__le__(other Object) (Object, error) {
+	return a.subsetCompare(other, false, false)
+}
+
+func (a *Set) M__gt__(other Object) (Object, error) {
+	return a.subsetCompare(other, true, true)
+}
+
+func (a *Set) M__ge__(other Object) (Object, error) {
+	return a.subsetCompare(other, true, false)
+}
+
+func (a *Set) M__eq__(other Object) (Object, error) {
+	b, ok := asSet(other)
+	if !ok {
+		return NotImplemented, nil
+	}
+	if a.Len() != b.Len() {
 		return False, nil
-	found:
 	}
-	return True, nil
+	return a.subsetCompare(other, false, false)
 }
 
 func (a *Set) M__ne__(other Object) (Object, error) {
@@ -0,0 +1,98 @@
+// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Standard input, output and error of a Context
+//
+// Each Context has its own sys.stdin, sys.stdout and sys.stderr so
+// the output of the code it runs can be captured separately from
+// that of any other Context.
+
+package py
+
+import (
+	"io"
+	"os"
+)
+
+// SetStdio sets sys.stdin, sys.stdout and sys.stderr and their
+// sys.__stdin__ etc originals to python files reading from stdin and
+// writing to stdout and stderr.  Any which are nil are set to
+// os.Stdin, os.Stdout or os.Stderr.
+//
+// This can be called between runs to give each run its own streams.
+func (ctx *Context) SetStdio(stdin io.Reader, stdout, stderr io.Writer) error {
+	sys, err := ctx.GetModule("sys")
+	if err != nil {
+		return err
+	}
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	for _, std := range []struct {
+		name string
+		file *File
+	}{
+		{"stdin", NewFile(stdin, FileRead)},
+		{"stdout", NewFile(stdout, FileWrite)},
+		{"stderr", NewFile(stderr, FileWrite)},
+	} {
+		sys.Globals.Set(std.name, std.file)
+		sys.Globals.Set("__"+std.name+"__", std.file)
+	}
+	return nil
+}
+
+// Stdout returns a Go writer which writes to the current sys.stdout
+// of the Context, so it honours any redirection done by python code
+func (ctx *Context) Stdout() io.Writer {
+	return &sysWriter{ctx: ctx, name: "stdout", fallback: os.Stdout}
+}
+
+// Stderr returns a Go writer which writes to the current sys.stderr
+// of the Context, so it honours any redirection done by python code
+func (ctx *Context) Stderr() io.Writer {
+	return &sysWriter{ctx: ctx, name: "stderr", fallback: os.Stderr}
+}
+
+// TracebackDump writes the traceback of err to the sys.stderr of the
+// Context
+func (ctx *Context) TracebackDump(err interface{}) {
+	TracebackDumpTo(ctx.Stderr(), err)
+}
+
+// sysWriter is an io.Writer which calls the write method of one of
+// the sys streams
+type sysWriter struct {
+	ctx      *Context
+	name     string
+	fallback io.Writer
+}
+
+// Write p to the sys stream, or to the fallback if sys hasn't been
+// set up
+func (w *sysWriter) Write(p []byte) (int, error) {
+	sys, err := w.ctx.GetModule("sys")
+	if err != nil {
+		return w.fallback.Write(p)
+	}
+	stream, ok := sys.Globals.Lookup(w.name)
+	if !ok || stream == None {
+		return w.fallback.Write(p)
+	}
+	write, err := GetAttrString(stream, "write")
+	if err != nil {
+		return 0, err
+	}
+	_, err = Call(write, Tuple{String(p)}, nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -21,6 +21,9 @@ import (
 	"unicode/utf8"
 )
 
+// Generate the special case mappings
+//go:generate ./make_casing.py
+
 type String string
 
 var StringType = ObjectType.NewType("str",
@@ -65,7 +68,7 @@ func StringEscape(a String, ascii bool) string {
 			}
 			out.WriteRune(c)
 		case c < 0x100:
-			if ascii || strconv.IsPrint(c) {
+			if (ascii && c < 0x7F) || (!ascii && strconv.IsPrint(c)) {
 				out.WriteRune(c)
 			} else {
 				fmt.Fprintf(&out, "\\x%02x", c)
@@ -90,130 +93,968 @@ func StringEscape(a String, ascii bool) string {
 	return out.String()
 }
 
-// standard golang strings.Fields doesn't have a 'first N' argument
-func fieldsN(s string, n int) []string {
+// isSpace reports whether r is whitespace as str.isspace sees it,
+// which includes the ASCII separator characters
+func isSpace(r rune) bool {
+	return unicode.IsSpace(r) || (r >= 0x1c && r <= 0x1f)
+}
+
+// isLineBreak reports whether r ends a line for str.splitlines
+func isLineBreak(r rune) bool {
+	switch r {
+	case '\n', '\r', '\v', '\f', 0x1c, 0x1d, 0x1e, 0x85, 0x2028, 0x2029:
+		return true
+	}
+	return false
+}
+
+// isUpper reports whether r is uppercase, including the characters
+// like Ⅰ which aren't letters
+func isUpper(r rune) bool {
+	return unicode.IsUpper(r) || unicode.Is(unicode.Other_Uppercase, r)
+}
+
+// isLower reports whether r is lowercase, including the characters
+// like ⅰ which aren't letters
+func isLower(r rune) bool {
+	return unicode.IsLower(r) || unicode.Is(unicode.Other_Lowercase, r)
+}
+
+// isCased reports whether r has an upper or lower case
+func isCased(r rune) bool {
+	return isUpper(r) || isLower(r) || unicode.IsTitle(r)
+}
+
+// isCaseIgnorable reports whether r is skipped when looking for the
+// cased letters either side of a capital sigma
+func isCaseIgnorable(r rune) bool {
+	switch r {
+	case '\'', '.', ':', 0xb7, 0x387, 0x55f, 0x5f4, 0x2018, 0x2019, 0x2024, 0x2027, 0xfe13, 0xfe52, 0xfe55, 0xff07, 0xff0e, 0xff1a:
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Lm, unicode.Sk)
+}
+
+// isFinalSigma reports whether the capital sigma at s[i] ends a word
+// so lowercases to a final sigma
+func isFinalSigma(s string, i int) bool {
+	before := strings.TrimRightFunc(s[:i], isCaseIgnorable)
+	r, _ := utf8.DecodeLastRuneInString(before)
+	if before == "" || !isCased(r) {
+		return false
+	}
+	after := strings.TrimLeftFunc(s[i+utf8.RuneLen('Σ'):], isCaseIgnorable)
+	r, _ = utf8.DecodeRuneInString(after)
+	return after == "" || !isCased(r)
+}
+
+// writeCase writes r to out mapped by the first of special which has
+// it, or by the simple case mapping f if none do
+//
+// The special mappings are the ones which map to more than one
+// character, like "ß".upper() == "SS".
+func writeCase(out *strings.Builder, r rune, f func(rune) rune, special ...map[rune]string) {
+	for _, m := range special {
+		if mapped, ok := m[r]; ok {
+			out.WriteString(mapped)
+			return
+		}
+	}
+	out.WriteRune(f(r))
+}
+
+// writeLower writes the lowercase of r, which is at s[i], to out
+func writeLower(out *strings.Builder, s string, i int, r rune) {
+	if r == 'Σ' {
+		if isFinalSigma(s, i) {
+			out.WriteRune('ς')
+		} else {
+			out.WriteRune('σ')
+		}
+		return
+	}
+	writeCase(out, r, unicode.ToLower, lowerSpecial)
+}
+
+// toLower returns s lowercased as str.lower does
+func toLower(s string) string {
+	var out strings.Builder
+	for i, r := range s {
+		writeLower(&out, s, i, r)
+	}
+	return out.String()
+}
+
+// toUpper returns s uppercased as str.upper does
+func toUpper(s string) string {
+	var out strings.Builder
+	for _, r := range s {
+		writeCase(&out, r, unicode.ToUpper, upperSpecial)
+	}
+	return out.String()
+}
+
+// isDigit reports whether r is a digit as str.isdigit sees it, which
+// is a decimal digit or a superscript or subscript digit
+func isDigit(r rune) bool {
+	switch {
+	case unicode.IsDigit(r):
+		return true
+	case r == 0xb2 || r == 0xb3 || r == 0xb9:
+		return true
+	case r == 0x2070 || (r >= 0x2074 && r <= 0x2079):
+		return true
+	case r >= 0x2080 && r <= 0x2089:
+		return true
+	}
+	return false
+}
+
+// isIdentifierStart reports whether an identifier may start with r
+func isIdentifierStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+// isIdentifierContinue reports whether an identifier may contain r
+// after the first character
+func isIdentifierContinue(r rune) bool {
+	return isIdentifierStart(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
+// all returns whether s is not empty and f is true for all its
+// characters
+func (s String) all(f func(rune) bool) Object {
+	if len(s) == 0 {
+		return False
+	}
+	for _, r := range s {
+		if !f(r) {
+			return False
+		}
+	}
+	return True
+}
+
+// find implements find, rfind, index and rindex returning the
+// character position of the substring or -1 if not found
+func (s String) find(args Tuple, name string, reverse bool) (int, error) {
+	var sub, start, end Object
+	err := ParseTuple(args, "U|OO:"+name, &sub, &start, &end)
+	if err != nil {
+		return 0, err
+	}
+	length := s.len()
+	i, j, err := indices(start, end, length)
+	if err != nil || i > j {
+		return -1, err
+	}
+	str := string(s.slice(i, j, length))
+	var n int
+	if reverse {
+		n = strings.LastIndex(str, string(sub.(String)))
+	} else {
+		n = strings.Index(str, string(sub.(String)))
+	}
+	if n < 0 {
+		return -1, nil
+	}
+	return i + utf8.RuneCountInString(str[:n]), nil
+}
+
+// splitArgs parses the arguments of split and rsplit
+func splitArgs(args Tuple, kwargs StringDict, name string) (sep Object, maxsplit int, err error) {
+	var sepObj Object = None
+	var maxsplitObj Object = Int(-1)
+	err = ParseTupleAndKeywords(args, kwargs, "|Oi:"+name, []string{"sep", "maxsplit"}, &sepObj, &maxsplitObj)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch x := sepObj.(type) {
+	case NoneType:
+	case String:
+		if len(x) == 0 {
+			return nil, 0, ExceptionNewf(ValueError, "empty separator")
+		}
+	default:
+		return nil, 0, ExceptionNewf(TypeError, "Can't convert '%s' object to str implicitly", sepObj.Type().Name)
+	}
+	return sepObj, int(maxsplitObj.(Int)), nil
+}
+
+// splitWhitespace splits s on runs of characters for which isSpace
+// is true at most maxsplit times, or without limit if maxsplit is
+// negative, as str.split() does
+func splitWhitespace(s string, maxsplit int, isSpace func(rune) bool) []string {
+	out := []string{}
+	for {
+		s = strings.TrimLeftFunc(s, isSpace)
+		if s == "" {
+			return out
+		}
+		i := strings.IndexFunc(s, isSpace)
+		if maxsplit == 0 || i < 0 {
+			return append(out, s)
+		}
+		out = append(out, s[:i])
+		s = s[i:]
+		maxsplit--
+	}
+}
+
+// rsplitWhitespace is splitWhitespace splitting from the right
+func rsplitWhitespace(s string, maxsplit int, isSpace func(rune) bool) []string {
+	out := []string{}
+	for {
+		s = strings.TrimRightFunc(s, isSpace)
+		if s == "" {
+			break
+		}
+		i := strings.LastIndexFunc(s, isSpace)
+		if maxsplit == 0 || i < 0 {
+			out = append(out, s)
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		out = append(out, s[i+size:])
+		s = s[:i]
+		maxsplit--
+	}
+	reverseStrings(out)
+	return out
+}
+
+// rsplit splits s on sep at most maxsplit times from the right, or
+// without limit if maxsplit is negative
+func rsplit(s, sep string, maxsplit int) []string {
+	out := []string{}
+	for maxsplit != 0 {
+		i := strings.LastIndex(s, sep)
+		if i < 0 {
+			break
+		}
+		out = append(out, s[i+len(sep):])
+		s = s[:i]
+		maxsplit--
+	}
+	out = append(out, s)
+	reverseStrings(out)
+	return out
+}
+
+// reverseStrings reverses ss in place
+func reverseStrings(ss []string) {
+	for i, j := 0, len(ss)-1; i < j; i, j = i+1, j-1 {
+		ss[i], ss[j] = ss[j], ss[i]
+	}
+}
+
+// splitLines splits s at line boundaries keeping the line endings if
+// keepends is set
+func splitLines(s string, keepends bool) []string {
 	out := []string{}
-	cur := []rune{}
-	r := []rune(s)
-	for _, c := range r {
-		//until we have covered the first N elements, multiple white-spaces are 'merged'
-		if n < 0 || len(out) < n {
-			if unicode.IsSpace(c) {
-				if len(cur) > 0 {
-					out = append(out, string(cur))
-					cur = []rune{}
+	for len(s) > 0 {
+		i := strings.IndexFunc(s, isLineBreak)
+		if i < 0 {
+			out = append(out, s)
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		j := i + size
+		if s[i] == '\r' && j < len(s) && s[j] == '\n' {
+			j++
+		}
+		if keepends {
+			out = append(out, s[:j])
+		} else {
+			out = append(out, s[:i])
+		}
+		s = s[j:]
+	}
+	return out
+}
+
+// stringList makes a python list of strings from ss
+func stringList(ss []string) *List {
+	l := NewListSized(len(ss))
+	for i, s := range ss {
+		l.Items[i] = String(s)
+	}
+	return l
+}
+
+// justifyArgs parses the width and optional fill character arguments
+// of center, ljust and rjust returning the amount of padding needed
+func (s String) justifyArgs(args Tuple, name string) (int, string, error) {
+	var width Object
+	var fill Object = String(" ")
+	err := ParseTuple(args, "i|U:"+name, &width, &fill)
+	if err != nil {
+		return 0, "", err
+	}
+	if fill.(String).len() != 1 {
+		return 0, "", ExceptionNewf(TypeError, "The fill character must be exactly one character long")
+	}
+	if err := checkSize(width.(Int), len(fill.(String))); err != nil {
+		return 0, "", err
+	}
+	return int(width.(Int)) - s.len(), string(fill.(String)), nil
+}
+
+// pad returns s with left fill characters before it and right after
+func (s String) pad(left, right int, fill string) String {
+	return String(strings.Repeat(fill, left) + string(s) + strings.Repeat(fill, right))
+}
+
+// expandTabs replaces the tabs in s with enough spaces to reach the
+// next multiple of tabsize columns
+func expandTabs(s string, tabsize int) (string, error) {
+	var out strings.Builder
+	column := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			if tabsize > 0 {
+				n := tabsize - column%tabsize
+				if err := checkSize(Int(out.Len())+Int(n), 1); err != nil {
+					return "", err
 				}
-			} else {
-				cur = append(cur, c)
+				out.WriteString(strings.Repeat(" ", n))
+				column += n
+			}
+		case '\n', '\r':
+			out.WriteRune(r)
+			column = 0
+		default:
+			out.WriteRune(r)
+			column++
+		}
+	}
+	return out.String(), nil
+}
+
+// translate maps each character of s through table as str.translate
+// does
+func (s String) translate(table Object) (Object, error) {
+	var out strings.Builder
+	for _, r := range s {
+		res, err := GetItem(table, Int(r))
+		if err != nil {
+			if IsException(LookupError, err) {
+				out.WriteRune(r)
+				continue
 			}
-			//until we see the next letter, after collecting the first N fields, continue to merge whitespaces
-		} else if len(out) == n && len(cur) == 0 {
-			if !unicode.IsSpace(c) {
-				cur = append(cur, c)
+			return nil, err
+		}
+		switch x := res.(type) {
+		case NoneType:
+		case Int:
+			if x < 0 || x > unicode.MaxRune {
+				return nil, ExceptionNewf(ValueError, "character mapping must be in range(0x110000)")
 			}
-			//now that enough words have been collected, just copy into the last element
-		} else {
-			cur = append(cur, c)
+			out.WriteRune(rune(x))
+		case String:
+			out.WriteString(string(x))
+		default:
+			return nil, ExceptionNewf(TypeError, "character mapping must return integer, None or str")
 		}
 	}
-	if len(cur) > 0 {
-		out = append(out, string(cur))
+	return String(out.String()), nil
+}
+
+// maketrans makes a translation table for str.translate
+func maketrans(self Object, args Tuple) (Object, error) {
+	var x, y, z Object
+	err := UnpackTuple(args, nil, "maketrans", 1, 3, &x, &y, &z)
+	if err != nil {
+		return nil, err
 	}
-	return out
+	table := NewDict()
+	if y == nil {
+		d, ok := x.(*Dict)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "if you give only one argument to maketrans it must be a dict")
+		}
+		for _, item := range d.Items() {
+			key := item[0]
+			switch k := key.(type) {
+			case Int:
+			case String:
+				if k.len() != 1 {
+					return nil, ExceptionNewf(ValueError, "string keys in translate table must be of length 1")
+				}
+				r, _ := utf8.DecodeRuneInString(string(k))
+				key = Int(r)
+			default:
+				return nil, ExceptionNewf(TypeError, "keys in translate table must be strings or integers")
+			}
+			err = table.SetItem(key, item[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return table, nil
+	}
+	from, ok := x.(String)
+	if !ok {
+		return nil, ExceptionNewf(TypeError, "first maketrans argument must be a string if there is a second argument")
+	}
+	to, ok := y.(String)
+	if !ok {
+		return nil, ExceptionNewf(TypeError, "maketrans() argument 2 must be str, not %s", y.Type().Name)
+	}
+	fromRunes, toRunes := []rune(string(from)), []rune(string(to))
+	if len(fromRunes) != len(toRunes) {
+		return nil, ExceptionNewf(ValueError, "the first two maketrans arguments must have equal length")
+	}
+	for i, r := range fromRunes {
+		err = table.SetItem(Int(r), Int(toRunes[i]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if z != nil {
+		del, ok := z.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "maketrans() argument 3 must be str, not %s", z.Type().Name)
+		}
+		for _, r := range del {
+			err = table.SetItem(Int(r), None)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return table, nil
+}
+
+// Encode returns s encoded with the named encoding
+//
+// It supports the utf-8, ascii and latin-1 codecs with the strict,
+// ignore, replace, backslashreplace and xmlcharrefreplace error
+// handlers.
+func (s String) Encode(encoding, errors string) (Bytes, error) {
+	codec, limit, err := lookupCodec(encoding)
+	if err != nil {
+		return nil, err
+	}
+	if codec == "utf-8" {
+		return Bytes(s), nil
+	}
+	out := make(Bytes, 0, len(s))
+	position := 0
+	for _, r := range s {
+		if r < limit {
+			out = append(out, byte(r))
+			position++
+			continue
+		}
+		switch errors {
+		case "strict":
+			return nil, ExceptionNewf(UnicodeEncodeError, "'%s' codec can't encode character '%s' in position %d: ordinal not in range(%d)", codec, escapeRune(r), position, limit)
+		case "ignore":
+		case "replace":
+			out = append(out, '?')
+		case "backslashreplace":
+			out = append(out, escapeRune(r)...)
+		case "xmlcharrefreplace":
+			out = append(out, fmt.Sprintf("&#%d;", r)...)
+		default:
+			return nil, ExceptionNewf(LookupError, "unknown error handler name '%s'", errors)
+		}
+		position++
+	}
+	return out, nil
+}
+
+// lookupCodec returns the canonical name of encoding and the first
+// character it can't encode, which is 0 for utf-8
+func lookupCodec(encoding string) (string, rune, error) {
+	switch strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(encoding)) {
+	case "utf_8", "utf8", "u8", "utf":
+		return "utf-8", 0, nil
+	case "ascii", "us_ascii", "646":
+		return "ascii", 0x80, nil
+	case "latin_1", "latin1", "iso_8859_1", "iso8859_1", "8859", "cp819", "l1":
+		return "latin-1", 0x100, nil
+	}
+	return "", 0, ExceptionNewf(LookupError, "unknown encoding: %s", encoding)
+}
+
+// escapeRune returns the python backslash escape of r
+func escapeRune(r rune) string {
+	switch {
+	case r < 0x100:
+		return fmt.Sprintf("\\x%02x", r)
+	case r < 0x10000:
+		return fmt.Sprintf("\\u%04x", r)
+	}
+	return fmt.Sprintf("\\U%08x", r)
 }
 
 func init() {
-	StringType.Dict["split"] = MustNewMethod("split", func(self Object, args Tuple) (Object, error) {
-		selfStr := self.(String)
-		var value Object = None
-		zeroRemove := true
-		if len(args) > 0 {
-			if _, ok := args[0].(NoneType); !ok {
-				value = args[0]
-				zeroRemove = false
+	StringType.Dict.Set("split", MustNewMethod("split", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		s := string(self.(String))
+		sep, maxsplit, err := splitArgs(args, kwargs, "split")
+		if err != nil {
+			return nil, err
+		}
+		if sep == None {
+			return stringList(splitWhitespace(s, maxsplit, isSpace)), nil
+		}
+		n := -1
+		if maxsplit >= 0 {
+			n = maxsplit + 1
+		}
+		return stringList(strings.SplitN(s, string(sep.(String)), n)), nil
+	}, 0, "S.split(sep=None, maxsplit=-1) -> list of strings\n\nReturn a list of the words in S, using sep as the\ndelimiter string.  If maxsplit is given, at most maxsplit\nsplits are done. If sep is not specified or is None, any\nwhitespace string is a separator and empty strings are\nremoved from the result."))
+
+	StringType.Dict.Set("rsplit", MustNewMethod("rsplit", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		s := string(self.(String))
+		sep, maxsplit, err := splitArgs(args, kwargs, "rsplit")
+		if err != nil {
+			return nil, err
+		}
+		if sep == None {
+			return stringList(rsplitWhitespace(s, maxsplit, isSpace)), nil
+		}
+		return stringList(rsplit(s, string(sep.(String)), maxsplit)), nil
+	}, 0, "S.rsplit(sep=None, maxsplit=-1) -> list of strings\n\nReturn a list of the words in S, using sep as the\ndelimiter string, starting at the end of the string and\nworking to the front.  If maxsplit is given, at most maxsplit\nsplits are done. If sep is not specified, any whitespace string\nis a separator."))
+
+	StringType.Dict.Set("splitlines", MustNewMethod("splitlines", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var keepends Object = False
+		err := ParseTupleAndKeywords(args, kwargs, "|O:splitlines", []string{"keepends"}, &keepends)
+		if err != nil {
+			return nil, err
+		}
+		keep, err := MakeBool(keepends)
+		if err != nil {
+			return nil, err
+		}
+		return stringList(splitLines(string(self.(String)), keep == True)), nil
+	}, 0, "S.splitlines([keepends]) -> list of strings\n\nReturn a list of the lines in S, breaking at line boundaries.\nLine breaks are not included in the resulting list unless keepends\nis given and true."))
+
+	StringType.Dict.Set("format", MustNewMethod("format", func(ctx *Context, self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return self.(String).format(ctx, args, kwargs)
+	}, 0, "S.format(*args, **kwargs) -> str\n\nReturn a formatted version of S, using substitutions from args and kwargs.\nThe substitutions are identified by braces ('{' and '}')."))
+
+	StringType.Dict.Set("join", MustNewMethod("join", func(self, iterable Object) (Object, error) {
+		var parts []string
+		var itemErr error
+		err := Iterate(iterable, func(item Object) bool {
+			s, ok := item.(String)
+			if !ok {
+				itemErr = ExceptionNewf(TypeError, "sequence item %d: expected str instance, %s found", len(parts), item.Type().Name)
+				return true
 			}
+			parts = append(parts, string(s))
+			return false
+		})
+		if err != nil {
+			return nil, err
 		}
-		var maxSplit int = -2
-		if len(args) > 1 {
-			if m, ok := args[1].(Int); ok {
-				maxSplit = int(m)
+		if itemErr != nil {
+			return nil, itemErr
+		}
+		return String(strings.Join(parts, string(self.(String)))), nil
+	}, 0, "S.join(iterable) -> str\n\nReturn a string which is the concatenation of the strings in the\niterable.  The separator between elements is S."))
+
+	for _, strip := range []struct {
+		name  string
+		trim  func(string, string) string
+		space func(string, func(rune) bool) string
+		doc   string
+	}{
+		{"strip", strings.Trim, strings.TrimFunc, "S.strip([chars]) -> str\n\nReturn a copy of the string S with leading and trailing\nwhitespace removed.\nIf chars is given and not None, remove characters in chars instead."},
+		{"lstrip", strings.TrimLeft, strings.TrimLeftFunc, "S.lstrip([chars]) -> str\n\nReturn a copy of the string S with leading whitespace removed.\nIf chars is given and not None, remove characters in chars instead."},
+		{"rstrip", strings.TrimRight, strings.TrimRightFunc, "S.rstrip([chars]) -> str\n\nReturn a copy of the string S with trailing whitespace removed.\nIf chars is given and not None, remove characters in chars instead."},
+	} {
+		strip := strip
+		StringType.Dict.Set(strip.name, MustNewMethod(strip.name, func(self Object, args Tuple) (Object, error) {
+			var chars Object = None
+			err := UnpackTuple(args, nil, strip.name, 0, 1, &chars)
+			if err != nil {
+				return nil, err
+			}
+			s := string(self.(String))
+			switch x := chars.(type) {
+			case NoneType:
+				return String(strip.space(s, isSpace)), nil
+			case String:
+				return String(strip.trim(s, string(x))), nil
 			}
+			return nil, ExceptionNewf(TypeError, "%s arg must be None or str", strip.name)
+		}, 0, strip.doc))
+	}
+
+	StringType.Dict.Set("replace", MustNewMethod("replace", func(self Object, args Tuple) (Object, error) {
+		var old, new Object
+		var count Object = Int(-1)
+		err := ParseTuple(args, "UU|i:replace", &old, &new, &count)
+		if err != nil {
+			return nil, err
 		}
-		valArray := []string{}
-		if valStr, ok := value.(String); ok {
-			valArray = strings.SplitN(string(selfStr), string(valStr), maxSplit+1)
-		} else if _, ok := value.(NoneType); ok {
-			valArray = fieldsN(string(selfStr), maxSplit)
-		} else {
-			return nil, ExceptionNewf(TypeError, "Can't convert '%s' object to str implicitly", value.Type())
+		return String(strings.Replace(string(self.(String)), string(old.(String)), string(new.(String)), int(count.(Int)))), nil
+	}, 0, "S.replace(old, new[, count]) -> str\n\nReturn a copy of S with all occurrences of substring\nold replaced by new.  If the optional argument count is\ngiven, only the first count occurrences are replaced."))
+
+	StringType.Dict.Set("find", MustNewMethod("find", func(self Object, args Tuple) (Object, error) {
+		i, err := self.(String).find(args, "find", false)
+		if err != nil {
+			return nil, err
+		}
+		return Int(i), nil
+	}, 0, "S.find(sub[, start[, end]]) -> int\n\nReturn the lowest index in S where substring sub is found,\nsuch that sub is contained within S[start:end].  Optional\narguments start and end are interpreted as in slice notation.\n\nReturn -1 on failure."))
+
+	StringType.Dict.Set("rfind", MustNewMethod("rfind", func(self Object, args Tuple) (Object, error) {
+		i, err := self.(String).find(args, "rfind", true)
+		if err != nil {
+			return nil, err
+		}
+		return Int(i), nil
+	}, 0, "S.rfind(sub[, start[, end]]) -> int\n\nReturn the highest index in S where substring sub is found,\nsuch that sub is contained within S[start:end].  Optional\narguments start and end are interpreted as in slice notation.\n\nReturn -1 on failure."))
+
+	StringType.Dict.Set("index", MustNewMethod("index", func(self Object, args Tuple) (Object, error) {
+		i, err := self.(String).find(args, "index", false)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, ExceptionNewf(ValueError, "substring not found")
+		}
+		return Int(i), nil
+	}, 0, "S.index(sub[, start[, end]]) -> int\n\nLike S.find() but raise ValueError when the substring is not found."))
+
+	StringType.Dict.Set("rindex", MustNewMethod("rindex", func(self Object, args Tuple) (Object, error) {
+		i, err := self.(String).find(args, "rindex", true)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, ExceptionNewf(ValueError, "substring not found")
+		}
+		return Int(i), nil
+	}, 0, "S.rindex(sub[, start[, end]]) -> int\n\nLike S.rfind() but raise ValueError when the substring is not found."))
+
+	StringType.Dict.Set("count", MustNewMethod("count", func(self Object, args Tuple) (Object, error) {
+		var sub, start, end Object
+		err := ParseTuple(args, "U|OO:count", &sub, &start, &end)
+		if err != nil {
+			return nil, err
+		}
+		s := self.(String)
+		length := s.len()
+		i, j, err := indices(start, end, length)
+		if err != nil {
+			return nil, err
+		}
+		if i > j {
+			return Int(0), nil
 		}
-		o := List{}
-		for _, j := range valArray {
-			if len(j) > 0 || !zeroRemove {
-				o.Items = append(o.Items, String(j))
+		return Int(strings.Count(string(s.slice(i, j, length)), string(sub.(String)))), nil
+	}, 0, "S.count(sub[, start[, end]]) -> int\n\nReturn the number of non-overlapping occurrences of substring sub in\nstring S[start:end].  Optional arguments start and end are\ninterpreted as in slice notation."))
+
+	StringType.Dict.Set("lower", MustNewMethod("lower", func(self Object) (Object, error) {
+		return String(toLower(string(self.(String)))), nil
+	}, 0, "S.lower() -> str\n\nReturn a copy of the string S converted to lowercase."))
+
+	StringType.Dict.Set("upper", MustNewMethod("upper", func(self Object) (Object, error) {
+		return String(toUpper(string(self.(String)))), nil
+	}, 0, "S.upper() -> str\n\nReturn a copy of S converted to uppercase."))
+
+	StringType.Dict.Set("casefold", MustNewMethod("casefold", func(self Object) (Object, error) {
+		var out strings.Builder
+		for _, r := range self.(String) {
+			writeCase(&out, r, unicode.ToLower, caseFold, lowerSpecial)
+		}
+		return String(out.String()), nil
+	}, 0, "S.casefold() -> str\n\nReturn a version of S suitable for caseless comparisons."))
+
+	StringType.Dict.Set("title", MustNewMethod("title", func(self Object) (Object, error) {
+		var out strings.Builder
+		s := string(self.(String))
+		previousCased := false
+		for i, r := range s {
+			if previousCased {
+				writeLower(&out, s, i, r)
+			} else {
+				writeCase(&out, r, unicode.ToTitle, titleSpecial)
 			}
+			previousCased = isCased(r)
 		}
-		return &o, nil
-	}, 0, "split(sub) -> split string with sub.")
-
-	StringType.Dict["startswith"] = MustNewMethod("startswith", func(self Object, args Tuple) (Object, error) {
-		selfStr := string(self.(String))
-		prefix := []string{}
-		if len(args) > 0 {
-			if s, ok := args[0].(String); ok {
-				prefix = append(prefix, string(s))
-			} else if s, ok := args[0].(Tuple); ok {
-				for _, t := range s {
-					if v, ok := t.(String); ok {
-						prefix = append(prefix, string(v))
-					}
-				}
+		return String(out.String()), nil
+	}, 0, "S.title() -> str\n\nReturn a titlecased version of S, i.e. words start with title case\ncharacters, all remaining cased characters have lower case."))
+
+	StringType.Dict.Set("capitalize", MustNewMethod("capitalize", func(self Object) (Object, error) {
+		var out strings.Builder
+		s := string(self.(String))
+		for i, r := range s {
+			if i == 0 {
+				writeCase(&out, r, unicode.ToTitle, titleSpecial)
 			} else {
-				return nil, ExceptionNewf(TypeError, "startswith first arg must be str, unicode, or tuple, not %s", args[0].Type())
+				writeLower(&out, s, i, r)
 			}
-		} else {
-			return nil, ExceptionNewf(TypeError, "startswith() takes at least 1 argument (0 given)")
 		}
-		if len(args) > 1 {
-			if s, ok := args[1].(Int); ok {
-				selfStr = selfStr[s:]
+		return String(out.String()), nil
+	}, 0, "S.capitalize() -> str\n\nReturn a capitalized version of S, i.e. make the first character\nhave upper case and the rest lower case."))
+
+	StringType.Dict.Set("swapcase", MustNewMethod("swapcase", func(self Object) (Object, error) {
+		var out strings.Builder
+		s := string(self.(String))
+		for i, r := range s {
+			switch {
+			case isUpper(r):
+				writeLower(&out, s, i, r)
+			case isLower(r):
+				writeCase(&out, r, unicode.ToUpper, upperSpecial)
+			default:
+				out.WriteRune(r)
 			}
 		}
+		return String(out.String()), nil
+	}, 0, "S.swapcase() -> str\n\nReturn a copy of S with uppercase characters converted to lowercase\nand vice versa."))
+
+	for _, is := range []struct {
+		name string
+		f    func(rune) bool
+		doc  string
+	}{
+		{"isalpha", unicode.IsLetter, "S.isalpha() -> bool\n\nReturn True if all characters in S are alphabetic\nand there is at least one character in S, False otherwise."},
+		{"isalnum", func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }, "S.isalnum() -> bool\n\nReturn True if all characters in S are alphanumeric\nand there is at least one character in S, False otherwise."},
+		{"isdecimal", unicode.IsDigit, "S.isdecimal() -> bool\n\nReturn True if there are only decimal characters in S,\nFalse otherwise."},
+		{"isdigit", isDigit, "S.isdigit() -> bool\n\nReturn True if all characters in S are digits\nand there is at least one character in S, False otherwise."},
+		{"isnumeric", unicode.IsNumber, "S.isnumeric() -> bool\n\nReturn True if there are only numeric characters in S,\nFalse otherwise."},
+		{"isspace", isSpace, "S.isspace() -> bool\n\nReturn True if all characters in S are whitespace\nand there is at least one character in S, False otherwise."},
+	} {
+		is := is
+		StringType.Dict.Set(is.name, MustNewMethod(is.name, func(self Object) (Object, error) {
+			return self.(String).all(is.f), nil
+		}, 0, is.doc))
+	}
 
-		for _, s := range prefix {
-			if strings.HasPrefix(selfStr, s) {
-				return Bool(true), nil
+	StringType.Dict.Set("islower", MustNewMethod("islower", func(self Object) (Object, error) {
+		cased := false
+		for _, r := range self.(String) {
+			if isUpper(r) || unicode.IsTitle(r) {
+				return False, nil
+			}
+			cased = cased || isLower(r)
+		}
+		return NewBool(cased), nil
+	}, 0, "S.islower() -> bool\n\nReturn True if all cased characters in S are lowercase and there is\nat least one cased character in S, False otherwise."))
+
+	StringType.Dict.Set("isupper", MustNewMethod("isupper", func(self Object) (Object, error) {
+		cased := false
+		for _, r := range self.(String) {
+			if isLower(r) || unicode.IsTitle(r) {
+				return False, nil
 			}
+			cased = cased || isUpper(r)
 		}
-		return Bool(false), nil
-	}, 0, "startswith(prefix[, start[, end]]) -> bool")
-
-	StringType.Dict["endswith"] = MustNewMethod("endswith", func(self Object, args Tuple) (Object, error) {
-		selfStr := string(self.(String))
-		suffix := []string{}
-		if len(args) > 0 {
-			if s, ok := args[0].(String); ok {
-				suffix = append(suffix, string(s))
-			} else if s, ok := args[0].(Tuple); ok {
-				for _, t := range s {
-					if v, ok := t.(String); ok {
-						suffix = append(suffix, string(v))
-					}
+		return NewBool(cased), nil
+	}, 0, "S.isupper() -> bool\n\nReturn True if all cased characters in S are uppercase and there is\nat least one cased character in S, False otherwise."))
+
+	StringType.Dict.Set("istitle", MustNewMethod("istitle", func(self Object) (Object, error) {
+		cased, previousCased := false, false
+		for _, r := range self.(String) {
+			switch {
+			case isUpper(r) || unicode.IsTitle(r):
+				if previousCased {
+					return False, nil
 				}
-			} else {
-				return nil, ExceptionNewf(TypeError, "endswith first arg must be str, unicode, or tuple, not %s", args[0].Type())
+				previousCased, cased = true, true
+			case isLower(r):
+				if !previousCased {
+					return False, nil
+				}
+				previousCased, cased = true, true
+			default:
+				previousCased = false
 			}
-		} else {
-			return nil, ExceptionNewf(TypeError, "endswith() takes at least 1 argument (0 given)")
 		}
-		for _, s := range suffix {
-			if strings.HasSuffix(selfStr, s) {
-				return Bool(true), nil
+		return NewBool(cased), nil
+	}, 0, "S.istitle() -> bool\n\nReturn True if S is a titlecased string and there is at least one\ncharacter in S, i.e. upper- and titlecase characters may only\nfollow uncased characters and lowercase characters only cased ones.\nReturn False otherwise."))
+
+	StringType.Dict.Set("isidentifier", MustNewMethod("isidentifier", func(self Object) (Object, error) {
+		s := self.(String)
+		r, size := utf8.DecodeRuneInString(string(s))
+		if len(s) == 0 || !isIdentifierStart(r) {
+			return False, nil
+		}
+		for _, r := range s[size:] {
+			if !isIdentifierContinue(r) {
+				return False, nil
 			}
 		}
-		return Bool(false), nil
-	}, 0, "endswith(suffix[, start[, end]]) -> bool")
+		return True, nil
+	}, 0, "S.isidentifier() -> bool\n\nReturn True if S is a valid identifier according\nto the language definition."))
+
+	StringType.Dict.Set("isprintable", MustNewMethod("isprintable", func(self Object) (Object, error) {
+		for _, r := range self.(String) {
+			if !unicode.IsPrint(r) {
+				return False, nil
+			}
+		}
+		return True, nil
+	}, 0, "S.isprintable() -> bool\n\nReturn True if all characters in S are considered\nprintable in repr() or S is empty, False otherwise."))
+
+	StringType.Dict.Set("partition", MustNewMethod("partition", func(self, sep Object) (Object, error) {
+		s := self.(String)
+		sepStr, ok := sep.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "must be str, not %s", sep.Type().Name)
+		}
+		if len(sepStr) == 0 {
+			return nil, ExceptionNewf(ValueError, "empty separator")
+		}
+		i := strings.Index(string(s), string(sepStr))
+		if i < 0 {
+			return Tuple{s, String(""), String("")}, nil
+		}
+		return Tuple{s[:i], sepStr, s[i+len(sepStr):]}, nil
+	}, 0, "S.partition(sep) -> (head, sep, tail)\n\nSearch for the separator sep in S, and return the part before it,\nthe separator itself, and the part after it.  If the separator is not\nfound, return S and two empty strings."))
+
+	StringType.Dict.Set("rpartition", MustNewMethod("rpartition", func(self, sep Object) (Object, error) {
+		s := self.(String)
+		sepStr, ok := sep.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "must be str, not %s", sep.Type().Name)
+		}
+		if len(sepStr) == 0 {
+			return nil, ExceptionNewf(ValueError, "empty separator")
+		}
+		i := strings.LastIndex(string(s), string(sepStr))
+		if i < 0 {
+			return Tuple{String(""), String(""), s}, nil
+		}
+		return Tuple{s[:i], sepStr, s[i+len(sepStr):]}, nil
+	}, 0, "S.rpartition(sep) -> (head, sep, tail)\n\nSearch for the separator sep in S, starting at the end of S, and return\nthe part before it, the separator itself, and the part after it.  If the\nseparator is not found, return two empty strings and S."))
+
+	StringType.Dict.Set("center", MustNewMethod("center", func(self Object, args Tuple) (Object, error) {
+		s := self.(String)
+		margin, fill, err := s.justifyArgs(args, "center")
+		if err != nil || margin <= 0 {
+			return s, err
+		}
+		width := margin + s.len()
+		left := margin/2 + (margin & width & 1)
+		return s.pad(left, margin-left, fill), nil
+	}, 0, "S.center(width[, fillchar]) -> str\n\nReturn S centered in a string of length width. Padding is\ndone using the specified fill character (default is a space)"))
+
+	StringType.Dict.Set("ljust", MustNewMethod("ljust", func(self Object, args Tuple) (Object, error) {
+		s := self.(String)
+		margin, fill, err := s.justifyArgs(args, "ljust")
+		if err != nil || margin <= 0 {
+			return s, err
+		}
+		return s.pad(0, margin, fill), nil
+	}, 0, "S.ljust(width[, fillchar]) -> str\n\nReturn S left-justified in a Unicode string of length width. Padding is\ndone using the specified fill character (default is a space)."))
+
+	StringType.Dict.Set("rjust", MustNewMethod("rjust", func(self Object, args Tuple) (Object, error) {
+		s := self.(String)
+		margin, fill, err := s.justifyArgs(args, "rjust")
+		if err != nil || margin <= 0 {
+			return s, err
+		}
+		return s.pad(margin, 0, fill), nil
+	}, 0, "S.rjust(width[, fillchar]) -> str\n\nReturn S right-justified in a string of length width. Padding is\ndone using the specified fill character (default is a space)."))
+
+	StringType.Dict.Set("zfill", MustNewMethod("zfill", func(self Object, args Tuple) (Object, error) {
+		var width Object
+		err := ParseTuple(args, "i:zfill", &width)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSize(width.(Int), 1); err != nil {
+			return nil, err
+		}
+		s := self.(String)
+		margin := int(width.(Int)) - s.len()
+		if margin <= 0 {
+			return s, nil
+		}
+		zeros := strings.Repeat("0", margin)
+		if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+			return s[:1] + String(zeros) + s[1:], nil
+		}
+		return String(zeros) + s, nil
+	}, 0, "S.zfill(width) -> str\n\nPad a numeric string S with zeros on the left, to fill a field\nof the specified width. The string S is never truncated."))
+
+	StringType.Dict.Set("expandtabs", MustNewMethod("expandtabs", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var tabsize Object = Int(8)
+		err := ParseTupleAndKeywords(args, kwargs, "|i:expandtabs", []string{"tabsize"}, &tabsize)
+		if err != nil {
+			return nil, err
+		}
+		out, err := expandTabs(string(self.(String)), int(tabsize.(Int)))
+		if err != nil {
+			return nil, err
+		}
+		return String(out), nil
+	}, 0, "S.expandtabs(tabsize=8) -> str\n\nReturn a copy of S where all tab characters are expanded using spaces.\nIf tabsize is not given, a tab size of 8 characters is assumed."))
+
+	StringType.Dict.Set("encode", MustNewMethod("encode", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		var encoding Object = String("utf-8")
+		var errors Object = String("strict")
+		err := ParseTupleAndKeywords(args, kwargs, "|ss:encode", []string{"encoding", "errors"}, &encoding, &errors)
+		if err != nil {
+			return nil, err
+		}
+		return self.(String).Encode(string(encoding.(String)), string(errors.(String)))
+	}, 0, "S.encode(encoding='utf-8', errors='strict') -> bytes\n\nEncode S using the codec registered for encoding. Default encoding\nis 'utf-8'. errors may be given to set a different error\nhandling scheme. Default is 'strict' meaning that encoding errors raise\na UnicodeEncodeError. Other possible values are 'ignore', 'replace' and\n'xmlcharrefreplace' as well as any other name registered with\ncodecs.register_error that can handle UnicodeEncodeErrors."))
 
+	StringType.Dict.Set("maketrans", &StaticMethod{
+		Callable: MustNewMethod("maketrans", maketrans, 0, "str.maketrans(x[, y[, z]]) -> dict (static method)\n\nReturn a translation table usable for str.translate().\nIf there is only one argument, it must be a dictionary mapping Unicode\nordinals (integers) or characters to Unicode ordinals, strings or None.\nCharacter keys will be then converted to ordinals.\nIf there are two arguments, they must be strings of equal length, and\nin the resulting dictionary, each character in x will be mapped to the\ncharacter at the same position in y. If there is a third argument, it\nmust be a string, whose characters will be mapped to None in the result."),
+		Dict:     NewStringDict(),
+	})
+
+	StringType.Dict.Set("translate", MustNewMethod("translate", func(self, table Object) (Object, error) {
+		return self.(String).translate(table)
+	}, 0, "S.translate(table) -> str\n\nReturn a copy of the string S in which each character has been mapped\nthrough the given translation table. The table must implement\nlookup/indexing via __getitem__, for instance a dictionary or list,\nmapping Unicode ordinals to Unicode ordinals, strings, or None. If\nthis operation raises LookupError, the character is left untouched.\nCharacters mapped to None are deleted."))
+
+	StringType.Dict.Set("startswith", MustNewMethod("startswith", func(self Object, args Tuple) (Object, error) {
+		return self.(String).hasAffix(args, "startswith", strings.HasPrefix)
+	}, 0, "S.startswith(prefix[, start[, end]]) -> bool\n\nReturn True if S starts with the specified prefix, False otherwise.\nWith optional start, test S beginning at that position.\nWith optional end, stop comparing S at that position.\nprefix can also be a tuple of strings to try."))
+
+	StringType.Dict.Set("endswith", MustNewMethod("endswith", func(self Object, args Tuple) (Object, error) {
+		return self.(String).hasAffix(args, "endswith", strings.HasSuffix)
+	}, 0, "S.endswith(suffix[, start[, end]]) -> bool\n\nReturn True if S ends with the specified suffix, False otherwise.\nWith optional start, test S beginning at that position.\nWith optional end, stop comparing S at that position.\nsuffix can also be a tuple of strings to try."))
+}
+
+// hasAffix implements startswith and endswith using has to test each
+// of the prefixes or suffixes against s[start:end]
+func (s String) hasAffix(args Tuple, name string, has func(string, string) bool) (Object, error) {
+	var affix, start, end Object
+	err := ParseTuple(args, "O|OO:"+name, &affix, &start, &end)
+	if err != nil {
+		return nil, err
+	}
+	var affixes Tuple
+	switch x := affix.(type) {
+	case String:
+		affixes = Tuple{x}
+	case Tuple:
+		affixes = x
+	default:
+		return nil, ExceptionNewf(TypeError, "%s first arg must be str or a tuple of str, not %s", name, affix.Type().Name)
+	}
+	length := s.len()
+	i, j, err := indices(start, end, length)
+	if err != nil {
+		return nil, err
+	}
+	if i > j {
+		return False, nil
+	}
+	str := string(s.slice(i, j, length))
+	for _, a := range affixes {
+		aStr, ok := a.(String)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "tuple for %s must only contain str, not %s", name, a.Type().Name)
+		}
+		if has(str, string(aStr)) {
+			return True, nil
+		}
+	}
+	return False, nil
 }
 
 // Type of this object
@@ -253,6 +1094,14 @@ func (a String) M__repr__() (Object, error) {
 	return String(out), nil
 }
 
+func (a String) M__format__(spec Object) (Object, error) {
+	s, err := formatSpecString(spec)
+	if err != nil {
+		return nil, err
+	}
+	return formatString(string(a), s)
+}
+
 func (s String) M__bool__() (Object, error) {
 	return NewBool(len(s) > 0), nil
 }
@@ -333,6 +1182,10 @@ func (a String) M__le__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a String) M__hash__() (Object, error) {
+	return Int(hashString(string(a))), nil
+}
+
 func (a String) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToString(other); ok {
 		return NewBool(a == b), nil
@@ -608,3 +1461,5 @@ var _ I__len__ = String("")
 var _ I__bool__ = String("")
 var _ I__getitem__ = String("")
 var _ I__contains__ = String("")
+var _ I__hash__ = String("")
+var _ I__format__ = String("")
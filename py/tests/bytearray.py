@@ -0,0 +1,193 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from libtest import assertRaises, assertRaisesText
+
+doc="constructor"
+assert bytearray() == bytearray(b"")
+assert bytearray(3) == b"\x00\x00\x00"
+assert bytearray([1, 2, 255]) == b"\x01\x02\xff"
+assert bytearray(b"abc") == b"abc"
+assert bytearray("café", "utf-8") == b"caf\xc3\xa9"
+assert bytearray(bytearray(b"x")) == b"x"
+assert bytes(bytearray(b"xy")) == b"xy"
+assert type(bytes(bytearray(b"xy"))) == bytes
+assertRaises(TypeError, bytearray, "abc")
+assertRaises(ValueError, bytearray, [256])
+assertRaises(MemoryError, bytearray, 1 << 62)
+assertRaises(MemoryError, bytes, 1 << 62)
+a = b"abc"
+b = bytearray(a)
+b[0] = 120
+assert a == b"abc"
+assert b == b"xbc"
+
+doc="repr"
+assert repr(bytearray()) == "bytearray(b'')"
+assert repr(bytearray(b"a\x00")) == "bytearray(b'a\\x00')"
+assert str(bytearray(b"hi")) == "bytearray(b'hi')"
+
+doc="comparison"
+assert bytearray(b"abc") == bytearray(b"abc")
+assert bytearray(b"abc") == b"abc"
+assert b"abc" == bytearray(b"abc")
+assert bytearray(b"abc") != b"abd"
+assert bytearray(b"abc") < b"abd"
+assert b"abc" <= bytearray(b"abc")
+assert bytearray(b"b") > bytearray(b"a")
+assert bytearray(b"b") >= b"b"
+assert bytearray(b"a") != "a"
+assertRaises(TypeError, hash, bytearray())
+
+doc="sequence"
+b = bytearray(b"hello")
+assert len(b) == 5
+assert b[1] == 101
+assert b[-1] == 111
+assert b[1:3] == bytearray(b"el")
+assert type(b[1:3]) == bytearray
+assert b[::-1] == b"olleh"
+assert list(b) == [104, 101, 108, 108, 111]
+assert 108 in b
+assert b"ll" in b
+assert b + b"!" == b"hello!"
+assert type(b + b"!") == bytearray
+assert bytearray(b"ab") * 2 == b"abab"
+assert type(bytearray(b"ab") * 2) == bytearray
+assert 2 * bytearray(b"ab") == b"abab"
+assert ord(bytearray(b"a")) == 97
+
+doc="item assignment"
+b = bytearray(b"abc")
+b[0] = 65
+b[-1] = 67
+assert b == b"AbC"
+assertRaisesText(ValueError, "byte must be in range(0, 256)", b.__setitem__, 0, 256)
+assertRaises(IndexError, b.__setitem__, 3, 0)
+assertRaises(TypeError, b.__setitem__, 0, b"a")
+del b[1]
+assert b == b"AC"
+del b[-1]
+assert b == b"A"
+
+doc="slice assignment"
+b = bytearray(b"0123456789")
+b[1:3] = b"ab"
+assert b == b"0ab3456789"
+b[1:3] = b""
+assert b == b"03456789"
+b[0:0] = [120, 121]
+assert b == b"xy03456789"
+b[2:5] = bytearray(b"Z")
+assert b == b"xyZ56789"
+b[:] = b
+assert b == b"xyZ56789"
+b[::2] = b"ABCD"
+assert b == b"AyB5C7D9"
+b[::-1] = b"abcdefgh"
+assert b == b"hgfedcba"
+assertRaisesText(ValueError, "attempt to assign bytes of size 1 to extended slice of size 4", b.__setitem__, slice(None, None, 2), b"x")
+assertRaises(TypeError, b.__setitem__, slice(0, 1), 5)
+b = bytearray(b"0123456789")
+del b[::3]
+assert b == b"124578"
+del b[1:3]
+assert b == b"1578"
+del b[::-1]
+assert b == b""
+
+doc="mutating methods"
+b = bytearray()
+b.append(97)
+b.append(98)
+assert b == b"ab"
+assertRaises(ValueError, b.append, 256)
+assertRaises(TypeError, b.append, b"c")
+b.extend(b"cd")
+b.extend([101, 102])
+b.extend(bytearray(b"g"))
+b.extend(x for x in [104])
+assert b == b"abcdefgh"
+b.extend(b)
+assert b == b"abcdefghabcdefgh"
+b = bytearray(b"ac")
+b.insert(1, 98)
+b.insert(100, 100)
+b.insert(-100, 64)
+assert b == b"@abcd"
+assert b.pop() == 100
+assert b.pop(0) == 64
+assert b == b"abc"
+assertRaisesText(IndexError, "pop index out of range", b.pop, 5)
+b.remove(98)
+assert b == b"ac"
+assertRaisesText(ValueError, "value not found in bytearray", b.remove, 98)
+c = b.copy()
+c.append(1)
+assert b == b"ac"
+b.reverse()
+assert b == b"ca"
+b.clear()
+assert b == b""
+assertRaisesText(IndexError, "pop from empty bytearray", b.pop)
+
+doc="in place operators"
+b = bytearray(b"ab")
+c = b
+b += b"cd"
+assert c is b
+assert b == b"abcd"
+b += bytearray(b"e")
+assert b == b"abcde"
+b *= 2
+assert c is b
+assert b == b"abcdeabcde"
+assertRaises((OverflowError, MemoryError), lambda: b * (1 << 62))
+assertRaises(MemoryError, lambda: bytearray(b"a") * (1 << 62))
+def imul():
+    global b
+    b *= 1 << 62
+assertRaises((OverflowError, MemoryError), imul)
+def imul_big():
+    global b
+    b *= 1 << 58
+assertRaises(MemoryError, imul_big)
+assert b == b"abcdeabcde"
+
+doc="shared methods"
+b = bytearray(b" a,b,c ")
+assert b.strip() == b"a,b,c"
+assert type(b.strip()) == bytearray
+assert b.strip().split(b",") == [b"a", b"b", b"c"]
+assert type(b.split()[0]) == bytearray
+assert b.find(b",") == 2
+assert b.count(b",") == 2
+assert b.replace(b",", b";") == b" a;b;c "
+assert type(b.replace(b",", b";")) == bytearray
+assert bytearray(b"-").join([b"a", b"b"]) == b"a-b"
+assert type(bytearray(b"-").join([b"a", b"b"])) == bytearray
+assert b.startswith(b" a")
+assert bytearray(b"caf\xc3\xa9").decode() == "café"
+assert bytearray(b"\x01\xff").hex() == "01ff"
+assert bytearray.fromhex("01ff") == bytearray(b"\x01\xff")
+assert type(bytearray.fromhex("01")) == bytearray
+b = bytearray(b"a,b")
+assert b.partition(b",") == (b"a", b",", b"b")
+assert [type(x) for x in b.partition(b",")] == [bytearray, bytearray, bytearray]
+assert [type(x) for x in b.rpartition(b";")] == [bytearray, bytearray, bytearray]
+assert type(bytearray(b"a\nb").splitlines()[0]) == bytearray
+assert type(b.upper()) == bytearray
+assert b.upper() == b"A,B"
+assert type(b.lower()) == bytearray
+assert type(b.center(5)) == bytearray
+assert type(b.ljust(1)) == bytearray
+assert b.ljust(1) is not b
+assert type(b.rjust(5)) == bytearray
+assert type(b.zfill(5)) == bytearray
+assert bytearray(b"ab").isalpha()
+assert type(bytearray(b"\t").expandtabs()) == bytearray
+assert type(b.translate(None)) == bytearray
+assert b.translate(None, b",") == b"ab"
+
+doc="finished"
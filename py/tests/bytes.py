@@ -2,6 +2,8 @@
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
 
+from libtest import assertRaises, assertRaisesText
+
 doc="str"
 assert str(b"") == "b''"
 assert str(b"hello") == r"b'hello'"
@@ -20,4 +22,263 @@ llo""") == r"""b'he\nllo'"""
 assert repr(rb"""hel'lo""") == r'''b"hel'lo"'''
 assert repr(b'\x00\x01\x02\x03\x04\x05\x06\x07\x08\t\n\x0b\x0c\r\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f !"#$%&\'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~\x7f\x80\x81\x82\x83\x84\x85\x86\x87\x88\x89\x8a\x8b\x8c\x8d\x8e\x8f\x90\x91\x92\x93\x94\x95\x96\x97\x98\x99\x9a\x9b\x9c\x9d\x9e\x9f\xa0\xa1\xa2\xa3\xa4\xa5\xa6\xa7\xa8\xa9\xaa\xab\xac\xad\xae\xaf\xb0\xb1\xb2\xb3\xb4\xb5\xb6\xb7\xb8\xb9\xba\xbb\xbc\xbd\xbe\xbf\xc0\xc1\xc2\xc3\xc4\xc5\xc6\xc7\xc8\xc9\xca\xcb\xcc\xcd\xce\xcf\xd0\xd1\xd2\xd3\xd4\xd5\xd6\xd7\xd8\xd9\xda\xdb\xdc\xdd\xde\xdf\xe0\xe1\xe2\xe3\xe4\xe5\xe6\xe7\xe8\xe9\xea\xeb\xec\xed\xee\xef\xf0\xf1\xf2\xf3\xf4\xf5\xf6\xf7\xf8\xf9\xfa\xfb\xfc\xfd\xfe\xff') == r"""b'\x00\x01\x02\x03\x04\x05\x06\x07\x08\t\n\x0b\x0c\r\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f !"#$%&\'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~\x7f\x80\x81\x82\x83\x84\x85\x86\x87\x88\x89\x8a\x8b\x8c\x8d\x8e\x8f\x90\x91\x92\x93\x94\x95\x96\x97\x98\x99\x9a\x9b\x9c\x9d\x9e\x9f\xa0\xa1\xa2\xa3\xa4\xa5\xa6\xa7\xa8\xa9\xaa\xab\xac\xad\xae\xaf\xb0\xb1\xb2\xb3\xb4\xb5\xb6\xb7\xb8\xb9\xba\xbb\xbc\xbd\xbe\xbf\xc0\xc1\xc2\xc3\xc4\xc5\xc6\xc7\xc8\xc9\xca\xcb\xcc\xcd\xce\xcf\xd0\xd1\xd2\xd3\xd4\xd5\xd6\xd7\xd8\xd9\xda\xdb\xdc\xdd\xde\xdf\xe0\xe1\xe2\xe3\xe4\xe5\xe6\xe7\xe8\xe9\xea\xeb\xec\xed\xee\xef\xf0\xf1\xf2\xf3\xf4\xf5\xf6\xf7\xf8\xf9\xfa\xfb\xfc\xfd\xfe\xff'"""
 
+doc="sequence"
+b = b"hello"
+assert len(b) == 5
+assert len(b"") == 0
+assert b[0] == 104
+assert b[-1] == 111
+assert b[1:3] == b"el"
+assert b[::-1] == b"olleh"
+assert b[::2] == b"hlo"
+assertRaises(IndexError, lambda: b[5])
+assert list(b"ab") == [97, 98]
+assert [c for c in b"ab"] == [97, 98]
+assert 104 in b
+assert 120 not in b
+assert b"ell" in b
+assert b"" in b
+assert b"elo" not in b
+assert bytearray(b"ll") in b
+assertRaises(TypeError, lambda: "e" in b)
+assertRaises(ValueError, lambda: 256 in b)
+assert b + b" world" == b"hello world"
+assert b + bytearray(b"!") == b"hello!"
+assert type(b + bytearray(b"!")) == bytes
+assert b"ab" * 3 == b"ababab"
+assert 2 * b"ab" == b"abab"
+assert b"ab" * -1 == b""
+assertRaises(TypeError, lambda: b + "x")
+
+doc="decode"
+assert b"abc".decode() == "abc"
+assert b"caf\xc3\xa9".decode("utf-8") == "café"
+assert b"caf\xe9".decode("latin-1") == "café"
+assert b"abc".decode("ascii") == "abc"
+assert b"abc".decode(encoding="ascii") == "abc"
+assertRaisesText(UnicodeDecodeError, "'utf-8' codec can't decode byte 0xff in position 1: invalid start byte", b"a\xffb".decode)
+assertRaisesText(UnicodeDecodeError, "'utf-8' codec can't decode byte 0xc3 in position 0: unexpected end of data", b"\xc3".decode)
+assertRaisesText(UnicodeDecodeError, "'utf-8' codec can't decode byte 0xc3 in position 0: invalid continuation byte", b"\xc3a".decode)
+assertRaisesText(UnicodeDecodeError, "'ascii' codec can't decode byte 0xe9 in position 3: ordinal not in range(128)", b"caf\xe9".decode, "ascii")
+assert b"a\xffb".decode("utf-8", "ignore") == "ab"
+assert b"a\xffb".decode("utf-8", "replace") == "a�b"
+assert b"a\xffb".decode("ascii", "backslashreplace") == "a\\xffb"
+assertRaises(LookupError, b"a".decode, "nope")
+assert "café".encode().decode() == "café"
+
+doc="hex and fromhex"
+assert b"\x00\xab\xff".hex() == "00abff"
+assert b"".hex() == ""
+assert bytes.fromhex("00abFF") == b"\x00\xab\xff"
+assert bytes.fromhex(" 01 02  0a ") == b"\x01\x02\x0a"
+assert bytes.fromhex("") == b""
+assert b"".fromhex("41") == b"A"
+assertRaisesText(ValueError, "non-hexadecimal number found in fromhex() arg at position 1", bytes.fromhex, "0g")
+assertRaisesText(ValueError, "non-hexadecimal number found in fromhex() arg at position 0", bytes.fromhex, "g0")
+assertRaisesText(ValueError, "non-hexadecimal number found in fromhex() arg at position 4", bytes.fromhex, "01 2")
+assertRaises(TypeError, bytes.fromhex, b"01")
+
+doc="find and index"
+b = b"abcabc"
+assert b.find(b"bc") == 1
+assert b.find(b"bc", 2) == 4
+assert b.find(b"bc", 2, 4) == -1
+assert b.find(99) == 2
+assert b.find(b"x") == -1
+assert b.find(b"") == 0
+assert b.find(b"", 7) == -1
+assert b.rfind(b"bc") == 4
+assert b.rfind(b"bc", 0, 5) == 1
+assert b.rfind(97) == 3
+assert b.index(b"c") == 2
+assert b.rindex(b"a") == 3
+assertRaisesText(ValueError, "subsection not found", b.index, b"x")
+assertRaisesText(ValueError, "subsection not found", b.rindex, b"x")
+assertRaises(TypeError, b.find, "a")
+assertRaises(ValueError, b.find, 300)
+
+doc="count"
+assert b"aaaa".count(b"aa") == 2
+assert b"abcabc".count(97) == 2
+assert b"abcabc".count(b"bc", 2) == 1
+assert b"abc".count(b"") == 4
+assert b"abc".count(b"", 1) == 3
+assert b"abc".count(b"x") == 0
+
+doc="split"
+assert b"a b  c".split() == [b"a", b"b", b"c"]
+assert b"  a\tb\nc  ".split() == [b"a", b"b", b"c"]
+assert b"a b c".split(maxsplit=1) == [b"a", b"b c"]
+assert b"a,b,,c".split(b",") == [b"a", b"b", b"", b"c"]
+assert b"a,b,c".split(b",", 1) == [b"a", b"b,c"]
+assert b"a\xffb\xffc".split(b"\xff") == [b"a", b"b", b"c"]
+assert b"".split() == []
+assert b"".split(b",") == [b""]
+assert b"a b c".rsplit(None, 1) == [b"a b", b"c"]
+assert b"a,b,c".rsplit(b",", 1) == [b"a,b", b"c"]
+assertRaisesText(ValueError, "empty separator", b"a".split, b"")
+assertRaises(TypeError, b"a".split, ",")
+
+doc="join"
+assert b",".join([b"a", b"b", b"c"]) == b"a,b,c"
+assert b"".join([]) == b""
+assert b"-".join((b"x", bytearray(b"y"))) == b"x-y"
+assertRaisesText(TypeError, "sequence item 1: expected a bytes-like object, str found", b",".join, [b"a", "b"])
+
+doc="replace"
+assert b"aaa".replace(b"a", b"b") == b"bbb"
+assert b"aaa".replace(b"a", b"b", 2) == b"bba"
+assert b"abc".replace(b"", b"-") == b"-a-b-c-"
+assert b"\xff\xfe".replace(b"", b"-") == b"-\xff-\xfe-"
+assert b"abc".replace(b"", b"-", 2) == b"-a-bc"
+assert b"abc".replace(b"x", b"y") == b"abc"
+assertRaises(TypeError, b"abc".replace, "a", "b")
+
+doc="strip"
+assert b"  ab \t\n".strip() == b"ab"
+assert b"  ab ".lstrip() == b"ab "
+assert b"  ab ".rstrip() == b"  ab"
+assert b"xxabyx".strip(b"xy") == b"ab"
+assert b"xxabyx".lstrip(b"x") == b"abyx"
+assert b"xxabyx".rstrip(b"xy") == b"xxab"
+assert b"\xffab\xff".strip(b"\xff") == b"ab"
+assert b"ab".strip(None) == b"ab"
+assert b"\x1c ab".strip() == b"\x1c ab"
+
+doc="startswith and endswith"
+assert b"hello".startswith(b"he")
+assert not b"hello".startswith(b"lo")
+assert b"hello".startswith((b"x", b"hel"))
+assert b"hello".startswith(b"ll", 2)
+assert b"hello".endswith(b"lo")
+assert b"hello".endswith(b"ell", 0, 4)
+assert b"hello".endswith(bytearray(b"o"))
+assertRaises(TypeError, b"hello".startswith, "h")
+assertRaises(TypeError, b"hello".endswith, ("o", b"o"))
+
+doc="percent formatting"
+assert b"%s and %s" % (b"x", bytearray(b"y")) == b"x and y"
+assert b"%b" % b"z" == b"z"
+assert b"%5s|%-5s|" % (b"ab", b"cd") == b"   ab|cd   |"
+assert b"%.2s" % b"abcdef" == b"ab"
+assert b"%d %i %u" % (1, -2, 3) == b"1 -2 3"
+assert b"%d" % 3.7 == b"3"
+assert b"%5d|%-5d|%05d" % (42, 42, -42) == b"   42|42   |-0042"
+assert b"%+d % d" % (5, 5) == b"+5  5"
+assert b"%x %X %o" % (255, 255, 8) == b"ff FF 10"
+assert b"%#x %#X %#o" % (255, 255, 8) == b"0xff 0XFF 0o10"
+assert b"%#06x" % 255 == b"0x00ff"
+assert b"%.3d" % 7 == b"007"
+assert b"%d" % 12345678901234567890 == b"12345678901234567890"
+assert b"%c%c" % (65, b"B") == b"AB"
+assert b"%f %.2f %e %g" % (1.5, 2.345, 1234.5, 0.0001) == b"1.500000 2.35 1.234500e+03 0.0001"
+assert b"%08.3f" % -3.14159 == b"-003.142"
+assert b"%r %a" % ("x", "é") == b"'x' '\\xe9'"
+assert b"%*d|%-*d" % (4, 1, 3, 2) == b"   1|2  "
+assert b"%.*f" % (1, 2.25) == b"2.2"
+assert b"%(a)s-%(b)d" % {b"a": b"x", b"b": 2} == b"x-2"
+assert b"100%%" % () == b"100%"
+assert b"%s" % (b"a",) == b"a"
+assert b"%s" % b"abc" == b"abc"
+assert bytearray(b"%d") % 1 == bytearray(b"1")
+assert type(bytearray(b"%d") % 1) == bytearray
+class B:
+    def __bytes__(self):
+        return b"custom"
+assert b"%s" % B() == b"custom"
+assertRaisesText(TypeError, "not enough arguments for format string", lambda: b"%s %s" % (b"a",))
+assertRaisesText(TypeError, "not all arguments converted during bytes formatting", lambda: b"%s" % (b"a", b"b"))
+assertRaisesText(ValueError, "incomplete format", lambda: b"abc%" % ())
+assertRaisesText(ValueError, "unsupported format character 'z' (0x7a) at index 1", lambda: b"%z" % 1)
+assertRaisesText(TypeError, "%b requires a bytes-like object, or an object that implements __bytes__, not 'str'", lambda: b"%s" % "x")
+assertRaisesText(TypeError, "%d format: a real number is required, not str", lambda: b"%d" % "x")
+assertRaisesText(TypeError, "%x format: an integer is required, not float", lambda: b"%x" % 1.5)
+assertRaisesText(TypeError, "format requires a mapping", lambda: b"%(a)s" % (1,))
+assertRaisesText(OverflowError, "%c arg not in range(256)", lambda: b"%c" % 256)
+assertRaises((ValueError, MemoryError), lambda: b"%99999999999d" % 1)
+assertRaises((ValueError, MemoryError), lambda: b"%*d" % (1 << 40, 1))
+assertRaisesText(ValueError, "prec too big", lambda: b"%.99999999999f" % 1.5)
+assertRaises(TypeError, lambda: b"%c" % "a")
+
+doc="repeat"
+assert b"ab" * 3 == b"ababab"
+assert 2 * b"ab" == b"abab"
+assert b"ab" * -1 == b""
+assert b"" * (1 << 62) == b""
+assertRaisesText(OverflowError, "repeated bytes are too long", lambda: b"ab" * (1 << 62))
+assertRaises(OverflowError, lambda: b"ab" * (1 << 64))
+assertRaises(MemoryError, lambda: b"a" * (1 << 62))
+assertRaises(MemoryError, lambda: (1 << 62) * b"a")
+
+doc="partition"
+assert b"a,b,c".partition(b",") == (b"a", b",", b"b,c")
+assert b"a,b,c".rpartition(b",") == (b"a,b", b",", b"c")
+assert b"abc".partition(b",") == (b"abc", b"", b"")
+assert b"abc".rpartition(b",") == (b"", b"", b"abc")
+assert b"a::b".partition(bytearray(b"::")) == (b"a", b"::", b"b")
+assertRaisesText(ValueError, "empty separator", b"abc".partition, b"")
+assertRaises(TypeError, b"abc".rpartition, ",")
+
+doc="splitlines"
+assert b"a\nb\r\nc\rd".splitlines() == [b"a", b"b", b"c", b"d"]
+assert b"a\nb\r\n".splitlines(True) == [b"a\n", b"b\r\n"]
+assert b"a\x0bb\x0cc\x1cd".splitlines() == [b"a\x0bb\x0cc\x1cd"]
+assert b"".splitlines() == []
+assert b"\n".splitlines(keepends=True) == [b"\n"]
+
+doc="case"
+assert b"Hello World \xe9".upper() == b"HELLO WORLD \xe9"
+assert b"Hello World \xc9".lower() == b"hello world \xc9"
+
+doc="justify"
+assert b"ab".center(6) == b"  ab  "
+assert b"ab".center(5, b"*") == b"**ab*"
+assert b"abc".center(6, b"*") == b"*abc**"
+assert b"abc".center(2) == b"abc"
+assert b"ab".ljust(4, b"-") == b"ab--"
+assert b"ab".rjust(4, bytearray(b"-")) == b"--ab"
+assert b"ab".rjust(1) == b"ab"
+assertRaisesText(TypeError, "center() argument 2 must be a byte string of length 1, not str", b"ab".center, 5, "*")
+assertRaisesText(TypeError, "ljust() argument 2 must be a byte string of length 1, not bytes", b"ab".ljust, 5, b"**")
+assertRaises(MemoryError, b"a".center, 1 << 62)
+assertRaises(MemoryError, b"a".ljust, 1 << 62)
+assertRaises(MemoryError, b"a".rjust, 1 << 62)
+
+doc="zfill"
+assert b"42".zfill(5) == b"00042"
+assert b"-42".zfill(5) == b"-0042"
+assert b"+42".zfill(2) == b"+42"
+assert b"".zfill(2) == b"00"
+assertRaises(MemoryError, b"1".zfill, 1 << 62)
+
+doc="is"
+assert b"123".isdigit()
+assert not b"12a".isdigit()
+assert not b"".isdigit()
+assert b"abC".isalpha()
+assert not b"ab\xe9".isalpha()
+assert not b"".isalpha()
+assert b" \t\n\r\x0b\x0c".isspace()
+assert not b" \x1c".isspace()
+assert not b"".isspace()
+
+doc="expandtabs"
+assert b"a\tb".expandtabs() == b"a       b"
+assert b"ab\tc\nd\te".expandtabs(4) == b"ab  c\nd   e"
+assert b"\xff\tb".expandtabs(tabsize=2) == b"\xff b"
+assert b"a\tb".expandtabs(0) == b"ab"
+
+doc="translate and maketrans"
+table = bytes.maketrans(b"abc", b"xyz")
+assert len(table) == 256
+assert table[ord("a")] == ord("x")
+assert table[ord("d")] == ord("d")
+assert b"aabbcd".translate(table) == b"xxyyzd"
+assert b"aabbcd".translate(table, b"b") == b"xxzd"
+assert b"aabbcd".translate(None, b"ab") == b"cd"
+assert b"aabbcd".translate(None, delete=b"c") == b"aabbd"
+assert bytearray.maketrans(b"a", b"b") == bytes.maketrans(b"a", b"b")
+assertRaisesText(ValueError, "maketrans arguments must have same length", bytes.maketrans, b"ab", b"c")
+assertRaisesText(ValueError, "translation table must be 256 characters long", b"a".translate, b"abc")
+
 doc="finished"
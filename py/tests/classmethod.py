@@ -16,4 +16,6 @@ assert a.fn(1) == 2
 a.x = 3
 assert a.x == 3
 
+assert A.fn(2) == 3
+
 doc="finished"
@@ -1,7 +1,7 @@
 # Copyright 2018 The go-python Authors.  All rights reserved.
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
-from libtest import assertRaises
+from libtest import assertRaises, assertRaisesText
 
 doc="str"
 assert str({}) == "{}"
@@ -54,4 +54,359 @@ assert a.__ne__({}) == True
 assert a.__eq__({'a': 'b'}) == True
 assert a.__ne__({'a': 'b'}) == False
 
+doc="non string keys"
+a = {1: "one", (2, 3): "tuple", None: "none", 2.5: "float"}
+assert a[1] == "one"
+assert a[(2, 3)] == "tuple"
+assert a[None] == "none"
+assert a[2.5] == "float"
+assert len(a) == 4
+a[None] = "None"
+assert a[None] == "None"
+assert len(a) == 4
+del a[(2, 3)]
+assert (2, 3) not in a
+assert len(a) == 3
+assertRaises(KeyError, lambda: a["missing"])
+try:
+    a[(9, 9)]
+except KeyError as e:
+    assert e.args[0] == (9, 9)
+else:
+    assert False, "KeyError not raised"
+
+doc="equivalent keys"
+a = {1: "int"}
+assert a[1.0] == "int"
+assert a[True] == "int"
+a[1.0] = "float"
+assert len(a) == 1
+assert a[1] == "float"
+a = {0: "zero", False: "false"}
+assert len(a) == 1
+assert a[0] == "false"
+a = {2**70: "big"}
+assert a[2**70] == "big"
+assert a[float(2**70)] == "big"
+
+doc="unhashable keys"
+def setitem(d, k, v):
+    d[k] = v
+assertRaises(TypeError, setitem, {}, [], 1)
+assertRaises(TypeError, setitem, {}, {}, 1)
+assertRaises(TypeError, lambda: {[]: 1})
+assertRaises(TypeError, lambda: [] in {})
+
+doc="user defined keys"
+class Key:
+    def __init__(self, x):
+        self.x = x
+    def __hash__(self):
+        return hash_calls.append(self.x) or self.x % 2
+    def __eq__(self, other):
+        return isinstance(other, Key) and self.x == other.x
+hash_calls = []
+a = {Key(1): "one", Key(3): "three"}
+assert a[Key(1)] == "one"
+assert a[Key(3)] == "three"
+assert Key(5) not in a
+assert len(hash_calls) == 5
+class Plain:
+    pass
+p = Plain()
+a = {p: 1, Plain: 2}
+assert a[p] == 1
+assert a[Plain] == 2
+assert Plain() not in a
+
+doc="keys are equal to themselves and __eq__ is truth tested"
+n = float("nan")
+a = {n: 1}
+assert n in a
+assert a[n] == 1
+t = (n,)
+assert t in {t: 1}
+class Loose:
+    def __hash__(self):
+        return 1
+    def __eq__(self, other):
+        return 1
+a = {Loose(): "loose"}
+assert a[Loose()] == "loose"
+class Never:
+    def __hash__(self):
+        return 1
+    def __eq__(self, other):
+        return 0
+k = Never()
+a = {k: "never"}
+assert a[k] == "never"
+assert Never() not in a
+
+doc="dict()"
+assert dict() == {}
+assert dict({1: 2}) == {1: 2}
+assert dict([(1, 2), [3, 4]]) == {1: 2, 3: 4}
+assert dict(a=1, b=2) == {"a": 1, "b": 2}
+assert dict({"a": 1}, b=2) == {"a": 1, "b": 2}
+assertRaises(ValueError, dict, [(1, 2, 3)])
+assertRaises(TypeError, dict, [1])
+assert type({}) == dict
+assert isinstance({}, dict)
+
+doc="comprehensions and unpacking"
+a = {i: i * i for i in range(5)}
+assert a[4] == 16
+assert len(a) == 5
+def kw(**kwargs):
+    return kwargs
+assert kw(**{"x": 1}) == {"x": 1}
+assertRaises(TypeError, lambda: kw(**{1: 2}))
+g = {"a": 1}
+exec("b = a + 1\ndel a", g)
+assert g["b"] == 2
+assert "a" not in g
+assert eval("x * 2", {"x": 21}) == 42
+g = {}
+exec("def f():\n    return x\nr = globals()", g)
+g["x"] = 5
+assert g["f"]() == 5
+assert g["r"] is g
+
+doc="namespaces are dicts"
+def kw_dict(**kwargs):
+    assert isinstance(kwargs, dict)
+    assert type(kwargs) is dict
+    kwargs[1] = 2
+    return kwargs
+assert kw_dict(a=1) == {"a": 1, 1: 2}
+assert type(globals()) is dict
+assert isinstance(locals(), dict)
+globals()[(1, 2)] = "tuple"
+assert globals()[(1, 2)] == "tuple"
+del globals()[(1, 2)]
+class C:
+    assert type(locals()) is dict
+
+doc="repr of a dict containing itself"
+a = {}
+a[1] = a
+assert repr(a) == "{1: {...}}"
+a = {"x": []}
+a["x"].append(a)
+assert repr(a) == "{'x': [{...}]}"
+assert str(a) == "{'x': [{...}]}"
+
+doc="insertion order"
+a = {}
+for k in ["z", 3, "a", (1, 2), None, "m"]:
+    a[k] = str(k)
+assert list(a) == ["z", 3, "a", (1, 2), None, "m"]
+assert repr(a) == "{'z': 'z', 3: '3', 'a': 'a', (1, 2): '(1, 2)', None: 'None', 'm': 'm'}"
+a["z"] = "zz"
+assert list(a)[0] == "z"
+del a["z"]
+a["z"] = "z"
+assert list(a) == [3, "a", (1, 2), None, "m", "z"]
+assert [k for k, v in a.items()] == [3, "a", (1, 2), None, "m", "z"]
+for i in range(100):
+    a[i + 100] = i
+for i in range(100):
+    del a[i + 100]
+assert list(a) == [3, "a", (1, 2), None, "m", "z"]
+assert dict([(2, 1), (1, 2)]) == {2: 1, 1: 2}
+assert list(dict([(2, 1), (1, 2)])) == [2, 1]
+assert list({c: None for c in ["p", "y", "t", "h", "o", "n"]}) == ["p", "y", "t", "h", "o", "n"]
+
+doc="namespaces iterate in insertion order"
+def kw(**kwargs):
+    return list(kwargs)
+assert kw(c=1, a=2, b=3) == ["c", "a", "b"]
+assert kw(c=1, **{"z": 1, "a": 2}) == ["c", "z", "a"]
+assert list(dict(b=1, a=2)) == ["b", "a"]
+assert list(dict(**{"y": 1, "x": 2})) == ["y", "x"]
+assert repr(kw.__dict__) == "{}"
+class C:
+    z = 1
+    a = 2
+    def m(self): pass
+    names = [k for k in locals() if not k.startswith("__")]
+assert C.names == ["z", "a", "m"]
+g = {}
+exec("b = 1\na = 2", g)
+assert [k for k in g if k != "__builtins__"] == ["b", "a"]
+
+doc="changing a dict during iteration"
+a = {"a": 1, "b": 2}
+for k in a:
+    a[k] = 3
+assert a == {"a": 3, "b": 3}
+def add(it):
+    for k in it:
+        a["c"] = 1
+assertRaisesText(RuntimeError, "dictionary changed size during iteration", add, a)
+del a["c"]
+assertRaisesText(RuntimeError, "dictionary changed size during iteration", add, a.keys())
+del a["c"]
+assertRaisesText(RuntimeError, "dictionary changed size during iteration", add, a.values())
+del a["c"]
+assertRaisesText(RuntimeError, "dictionary changed size during iteration", add, a.items())
+del a["c"]
+def remove(it):
+    for k in it:
+        del a["a"]
+assertRaisesText(RuntimeError, "dictionary changed size during iteration", remove, a)
+def kw(**kwargs):
+    return kwargs
+a = kw(x=1, y=2)
+assertRaisesText(RuntimeError, "dictionary changed size during iteration", add, a)
+
+doc="keys, values and items"
+a = {"a": 1, 2: "b"}
+assert list(a.keys()) == ["a", 2]
+assert list(a.values()) == [1, "b"]
+assert list(a.items()) == [("a", 1), (2, "b")]
+assert len(a.keys()) == 2
+assert "a" in a.keys()
+assert "b" not in a.keys()
+assert "b" in a.values()
+assert ("a", 1) in a.items()
+assert ("a", 2) not in a.items()
+assert 1 not in a.items()
+assert repr(a.keys()) == "dict_keys(['a', 2])"
+assert repr(a.values()) == "dict_values([1, 'b'])"
+assert repr(a.items()) == "dict_items([('a', 1), (2, 'b')])"
+
+doc="views are live"
+a = {1: 2}
+k = a.keys()
+v = a.values()
+i = a.items()
+a[3] = 4
+assert len(k) == 2
+assert list(k) == [1, 3]
+assert list(v) == [2, 4]
+assert list(i) == [(1, 2), (3, 4)]
+del a[1]
+assert list(k) == [3]
+assert 1 not in k
+
+doc="view set operations"
+a = {1: "a", 2: "b", 3: "c"}
+assert a.keys() & {2, 3, 4} == {2, 3}
+assert a.keys() & [2, 5] == {2}
+assert {2, 3, 4} & a.keys() == {2, 3}
+assert a.keys() | {4} == {1, 2, 3, 4}
+assert [4] | a.keys() == {1, 2, 3, 4}
+assert a.keys() - {1} == {2, 3}
+assert {1, 5} - a.keys() == {5}
+assert a.keys() ^ {1, 5} == {2, 3, 5}
+assert a.keys() == {1, 2, 3}
+assert {1, 2, 3} == a.keys()
+assert a.keys() != {1, 2}
+assert a.keys() == {3: 0, 2: 0, 1: 0}.keys()
+assert a.items() & {(1, "a"), (2, "x")} == {(1, "a")}
+assert a.items() - {(1, "a")} == {(2, "b"), (3, "c")}
+assert a.items() == {(1, "a"), (2, "b"), (3, "c")}
+assertRaises(TypeError, lambda: a.keys() & 1)
+
+doc="get"
+a = {"a": 1, 2: 3}
+assert a.get("a") == 1
+assert a.get(2) == 3
+assert a.get("b") is None
+assert a.get("b", 4) == 4
+assertRaises(TypeError, a.get)
+assertRaises(TypeError, a.get, [])
+
+doc="pop"
+a = {"a": 1, 2: 3}
+assert a.pop("a") == 1
+assert a == {2: 3}
+assert a.pop("a", 5) == 5
+assertRaises(KeyError, a.pop, "a")
+assert a.pop(2.0) == 3
+assert a == {}
+
+doc="popitem"
+a = {"a": 1, 2: 3}
+assert a.popitem() == (2, 3)
+assert a.popitem() == ("a", 1)
+try:
+    a.popitem()
+except KeyError as e:
+    assert e.args[0] == "popitem(): dictionary is empty"
+else:
+    assert False, "KeyError not raised"
+
+doc="setdefault"
+a = {}
+assert a.setdefault("a", 1) == 1
+assert a.setdefault("a", 2) == 1
+assert a.setdefault(3) is None
+assert a == {"a": 1, 3: None}
+
+doc="update"
+a = {1: 2}
+assert a.update({3: 4}) is None
+assert a == {1: 2, 3: 4}
+a.update([(5, 6)], x=7)
+assert a == {1: 2, 3: 4, 5: 6, "x": 7}
+a.update(y=8)
+assert a["y"] == 8
+a.update(a.items())
+assert len(a) == 5
+assertRaises(TypeError, a.update, 1)
+assertRaises(ValueError, a.update, [(1, 2, 3)])
+
+doc="clear and copy"
+a = {1: 2, "a": "b"}
+b = a.copy()
+a.clear()
+assert a == {}
+assert len(a) == 0
+assert b == {1: 2, "a": "b"}
+a[3] = 4
+assert a == {3: 4}
+assert b is not b.copy()
+
+doc="fromkeys"
+assert dict.fromkeys([1, "a"]) == {1: None, "a": None}
+assert dict.fromkeys((1, 2), 0) == {1: 0, 2: 0}
+assert {}.fromkeys([3]) == {3: None}
+assert list(dict.fromkeys([3, 1, 3, 2])) == [3, 1, 2]
+assertRaises(TypeError, dict.fromkeys, [[1]])
+
+doc="__delitem__ and __len__"
+a = {1: 2, 3: 4}
+a.__delitem__(1)
+assert a.__len__() == 1
+assertRaises(KeyError, a.__delitem__, 1)
+
+doc="namespace dict methods"
+def kw(**kwargs):
+    return kwargs
+a = kw(b=1, a=2)
+assert list(a.keys()) == ["b", "a"]
+assert list(a.values()) == [1, 2]
+assert list(a.items()) == [("b", 1), ("a", 2)]
+assert a.keys() & {"a"} == {"a"}
+k = a.keys()
+a["c"] = 3
+assert len(k) == 3
+assert a.get(1) is None
+assert 1 not in a
+assert a.pop("c") == 3
+assertRaises(KeyError, a.pop, "c")
+assert a.setdefault("d", 4) == 4
+a.update({"e": 5}, f=6)
+assert a.popitem() == ("f", 6)
+assert a.__len__() == 4
+a.__delitem__("e")
+b = a.copy()
+a.clear()
+assert len(a) == 0
+assert len(b) == 3
+assertRaises(KeyError, lambda: a["x"])
+
 doc="finished"
@@ -2,7 +2,7 @@
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
 
-from libtest import assertRaises
+from libtest import assertRaises, assertRaisesText
 
 doc="str"
 assert str([]) == "[]"
@@ -17,6 +17,10 @@ assert repr([1,2,3]) == "[1, 2, 3]"
 assert repr([1,[2,3],4]) == "[1, [2, 3], 4]"
 assert repr(["1",[2.5,17,[]]]) == "['1', [2.5, 17, []]]"
 assert repr([1, 1.0]) == "[1, 1.0]"
+a = [1]
+a.append(a)
+assert repr(a) == "[1, [...]]"
+assert repr([a, a]) == "[[1, [...]], [1, [...]]]"
 
 doc="enumerate"
 a = [e for e in enumerate([3,4,5,6,7], 4)]
@@ -143,4 +147,230 @@ except TypeError:
 else:
     assert False, "TypeError not raised"
 
+doc="extend"
+a = [1]
+a.extend((2, 3))
+a.extend(x for x in ["a", "b"])
+a.extend(a)
+assert a == [1, 2, 3, "a", "b", 1, 2, 3, "a", "b"]
+assertRaises(TypeError, a.extend, 1)
+
+doc="insert"
+a = [1, 2, 3]
+a.insert(0, 0)
+assert a == [0, 1, 2, 3]
+a.insert(2, "x")
+assert a == [0, 1, "x", 2, 3]
+a.insert(100, 4)
+assert a == [0, 1, "x", 2, 3, 4]
+a.insert(-1, "y")
+assert a == [0, 1, "x", 2, 3, "y", 4]
+a.insert(-100, "z")
+assert a == ["z", 0, 1, "x", 2, 3, "y", 4]
+a = []
+a.insert(5, 1)
+assert a == [1]
+assertRaises(TypeError, a.insert, 1)
+assertRaises(TypeError, a.insert, "a", 1)
+
+doc="pop"
+a = [1, 2, 3, 4]
+assert a.pop() == 4
+assert a == [1, 2, 3]
+assert a.pop(0) == 1
+assert a == [2, 3]
+assert a.pop(-2) == 2
+assert a == [3]
+assertRaisesText(IndexError, "pop index out of range", a.pop, 1)
+assertRaisesText(IndexError, "pop index out of range", a.pop, -2)
+assert a.pop() == 3
+assertRaisesText(IndexError, "pop from empty list", a.pop)
+
+doc="remove"
+a = [1, 2, 3, 2]
+a.remove(2)
+assert a == [1, 3, 2]
+a.remove(2.0)
+assert a == [1, 3]
+assertRaisesText(ValueError, "list.remove(x): x not in list", a.remove, 5)
+assert a == [1, 3]
+
+doc="index"
+a = ["a", "b", "c", "b", "a"]
+assert a.index("b") == 1
+assert a.index("b", 2) == 3
+assert a.index("a", -1) == 4
+assert a.index("b", 0, 2) == 1
+assert a.index("b", -4, -1) == 1
+assert a.index("a", 1, 100) == 4
+assertRaisesText(ValueError, "'b' is not in list", a.index, "b", 0, 1)
+assertRaisesText(ValueError, "'z' is not in list", a.index, "z")
+assertRaises(ValueError, a.index, "a", 10)
+assertRaises(TypeError, a.index)
+assertRaises(TypeError, a.index, "a", "b")
+
+doc="count"
+a = [1, 2, 1.0, "1", [1], 1]
+assert a.count(1) == 3
+assert a.count("1") == 1
+assert a.count([1]) == 1
+assert a.count(5) == 0
+assert [].count(1) == 0
+
+doc="reverse"
+a = [1, 2, 3]
+assert a.reverse() is None
+assert a == [3, 2, 1]
+a = [1, 2, 3, 4]
+a.reverse()
+assert a == [4, 3, 2, 1]
+a = []
+a.reverse()
+assert a == []
+
+doc="copy and clear"
+a = [1, [2]]
+b = a.copy()
+assert b == a
+assert b is not a
+assert b[1] is a[1]
+b.append(3)
+assert a == [1, [2]]
+assert a.clear() is None
+assert a == []
+assert b == [1, [2], 3]
+
+doc="slice assignment"
+a = [0, 1, 2, 3, 4, 5]
+a[1:3] = ["a", "b", "c"]
+assert a == [0, "a", "b", "c", 3, 4, 5]
+a[1:4] = []
+assert a == [0, 3, 4, 5]
+a[2:2] = ("x", "y")
+assert a == [0, 3, "x", "y", 4, 5]
+a[4:1] = [9]
+assert a == [0, 3, "x", "y", 9, 4, 5]
+a[:] = a
+assert a == [0, 3, "x", "y", 9, 4, 5]
+a[len(a):] = a
+assert a == [0, 3, "x", "y", 9, 4, 5, 0, 3, "x", "y", 9, 4, 5]
+a = list(range(10))
+a[::2] = ("a", "b", "c", "d", "e")
+assert a == ["a", 1, "b", 3, "c", 5, "d", 7, "e", 9]
+a[::-3] = [10, 20, 30, 40]
+assert a == [40, 1, "b", 30, "c", 5, 20, 7, "e", 10]
+a[8:2:-2] = (1, 2, 3)
+assert a == [40, 1, "b", 30, 3, 5, 2, 7, 1, 10]
+assertRaisesText(ValueError, "attempt to assign sequence of size 2 to extended slice of size 3", a.__setitem__, slice(8, 2, -2), [1, 2])
+a[1:1:-1] = []
+assert a == [40, 1, "b", 30, 3, 5, 2, 7, 1, 10]
+
+doc="slice deletion"
+a = list(range(10))
+del a[::2]
+assert a == [1, 3, 5, 7, 9]
+a = list(range(10))
+del a[::-2]
+assert a == [0, 2, 4, 6, 8]
+a = list(range(10))
+del a[7:1:-3]
+assert a == [0, 1, 2, 3, 5, 6, 8, 9]
+a = list(range(10))
+del a[1:8:3]
+assert a == [0, 2, 3, 5, 6, 8, 9]
+a = list(range(10))
+del a[5:2]
+assert a == list(range(10))
+del a[2:5]
+assert a == [0, 1, 5, 6, 7, 8, 9]
+del a[::-1]
+assert a == []
+
+doc="sort stability and keys"
+a = [("b", 2), ("a", 2), ("c", 1), ("d", 1), ("e", 3)]
+a.sort(key=lambda t: t[1])
+assert a == [("c", 1), ("d", 1), ("b", 2), ("a", 2), ("e", 3)]
+a.sort(key=lambda t: t[1], reverse=True)
+assert a == [("e", 3), ("b", 2), ("a", 2), ("c", 1), ("d", 1)]
+calls = []
+def key(x):
+    calls.append(x)
+    return -x
+a = [3, 1, 4, 1, 5, 9, 2, 6]
+a.sort(key=key)
+assert a == [9, 6, 5, 4, 3, 2, 1, 1]
+assert sorted(calls) == [1, 1, 2, 3, 4, 5, 6, 9]
+a = list(range(100))
+a.sort(key=lambda x: x % 3)
+assert a == list(range(0, 100, 3)) + list(range(1, 100, 3)) + list(range(2, 100, 3))
+a.sort(key=lambda x: x % 3, reverse=True)
+assert a == list(range(2, 100, 3)) + list(range(1, 100, 3)) + list(range(0, 100, 3))
+
+doc="sort exceptions"
+def badkey(x):
+    if x == 3:
+        raise ZeroDivisionError("bad key")
+    return x
+a = [5, 3, 1]
+assertRaisesText(ZeroDivisionError, "bad key", a.sort, key=badkey)
+assert a == [5, 3, 1]
+class Bad:
+    def __lt__(self, other):
+        raise OverflowError("bad lt")
+a = [Bad(), Bad()]
+assertRaisesText(OverflowError, "bad lt", a.sort)
+assertRaisesText(OverflowError, "bad lt", sorted, [1, 2], key=lambda x: Bad())
+class Rev:
+    def __init__(self, v):
+        self.v = v
+    def __lt__(self, other):
+        return self.v > other.v
+a = [Rev(1), Rev(3), Rev(2)]
+a.sort()
+assert [x.v for x in a] == [3, 2, 1]
+
+doc="sort modifying the list"
+a = [3, 1, 2]
+lengths = []
+def key(x):
+    lengths.append(len(a))
+    return x
+a.sort(key=key)
+assert a == [1, 2, 3]
+assert lengths == [0, 0, 0]
+def append(x):
+    a.append(x)
+    return x
+a = [3, 1, 2]
+assertRaisesText(ValueError, "list modified during sort", a.sort, key=append)
+assert a == [1, 2, 3]
+def appendpop(x):
+    a.append(x)
+    a.pop()
+    return x
+a = [3, 1, 2]
+assertRaisesText(ValueError, "list modified during sort", a.sort, key=appendpop)
+assert a == [1, 2, 3]
+def clear(x):
+    a.clear()
+    return x
+a = [3, 1, 2]
+a.sort(key=clear)
+assert a == [1, 2, 3]
+def appendclear(x):
+    a.append(x)
+    a.clear()
+    return x
+assertRaisesText(ValueError, "list modified during sort", a.sort, key=appendclear)
+assert a == [1, 2, 3]
+class Modify:
+    def __init__(self, v):
+        self.v = v
+    def __lt__(self, other):
+        a.insert(0, 1)
+        return self.v < other.v
+a = [Modify(2), Modify(1)]
+assertRaisesText(ValueError, "list modified during sort", a.sort)
+assert [x.v for x in a] == [1, 2]
+
 doc="finished"
@@ -0,0 +1,206 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from libtest import assertRaises, assertRaisesText
+
+doc="new"
+m = memoryview(b"hello")
+assert len(m) == 5
+assert m.readonly
+assert m.format == "B"
+assert m.itemsize == 1
+assert m.nbytes == 5
+assert m.ndim == 1
+assert m.shape == (5,)
+assert m.strides == (1,)
+assert m.contiguous
+b = bytearray(b"abc")
+assert memoryview(b).obj is b
+assert memoryview(memoryview(b)).obj is b
+assert not memoryview(bytearray(b"x")).readonly
+assertRaisesText(TypeError, "memoryview: a bytes-like object is required, not 'str'", memoryview, "abc")
+assertRaises(TypeError, memoryview, 1)
+assertRaises(TypeError, memoryview)
+
+doc="getitem"
+m = memoryview(b"hello")
+assert m[0] == 104
+assert m[-1] == 111
+assertRaisesText(IndexError, "index out of bounds on dimension 1", lambda: m[5])
+assertRaises(IndexError, lambda: m[-6])
+assert m[1:3].tobytes() == b"el"
+assert m[::2].tobytes() == b"hlo"
+assert m[::-1].tobytes() == b"olleh"
+assert m[4:1:-2].tobytes() == b"ol"
+assert m[3:1].tobytes() == b""
+assert m[1:4][1:].tobytes() == b"ll"
+assert m[::2].strides == (2,)
+assert not m[::2].contiguous
+assert list(m) == [104, 101, 108, 108, 111]
+assert m.tolist() == [104, 101, 108, 108, 111]
+assert m.hex() == "68656c6c6f"
+
+doc="setitem"
+a = bytearray(b"hello")
+m = memoryview(a)
+m[0] = 72
+assert a == bytearray(b"Hello")
+m[1:3] = b"EL"
+assert a == bytearray(b"HELlo")
+m[::2] = b"xyz"
+assert a == bytearray(b"xEylz")
+v = m[1:4]
+v[0] = 0
+assert a == bytearray(b"x\x00ylz")
+a[4] = 33
+assert m[4] == 33
+m[:2] = m[3:]
+assert a == bytearray(b"l!yl!")
+assertRaisesText(ValueError, "memoryview: invalid value for format 'B'", m.__setitem__, 0, 256)
+assertRaisesText(TypeError, "memoryview: invalid type for format 'B'", m.__setitem__, 0, "a")
+assertRaisesText(ValueError, "memoryview assignment: lvalue and rvalue have different structures", m.__setitem__, slice(0, 2), b"abc")
+assertRaises(IndexError, m.__setitem__, 5, 0)
+m = memoryview(b"abc")
+assertRaisesText(TypeError, "cannot modify read-only memory", m.__setitem__, 0, 1)
+
+doc="bytes-like"
+a = bytearray(b"hello world")
+m = memoryview(a)
+assert bytes(m[6:]) == b"world"
+assert bytearray(m[:5]) == bytearray(b"hello")
+assert b"-".join([m[:5], m[6:]]) == b"hello-world"
+assert b"hello world".startswith(m[:5])
+assert b"xy" + memoryview(b"z") == b"xyz"
+assert m[::2].tobytes() == b"hlowrd"
+assert bytes(m[::2]) == b"hlowrd"
+
+doc="eq"
+m = memoryview(b"abc")
+assert m == b"abc"
+assert b"abc" == m
+assert m == bytearray(b"abc")
+assert m == memoryview(b"abc")
+assert m != b"abd"
+assert m != b"ab"
+assert m[::-1] == b"cba"
+assert not (m == "abc")
+assert m == m
+
+doc="hash"
+assert hash(memoryview(b"abc")) == hash(b"abc")
+assert hash(memoryview(b"xabcx")[1:4]) == hash(b"abc")
+assertRaisesText(ValueError, "cannot hash writable memoryview object", hash, memoryview(bytearray(b"abc")))
+
+doc="cast"
+a = bytearray(b"\x01\x02\x03\x04\x05\x06\x07\x08")
+m = memoryview(a)
+h = m.cast("H")
+assert h.format == "H"
+assert h.itemsize == 2
+assert len(h) == 4
+assert h.nbytes == 8
+assert h.tolist() == [0x0201, 0x0403, 0x0605, 0x0807]
+h[0] = 0xffff
+assert a[:2] == bytearray(b"\xff\xff")
+assertRaisesText(ValueError, "memoryview: invalid value for format 'H'", h.__setitem__, 0, 0x10000)
+assert m.cast("i")[0] == 0x0403ffff
+assert m.cast("q")[0] == 0x080706050403ffff
+assert memoryview(b"\xff" * 4).cast("i")[0] == -1
+assert m.cast("Q").cast("B").tolist() == list(a)
+b = m.cast("b")
+b[0] = -1
+assert b[0] == -1
+assert m[0] == 255
+assertRaisesText(ValueError, "memoryview: invalid value for format 'b'", b.__setitem__, 0, 128)
+c = m.cast("c")
+assert c[2] == b"\x03"
+c[2] = b"z"
+assert a[2] == ord("z")
+assertRaisesText(ValueError, "memoryview: invalid value for format 'c'", c.__setitem__, 0, b"ab")
+d = memoryview(bytearray(8)).cast("d")
+d[0] = 1.5
+assert d[0] == 1.5
+assert d.tolist() == [1.5]
+assert memoryview(bytearray(b"\x00\x01")).cast("?").tolist() == [False, True]
+assertRaisesText(TypeError, "memoryview: length is not a multiple of itemsize", m[:7].cast, "H")
+assertRaisesText(TypeError, "memoryview: casts are restricted to C-contiguous views", m[::2].cast, "H")
+assertRaisesText(TypeError, "memoryview: cannot cast between two non-byte formats", h.cast, "I")
+assertRaises(ValueError, m.cast, "X")
+assert m.cast("@B").tolist() == list(a)
+
+doc="release"
+a = bytearray(b"abc")
+m = memoryview(a)
+a[0:2] = b"AB"
+assert m.tobytes() == b"ABc"
+m.release()
+m.release()
+a.append(100)
+assert a == bytearray(b"ABcd")
+assertRaisesText(ValueError, "operation forbidden on released memoryview object", len, m)
+assertRaises(ValueError, m.tobytes)
+assertRaises(ValueError, lambda: m[0])
+assertRaises(ValueError, lambda: m.format)
+assert "released" in repr(m)
+with memoryview(a) as m:
+    assert m[3] == 100
+a.append(101)
+assert a == bytearray(b"ABcde")
+assertRaises(ValueError, len, m)
+
+doc="resizing the exporter"
+a = bytearray(b"abc")
+m = memoryview(a)
+assertRaisesText(BufferError, "Existing exports of data: object cannot be re-sized", a.append, 100)
+assertRaises(BufferError, a.extend, b"d")
+assertRaises(BufferError, a.pop)
+assertRaises(BufferError, a.clear)
+def delitem():
+    del a[0]
+assertRaises(BufferError, delitem)
+def setslice():
+    a[0:1] = b"xy"
+assertRaises(BufferError, setslice)
+def iadd():
+    global a
+    a += b"d"
+assertRaises(BufferError, iadd)
+a[0:1] = b"A"
+assert m[0] == ord("A")
+m.release()
+a.append(100)
+assert a == bytearray(b"Abcd")
+with memoryview(a) as m:
+    assertRaises(BufferError, a.append, 101)
+a.append(101)
+assert a == bytearray(b"Abcde")
+
+# Slices and casts share the export of the view they were made from,
+# so releasing it allows resizing.  CPython instead waits for them to
+# be freed which gpython can't detect.
+m = memoryview(a)
+v = m[1:3]
+c = m.cast("B")
+v.release()
+assertRaises(BufferError, a.append, 102)
+m.release()
+a.append(102)
+assert c.tobytes() == b"Abcde"
+
+# A slice which outlives its parent sees the exporter being resized
+m = memoryview(a)
+v = m[1:3]
+m.release()
+a.extend(b"g" * 1000)
+del a[1000:]
+a[1] = ord("B")
+assert v.tobytes() == b"Bc"
+v[0] = ord("b")
+assert a[1] == ord("b")
+del a[2:]
+assertRaisesText(IndexError, "memoryview: underlying buffer was resized", v.tobytes)
+a.extend(b"cd")
+assert v.tobytes() == b"bc"
+
+doc="finished"
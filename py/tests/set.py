@@ -1,6 +1,7 @@
 # Copyright 2019 The go-python Authors.  All rights reserved.
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
+from libtest import assertRaises
 
 doc="__and__"
 a = {1, 2, 3}
@@ -92,4 +93,114 @@ assert a.__ne__(set()) == True
 assert a.__eq__({1,2,3}) == True
 assert a.__ne__({1,2,3}) == False
 
+doc="hash based membership"
+assert {1} == {1.0}
+assert {1, 1.0, True} == {1}
+assert len({1, 1.0, True}) == 1
+assert 1.0 in {1}
+assert 2**70 in {2**70}
+assert {2**70} == {2**69 * 2}
+t = (1, "a")
+assert (1, "a") in {t}
+assert len({(1, "a"), (1, "a")}) == 1
+assert len(set([(1, 2), (1, 2), (2, 1)])) == 2
+
+class K:
+    def __init__(self, v):
+        self.v = v
+    def __hash__(self):
+        return self.v
+    def __eq__(self, other):
+        return self.v == other.v
+assert len({K(1), K(1), K(2)}) == 2
+assert K(2) in {K(1), K(2)}
+assert {K(1)} == {K(1)}
+
+assert {x % 3 for x in range(10)} == {0, 1, 2}
+
+doc="unhashable"
+assertRaises(TypeError, set, [[1]])
+assertRaises(TypeError, frozenset, [[1]])
+try:
+    {[1]}
+except TypeError as e:
+    assert e.args[0] == "unhashable type: 'list'"
+else:
+    assert False, "TypeError not raised"
+try:
+    [] in {1}
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="operators"
+assert {1, 2, 3} & {2.0, 3, 4} == {2, 3}
+assert {1, 2} | {2, 3} == {1, 2, 3}
+assert {1, 2, 3} - {2} == {1, 3}
+assert {1, 2} ^ {2, 3} == {1, 3}
+assert {1, 2} <= {1, 2}
+assert {1} < {1, 2}
+assert not {1, 2} < {1, 2}
+assert {1, 2} >= {2}
+assert {1, 2} > {2}
+assert not {3} <= {1, 2}
+try:
+    {1} & [1]
+except TypeError as e:
+    assert e.args[0] == "unsupported operand type(s) for &: 'set' and 'list'"
+else:
+    assert False, "TypeError not raised"
+
+doc="repr"
+assert repr(set()) == "set()"
+assert repr({1, 2}) == "{1, 2}"
+assert repr(frozenset()) == "frozenset()"
+assert repr(frozenset([1, 2])) == "frozenset({1, 2})"
+
+doc="frozenset"
+a = frozenset([1, 2, 3])
+assert len(a) == 3
+assert 1 in a
+assert a == {1, 2, 3}
+assert {1, 2, 3} == a
+assert a != frozenset()
+assert frozenset() == frozenset([])
+assert type(a & {1}) == frozenset
+assert type({1} & a) == set
+assert type(a | a) == frozenset
+assert type(a - {1}) == frozenset
+assert type(a ^ {1}) == frozenset
+assert frozenset(a) == a
+
+doc="frozenset hash"
+assert frozenset([1, 2, 3]).__hash__() == frozenset([3, 2, 1]).__hash__()
+assert frozenset([1, 2]).__hash__() == frozenset([1.0, 2]).__hash__()
+assert frozenset().__hash__() == frozenset([]).__hash__()
+try:
+    {{1}}
+except TypeError as e:
+    assert e.args[0] == "unhashable type: 'set'"
+else:
+    assert False, "TypeError not raised"
+s = {frozenset([1, 2]), frozenset([2, 1]), frozenset()}
+assert len(s) == 2
+assert frozenset([1, 2]) in s
+assert {2, 1} in s
+d = {frozenset([1, 2]): "a"}
+assert d[frozenset([2, 1])] == "a"
+assert frozenset([frozenset([1])]) == frozenset([frozenset([1])])
+
+doc="items are equal to themselves and __eq__ is truth tested"
+n = float("nan")
+assert n in {n}
+assert len({n, n}) == 1
+class Loose:
+    def __hash__(self):
+        return 1
+    def __eq__(self, other):
+        return 1
+assert Loose() in {Loose()}
+assert len({Loose(), Loose()}) == 1
+
 doc="finished"
@@ -917,4 +917,407 @@ else:
     assert False, "TypeError not raised"
 
 
+doc="split"
+assert "a b  c".split(" ") == ["a", "b", "", "c"]
+assert "a,b,c".split(",", maxsplit=1) == ["a", "b,c"]
+assert "a,b,c".split(sep=",") == ["a", "b", "c"]
+assert "a,b,c".split(",", 0) == ["a,b,c"]
+assert "".split() == []
+assert "   ".split() == []
+assert "a　b\x1cc".split() == ["a", "b", "c"]
+assert "£a£b".split("£") == ["", "a", "b"]
+assertRaisesText(ValueError, "empty separator", lambda: "abc".split(""))
+
+doc="rsplit"
+assert "a,b,c".rsplit(",") == ["a", "b", "c"]
+assert "a,b,c".rsplit(",", 1) == ["a,b", "c"]
+assert "a,,b".rsplit(",") == ["a", "", "b"]
+assert "  a  b  c  ".rsplit() == ["a", "b", "c"]
+assert "  a  b  c  ".rsplit(None, 1) == ["  a  b", "c"]
+assert "  a  b  c  ".rsplit(maxsplit=0) == ["  a  b  c"]
+assert "世,界,世".rsplit(",", 1) == ["世,界", "世"]
+assert "".rsplit(",") == [""]
+
+doc="splitlines"
+assert "a\nb\r\nc\rd".splitlines() == ["a", "b", "c", "d"]
+assert "a\nb\r\n".splitlines(True) == ["a\n", "b\r\n"]
+assert "a\nb".splitlines(keepends=True) == ["a\n", "b"]
+assert "a b\x0cc\x1ed".splitlines() == ["a", "b", "c", "d"]
+assert "".splitlines() == []
+assert "\n".splitlines() == [""]
+
+doc="join"
+assert ",".join(["a", "b", "c"]) == "a,b,c"
+assert "".join([]) == ""
+assert "世".join(("a", "b")) == "a世b"
+assert "-".join("abc") == "a-b-c"
+assert ", ".join({"x": 1, "y": 2}) == "x, y"
+assertRaisesText(TypeError, "sequence item 1: expected str instance, int found", lambda: ",".join(["a", 1]))
+
+doc="strip"
+assert "  a b  ".strip() == "a b"
+assert "  a b  ".lstrip() == "a b  "
+assert "  a b  ".rstrip() == "  a b"
+assert "\t\n a 　".strip() == "a"
+assert "xxaxyx".strip("xy") == "a"
+assert "xxaxyx".lstrip("x") == "axyx"
+assert "xxaxyx".rstrip("xy") == "xxa"
+assert "世界世a世".strip("世") == "界世a"
+assert "  a  ".strip(None) == "a"
+assertRaises(TypeError, "a".strip, 1)
+
+doc="replace"
+assert "aaa".replace("a", "b") == "bbb"
+assert "aaa".replace("a", "b", 2) == "bba"
+assert "aaa".replace("a", "b", 0) == "aaa"
+assert "abc".replace("", "-") == "-a-b-c-"
+assert "世界".replace("界", "x") == "世x"
+assertRaises(TypeError, "a".replace, 1, "b")
+
+doc="find"
+assert "hello".find("l") == 2
+assert "hello".rfind("l") == 3
+assert "hello".find("z") == -1
+assert "hello".rfind("z") == -1
+assert "hello".find("l", 3) == 3
+assert "hello".find("l", -2) == 3
+assert "hello".find("l", 0, 2) == -1
+assert "hello".find("") == 0
+assert "hello".rfind("") == 5
+assert "hello".find("", 5) == 5
+assert "hello".find("", 6) == -1
+assert "£100世界𠜎".find("界") == 5
+assert "£100世界𠜎".find("𠜎") == 6
+assert "£100世界𠜎".rfind("0", 0, 3) == 2
+assert "世界世界".find("界", 2) == 3
+assert "世界世界".rfind("界", None, -1) == 1
+
+doc="index"
+assert "hello".index("l") == 2
+assert "hello".rindex("l") == 3
+assert "世界世界".index("界", 2) == 3
+assertRaisesText(ValueError, "substring not found", lambda: "hello".index("z"))
+assertRaisesText(ValueError, "substring not found", lambda: "hello".rindex("h", 1))
+
+doc="count"
+assert "aaa".count("a") == 3
+assert "aaaa".count("aa") == 2
+assert "abc".count("") == 4
+assert "世界世界".count("世") == 2
+assert "世界世界".count("世", 1) == 1
+assert "世界世界".count("世", 0, -2) == 1
+assert "abc".count("a", 5) == 0
+
+doc="case"
+assert "Hello World".lower() == "hello world"
+assert "Hello World".upper() == "HELLO WORLD"
+assert "ÀÉÎ".lower() == "àéî"
+assert "àéî".upper() == "ÀÉÎ"
+assert "hello wORLD".title() == "Hello World"
+assert "they're bill's".title() == "They'Re Bill'S"
+assert "ǆemal".title() == "ǅemal"
+assert "hello World".capitalize() == "Hello world"
+assert "éCOLE".capitalize() == "École"
+assert "".capitalize() == ""
+assert "Hello World".swapcase() == "hELLO wORLD"
+assert "ÀéÎ".swapcase() == "àÉî"
+
+doc="special casing"
+assert "ß".upper() == "SS"
+assert "straße".upper() == "STRASSE"
+assert "ﬁ".upper() == "FI"
+assert "ﬁre".title() == "Fire"
+assert "ß".title() == "Ss"
+assert "ßx".capitalize() == "Ssx"
+assert "İ".lower() == "i\u0307"
+assert len("İ".lower()) == 2
+assert "ΣΑΣ".lower() == "σας"
+assert "ΟΔΟΣ ΟΔΟΣ.".lower() == "οδος οδος."
+assert "Σ".lower() == "σ"
+assert "ΑΣΑ".lower() == "ασα"
+assert "ΑΣ'Α".lower() == "ασ'α"
+assert "ΑΣ'".lower() == "ας'"
+assert "ΟΔΟΣ".title() == "Οδος"
+assert "οδοσ".swapcase() == "ΟΔΟΣ"
+assert "ΟΔΟΣ".swapcase() == "οδος"
+assert "ß".swapcase() == "SS"
+assert "Ⅳ ⓐ".swapcase() == "ⅳ Ⓐ"
+assert "Ⅳ".isupper()
+assert "ⓐ".islower()
+
+doc="casefold"
+assert "Hello World".casefold() == "hello world"
+assert "ß".casefold() == "ss"
+assert "Straße".casefold() == "STRASSE".casefold()
+assert "ﬁ".casefold() == "fi"
+assert "ς".casefold() == "σ"
+assert "ΟΔΟΣ".casefold() == "οδοσ"
+assert "İ".casefold() == "i\u0307"
+assert "Ꭰ".casefold() == "Ꭰ"
+assert "ꭰ".casefold() == "Ꭰ"
+
+doc="is"
+assert "abc".isalpha()
+assert "世界é".isalpha()
+assert not "ab1".isalpha()
+assert not "".isalpha()
+assert "ab1".isalnum()
+assert not "ab 1".isalnum()
+assert "123".isdecimal()
+assert "١٢٣".isdecimal()
+assert not "²".isdecimal()
+assert "²".isdigit()
+assert "123".isdigit()
+assert not "½".isdigit()
+assert "½".isnumeric()
+assert "Ⅻ".isnumeric()
+assert not "a".isnumeric()
+assert " \t\n\x1c　".isspace()
+assert not " a ".isspace()
+assert not "".isspace()
+assert "abc".islower()
+assert "abc1".islower()
+assert not "aBc".islower()
+assert not "123".islower()
+assert "ABC".isupper()
+assert "ÀB1".isupper()
+assert not "AbC".isupper()
+assert not "".isupper()
+assert "Hello World".istitle()
+assert "Hello  World 1".istitle()
+assert not "Hello world".istitle()
+assert not "HEllo".istitle()
+assert not "".istitle()
+assert "abc".isidentifier()
+assert "_a1".isidentifier()
+assert "été".isidentifier()
+assert not "1a".isidentifier()
+assert not "a-b".isidentifier()
+assert not "".isidentifier()
+assert "abc def".isprintable()
+assert "".isprintable()
+assert not "a\nb".isprintable()
+assert not "\x7f".isprintable()
+
+doc="partition"
+assert "a=b=c".partition("=") == ("a", "=", "b=c")
+assert "a=b=c".rpartition("=") == ("a=b", "=", "c")
+assert "abc".partition("=") == ("abc", "", "")
+assert "abc".rpartition("=") == ("", "", "abc")
+assert "世=界".partition("=") == ("世", "=", "界")
+assertRaisesText(ValueError, "empty separator", lambda: "abc".partition(""))
+assertRaises(TypeError, "abc".partition, 1)
+
+doc="justify"
+assert "abc".center(7) == "  abc  "
+assert "abc".center(6) == " abc  "
+assert "ab".center(5, "*") == "**ab*"
+assert "abcd".center(7, "*") == "**abcd*"
+assert "abc".center(2) == "abc"
+assert "世".center(3, "界") == "界世界"
+assert "abc".ljust(5) == "abc  "
+assert "abc".ljust(5, "-") == "abc--"
+assert "abc".rjust(5) == "  abc"
+assert "世界".rjust(4, "·") == "··世界"
+assert "abc".rjust(1) == "abc"
+assertRaisesText(TypeError, "The fill character must be exactly one character long", lambda: "a".center(3, "ab"))
+assert "42".zfill(5) == "00042"
+assert "-42".zfill(5) == "-0042"
+assert "+42".zfill(5) == "+0042"
+assert "abc".zfill(2) == "abc"
+assert "".zfill(3) == "000"
+assertRaises(MemoryError, "a".center, 1 << 62)
+assertRaises(MemoryError, "a".ljust, 1 << 62, "界")
+assertRaises(MemoryError, "a".rjust, 1 << 62)
+assertRaises(MemoryError, "1".zfill, 1 << 62)
+
+doc="expandtabs"
+assert "a\tb".expandtabs() == "a       b"
+assert "a\tb".expandtabs(4) == "a   b"
+assert "ab\tc\nd\te".expandtabs(tabsize=4) == "ab  c\nd   e"
+assert "世\tb".expandtabs(4) == "世   b"
+assert "a\tb".expandtabs(0) == "ab"
+assertRaises((OverflowError, MemoryError), "\t".expandtabs, 1 << 60)
+
+doc="encode"
+assert "abc".encode() == b"abc"
+assert "é".encode() == b"\xc3\xa9"
+assert "é".encode("utf-8") == b"\xc3\xa9"
+assert "é".encode("UTF8") == b"\xc3\xa9"
+assert "é".encode("latin-1") == b"\xe9"
+assert "abc".encode("ascii") == b"abc"
+assertRaisesText(UnicodeEncodeError, "'ascii' codec can't encode character '\\xe9' in position 1: ordinal not in range(128)", lambda: "aé".encode("ascii"))
+assertRaises(UnicodeError, "€".encode, "latin-1")
+assert "aéb".encode("ascii", "ignore") == b"ab"
+assert "aéb".encode("ascii", "replace") == b"a?b"
+assert "a€".encode("ascii", errors="backslashreplace") == b"a\\u20ac"
+assert "a€".encode("ascii", "xmlcharrefreplace") == b"a&#8364;"
+assertRaises(LookupError, "a".encode, "nope")
+assertRaises(LookupError, "aé".encode, "ascii", "nope")
+assert "a".encode("ascii", "nope") == b"a"
+assert bytes("é", "latin-1") == b"\xe9"
+
+doc="maketrans and translate"
+t = str.maketrans("abc", "xyz")
+assert t == {97: 120, 98: 121, 99: 122}
+assert "aabbcc".translate(t) == "xxyyzz"
+t = str.maketrans("ab", "xy", "c")
+assert "abcd".translate(t) == "xyd"
+t = str.maketrans({"a": "1", "b": None, 99: 100})
+assert "abcd".translate(t) == "1dd"
+assert "世界".translate({ord("世"): "x"}) == "x界"
+assert "abc".translate({97: "AA"}) == "AAbc"
+assert "abc".maketrans("a", "b") == {97: 98}
+assertRaises(ValueError, str.maketrans, "ab", "x")
+assertRaises(ValueError, str.maketrans, {"ab": 1})
+assertRaises(TypeError, str.maketrans, [1])
+assertRaises(TypeError, "abc".translate, {97: 1.5})
+
+doc="startswith and endswith ranges"
+assert "世界世界".startswith("界", 1)
+assert not "世界世界".startswith("界", 2)
+assert "世界世界".endswith("世", 0, 3)
+assert "hello".endswith("ell", 0, 4)
+assert "hello".startswith("", 5)
+assert not "hello".startswith("", 6)
+assertRaises(TypeError, "hello".startswith, 1)
+
+doc="str.format fields"
+assert "{} {}".format(1, "a") == "1 a"
+assert "{1} {0} {1}".format("a", "b") == "b a b"
+assert "{x}-{y}".format(x=1, y=2) == "1-2"
+assert "{{}} {{{}}}".format(3) == "{} {3}"
+assert "{0[1]} {0[0]}".format([5, 6]) == "6 5"
+assert "{d[k]} {d[1]}".format(d={"k": "v", 1: "one"}) == "v one"
+class A:
+    pass
+a = A()
+a.b = A()
+a.b.c = 42
+assert "{0.b.c}".format(a) == "42"
+assert "{a.b.c:>4}".format(a=a) == "  42"
+assert "{!r} {!s} {!a}".format("x", "y", "é") == "'x' y '\\xe9'"
+assert "{0!r:>5}".format("a") == "  'a'"
+assert "{:{}{}}".format("x", ">", 3) == "  x"
+assert "{:{w}.{p}f}".format(3.14159, w=8, p=2) == "    3.14"
+assert "".format() == ""
+assert "no fields".format(1, 2) == "no fields"
+assertRaisesText(ValueError, "Single '}' encountered in format string", "}".format)
+assertRaisesText(ValueError, "Single '{' encountered in format string", "{".format)
+assertRaisesText(ValueError, "expected '}' before end of string", "{0".format)
+assertRaisesText(ValueError, "cannot switch from manual field specification to automatic field numbering", "{0} {}".format, 1, 2)
+assertRaisesText(ValueError, "cannot switch from automatic field numbering to manual field specification", "{} {0}".format, 1, 2)
+assertRaisesText(ValueError, "Unknown conversion specifier x", "{!x}".format, 1)
+assertRaisesText(ValueError, "Max string recursion exceeded", "{:{:{}}}".format, 1, 2, 3)
+assertRaises(IndexError, "{} {}".format, 1)
+assertRaises(KeyError, "{x}".format, y=1)
+assertRaises(AttributeError, "{0.nope}".format, a)
+
+doc="format spec strings"
+assert format("abc") == "abc"
+assert format("abc", "5") == "abc  "
+assert format("abc", ">5") == "  abc"
+assert format("abc", "^6") == " abc  "
+assert format("abc", "*^7") == "**abc**"
+assert format("abcdef", ".2") == "ab"
+assert format("世界", "_>4") == "__世界"
+assert format("ab", "s") == "ab"
+assert format("ab", "05") == "ab000"
+assert format("ab", ">05") == "000ab"
+assert format("ab", " <05") == "ab   "
+assertRaisesText(ValueError, "Sign not allowed in string format specifier", format, "a", "+")
+assertRaisesText(ValueError, "Alternate form (#) not allowed in string format specifier", format, "a", "#")
+assertRaisesText(ValueError, "'=' alignment not allowed in string format specifier", format, "a", "=5")
+assertRaisesText(ValueError, "Unknown format code 'd' for object of type 'str'", format, "a", "d")
+assertRaisesText(ValueError, "Invalid format specifier", format, "a", "5x5")
+
+doc="format spec ints"
+assert format(42) == "42"
+assert format(42, "d") == "42"
+assert format(42, "5") == "   42"
+assert format(42, "<5") == "42   "
+assert format(-42, "=6") == "-   42"
+assert format(-42, "06") == "-00042"
+assert format(42, "+") == "+42"
+assert format(42, " ") == " 42"
+assert format(-42, " ") == "-42"
+assert format(255, "b") == "11111111"
+assert format(255, "#b") == "0b11111111"
+assert format(255, "o") == "377"
+assert format(255, "#o") == "0o377"
+assert format(255, "x") == "ff"
+assert format(255, "#X") == "0XFF"
+assert format(-255, "#x") == "-0xff"
+assert format(255, "#010x") == "0x000000ff"
+assert format(1234567, ",") == "1,234,567"
+assert format(1234567, "_") == "1_234_567"
+assert format(-1234567, ",d") == "-1,234,567"
+assert format(0xdeadbeef, "_x") == "dead_beef"
+assert format(1234, "08,") == "0,001,234"
+assert "{:<05}".format(1) == "10000"
+assert "{:^05}".format(1) == "00100"
+assert "{:>05}".format(-1) == "000-1"
+assert "{:=05}".format(-1) == "-0001"
+assert "{:x<05}".format(1) == "1xxxx"
+assert "{:<08,}".format(1234) == "1,234000"
+assert format(65, "c") == "A"
+assert format(42, "n") == "42"
+assert format(3, "f") == "3.000000"
+assert format(3, "%") == "300.000000%"
+assert format(12345678901234567890, ",") == "12,345,678,901,234,567,890"
+assert format(-12345678901234567890, "x") == "-ab54a98ceb1f0ad2"
+assert format(True) == "True"
+assert format(True, "d") == "1"
+assert format(False, "5") == "    0"
+assertRaisesText(ValueError, "Precision not allowed in integer format specifier", format, 1, ".2")
+assertRaisesText(ValueError, "Cannot specify ',' with 'x'.", format, 1, ",x")
+assertRaisesText(ValueError, "Sign not allowed with integer format specifier 'c'", format, 65, "+c")
+assertRaisesText(ValueError, "Unknown format code 's' for object of type 'int'", format, 1, "s")
+assertRaisesText(ValueError, "Format specifier missing precision", format, 1, ".")
+assertRaises((ValueError, MemoryError), "{:2000000000000000000}".format, 1)
+assertRaisesText(ValueError, "Too many decimal digits in format string", "{:99999999999999999999}".format, 1)
+assert format(1234, "=010,") == "00,001,234"
+assert format(1234, "0=10,") == "00,001,234"
+assert format(1234, "0>10,") == "000001,234"
+assert format(1234, "x=10,") == "xxxxx1,234"
+assertRaisesText(OverflowError, "int too large to convert to float", "{:e}".format, 2**2000)
+
+doc="format spec floats"
+assert format(1.5) == "1.5"
+assert format(1.5, "") == str(1.5)
+assert format(3.14159, ".2f") == "3.14"
+assert format(3.14159, "8.3f") == "   3.142"
+assert format(-3.14159, "08.3f") == "-003.142"
+assert format(2.5, "F") == "2.500000"
+assert format(1234.5, "e") == "1.234500e+03"
+assert format(1234.5, ".2E") == "1.23E+03"
+assert format(1234.5, "g") == "1234.5"
+assert format(0.00001234, "g") == "1.234e-05"
+assert format(1e20, "G") == "1E+20"
+assert format(100.0, ".3g") == "100"
+assert format(100.0, "#.3g") == "100."
+assert format(1.0, ".0f") == "1"
+assert format(1.0, "#.0f") == "1."
+assert format(1.0, ".3") == "1.0"
+assert format(1234.5678, ".6") == "1234.57"
+assert format(1e20, ".3") == "1e+20"
+assert format(0.25, "%") == "25.000000%"
+assert format(0.25, ".1%") == "25.0%"
+assert format(1234567.891, ",.2f") == "1,234,567.89"
+assert format(1234567.891, "_.1f") == "1_234_567.9"
+assert format(1.5, "+") == "+1.5"
+assert format(-0.0, "f") == "-0.000000"
+assert format(float("inf"), "f") == "inf"
+assert format(float("-inf"), "F") == "-INF"
+assert format(float("nan"), "+g") == "+nan"
+assert format(float("inf"), "08f") == "00000inf"
+assert format(1.5, "*^9.2f") == "**1.50***"
+assert format(1.5, "=+8") == "+    1.5"
+assert format(1.5, "=+08,.1f") == "+0,001.5"
+assert format(1.5, "0=+8,.1f") == "+0,001.5"
+assert format(1.5, "<+08,.1f") == "+1.50000"
+assertRaisesText(ValueError, "precision too big", "{:.99999999999}".format, 1.5)
+assertRaisesText(ValueError, "Unknown format code 'd' for object of type 'float'", format, 1.5, "d")
+assertRaisesText(ValueError, "Unknown format code 'x' for object of type 'float'", format, 1.5, "x")
+
 doc="finished"
@@ -58,23 +58,28 @@ func (tb *Traceback) TracebackDump(w io.Writer) {
 
 // Dumps a traceback to stderr
 func TracebackDump(err interface{}) {
+	TracebackDumpTo(os.Stderr, err)
+}
+
+// Dumps a traceback to w
+func TracebackDumpTo(w io.Writer, err interface{}) {
 	switch e := err.(type) {
 	case ExceptionInfo:
-		e.TracebackDump(os.Stderr)
+		e.TracebackDump(w)
 	case *ExceptionInfo:
-		e.TracebackDump(os.Stderr)
+		e.TracebackDump(w)
 	case *Exception:
-		fmt.Fprintf(os.Stderr, "Exception %#v\n", e)
-		fmt.Fprintf(os.Stderr, "-- No traceback available --\n")
+		fmt.Fprintf(w, "Exception %#v\n", e)
+		fmt.Fprintf(w, "-- No traceback available --\n")
 	default:
-		fmt.Fprintf(os.Stderr, "Error %#v\n", err)
-		fmt.Fprintf(os.Stderr, "-- No traceback available --\n")
+		fmt.Fprintf(w, "Error %#v\n", err)
+		fmt.Fprintf(w, "-- No traceback available --\n")
 	}
 }
 
 // Properties
 func init() {
-	TracebackType.Dict["__tb_next__"] = &Property{
+	TracebackType.Dict.Set("__tb_next__", &Property{
 		Fget: func(self Object) (Object, error) {
 			next := self.(*Traceback).Next
 			if next == nil {
@@ -82,22 +87,22 @@ func init() {
 			}
 			return next, nil
 		},
-	}
-	TracebackType.Dict["__tb_frame__"] = &Property{
+	})
+	TracebackType.Dict.Set("__tb_frame__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return self.(*Traceback).Frame, nil
 		},
-	}
-	TracebackType.Dict["__tb_lasti__"] = &Property{
+	})
+	TracebackType.Dict.Set("__tb_lasti__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return Int(self.(*Traceback).Lasti), nil
 		},
-	}
-	TracebackType.Dict["__tb_lineno__"] = &Property{
+	})
+	TracebackType.Dict.Set("__tb_lineno__", &Property{
 		Fget: func(self Object) (Object, error) {
 			return Int(self.(*Traceback).Lineno), nil
 		},
-	}
+	})
 }
 
 // Make sure it satisfies the interface
@@ -192,6 +192,14 @@ func (a Tuple) M__ne__(other Object) (Object, error) {
 	return False, nil
 }
 
+func (a Tuple) M__hash__() (Object, error) {
+	h, err := hashTuple(a)
+	if err != nil {
+		return nil, err
+	}
+	return Int(h), nil
+}
+
 // Check interface is satisfied
 var _ sequenceArithmetic = Tuple(nil)
 var _ I__str__ = Tuple(nil)
@@ -204,3 +212,4 @@ var _ I__eq__ = Tuple(nil)
 var _ I__ne__ = Tuple(nil)
 
 // var _ richComparison = Tuple(nil)
+var _ I__hash__ = Tuple(nil)
@@ -182,14 +182,14 @@ type Type struct {
 var TypeType *Type = &Type{
 	Name: "type",
 	Doc:  "type(object) -> the object's type\ntype(name, bases, dict) -> a new type",
-	Dict: StringDict{},
+	Dict: NewStringDict(),
 }
 
 var ObjectType = &Type{
 	Name:  "object",
 	Doc:   "The most base type",
 	Flags: TPFLAGS_BASETYPE,
-	Dict:  StringDict{},
+	Dict:  NewStringDict(),
 }
 
 func init() {
@@ -262,7 +262,7 @@ func NewType(Name string, Doc string) *Type {
 		ObjectType: TypeType,
 		Name:       Name,
 		Doc:        Doc,
-		Dict:       StringDict{},
+		Dict:       NewStringDict(),
 	}
 	TypeDelayReady(t)
 	return t
@@ -278,7 +278,7 @@ func NewTypeX(Name string, Doc string, New NewFunc, Init InitFunc) *Type {
 		Doc:        Doc,
 		New:        New,
 		Init:       Init,
-		Dict:       StringDict{},
+		Dict:       NewStringDict(),
 	}
 	TypeDelayReady(t)
 	return t
@@ -303,10 +303,10 @@ func (t *Type) NewTypeFlags(Name string, Doc string, New NewFunc, Init InitFunc,
 		New:        New,
 		Init:       Init,
 		Flags:      Flags,
-		Dict:       StringDict{},
+		Dict:       NewStringDict(),
 		Bases:      Tuple{t},
 	}
-	TypeDelayReady(t)
+	TypeDelayReady(tt)
 	return tt
 }
 
@@ -383,7 +383,7 @@ func (t *Type) M__call__(args Tuple, kwargs StringDict) (Object, error) {
 	}
 	// Ugly exception: when the call was type(something),
 	// don't call tp_init on the result.
-	if t == TypeType && len(args) == 1 && len(kwargs) == 0 {
+	if t == TypeType && len(args) == 1 && kwargs.Len() == 0 {
 		return obj, nil
 	}
 	// If the returned object is not an instance of type,
@@ -435,7 +435,7 @@ func (t *Type) Lookup(name string) Object {
 	for _, baseObj := range mro {
 		base := baseObj.(*Type)
 		var ok bool
-		res, ok = base.Dict[name]
+		res, ok = base.Dict.Lookup(name)
 		if ok {
 			break
 		}
@@ -467,7 +467,7 @@ func (t *Type) Lookup(name string) Object {
 // See _PyObject_GenericGetAttrWithDict in object.c
 func (t *Type) NativeGetAttrOrNil(name string) Object {
 	// Look in type Dict
-	if res, ok := t.Dict[name]; ok {
+	if res, ok := t.Dict.Lookup(name); ok {
 		return res
 	}
 	// Now look through base classes etc
@@ -485,11 +485,11 @@ func (t *Type) NativeGetAttrOrNil(name string) Object {
 // See _PyObject_GenericGetAttrWithDict in object.c
 func (t *Type) GetAttrOrNil(name string) Object {
 	// Look in instance dictionary first
-	if res, ok := t.Dict[name]; ok {
+	if res, ok := t.Dict.Lookup(name); ok {
 		return res
 	}
 	// Then look in type Dict
-	if res, ok := t.Type().Dict[name]; ok {
+	if res, ok := t.Type().Dict.Lookup(name); ok {
 		return res
 	}
 	// Now look through base classes etc
@@ -1092,11 +1092,11 @@ func (t *Type) Ready() error {
 
 	// if the type dictionary doesn't contain a __doc__, set it from
 	// the tp_doc slot.
-	if _, ok := t.Dict["__doc__"]; ok {
+	if _, ok := t.Dict.Lookup("__doc__"); !ok {
 		if t.Doc != "" {
-			t.Dict["__doc__"] = String(t.Doc)
+			t.Dict.Set("__doc__", String(t.Doc))
 		} else {
-			t.Dict["__doc__"] = None
+			t.Dict.Set("__doc__", None)
 		}
 	}
 
@@ -1205,7 +1205,7 @@ func (t *Type) Alloc() *Type {
 	obj := &Type{
 		ObjectType: t,
 		Base:       t,
-		Dict:       StringDict{},
+		Dict:       NewStringDict(),
 	}
 	return obj
 }
@@ -1222,14 +1222,14 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	// _Py_IDENTIFIER(__slots__);
 
 	// Special case: type(x) should return x.ob_type
-	if metatype != nil && len(args) == 1 && len(kwargs) == 0 {
+	if metatype != nil && len(args) == 1 && kwargs.Len() == 0 {
 		return args[0].Type(), nil
 	}
 
 	// SF bug 475327 -- if that didn't trigger, we need 3
 	// arguments. but PyArg_ParseTupleAndKeywords below may give
 	// a msg saying type() needs exactly 3.
-	if len(args)+len(kwargs) != 3 {
+	if len(args)+kwargs.Len() != 3 {
 		return nil, ExceptionNewf(TypeError, "type() takes 1 or 3 arguments")
 	}
 
@@ -1243,7 +1243,10 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	}
 	name := nameObj.(String)
 	bases := basesObj.(Tuple)
-	orig_dict := orig_dictObj.(StringDict)
+	orig_dict, err := DictAsStringDict(orig_dictObj)
+	if err != nil {
+		return nil, err
+	}
 
 	// Determine the proper metatype to deal with this:
 	winner, err = metatype.CalculateMetaclass(bases)
@@ -1277,7 +1280,7 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	dict := orig_dict.Copy()
 
 	// Check for a __slots__ sequence variable in dict, and count it
-	slots, haveSlots := dict["__slots__"]
+	slots, haveSlots := dict.Lookup("__slots__")
 	nslots := 0
 	// add_dict := 0
 	// add_weak := 0
@@ -1431,8 +1434,15 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	new_type.Dict = dict
 	// fmt.Printf("New type dict is %v\n", dict)
 
+	// A class which defines __eq__ but not __hash__ is unhashable
+	if _, ok := dict.Lookup("__eq__"); ok {
+		if _, ok := dict.Lookup("__hash__"); !ok {
+			dict.Set("__hash__", None)
+		}
+	}
+
 	// Set __module__ in the dict
-	if _, ok := dict["__module__"]; !ok {
+	if _, ok := dict.Lookup("__module__"); !ok {
 		fmt.Printf("*** FIXME need to get the current vm globals somehow\n")
 		// tmp = PyEval_GetGlobals()
 		// if tmp != nil {
@@ -1445,13 +1455,13 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 
 	// Set ht_qualname to dict['__qualname__'] if available, else to
 	// __name__.  The __qualname__ accessor will look for ht_qualname.
-	if qualname, ok := dict["__qualname__"]; ok {
+	if qualname, ok := dict.Lookup("__qualname__"); ok {
 		if Qualname, ok := qualname.(String); !ok {
 			return nil, ExceptionNewf(TypeError, "type __qualname__ must be a str, not %s", qualname.Type().Name)
 		} else {
 			et.Qualname = string(Qualname)
 		}
-		delete(dict, "__qualname__")
+		dict.Delete("__qualname__")
 	} else {
 		et.Qualname = et.Name
 	}
@@ -1459,7 +1469,7 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	// Set tp_doc to a copy of dict['__doc__'], if the latter is there
 	// and is a string.  The __doc__ accessor will first look for tp_doc;
 	// if that fails, it will still look into __dict__.
-	if doc, ok := dict["__doc__"]; ok {
+	if doc, ok := dict.Lookup("__doc__"); ok {
 		if Doc, ok := doc.(String); ok {
 			new_type.Doc = string(Doc)
 		}
@@ -1565,7 +1575,7 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 }
 
 func TypeInit(cls Object, args Tuple, kwargs StringDict) error {
-	if len(kwargs) != 0 {
+	if kwargs.Len() != 0 {
 		return ExceptionNewf(TypeError, "type.__init__() takes no keyword arguments")
 	}
 
@@ -1620,7 +1630,7 @@ func TypeInit(cls Object, args Tuple, kwargs StringDict) error {
 
 // Return true if any arguments supplied
 func excess_args(args Tuple, kwargs StringDict) bool {
-	return len(args) != 0 || len(kwargs) != 0
+	return len(args) != 0 || kwargs.Len() != 0
 }
 
 func ObjectInit(self Object, args Tuple, kwargs StringDict) error {
@@ -1721,20 +1731,68 @@ func ObjectNew(t *Type, args Tuple, kwargs StringDict) (Object, error) {
 	return t.Alloc(), nil
 }
 
-// FIXME this should be the default?
+// lookupSpecial looks up the special method called name in the type
+// of ty, not in ty itself, so that a class doesn't find the methods
+// meant for its instances.  It returns nil if not found.
+func (ty *Type) lookupSpecial(name string) Object {
+	return ty.Type().Lookup(name)
+}
+
+// Calls __eq__ if defined otherwise compares identities
 func (ty *Type) M__eq__(other Object) (Object, error) {
+	if fn := ty.lookupSpecial("__eq__"); fn != nil {
+		return Call(fn, Tuple{ty, other}, nil)
+	}
 	if otherTy, ok := other.(*Type); ok && ty == otherTy {
 		return True, nil
 	}
 	return False, nil
 }
 
-// FIXME this should be the default?
+// Calls __ne__ if defined, otherwise inverts __eq__
 func (ty *Type) M__ne__(other Object) (Object, error) {
-	if otherTy, ok := other.(*Type); ok && ty == otherTy {
-		return False, nil
+	if fn := ty.lookupSpecial("__ne__"); fn != nil {
+		return Call(fn, Tuple{ty, other}, nil)
+	}
+	return notEq(ty.M__eq__(other))
+}
+
+// callOrder calls the ordering method name if defined otherwise
+// returns NotImplemented
+func (ty *Type) callOrder(name string, other Object) (Object, error) {
+	if fn := ty.lookupSpecial(name); fn != nil {
+		return Call(fn, Tuple{ty, other}, nil)
+	}
+	return NotImplemented, nil
+}
+
+func (ty *Type) M__lt__(other Object) (Object, error) {
+	return ty.callOrder("__lt__", other)
+}
+
+func (ty *Type) M__le__(other Object) (Object, error) {
+	return ty.callOrder("__le__", other)
+}
+
+func (ty *Type) M__gt__(other Object) (Object, error) {
+	return ty.callOrder("__gt__", other)
+}
+
+func (ty *Type) M__ge__(other Object) (Object, error) {
+	return ty.callOrder("__ge__", other)
+}
+
+// Calls __hash__ if defined otherwise hashes the identity
+//
+// If __hash__ is None then the object is unhashable.
+func (ty *Type) M__hash__() (Object, error) {
+	if fn := ty.lookupSpecial("__hash__"); fn != nil {
+		if fn == None {
+			return nil, ExceptionNewf(TypeError, "unhashable type: '%s'", ty.Type().Name)
+		}
+		return Call(fn, Tuple{ty}, nil)
 	}
-	return True, nil
+	return Int(hashPointer(ty)), nil
 }
 
 func (ty *Type) M__str__() (Object, error) {
@@ -1762,3 +1820,10 @@ var _ I__call__ = (*Type)(nil)
 var _ IGetDict = (*Type)(nil)
 var _ I__repr__ = (*Type)(nil)
 var _ I__str__ = (*Type)(nil)
+var _ I__eq__ = (*Type)(nil)
+var _ I__ne__ = (*Type)(nil)
+var _ I__lt__ = (*Type)(nil)
+var _ I__le__ = (*Type)(nil)
+var _ I__gt__ = (*Type)(nil)
+var _ I__ge__ = (*Type)(nil)
+var _ I__hash__ = (*Type)(nil)
@@ -32,6 +32,9 @@ func TestMro(t *testing.T) {
 		{BaseException, []*Type{BaseException, ObjectType}},
 		{ExceptionType, []*Type{ExceptionType, BaseException, ObjectType}},
 		{ValueError, []*Type{ValueError, ExceptionType, BaseException, ObjectType}},
+		// Types made with NewType are readied too
+		{KeyError, []*Type{KeyError, LookupError, ExceptionType, BaseException, ObjectType}},
+		{DictType, []*Type{DictType, ObjectType}},
 	} {
 		got := test.t.Mro
 		if len(test.want) != len(got) {
@@ -48,3 +51,26 @@ func TestMro(t *testing.T) {
 		}
 	}
 }
+
+func TestReadyDoc(t *testing.T) {
+	if got := ValueError.Dict.Get("__doc__"); got != String(ValueError.Doc) {
+		t.Errorf("want __doc__ %q got %v", ValueError.Doc, got)
+	}
+	for _, test := range []struct {
+		doc  string
+		dict StringDict
+		want Object
+	}{
+		{"from Doc", nil, String("from Doc")},
+		{"", nil, None},
+		{"from Doc", NewStringDictFromMap(map[string]Object{"__doc__": String("from Dict")}), String("from Dict")},
+	} {
+		tt := &Type{ObjectType: TypeType, Name: "doctest", Doc: test.doc, Dict: test.dict, Bases: Tuple{ObjectType}}
+		if err := tt.Ready(); err != nil {
+			t.Fatalf("Ready failed: %v", err)
+		}
+		if got := tt.Dict.Get("__doc__"); got != test.want {
+			t.Errorf("Doc %q, Dict %v: want __doc__ %v got %v", test.doc, test.dict, test.want, got)
+		}
+	}
+}
@@ -41,16 +41,18 @@ func compileProgram(t testing.TB, prog string) (*py.Module, *py.Code) {
 	}
 
 	code := obj.(*py.Code)
-	module := py.NewModule("__main__", "", nil, nil)
-	module.Globals["__file__"] = py.String(prog)
+	// Don't leave __pycache__ directories in the test directories
+	ctx := py.NewContext(py.ContextOpts{DontWriteBytecode: true})
+	module := ctx.NewModule("__main__", "", nil, nil)
+	module.Globals.Set("__file__", py.String(prog))
 	return module, code
 }
 
 // Run the code in the module
 func run(t testing.TB, module *py.Module, code *py.Code) {
-	_, err := vm.Run(module.Globals, module.Globals, code, nil)
+	_, err := vm.Run(module.Context, module.Globals, module.Globals, code, nil)
 	if err != nil {
-		if wantErr, ok := module.Globals["err"]; ok {
+		if wantErr, ok := module.Globals.Lookup("err"); ok {
 			wantErrObj, ok := wantErr.(py.Object)
 			if !ok {
 				t.Fatalf("want err is not py.Object: %#v", wantErr)
@@ -66,12 +68,12 @@ func run(t testing.TB, module *py.Module, code *py.Code) {
 			return
 		} else {
 			py.TracebackDump(err)
-			t.Fatalf("Run failed: %v at %q", err, module.Globals["doc"])
+			t.Fatalf("Run failed: %v at %q", err, module.Globals.Get("doc"))
 		}
 	}
 
 	// t.Logf("%s: Return = %v", prog, res)
-	if doc, ok := module.Globals["doc"]; ok {
+	if doc, ok := module.Globals.Lookup("doc"); ok {
 		if docStr, ok := doc.(py.String); ok {
 			if string(docStr) != "finished" {
 				t.Fatalf("Didn't finish at %q", docStr)
@@ -118,9 +118,9 @@ func (rl *readline) Print(out string) {
 	_, _ = os.Stdout.WriteString(out + "\n")
 }
 
-// RunREPL starts the REPL loop
-func RunREPL() {
-	repl := repl.New()
+// RunREPL starts the REPL loop in the interpreter ctx
+func RunREPL(ctx *py.Context) {
+	repl := repl.New(ctx)
 	rl := newReadline(repl)
 	repl.SetUI(rl)
 	defer rl.Close()
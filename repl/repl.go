@@ -23,6 +23,7 @@ const (
 
 // Repl state
 type REPL struct {
+	ctx          *py.Context
 	module       *py.Module
 	prog         string
 	continuation bool
@@ -39,15 +40,17 @@ type UI interface {
 	Print(string)
 }
 
-// New create a new REPL and initialises the state machine
-func New() *REPL {
+// New create a new REPL running in the interpreter ctx and initialises
+// the state machine
+func New(ctx *py.Context) *REPL {
 	r := &REPL{
-		module:       py.NewModule("__main__", "", nil, nil),
+		ctx:          ctx,
+		module:       ctx.NewModule("__main__", "", nil, nil),
 		prog:         "<stdin>",
 		continuation: false,
 		previous:     "",
 	}
-	r.module.Globals["__file__"] = py.String(r.prog)
+	r.module.Globals.Set("__file__", py.String(r.prog))
 	return r
 }
 
@@ -60,10 +63,10 @@ func (r *REPL) SetUI(term UI) {
 // Run runs a single line of the REPL
 func (r *REPL) Run(line string) {
 	// Override the PrintExpr output temporarily
-	oldPrintExpr := vm.PrintExpr
-	vm.PrintExpr = r.term.Print
+	oldPrintExpr := r.ctx.PrintExpr
+	r.ctx.PrintExpr = r.term.Print
 	defer func() {
-		vm.PrintExpr = oldPrintExpr
+		r.ctx.PrintExpr = oldPrintExpr
 	}()
 	if r.continuation {
 		if line != "" {
@@ -100,9 +103,9 @@ func (r *REPL) Run(line string) {
 		return
 	}
 	code := obj.(*py.Code)
-	_, err = vm.Run(r.module.Globals, r.module.Globals, code, nil)
+	_, err = vm.Run(r.ctx, r.module.Globals, r.module.Globals, code, nil)
 	if err != nil {
-		py.TracebackDump(err)
+		r.ctx.TracebackDump(err)
 	}
 }
 
@@ -120,7 +123,7 @@ func (r *REPL) Completer(line string, pos int) (head string, completions []strin
 	// log.Printf("head = %q, partial = %q, tail = %q", head, partial, tail)
 	found := make(map[string]struct{})
 	match := func(d py.StringDict) {
-		for k := range d {
+		for _, k := range d.StringKeys() {
 			if strings.HasPrefix(k, partial) {
 				if _, ok := found[k]; !ok {
 					completions = append(completions, k)
@@ -130,7 +133,7 @@ func (r *REPL) Completer(line string, pos int) (head string, completions []strin
 		}
 	}
 	match(r.module.Globals)
-	match(py.Builtins.Globals)
+	match(r.ctx.Builtins.Globals)
 	sort.Strings(completions)
 	return head, completions, tail
 }
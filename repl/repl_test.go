@@ -8,6 +8,7 @@ import (
 	// import required modules
 	_ "github.com/go-python/gpython/builtin"
 	_ "github.com/go-python/gpython/math"
+	"github.com/go-python/gpython/py"
 	_ "github.com/go-python/gpython/sys"
 	_ "github.com/go-python/gpython/time"
 )
@@ -38,7 +39,7 @@ func (rt *replTest) assert(t *testing.T, what, wantPrompt, wantOut string) {
 }
 
 func TestREPL(t *testing.T) {
-	r := New()
+	r := New(py.NewContext(py.ContextOpts{}))
 	rt := &replTest{}
 	r.SetUI(rt)
 
@@ -78,7 +79,7 @@ func TestREPL(t *testing.T) {
 }
 
 func TestCompleter(t *testing.T) {
-	r := New()
+	r := New(py.NewContext(py.ContextOpts{}))
 	rt := &replTest{}
 	r.SetUI(rt)
 
@@ -17,6 +17,7 @@ import (
 	// import required modules
 	_ "github.com/go-python/gpython/builtin"
 	_ "github.com/go-python/gpython/math"
+	"github.com/go-python/gpython/py"
 	"github.com/go-python/gpython/repl"
 	_ "github.com/go-python/gpython/sys"
 	_ "github.com/go-python/gpython/time"
@@ -77,7 +78,7 @@ func main() {
 	node.Get("classList").Call("add", "active")
 
 	// Make a repl referring to an empty term for the moment
-	REPL := repl.New()
+	REPL := repl.New(py.NewContext(py.ContextOpts{}))
 	cb := js.NewCallback(func(args []js.Value) {
 		REPL.Run(args[0].String())
 	})
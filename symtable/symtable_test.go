@@ -154,14 +154,14 @@ func TestSymTable(t *testing.T) {
 				if msg != test.errString {
 					t.Errorf("%s: want exception text %q got %q", test.in, test.errString, msg)
 				}
-				if lineno, ok := exc.Dict["lineno"]; ok {
+				if lineno, ok := exc.Dict.Lookup("lineno"); ok {
 					if lineno.(py.Int) == 0 {
 						t.Errorf("%s: lineno not set in exception: %v", test.in, exc.Dict)
 					}
 				} else {
 					t.Errorf("%s: lineno not found in exception: %v", test.in, exc.Dict)
 				}
-				if filename, ok := exc.Dict["filename"]; ok {
+				if filename, ok := exc.Dict.Lookup("filename"); ok {
 					if filename.(py.String) == py.String("") {
 						t.Errorf("%s: filename not set in exception: %v", test.in, exc.Dict)
 					}
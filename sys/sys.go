@@ -18,7 +18,7 @@
 package sys
 
 import (
-	"os"
+	"io"
 
 	"github.com/go-python/gpython/py"
 )
@@ -97,7 +97,25 @@ const displayhook_doc = `displayhook(object) -> None
 Print an object to sys.stdout and also save it in builtins._`
 
 func sys_displayhook(self, o py.Object) (py.Object, error) {
-	return nil, py.NotImplementedError
+	if o == py.None {
+		return py.None, nil
+	}
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	// Set '_' to None first to avoid recursion
+	ctx.Builtins.Globals.Set("_", py.None)
+	repr, err := py.ReprAsString(o)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(ctx.Stdout(), repr+"\n")
+	if err != nil {
+		return nil, err
+	}
+	ctx.Builtins.Globals.Set("_", o)
+	return py.None, nil
 }
 
 const excepthook_doc = `excepthook(exctype, value, traceback) -> None
@@ -105,7 +123,27 @@ const excepthook_doc = `excepthook(exctype, value, traceback) -> None
 Handle an exception by displaying it with a traceback on sys.stderr.`
 
 func sys_excepthook(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
+	var excType, value, traceback py.Object
+	err := py.UnpackTuple(args, nil, "excepthook", 3, 3, &excType, &value, &traceback)
+	if err != nil {
+		return nil, err
+	}
+	exc := py.ExceptionInfo{Value: value}
+	var ok bool
+	if exc.Type, ok = excType.(*py.Type); !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "excepthook() argument 1 must be type, not %s", excType.Type().Name)
+	}
+	if traceback != py.None {
+		if exc.Traceback, ok = traceback.(*py.Traceback); !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "excepthook() argument 3 must be traceback, not %s", traceback.Type().Name)
+		}
+	}
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	exc.TracebackDump(ctx.Stderr())
+	return py.None, nil
 }
 
 const exc_info_doc = `exc_info() -> (type, value, traceback)
@@ -332,19 +370,20 @@ stack and crashing Python.  The highest possible limit is platform-
 dependent.`
 
 func sys_setrecursionlimit(self py.Object, args py.Tuple) (py.Object, error) {
-	// int new_limit;
-	// if (!PyArg_ParseTuple(args, "i:setrecursionlimit", &new_limit)) {
-	//     return nil;
-	// }
-	// if (new_limit <= 0) {
-	//     PyErr_SetString(PyExc_ValueError,
-	//                     "recursion limit must be positive");
-	//     return nil;
-	// }
-	// Py_SetRecursionLimit(new_limit);
-	// Py_INCREF(Py_None);
-	// return Py_None;
-	return nil, py.NotImplementedError
+	var newLimit py.Object
+	err := py.ParseTuple(args, "i:setrecursionlimit", &newLimit)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.SetRecursionLimit(int(newLimit.(py.Int)))
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
 }
 
 const hash_info_doc = `hash_info
@@ -402,8 +441,11 @@ of the Python interpreter stack.  This limit prevents infinite
 recursion from causing an overflow of the C stack and crashing Python.`
 
 func sys_getrecursionlimit(self py.Object) (py.Object, error) {
-	// return PyLong_FromLong(Py_GetRecursionLimit());
-	return nil, py.NotImplementedError
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(ctx.RecursionLimit()), nil
 }
 
 const getsizeof_doc = `getsizeof(object, default) -> int
@@ -652,145 +694,157 @@ func init() {
 		py.MustNewMethod("call_tracing", sys_call_tracing, 0, call_tracing_doc),
 		py.MustNewMethod("_debugmallocstats", sys_debugmallocstats, 0, debugmallocstats_doc),
 	}
-	argv := MakeArgv(os.Args[1:])
-	stdin, stdout, stderr := &py.File{os.Stdin, py.FileRead},
-		&py.File{os.Stdout, py.FileWrite},
-		&py.File{os.Stderr, py.FileWrite}
-	globals := py.StringDict{
-		"argv":       argv,
-		"stdin":      stdin,
-		"stdout":     stdout,
-		"stderr":     stderr,
-		"__stdin__":  stdin,
-		"__stdout__": stdout,
-		"__stderr__": stderr,
-		//"version": py.Int(MARSHAL_VERSION),
-		//     /* stdin/stdout/stderr are now set by pythonrun.c */
-
-		//     PyDict_SetItemString(sysdict, "__displayhook__",
-		//                          PyDict_GetItemString(sysdict, "displayhook"));
-		//     PyDict_SetItemString(sysdict, "__excepthook__",
-		//                          PyDict_GetItemString(sysdict, "excepthook"));
-		//     SET_SYS_FROM_STRING("version",
-		//                         PyUnicode_FromString(Py_GetVersion()));
-		//     SET_SYS_FROM_STRING("hexversion",
-		//                         PyLong_FromLong(PY_VERSION_HEX));
-		//     SET_SYS_FROM_STRING("_mercurial",
-		//                         Py_BuildValue("(szz)", "CPython", _Py_hgidentifier(),
-		//                                       _Py_hgversion()));
-		//     SET_SYS_FROM_STRING("dont_write_bytecode",
-		//                         PyBool_FromLong(Py_DontWriteBytecodeFlag));
-		//     SET_SYS_FROM_STRING("api_version",
-		//                         PyLong_FromLong(PYTHON_API_VERSION));
-		//     SET_SYS_FROM_STRING("copyright",
-		//                         PyUnicode_FromString(Py_GetCopyright()));
-		//     SET_SYS_FROM_STRING("platform",
-		//                         PyUnicode_FromString(Py_GetPlatform()));
-		//     SET_SYS_FROM_STRING("executable",
-		//                         PyUnicode_FromWideChar(
-		//                             Py_GetProgramFullPath(), -1));
-		//     SET_SYS_FROM_STRING("prefix",
-		//                         PyUnicode_FromWideChar(Py_GetPrefix(), -1));
-		//     SET_SYS_FROM_STRING("exec_prefix",
-		//                         PyUnicode_FromWideChar(Py_GetExecPrefix(), -1));
-		//     SET_SYS_FROM_STRING("base_prefix",
-		//                         PyUnicode_FromWideChar(Py_GetPrefix(), -1));
-		//     SET_SYS_FROM_STRING("base_exec_prefix",
-		//                         PyUnicode_FromWideChar(Py_GetExecPrefix(), -1));
-		//     SET_SYS_FROM_STRING("maxsize",
-		//                         PyLong_FromSsize_t(PY_SSIZE_T_MAX));
-		//     SET_SYS_FROM_STRING("float_info",
-		//                         PyFloat_GetInfo());
-		//     SET_SYS_FROM_STRING("int_info",
-		//                         PyLong_GetInfo());
-		//     /* initialize hash_info */
-		//     if (Hash_InfoType.tp_name == 0) {
-		//         PyStructSequence_InitType(&Hash_InfoType, &hash_info_desc);
-		//     }
-		//     SET_SYS_FROM_STRING("hash_info",
-		//                         get_hash_info());
-		//     SET_SYS_FROM_STRING("maxunicode",
-		//                         PyLong_FromLong(0x10FFFF));
-		//     SET_SYS_FROM_STRING("builtin_module_names",
-		//                         list_builtin_module_names());
-		//     {
-		//         /* Assumes that longs are at least 2 bytes long.
-		//            Should be safe! */
-		//         unsigned long number = 1;
-		//         char *value;
-
-		//         s = (char *) &number;
-		//         if (s[0] == 0) {
-		//             value = "big";
-		//         } else {
-		//             value = "little";
-		//         }
-		//         SET_SYS_FROM_STRING("byteorder",
-		//                             PyUnicode_FromString(value));
-		//     }
-		// #ifdef MS_COREDLL
-		//     SET_SYS_FROM_STRING("dllhandle",
-		//                         PyLong_FromVoidPtr(PyWin_DLLhModule));
-		//     SET_SYS_FROM_STRING("winver",
-		//                         PyUnicode_FromString(PyWin_DLLVersionString));
-		// #endif
-		// #ifdef ABIFLAGS
-		//     SET_SYS_FROM_STRING("abiflags",
-		//                         PyUnicode_FromString(ABIFLAGS));
-		// #endif
-		//     if (warnoptions == nil) {
-		//         warnoptions = PyList_New(0);
-		//     } else {
-		//         Py_INCREF(warnoptions);
-		//     }
-		//     if (warnoptions != nil) {
-		//         PyDict_SetItemString(sysdict, "warnoptions", warnoptions);
-		//     }
-
-		//     v = get_xoptions();
-		//     if (v != nil) {
-		//         PyDict_SetItemString(sysdict, "_xoptions", v);
-		//     }
-
-		//     /* version_info */
-		//     if (VersionInfoType.tp_name == 0) {
-		//         PyStructSequence_InitType(&VersionInfoType, &version_info_desc);
-		//     }
-		//     version_info = make_version_info();
-		//     SET_SYS_FROM_STRING("version_info", version_info);
-		//     /* prevent user from creating new instances */
-		//     VersionInfoType.tp_init = nil;
-		//     VersionInfoType.tp_new = nil;
-
-		//     /* implementation */
-		//     SET_SYS_FROM_STRING("implementation", make_impl_info(version_info));
-
-		//     /* flags */
-		//     if (FlagsType.tp_name == 0) {
-		//         PyStructSequence_InitType(&FlagsType, &flags_desc);
-		//     }
-		//     SET_SYS_FROM_STRING("flags", make_flags());
-		//     /* prevent user from creating new instances */
-		//     FlagsType.tp_init = nil;
-		//     FlagsType.tp_new = nil;
-
-		//     /* float repr style: 0.03 (short) vs 0.029999999999999999 (legacy) */
-		// #ifndef PY_NO_SHORT_FLOAT_REPR
-		//     SET_SYS_FROM_STRING("float_repr_style",
-		//                         PyUnicode_FromString("short"));
-		// #else
-		//     SET_SYS_FROM_STRING("float_repr_style",
-		//                         PyUnicode_FromString("legacy"));
-		// #endif
-
-		// #ifdef WITH_THREAD
-		//     SET_SYS_FROM_STRING("thread_info", PyThread_GetInfo());
-		// #endif
-	}
-	py.NewModule("sys", module_doc, methods, globals)
+	globals := py.NewStringDict()
+	// argv, stdin, stdout, stderr and friends are set
+	// per interpreter in initContext
+	//"version": py.Int(MARSHAL_VERSION),
+	//     /* stdin/stdout/stderr are now set by pythonrun.c */
+
+	//     PyDict_SetItemString(sysdict, "__displayhook__",
+	//                          PyDict_GetItemString(sysdict, "displayhook"));
+	//     PyDict_SetItemString(sysdict, "__excepthook__",
+	//                          PyDict_GetItemString(sysdict, "excepthook"));
+	//     SET_SYS_FROM_STRING("version",
+	//                         PyUnicode_FromString(Py_GetVersion()));
+	//     SET_SYS_FROM_STRING("hexversion",
+	//                         PyLong_FromLong(PY_VERSION_HEX));
+	//     SET_SYS_FROM_STRING("_mercurial",
+	//                         Py_BuildValue("(szz)", "CPython", _Py_hgidentifier(),
+	//                                       _Py_hgversion()));
+	//     SET_SYS_FROM_STRING("dont_write_bytecode",
+	//                         PyBool_FromLong(Py_DontWriteBytecodeFlag));
+	//     SET_SYS_FROM_STRING("api_version",
+	//                         PyLong_FromLong(PYTHON_API_VERSION));
+	//     SET_SYS_FROM_STRING("copyright",
+	//                         PyUnicode_FromString(Py_GetCopyright()));
+	//     SET_SYS_FROM_STRING("platform",
+	//                         PyUnicode_FromString(Py_GetPlatform()));
+	//     SET_SYS_FROM_STRING("executable",
+	//                         PyUnicode_FromWideChar(
+	//                             Py_GetProgramFullPath(), -1));
+	//     SET_SYS_FROM_STRING("prefix",
+	//                         PyUnicode_FromWideChar(Py_GetPrefix(), -1));
+	//     SET_SYS_FROM_STRING("exec_prefix",
+	//                         PyUnicode_FromWideChar(Py_GetExecPrefix(), -1));
+	//     SET_SYS_FROM_STRING("base_prefix",
+	//                         PyUnicode_FromWideChar(Py_GetPrefix(), -1));
+	//     SET_SYS_FROM_STRING("base_exec_prefix",
+	//                         PyUnicode_FromWideChar(Py_GetExecPrefix(), -1));
+	//     SET_SYS_FROM_STRING("maxsize",
+	//                         PyLong_FromSsize_t(PY_SSIZE_T_MAX));
+	//     SET_SYS_FROM_STRING("float_info",
+	//                         PyFloat_GetInfo());
+	//     SET_SYS_FROM_STRING("int_info",
+	//                         PyLong_GetInfo());
+	//     /* initialize hash_info */
+	//     if (Hash_InfoType.tp_name == 0) {
+	//         PyStructSequence_InitType(&Hash_InfoType, &hash_info_desc);
+	//     }
+	//     SET_SYS_FROM_STRING("hash_info",
+	//                         get_hash_info());
+	//     SET_SYS_FROM_STRING("maxunicode",
+	//                         PyLong_FromLong(0x10FFFF));
+	//     SET_SYS_FROM_STRING("builtin_module_names",
+	//                         list_builtin_module_names());
+	//     {
+	//         /* Assumes that longs are at least 2 bytes long.
+	//            Should be safe! */
+	//         unsigned long number = 1;
+	//         char *value;
+
+	//         s = (char *) &number;
+	//         if (s[0] == 0) {
+	//             value = "big";
+	//         } else {
+	//             value = "little";
+	//         }
+	//         SET_SYS_FROM_STRING("byteorder",
+	//                             PyUnicode_FromString(value));
+	//     }
+	// #ifdef MS_COREDLL
+	//     SET_SYS_FROM_STRING("dllhandle",
+	//                         PyLong_FromVoidPtr(PyWin_DLLhModule));
+	//     SET_SYS_FROM_STRING("winver",
+	//                         PyUnicode_FromString(PyWin_DLLVersionString));
+	// #endif
+	// #ifdef ABIFLAGS
+	//     SET_SYS_FROM_STRING("abiflags",
+	//                         PyUnicode_FromString(ABIFLAGS));
+	// #endif
+	//     if (warnoptions == nil) {
+	//         warnoptions = PyList_New(0);
+	//     } else {
+	//         Py_INCREF(warnoptions);
+	//     }
+	//     if (warnoptions != nil) {
+	//         PyDict_SetItemString(sysdict, "warnoptions", warnoptions);
+	//     }
+
+	//     v = get_xoptions();
+	//     if (v != nil) {
+	//         PyDict_SetItemString(sysdict, "_xoptions", v);
+	//     }
+
+	//     /* version_info */
+	//     if (VersionInfoType.tp_name == 0) {
+	//         PyStructSequence_InitType(&VersionInfoType, &version_info_desc);
+	//     }
+	//     version_info = make_version_info();
+	//     SET_SYS_FROM_STRING("version_info", version_info);
+	//     /* prevent user from creating new instances */
+	//     VersionInfoType.tp_init = nil;
+	//     VersionInfoType.tp_new = nil;
+
+	//     /* implementation */
+	//     SET_SYS_FROM_STRING("implementation", make_impl_info(version_info));
+
+	//     /* flags */
+	//     if (FlagsType.tp_name == 0) {
+	//         PyStructSequence_InitType(&FlagsType, &flags_desc);
+	//     }
+	//     SET_SYS_FROM_STRING("flags", make_flags());
+	//     /* prevent user from creating new instances */
+	//     FlagsType.tp_init = nil;
+	//     FlagsType.tp_new = nil;
+
+	//     /* float repr style: 0.03 (short) vs 0.029999999999999999 (legacy) */
+	// #ifndef PY_NO_SHORT_FLOAT_REPR
+	//     SET_SYS_FROM_STRING("float_repr_style",
+	//                         PyUnicode_FromString("short"));
+	// #else
+	//     SET_SYS_FROM_STRING("float_repr_style",
+	//                         PyUnicode_FromString("legacy"));
+	// #endif
+
+	// #ifdef WITH_THREAD
+	//     SET_SYS_FROM_STRING("thread_info", PyThread_GetInfo());
+	// #endif
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "sys",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+		Init:    initContext,
+	})
+}
+
+// Sets up the parts of the sys module which belong to a single
+// interpreter
+func initContext(m *py.Module) error {
+	opts := m.Context.Opts
+	m.Globals.Set("argv", MakeArgv(opts.Argv))
+	m.Globals.Set("path", MakeArgv(m.Context.DefaultPath()))
+	m.Globals.Set("modules", m.Context.Modules())
+	m.Globals.Set("meta_path", py.NewListFromItems(m.Context.DefaultMetaPath()))
+	m.Globals.Set("path_hooks", py.NewListFromItems(m.Context.DefaultPathHooks()))
+	m.Globals.Set("path_importer_cache", py.NewStringDict())
+	m.Globals.Set("dont_write_bytecode", py.NewBool(opts.DontWriteBytecode))
+	m.Globals.Set("__displayhook__", m.Globals.Get("displayhook"))
+	m.Globals.Set("__excepthook__", m.Globals.Get("excepthook"))
+	return m.Context.SetStdio(opts.Stdin, opts.Stdout, opts.Stderr)
 }
 
-// Makes an argv into a tuple
+// Makes an argv or a path into a list
 func MakeArgv(pyargs []string) py.Object {
 	argv := py.NewListSized(len(pyargs))
 	for i, v := range pyargs {
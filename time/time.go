@@ -141,7 +141,19 @@ func time_sleep(self py.Object, args py.Tuple) (py.Object, error) {
 	if secs < 0 {
 		return nil, py.ExceptionNewf(py.ValueError, "sleep length must be non-negative")
 	}
-	time.Sleep(time.Duration(secs * 1e9))
+	// Sleep, waking up early if the interpreter is cancelled
+	ctx, err := py.ModuleContext(self)
+	if err != nil {
+		return nil, err
+	}
+	goCtx := ctx.GoContext()
+	timer := time.NewTimer(time.Duration(secs * 1e9))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-goCtx.Done():
+		return nil, py.ExceptionNewf(py.CancelledError, "%v", goCtx.Err())
+	}
 	return py.None, nil
 }
 
@@ -997,10 +1009,13 @@ func init() {
 		py.MustNewMethod("perf_counter", time_perf_counter, 0, perf_counter_doc),
 		py.MustNewMethod("get_clock_info", time_get_clock_info, 0, get_clock_info_doc),
 	}
-	globals := py.StringDict{
-		//"version": py.Int(MARSHAL_VERSION),
-	}
-	py.NewModule("time", module_doc, methods, globals)
+	globals := py.NewStringDict()
+	py.RegisterModule(&py.ModuleImpl{
+		Name:    "time",
+		Doc:     module_doc,
+		Methods: methods,
+		Globals: globals,
+	})
 
 }
 
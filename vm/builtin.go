@@ -12,13 +12,17 @@ import (
 	"github.com/go-python/gpython/py"
 )
 
-func builtinEvalOrExec(self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict, mode string) (py.Object, error) {
+func builtinEvalOrExec(ctx *py.Context, self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict, mode string) (py.Object, error) {
 	var (
 		cmd     py.Object
 		globals py.Object = py.None
 		locals  py.Object = py.None
 	)
-	err := py.UnpackTuple(args, kwargs, mode, 1, 3, &cmd, &globals, &locals)
+	err := ctx.CheckBuiltin(mode)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, kwargs, mode, 1, 3, &cmd, &globals, &locals)
 	if err != nil {
 		return nil, err
 	}
@@ -41,8 +45,8 @@ func builtinEvalOrExec(self py.Object, args py.Tuple, kwargs, currentLocals, cur
 	}
 
 	// Set __builtins__ if not set
-	if _, ok := globalsDict["__builtins__"]; !ok {
-		globalsDict["__builtins__"] = builtins
+	if _, ok := globalsDict.Lookup("__builtins__"); !ok {
+		globalsDict.Set("__builtins__", builtins)
 	}
 
 	var codeStr string
@@ -69,15 +73,15 @@ func builtinEvalOrExec(self py.Object, args py.Tuple, kwargs, currentLocals, cur
 	if code.GetNumFree() > 0 {
 		return nil, py.ExceptionNewf(py.TypeError, "code passed to %s() may not contain free variables", mode)
 	}
-	return EvalCode(code, globalsDict, localsDict)
+	return EvalCode(ctx, code, globalsDict, localsDict)
 }
 
-func builtinEval(self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict) (py.Object, error) {
-	return builtinEvalOrExec(self, args, kwargs, currentLocals, currentGlobals, builtins, "eval")
+func builtinEval(ctx *py.Context, self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict) (py.Object, error) {
+	return builtinEvalOrExec(ctx, self, args, kwargs, currentLocals, currentGlobals, builtins, "eval")
 }
 
-func builtinExec(self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict) (py.Object, error) {
-	_, err := builtinEvalOrExec(self, args, kwargs, currentLocals, currentGlobals, builtins, "exec")
+func builtinExec(ctx *py.Context, self py.Object, args py.Tuple, kwargs, currentLocals, currentGlobals, builtins py.StringDict) (py.Object, error) {
+	_, err := builtinEvalOrExec(ctx, self, args, kwargs, currentLocals, currentGlobals, builtins, "exec")
 	if err != nil {
 		return nil, err
 	}
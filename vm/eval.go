@@ -21,8 +21,8 @@ objects so they can be GCed
 */
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"runtime/debug"
 	"strings"
 
@@ -445,31 +445,37 @@ func do_DELETE_SUBSCR(vm *Vm, arg int32) error {
 
 // Miscellaneous opcodes.
 
-// PrintExpr controls where the output of PRINT_EXPR goes which is
-// used in the REPL
-var PrintExpr = func(out string) {
-	_, _ = os.Stdout.WriteString(out + "\n")
-}
-
 // Implements the expression statement for the interactive mode. TOS
 // is removed from the stack and printed. In non-interactive mode, an
 // expression statement is terminated with POP_STACK.
 func do_PRINT_EXPR(vm *Vm, arg int32) error {
-	// FIXME this should be calling sys.displayhook
+	value := vm.POP()
+	ctx := vm.frame.Context
+	if ctx.PrintExpr == nil {
+		sys, err := ctx.GetModule("sys")
+		if err != nil {
+			return err
+		}
+		displayhook, ok := sys.Globals.Lookup("displayhook")
+		if !ok {
+			return py.ExceptionNewf(py.RuntimeError, "lost sys.displayhook")
+		}
+		_, err = py.Call(displayhook, py.Tuple{value}, nil)
+		return err
+	}
 
 	// Print value except if None
 	// After printing, also assign to '_'
 	// Before, set '_' to None to avoid recursion
-	value := vm.POP()
-	vm.frame.Globals["_"] = py.None
+	vm.frame.Globals.Set("_", py.None)
 	if value != py.None {
 		repr, err := py.Repr(value)
 		if err != nil {
 			return err
 		}
-		PrintExpr(fmt.Sprint(repr))
+		ctx.PrintExpr(fmt.Sprint(repr))
 	}
-	vm.frame.Globals["_"] = value
+	vm.frame.Globals.Set("_", value)
 	return nil
 }
 
@@ -565,8 +571,7 @@ func do_UNPACK_EX(vm *Vm, counts int32) error {
 func do_SET_ADD(vm *Vm, i int32) error {
 	w := vm.POP()
 	v := vm.PEEK(int(i))
-	v.(*py.Set).Add(w)
-	return nil
+	return v.(*py.Set).Add(w)
 }
 
 // Calls list.append(TOS[-i], TOS). Used to implement list
@@ -586,12 +591,11 @@ func do_MAP_ADD(vm *Vm, i int32) error {
 	value := vm.SECOND()
 	vm.DROPN(2)
 	dictObj := vm.PEEK(int(i))
-	dict, err := py.DictCheckExact(dictObj)
-	if err != nil {
-		return err
+	dict, ok := dictObj.(*py.Dict)
+	if !ok {
+		return py.ExceptionNewf(py.SystemError, "MAP_ADD needs a dict not %s", dictObj.Type().Name)
 	}
-	_, err = dict.M__setitem__(key, value)
-	return err
+	return dict.SetItem(key, value)
 }
 
 // Returns with TOS to the caller of the function.
@@ -649,7 +653,7 @@ func do_IMPORT_STAR(vm *Vm, arg int32) error {
 	vm.frame.FastToLocals()
 	from := vm.POP()
 	module := from.(*py.Module)
-	if all, ok := module.Globals["__all__"]; ok {
+	if all, ok := module.Globals.Lookup("__all__"); ok {
 		var loopErr error
 		iterErr := py.Iterate(all, func(item py.Object) bool {
 			name, err := py.AttributeName(item)
@@ -657,11 +661,12 @@ func do_IMPORT_STAR(vm *Vm, arg int32) error {
 				loopErr = err
 				return true
 			}
-			vm.frame.Locals[name], err = py.GetAttrString(module, name)
+			value, err := py.GetAttrString(module, name)
 			if err != nil {
 				loopErr = err
 				return true
 			}
+			vm.frame.Locals.Set(name, value)
 			return false
 		})
 		if iterErr != nil {
@@ -671,9 +676,10 @@ func do_IMPORT_STAR(vm *Vm, arg int32) error {
 			return loopErr
 		}
 	} else {
-		for name, value := range module.Globals {
+		for _, name := range module.Globals.StringKeys() {
+			value := module.Globals.Get(name)
 			if !strings.HasPrefix(name, "_") {
-				vm.frame.Locals[name] = value
+				vm.frame.Locals.Set(name, value)
 			}
 		}
 	}
@@ -767,7 +773,7 @@ func do_END_FINALLY(vm *Vm, arg int32) error {
 // Loads the __build_class__ helper function to the stack which
 // creates a new class object.
 func do_LOAD_BUILD_CLASS(vm *Vm, arg int32) error {
-	vm.PUSH(py.Builtins.Globals["__build_class__"])
+	vm.PUSH(vm.frame.Builtins.Get("__build_class__"))
 	return nil
 }
 
@@ -890,7 +896,7 @@ func do_STORE_NAME(vm *Vm, namei int32) error {
 	if debugging {
 		debugf("STORE_NAME %v\n", vm.frame.Code.Names[namei])
 	}
-	vm.frame.Locals[vm.frame.Code.Names[namei]] = vm.POP()
+	vm.frame.Locals.Set(vm.frame.Code.Names[namei], vm.POP())
 	return nil
 }
 
@@ -898,10 +904,10 @@ func do_STORE_NAME(vm *Vm, namei int32) error {
 // attribute of the code object.
 func do_DELETE_NAME(vm *Vm, namei int32) error {
 	name := vm.frame.Code.Names[namei]
-	if _, ok := vm.frame.Locals[name]; !ok {
+	if _, ok := vm.frame.Locals.Lookup(name); !ok {
 		return py.ExceptionNewf(py.NameError, nameErrorMsg, name)
 	} else {
-		delete(vm.frame.Locals, name)
+		vm.frame.Locals.Delete(name)
 	}
 	return nil
 }
@@ -930,7 +936,11 @@ func do_STORE_ATTR(vm *Vm, namei int32) error {
 	v := vm.TOP()
 	u := vm.SECOND()
 	vm.DROPN(2)
-	_, err := py.SetAttrString(v, w, u) /* v.w = u */
+	err := vm.frame.Context.CheckAttr(w)
+	if err != nil {
+		return err
+	}
+	_, err = py.SetAttrString(v, w, u) /* v.w = u */
 	if err != nil {
 		return err
 	}
@@ -939,22 +949,26 @@ func do_STORE_ATTR(vm *Vm, namei int32) error {
 
 // Implements del TOS.name, using namei as index into co_names.
 func do_DELETE_ATTR(vm *Vm, namei int32) error {
-	return py.DeleteAttrString(vm.POP(), vm.frame.Code.Names[namei])
+	name := vm.frame.Code.Names[namei]
+	if err := vm.frame.Context.CheckAttr(name); err != nil {
+		return err
+	}
+	return py.DeleteAttrString(vm.POP(), name)
 }
 
 // Works as STORE_NAME, but stores the name as a global.
 func do_STORE_GLOBAL(vm *Vm, namei int32) error {
-	vm.frame.Globals[vm.frame.Code.Names[namei]] = vm.POP()
+	vm.frame.Globals.Set(vm.frame.Code.Names[namei], vm.POP())
 	return nil
 }
 
 // Works as DELETE_NAME, but deletes a global name.
 func do_DELETE_GLOBAL(vm *Vm, namei int32) error {
 	name := vm.frame.Code.Names[namei]
-	if _, ok := vm.frame.Globals[name]; !ok {
+	if _, ok := vm.frame.Globals.Lookup(name); !ok {
 		return py.ExceptionNewf(py.NameError, nameErrorMsg, name)
 	} else {
-		delete(vm.frame.Globals, name)
+		vm.frame.Globals.Delete(name)
 	}
 	return nil
 }
@@ -993,8 +1007,11 @@ func do_BUILD_TUPLE(vm *Vm, count int32) error {
 
 // Works as BUILD_TUPLE, but creates a set.
 func do_BUILD_SET(vm *Vm, count int32) error {
-	set := py.NewSetFromItems(vm.frame.Stack[len(vm.frame.Stack)-int(count):])
+	set, err := py.NewSetFromItems(vm.frame.Stack[len(vm.frame.Stack)-int(count):])
 	vm.DROPN(int(count))
+	if err != nil {
+		return err
+	}
 	vm.PUSH(set)
 	return nil
 }
@@ -1010,13 +1027,18 @@ func do_BUILD_LIST(vm *Vm, count int32) error {
 // Pushes a new dictionary object onto the stack. The dictionary is
 // pre-sized to hold count entries.
 func do_BUILD_MAP(vm *Vm, count int32) error {
-	vm.PUSH(py.NewStringDictSized(int(count)))
+	vm.PUSH(py.NewDictSized(int(count)))
 	return nil
 }
 
 // Replaces TOS with getattr(TOS, co_names[namei]).
 func do_LOAD_ATTR(vm *Vm, namei int32) error {
-	return vm.setTopAndCheckErr(py.GetAttrString(vm.TOP(), vm.frame.Code.Names[namei]))
+	name := vm.frame.Code.Names[namei]
+	if err := vm.frame.Context.CheckAttr(name); err != nil {
+		return err
+	}
+	obj, err := py.GetAttrString(vm.TOP(), name)
+	return vm.setTopAndCheckErr(py.BindContext(vm.frame.Context, obj), err)
 }
 
 // Performs a Boolean operation. The operation name can be found in
@@ -1081,7 +1103,7 @@ func do_COMPARE_OP(vm *Vm, opname int32) error {
 // STORE_FAST instruction modifies the namespace.
 func do_IMPORT_NAME(vm *Vm, namei int32) error {
 	name := py.String(vm.frame.Code.Names[namei])
-	__import__, ok := vm.frame.Builtins["__import__"]
+	__import__, ok := vm.frame.Builtins.Lookup("__import__")
 	if !ok {
 		return py.ExceptionNewf(py.ImportError, "__import__ not found")
 	}
@@ -1111,6 +1133,9 @@ func do_IMPORT_NAME(vm *Vm, namei int32) error {
 func do_IMPORT_FROM(vm *Vm, namei int32) error {
 	name := vm.frame.Code.Names[namei]
 	module := vm.TOP()
+	if err := vm.frame.Context.CheckAttr(name); err != nil {
+		return err
+	}
 	res, err := py.GetAttrString(module, name)
 	if err != nil {
 		// Catch AttributeError and rethrow as ImportError
@@ -1194,8 +1219,11 @@ func do_JUMP_ABSOLUTE(vm *Vm, target int32) error {
 // iterator indicates it is exhausted TOS is popped, and the bytecode
 // counter is incremented by delta.
 func do_FOR_ITER(vm *Vm, delta int32) error {
-	r, finished := py.Next(vm.TOP())
-	if finished != nil {
+	r, err := py.Next(vm.TOP())
+	if err != nil {
+		if !py.IsException(py.StopIteration, err) {
+			return err
+		}
 		vm.DROP()
 		vm.frame.Lasti += delta
 	} else {
@@ -1247,12 +1275,11 @@ func do_STORE_MAP(vm *Vm, arg int32) error {
 	value := vm.SECOND()
 	dictObj := vm.THIRD()
 	vm.DROPN(2)
-	dict, err := py.DictCheckExact(dictObj)
-	if err != nil {
-		return err
+	dict, ok := dictObj.(*py.Dict)
+	if !ok {
+		return py.ExceptionNewf(py.SystemError, "STORE_MAP needs a dict not %s", dictObj.Type().Name)
 	}
-	_, err = dict.M__setitem__(key, value)
-	return err
+	return dict.SetItem(key, value)
 }
 
 // Pushes a reference to the local co_varnames[var_num] onto the stack.
@@ -1337,7 +1364,7 @@ func do_LOAD_CLASSDEREF(vm *Vm, i int32) error {
 	name, _ := _var_name(vm, i)
 
 	// Lookup in locals
-	if obj, ok := vm.frame.Locals[name]; ok {
+	if obj, ok := vm.frame.Locals.Lookup(name); ok {
 		vm.PUSH(obj)
 	}
 	// If that failed look at the cell
@@ -1435,7 +1462,7 @@ func _make_function(vm *Vm, argc int32, opcode OpCode) {
 	num_annotations := (argc >> 16) & 0x7fff
 	qualname := vm.POP()
 	code := vm.POP()
-	function := py.NewFunction(code.(*py.Code), vm.frame.Globals, string(qualname.(py.String)))
+	function := py.NewFunction(vm.frame.Context, code.(*py.Code), vm.frame.Globals, string(qualname.(py.String)))
 
 	if opcode == MAKE_CLOSURE {
 		function.Closure = vm.POP().(py.Tuple)
@@ -1452,7 +1479,7 @@ func _make_function(vm *Vm, argc int32, opcode OpCode) {
 			name_ix--
 			name := names[name_ix]
 			value := vm.POP()
-			anns[string(name.(py.String))] = value
+			anns.Set(string(name.(py.String)), value)
 		}
 		function.Annotations = anns
 	}
@@ -1462,7 +1489,7 @@ func _make_function(vm *Vm, argc int32, opcode OpCode) {
 		for kwdefaults--; kwdefaults >= 0; kwdefaults-- {
 			v := vm.POP()   // default value
 			key := vm.POP() // kw only arg name
-			defs[string(key.(py.String))] = v
+			defs.Set(string(key.(py.String)), v)
 		}
 		function.KwDefaults = defs
 	}
@@ -1592,13 +1619,13 @@ func callInternal(fn py.Object, args py.Tuple, kwargs py.StringDict, f *py.Frame
 			f.FastToLocals()
 			return f.Locals, nil
 		case py.InternalMethodImport:
-			return py.BuiltinImport(nil, args, kwargs, f.Globals)
+			return py.BuiltinImport(f.Context, nil, args, kwargs, f.Globals)
 		case py.InternalMethodEval:
 			f.FastToLocals()
-			return builtinEval(nil, args, kwargs, f.Locals, f.Globals, f.Builtins)
+			return builtinEval(f.Context, nil, args, kwargs, f.Locals, f.Globals, f.Builtins)
 		case py.InternalMethodExec:
 			f.FastToLocals()
-			return builtinExec(nil, args, kwargs, f.Locals, f.Globals, f.Builtins)
+			return builtinExec(f.Context, nil, args, kwargs, f.Locals, f.Globals, f.Builtins)
 		default:
 			return nil, py.ExceptionNewf(py.SystemError, "Internal method %v not found", x)
 		}
@@ -1646,10 +1673,10 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 			}
 			k := string(kPy)
 			v := kwargsTuple[i+1]
-			if _, ok := kwargs[k]; ok {
+			if _, ok := kwargs.Lookup(k); ok {
 				return py.ExceptionNewf(py.TypeError, multipleValues, EvalGetFuncName(fn), EvalGetFuncDesc(fn), k)
 			}
-			kwargs[k] = v
+			kwargs.Set(k, v)
 		}
 	}
 
@@ -1658,16 +1685,22 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 		if kwargs == nil {
 			kwargs = py.NewStringDict()
 		}
-		// FIXME should be some sort of dictionary iterator...
-		starKwargsDict, ok := starKwargs.(py.StringDict)
-		if !ok {
-			return py.ExceptionNewf(py.SystemError, "FIXME can't use %T as **kwargs", starKwargs)
+		starKwargsDict, err := py.DictAsStringDict(starKwargs)
+		if err != nil {
+			if py.IsException(py.TypeError, err) {
+				if _, ok := starKwargs.(*py.Dict); ok {
+					return py.ExceptionNewf(py.TypeError, "%s%s keywords must be strings", EvalGetFuncName(fn), EvalGetFuncDesc(fn))
+				}
+				return py.ExceptionNewf(py.TypeError, "%s%s argument after ** must be a mapping, not %s", EvalGetFuncName(fn), EvalGetFuncDesc(fn), starKwargs.Type().Name)
+			}
+			return err
 		}
-		for k, v := range starKwargsDict {
-			if _, ok := kwargs[k]; ok {
+		for _, k := range starKwargsDict.StringKeys() {
+			v := starKwargsDict.Get(k)
+			if _, ok := kwargs.Lookup(k); ok {
 				return py.ExceptionNewf(py.TypeError, multipleValues, EvalGetFuncName(fn), EvalGetFuncDesc(fn), k)
 			}
-			kwargs[k] = v
+			kwargs.Set(k, v)
 		}
 	}
 
@@ -1756,6 +1789,11 @@ func RunFrame(frame *py.Frame) (res py.Object, err error) {
 		return nil, py.ExceptionNewf(py.SystemError, "vm: instruction out of range - code most likely finished already")
 	}
 
+	if err = frame.Context.EnterCall(); err != nil {
+		return nil, err
+	}
+	defer frame.Context.LeaveCall()
+
 	var opcode OpCode
 	var arg int32
 	opcodes := frame.Code.Code
@@ -1782,7 +1820,10 @@ func RunFrame(frame *py.Frame) (res py.Object, err error) {
 			}
 		}
 		vm.extended = false
-		err = jumpTable[opcode](&vm, arg)
+		err = frame.Context.CheckInterrupt()
+		if err == nil {
+			err = jumpTable[opcode](&vm, arg)
+		}
 		if err != nil {
 			// FIXME shouldn't be doing this - just use err?
 			if errExcInfo, ok := err.(py.ExceptionInfo); ok {
@@ -1933,6 +1974,14 @@ fast_yield:
 	return vm.retval, nil
 }
 
+// RunFrameContext is as RunFrame but the code is stopped with a
+// py.CancelledError when goCtx is cancelled or its deadline passes
+func RunFrameContext(goCtx context.Context, frame *py.Frame) (res py.Object, err error) {
+	old := frame.Context.SetGoContext(goCtx)
+	defer frame.Context.SetGoContext(old)
+	return RunFrame(frame)
+}
+
 // Chooses trueString if flag is true, falseString otherwise
 func chooseString(flag bool, trueString, falseString string) string {
 	if flag {
@@ -2033,7 +2082,7 @@ func tooManyPositional(co *py.Code, given, defcount int, fastlocals []py.Object)
 		chooseString(given == 1 && kwonly_given == 0, "was", "were"))
 }
 
-func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kws py.StringDict, defs []py.Object, kwdefs py.StringDict, closure py.Tuple) (retval py.Object, err error) {
+func EvalCodeEx(ctx *py.Context, co *py.Code, globals, locals py.StringDict, args []py.Object, kws py.StringDict, defs []py.Object, kwdefs py.StringDict, closure py.Tuple) (retval py.Object, err error) {
 	total_args := int(co.Argcount + co.Kwonlyargcount)
 	n := len(args)
 	var kwdict py.StringDict
@@ -2045,7 +2094,7 @@ func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kw
 	//assert(tstate != nil)
 	//assert(globals != nil)
 	// f = PyFrame_New(tstate, co, globals, locals)
-	f := py.NewFrame(globals, locals, co, closure) // FIXME extra closure parameter?
+	f := py.NewFrame(ctx, globals, locals, co, closure) // FIXME extra closure parameter?
 
 	fastlocals := f.Localsplus
 	freevars := f.CellAndFreeVars
@@ -2072,7 +2121,8 @@ func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kw
 			u[i-n] = args[i]
 		}
 	}
-	for keyword, value := range kws {
+	for _, keyword := range kws.StringKeys() {
+		value := kws.Get(keyword)
 		j := 0
 		for ; j < total_args; j++ {
 			if co.Varnames[j] == keyword {
@@ -2082,7 +2132,7 @@ func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kw
 		if j >= total_args && kwdict == nil {
 			return nil, py.ExceptionNewf(py.TypeError, "%s() got an unexpected keyword argument '%s'", co.Name, keyword)
 		}
-		kwdict[keyword] = value
+		kwdict.Set(keyword, value)
 		continue
 	kw_found:
 		if fastlocals[j] != nil {
@@ -2122,7 +2172,7 @@ func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kw
 			}
 			name := co.Varnames[i]
 			if kwdefs != nil {
-				if def, ok := kwdefs[name]; ok {
+				if def, ok := kwdefs.Lookup(name); ok {
 					fastlocals[i] = def
 					continue
 				}
@@ -2162,8 +2212,16 @@ func EvalCodeEx(co *py.Code, globals, locals py.StringDict, args []py.Object, kw
 	return RunFrame(f)
 }
 
-func EvalCode(co *py.Code, globals, locals py.StringDict) (py.Object, error) {
-	return EvalCodeEx(co,
+// EvalCodeContext is as EvalCode but the code is stopped with a
+// py.CancelledError when goCtx is cancelled or its deadline passes
+func EvalCodeContext(goCtx context.Context, ctx *py.Context, co *py.Code, globals, locals py.StringDict) (py.Object, error) {
+	old := ctx.SetGoContext(goCtx)
+	defer ctx.SetGoContext(old)
+	return EvalCode(ctx, co, globals, locals)
+}
+
+func EvalCode(ctx *py.Context, co *py.Code, globals, locals py.StringDict) (py.Object, error) {
+	return EvalCodeEx(ctx, co,
 		globals, locals,
 		nil,
 		nil,
@@ -2171,15 +2229,15 @@ func EvalCode(co *py.Code, globals, locals py.StringDict) (py.Object, error) {
 		nil, nil)
 }
 
-// Run the virtual machine on a Code object
+// Run the virtual machine on a Code object in the interpreter ctx
 //
 // Any parameters are expected to have been decoded into locals
 //
 // Returns an Object and an error.  The error will be a py.ExceptionInfo
 //
 // This is the equivalent of PyEval_EvalCode with closure support
-func Run(globals, locals py.StringDict, code *py.Code, closure py.Tuple) (res py.Object, err error) {
-	return EvalCodeEx(code,
+func Run(ctx *py.Context, globals, locals py.StringDict, code *py.Code, closure py.Tuple) (res py.Object, err error) {
+	return EvalCodeEx(ctx, code,
 		globals, locals,
 		nil,
 		nil,
@@ -2187,6 +2245,17 @@ func Run(globals, locals py.StringDict, code *py.Code, closure py.Tuple) (res py
 		nil, closure)
 }
 
+// RunContext is as Run but the code is stopped with a
+// py.CancelledError when goCtx is cancelled or its deadline passes.
+//
+// The exception can be caught by the python code, but if the code
+// carries on running it will be raised again shortly afterwards.
+func RunContext(goCtx context.Context, ctx *py.Context, globals, locals py.StringDict, code *py.Code, closure py.Tuple) (res py.Object, err error) {
+	old := ctx.SetGoContext(goCtx)
+	defer ctx.SetGoContext(old)
+	return Run(ctx, globals, locals, code, closure)
+}
+
 // Write the py global to avoid circular import
 func init() {
 	py.VmRun = Run
@@ -60,4 +60,55 @@ assert c.method1(1) == 2
 # c = x()
 # assert c.method1(1) == 2
 
+doc="Ordering methods"
+class V:
+    def __init__(self, v):
+        self.v = v
+    def __lt__(self, other):
+        return self.v < other.v
+    def __le__(self, other):
+        return self.v <= other.v
+    def __gt__(self, other):
+        return self.v > other.v
+    def __ge__(self, other):
+        return self.v >= other.v
+a, b = V(1), V(2)
+assert a < b
+assert a <= b
+assert not a > b
+assert not a >= b
+assert b > a
+assert b >= a
+assert a <= a
+assert a >= a
+
+# The reflected method is used if only one is defined
+class L:
+    def __init__(self, v):
+        self.v = v
+    def __lt__(self, other):
+        return self.v < other.v
+assert L(2) > L(1)
+assert not L(1) > L(2)
+assert [x.v for x in sorted([L(3), L(1), L(2)])] == [1, 2, 3]
+
+class N:
+    pass
+ok = False
+try:
+    N() < N()
+except TypeError:
+    ok = True
+assert ok
+
+class NI:
+    def __lt__(self, other):
+        return NotImplemented
+ok = False
+try:
+    NI() < 1
+except TypeError:
+    ok = True
+assert ok
+
 doc="finished"
@@ -165,4 +165,22 @@ except ValueError:
     ok = True
 assert ok, "ValueError not raised"
 
+doc = "except base class"
+ok = False
+try:
+    raise KeyboardInterrupt
+except BaseException:
+    ok = True
+assert ok, "KeyboardInterrupt not caught by BaseException"
+
+doc = "RecursionError"
+ok = False
+def recurse():
+    recurse()
+try:
+    recurse()
+except RuntimeError:
+    ok = True
+assert ok, "RecursionError not raised"
+
 doc = "finished"
@@ -0,0 +1,170 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+import lib
+
+doc="spec of a file module"
+ModuleSpec = type(lib.__spec__)
+assert lib.__spec__.name == "lib"
+assert lib.__spec__.origin == lib.__file__
+assert lib.__spec__.has_location
+assert lib.__spec__.parent == ""
+assert lib.__spec__.submodule_search_locations is None
+assert lib.__loader__ is lib.__spec__.loader
+assert lib.__loader__ is not None
+
+doc="ModuleSpec"
+spec = ModuleSpec("a.b", None)
+assert spec.name == "a.b"
+assert spec.loader is None
+assert spec.origin is None
+assert spec.parent == "a"
+assert spec.submodule_search_locations is None
+assert not spec.has_location
+assert repr(spec) == "ModuleSpec(name='a.b', loader=None)"
+spec = ModuleSpec("a.b", None, origin="here", is_package=True)
+assert spec.origin == "here"
+assert spec.parent == "a.b"
+assert spec.submodule_search_locations == []
+assert repr(spec) == "ModuleSpec(name='a.b', loader=None, origin='here', submodule_search_locations=[])"
+
+doc="meta_path"
+class Thing:
+    pass
+
+class Finder:
+    def __init__(self):
+        self.calls = []
+    def find_spec(self, name, path, target=None):
+        self.calls.append((name, path))
+        if not name.startswith("virtual"):
+            return None
+        return ModuleSpec(name, self, is_package=(name == "virtualpkg"))
+    def create_module(self, spec):
+        if spec.name == "virtualthing":
+            return Thing()
+        if spec.name == "virtualint":
+            return 1
+        if spec.name == "virtualcreatebad":
+            raise KeyError("create failed")
+        return None
+    def exec_module(self, module):
+        if module.__name__ == "virtualbad":
+            raise ValueError("bad module")
+        module.answer = 42
+
+finder = Finder()
+sys.meta_path.insert(0, finder)
+import virtualmod
+assert virtualmod.answer == 42
+assert virtualmod.__name__ == "virtualmod"
+assert virtualmod.__loader__ is finder
+assert virtualmod.__spec__.name == "virtualmod"
+assert virtualmod.__package__ == ""
+assert sys.modules["virtualmod"] is virtualmod
+assert ("virtualmod", None) in finder.calls
+
+import virtualpkg.child
+assert virtualpkg.__path__ == []
+assert virtualpkg.__package__ == "virtualpkg"
+assert virtualpkg.child.answer == 42
+assert virtualpkg.child.__package__ == "virtualpkg"
+assert ("virtualpkg.child", []) in finder.calls
+
+import virtualthing
+assert isinstance(virtualthing, Thing)
+assert virtualthing.answer == 42
+assert virtualthing.__spec__.name == "virtualthing"
+
+ok = False
+try:
+    import virtualbad
+except ValueError:
+    ok = True
+assert ok
+assert "virtualbad" not in sys.modules
+
+ok = False
+try:
+    import virtualint
+except AttributeError:
+    ok = True
+assert ok
+assert "virtualint" not in sys.modules
+
+ok = False
+try:
+    import virtualcreatebad
+except KeyError:
+    ok = True
+assert ok
+assert "virtualcreatebad" not in sys.modules
+
+class BrokenLoader:
+    def find_spec(self, name, path, target=None):
+        if name != "brokenloader":
+            return None
+        return ModuleSpec(name, self)
+    def __getattr__(self, name):
+        raise KeyError(name)
+    def exec_module(self, module):
+        module.answer = 42
+
+broken = BrokenLoader()
+sys.meta_path.insert(0, broken)
+ok = False
+try:
+    import brokenloader
+except KeyError:
+    ok = True
+assert ok
+assert "brokenloader" not in sys.modules
+sys.meta_path.remove(broken)
+
+# Finders which return None fall through to the path finder
+import lib1
+assert lib1.__spec__.origin == lib1.__file__
+sys.meta_path.remove(finder)
+ok = False
+try:
+    import virtualother
+except ImportError:
+    ok = True
+assert ok
+
+doc="path_hooks"
+class EntryFinder:
+    def __init__(self, entry):
+        self.entry = entry
+    def find_spec(self, name, target=None):
+        if name != "hooked":
+            return None
+        return ModuleSpec(name, self, origin=self.entry)
+    def create_module(self, spec):
+        return None
+    def exec_module(self, module):
+        module.entry = self.entry
+
+def hook(entry):
+    if not entry.startswith("virtual:"):
+        raise ImportError("not virtual")
+    return EntryFinder(entry)
+
+sys.path_hooks.insert(0, hook)
+sys.path.append("virtual:here")
+import hooked
+assert hooked.entry == "virtual:here"
+assert hooked.__spec__.origin == "virtual:here"
+assert not hasattr(hooked, "__file__")
+entry_finder = sys.path_importer_cache["virtual:here"]
+assert isinstance(entry_finder, EntryFinder)
+del sys.modules["hooked"]
+del sys.path_hooks[0]
+import hooked
+assert hooked.entry == "virtual:here"
+sys.path.remove("virtual:here")
+del sys.path_importer_cache["virtual:here"]
+
+doc="finished"
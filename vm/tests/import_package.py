@@ -0,0 +1,87 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="import package"
+import pkg
+assert pkg.NAME == "pkg"
+assert pkg.__package__ == "pkg"
+assert pkg.__name__ == "pkg"
+assert len(pkg.__path__) == 1
+assert pkg.__path__[0].endswith("pkg")
+assert pkg.value1 == 1
+assert pkg.mod1.value1 == 1
+assert pkg.mod1.mod2.value2 == 2
+assert pkg.mod1.value2 == 2
+assert pkg.mod1.__package__ == "pkg"
+assert pkg.mod1.__name__ == "pkg.mod1"
+
+doc="import dotted"
+import pkg.sub.leaf
+assert pkg.sub.leaf.LEAF == "leaf"
+assert pkg.sub.leaf.PACKAGE == "pkg.sub"
+assert pkg.sub.leaf.NAME == "pkg.sub.leaf"
+assert pkg.sub.parent_mod1 is pkg.mod1
+assert pkg.sub.value2 == 2
+import pkg.sub.leaf as leaf
+assert leaf is pkg.sub.leaf
+assert __import__("pkg.sub") is pkg
+assert __import__("pkg.sub", fromlist=["x"]) is pkg.sub
+
+doc="from package import submodule"
+from pkg.sub import lazy
+assert lazy.LAZY
+assert pkg.sub.lazy is lazy
+from pkg.sub import *
+assert deep.DEEP == "deep"
+assert leaf is pkg.sub.leaf
+
+doc="missing"
+ok = False
+try:
+    from pkg.sub import missing
+except ImportError as e:
+    ok = True
+assert ok
+ok = False
+try:
+    import pkg.missing
+except ImportError as e:
+    ok = True
+assert ok
+ok = False
+try:
+    import pkg.mod1.value1
+except ImportError as e:
+    ok = True
+assert ok
+
+doc="errors in submodules"
+ok = False
+try:
+    from pkg.sub import bad
+except ImportError as e:
+    ok = True
+assert ok
+assert not hasattr(pkg.sub, "bad")
+ok = False
+try:
+    import pkg.sub.beyond
+except ImportError as e:
+    ok = True
+assert ok
+
+doc="relative import from a script"
+ok = False
+try:
+    from . import lib
+except ImportError as e:
+    ok = True
+assert ok
+
+doc="namespace package"
+import nspkg.nsmod
+assert nspkg.nsmod.NS == "ns"
+assert nspkg.__path__[0].endswith("nspkg")
+
+doc="finished"
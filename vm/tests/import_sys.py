@@ -0,0 +1,62 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+
+doc="sys.path"
+assert isinstance(sys.path, list)
+assert len(sys.path) > 0
+here = __file__.rpartition("/")[0]
+extra_dir = here + "/pathdir" if here else "pathdir"
+ok = False
+try:
+    import extra
+except ImportError:
+    ok = True
+assert ok
+sys.path.append(extra_dir)
+import extra
+assert extra.EXTRA == "extra"
+sys.path.remove(extra_dir)
+del sys.modules["extra"]
+ok = False
+try:
+    import extra
+except ImportError:
+    ok = True
+assert ok
+sys.path.insert(0, extra_dir)
+import extra
+assert extra.EXTRA == "extra"
+del sys.path[0]
+
+doc="sys.modules"
+assert sys.modules["sys"] is sys
+assert "builtins" in sys.modules
+import lib
+assert sys.modules["lib"] is lib
+del sys.modules["lib"]
+import lib as lib2
+assert lib2 is not lib
+assert lib2.libvar == 43
+assert sys.modules["lib"] is lib2
+
+doc="pre-seeded sys.modules"
+class Fake:
+    value = 42
+sys.modules["fake"] = Fake
+import fake
+assert fake.value == 42
+from fake import value
+assert value == 42
+sys.modules["blocked"] = None
+ok = False
+try:
+    import blocked
+except ImportError:
+    ok = True
+assert ok
+del sys.modules["blocked"]
+
+doc="finished"
@@ -88,4 +88,19 @@ for i in (1,2,3,4,5):
 assert a == 12
 assert ok
 
+doc="For with an iterator raising an exception"
+def gen():
+    yield 1
+    yield 2
+    raise ValueError("broken")
+a = 0
+ok = False
+try:
+    for i in gen():
+        a += i
+except ValueError:
+    ok = True
+assert ok
+assert a == 3
+
 doc="finished"
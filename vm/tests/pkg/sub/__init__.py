@@ -0,0 +1,9 @@
+# Copyright 2018 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from .. import mod1 as parent_mod1
+from ..mod2 import value2
+from . import leaf
+
+__all__ = ["leaf", "deep"]
@@ -5,9 +5,15 @@
 package vm_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/go-python/gpython/compile"
+	"github.com/go-python/gpython/py"
 	"github.com/go-python/gpython/pytest"
+	_ "github.com/go-python/gpython/time"
+	"github.com/go-python/gpython/vm"
 )
 
 func TestVm(t *testing.T) {
@@ -17,3 +23,155 @@ func TestVm(t *testing.T) {
 func BenchmarkVM(b *testing.B) {
 	pytest.RunBenchmarks(b, "benchmarks")
 }
+
+// runCancelled runs src with a short deadline and checks it was
+// stopped with a CancelledError
+func runCancelled(t *testing.T, src string) *py.Module {
+	obj, err := compile.Compile(src, "<string>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	ctx := py.NewContext(py.ContextOpts{})
+	module := ctx.NewModule("__main__", "", nil, nil)
+	goCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = vm.RunContext(goCtx, ctx, module.Globals, module.Globals, obj.(*py.Code), nil)
+	if exc, ok := err.(py.ExceptionInfo); !ok || !py.IsException(py.CancelledError, exc.Value) {
+		t.Fatalf("Want CancelledError got %v", err)
+	}
+	if dt := time.Since(start); dt > 5*time.Second {
+		t.Errorf("Took too long to cancel: %v", dt)
+	}
+	return module
+}
+
+func TestRunContext(t *testing.T) {
+	runCancelled(t, "while True: pass")
+
+	// The exception can be caught but is raised again
+	module := runCancelled(t, `
+caught = False
+try:
+    while True: pass
+except CancelledError:
+    caught = True
+while True: pass
+`)
+	if module.Globals.Get("caught") != py.True {
+		t.Errorf("CancelledError wasn't catchable")
+	}
+
+	// Exception doesn't inherit from Exception
+	module = runCancelled(t, `
+caught = False
+def spin():
+    try:
+        while True: pass
+    except Exception:
+        global caught
+        caught = True
+spin()
+`)
+	if module.Globals.Get("caught") != py.False {
+		t.Errorf("CancelledError was caught by except Exception")
+	}
+
+	// Blocking calls are cancelled too
+	runCancelled(t, "import time\ntime.sleep(60)")
+}
+
+// runLimited runs src in a Context made with opts and returns the
+// module and the error
+func runLimited(t *testing.T, opts py.ContextOpts, src string) (*py.Module, error) {
+	obj, err := compile.Compile(src, "<string>", "exec", 0, true)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	ctx := py.NewContext(opts)
+	module := ctx.NewModule("__main__", "", nil, nil)
+	_, err = vm.Run(ctx, module.Globals, module.Globals, obj.(*py.Code), nil)
+	return module, err
+}
+
+// wantException checks err is an ExceptionInfo holding an exception of type want
+func wantException(t *testing.T, want *py.Type, err error) {
+	t.Helper()
+	if exc, ok := err.(py.ExceptionInfo); !ok || !py.IsException(want, exc.Value) {
+		t.Fatalf("Want %s got %v", want.Name, err)
+	}
+}
+
+func TestInstructionBudget(t *testing.T) {
+	opts := py.ContextOpts{MaxInstructions: 100000}
+
+	_, err := runLimited(t, opts, "while True: pass")
+	wantException(t, py.ResourceError, err)
+
+	// Within budget runs normally
+	module, err := runLimited(t, opts, "n = 0\nfor i in range(100): n += i")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if module.Globals.Get("n") != py.Int(4950) {
+		t.Errorf("Want n = 4950 got %v", module.Globals.Get("n"))
+	}
+
+	// Can be caught once but catching it again doesn't help
+	module, err = runLimited(t, opts, `
+caught = 0
+while True:
+    try:
+        while True: pass
+    except BaseException:
+        caught += 1
+`)
+	wantException(t, py.ResourceError, err)
+	if module.Globals.Get("caught") != py.Int(1) {
+		t.Errorf("Want caught = 1 got %v", module.Globals.Get("caught"))
+	}
+}
+
+func TestRecursionLimit(t *testing.T) {
+	module, err := runLimited(t, py.ContextOpts{RecursionLimit: 50}, `
+import sys
+limit = sys.getrecursionlimit()
+def f(n):
+    return f(n+1)
+try:
+    f(0)
+except RecursionError as e:
+    msg = e.args[0]
+def g(n):
+    if n == 0:
+        return 0
+    return g(n-1) + 1
+sys.setrecursionlimit(200)
+depth = g(150)
+try:
+    sys.setrecursionlimit(0)
+except ValueError:
+    bad = True
+`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for name, want := range map[string]py.Object{
+		"limit": py.Int(50),
+		"msg":   py.String("maximum recursion depth exceeded"),
+		"depth": py.Int(150),
+		"bad":   py.True,
+	} {
+		if got := module.Globals.Get(name); got != want {
+			t.Errorf("%s: want %v got %v", name, want, got)
+		}
+	}
+
+	// Unbounded recursion stops at the limit
+	_, err = runLimited(t, py.ContextOpts{RecursionLimit: 200}, `
+def f(n):
+    return f(n+1)
+f(0)
+`)
+	wantException(t, py.RecursionError, err)
+}